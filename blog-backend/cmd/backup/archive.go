@@ -0,0 +1,532 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：archive.go
+ * 创建时间：2026-10-17 15:10:46
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：备份归档的读写实现，负责按依赖顺序导出/恢复数据表、打包 uploads 目录以及恢复后重置自增序列
+ */
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"blog-backend/db"
+	"blog-backend/util"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// archiveFormatVersion 备份格式版本号，结构不兼容变更时递增
+	archiveFormatVersion = 1
+	// manifestName 归档清单文件名
+	manifestName = "manifest.json"
+	// dataDir 归档中存放数据表 JSON 的目录
+	dataDir = "data"
+	// uploadsDir 归档中存放上传文件的目录
+	uploadsDir = "uploads"
+	// exportBatchSize 导出时每批读取的行数
+	exportBatchSize = 1000
+	// restoreBatchSize 恢复时每批写入的行数
+	restoreBatchSize = 500
+)
+
+// tableSpec 需要备份的数据表定义
+type tableSpec struct {
+	Name  string // 表名
+	HasID bool   // 是否有自增主键 id（有则按 id 分批导出并在恢复后重置序列）
+}
+
+// backupTables 备份的数据表，按外键依赖顺序排列（被引用的表在前），恢复时按此顺序写入
+var backupTables = []tableSpec{
+	{Name: "roles", HasID: true},
+	{Name: "role_permissions"},
+	{Name: "users", HasID: true},
+	{Name: "categories", HasID: true},
+	{Name: "tags", HasID: true},
+	{Name: "posts", HasID: true},
+	{Name: "post_tags"},
+	{Name: "post_revisions", HasID: true},
+	{Name: "comments", HasID: true},
+	{Name: "post_views", HasID: true},
+	{Name: "post_likes", HasID: true},
+	{Name: "settings", HasID: true},
+	{Name: "moments", HasID: true},
+	{Name: "moment_likes", HasID: true},
+	{Name: "ip_blacklist", HasID: true},
+	{Name: "ip_whitelist", HasID: true},
+	{Name: "password_reset_tokens", HasID: true},
+	{Name: "email_change_records", HasID: true},
+	{Name: "user_sessions", HasID: true},
+	{Name: "refresh_tokens", HasID: true},
+	{Name: "user_two_factors"},
+	{Name: "two_factor_recovery_codes", HasID: true},
+	{Name: "personal_access_tokens", HasID: true},
+	{Name: "user_oauth_bindings", HasID: true},
+	{Name: "webauthn_credentials", HasID: true},
+	{Name: "invite_codes", HasID: true},
+	{Name: "account_deletion_requests"},
+	{Name: "login_history", HasID: true},
+	{Name: "chat_rooms", HasID: true},
+	{Name: "chat_messages", HasID: true},
+	{Name: "direct_messages", HasID: true},
+	{Name: "user_blocks", HasID: true},
+	{Name: "friend_link_categories", HasID: true},
+	{Name: "friend_links", HasID: true},
+	{Name: "friend_link_health"},
+	{Name: "friend_link_applications", HasID: true},
+	{Name: "friend_feed_items", HasID: true},
+	{Name: "friend_feed_states"},
+	{Name: "albums", HasID: true},
+	{Name: "operation_logs", HasID: true},
+}
+
+// skippedColumns 不导出的派生列（全文搜索向量），恢复后重新计算
+var skippedColumns = map[string]bool{
+	"search_tsv":  true,
+	"content_tsv": true,
+}
+
+// Manifest 归档清单
+type Manifest struct {
+	FormatVersion int            `json:"format_version"`
+	CreatedAt     time.Time      `json:"created_at"`
+	Tables        []ManifestItem `json:"tables"`
+	UploadFiles   int            `json:"upload_files"`
+}
+
+// ManifestItem 单张表的导出信息
+type ManifestItem struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+	Rows int64  `json:"rows"`
+}
+
+// exportArchive 导出所有数据表（及可选的 uploads 目录）到 zip 归档
+func exportArchive(filePath string, withUploads bool) (*Manifest, error) {
+	out, err := os.Create(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer out.Close()
+
+	zw := zip.NewWriter(out)
+	manifest := &Manifest{
+		FormatVersion: archiveFormatVersion,
+		CreatedAt:     time.Now(),
+	}
+
+	// 在同一个只读事务中导出，保证各表数据处于同一时间点
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SET TRANSACTION ISOLATION LEVEL REPEATABLE READ READ ONLY").Error; err != nil {
+			return err
+		}
+		for _, table := range backupTables {
+			name := path.Join(dataDir, table.Name+".json")
+			w, err := zw.Create(name)
+			if err != nil {
+				return err
+			}
+			rows, err := exportTable(tx, table, w)
+			if err != nil {
+				return fmt.Errorf("导出表 %s 失败: %w", table.Name, err)
+			}
+			manifest.Tables = append(manifest.Tables, ManifestItem{Name: table.Name, File: name, Rows: rows})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if withUploads {
+		count, err := exportUploads(zw)
+		if err != nil {
+			return nil, fmt.Errorf("打包上传目录失败: %w", err)
+		}
+		manifest.UploadFiles = count
+	}
+
+	w, err := zw.Create(manifestName)
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// exportTable 以 JSON 数组形式流式写出一张表，有 id 的表按 id 分批读取
+func exportTable(tx *gorm.DB, table tableSpec, w io.Writer) (int64, error) {
+	if _, err := io.WriteString(w, "[\n"); err != nil {
+		return 0, err
+	}
+
+	var total int64
+	writeRows := func(rows []map[string]interface{}) error {
+		for _, row := range rows {
+			for col := range skippedColumns {
+				delete(row, col)
+			}
+			data, err := json.Marshal(row)
+			if err != nil {
+				return err
+			}
+			if total > 0 {
+				if _, err := io.WriteString(w, ",\n"); err != nil {
+					return err
+				}
+			}
+			if _, err := w.Write(data); err != nil {
+				return err
+			}
+			total++
+		}
+		return nil
+	}
+
+	if table.HasID {
+		var lastID int64
+		for {
+			var rows []map[string]interface{}
+			if err := tx.Table(table.Name).Where("id > ?", lastID).Order("id").Limit(exportBatchSize).Find(&rows).Error; err != nil {
+				return 0, err
+			}
+			if len(rows) == 0 {
+				break
+			}
+			lastID = toInt64(rows[len(rows)-1]["id"])
+			if err := writeRows(rows); err != nil {
+				return 0, err
+			}
+			if len(rows) < exportBatchSize {
+				break
+			}
+		}
+	} else {
+		var rows []map[string]interface{}
+		if err := tx.Table(table.Name).Find(&rows).Error; err != nil {
+			return 0, err
+		}
+		if err := writeRows(rows); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := io.WriteString(w, "\n]\n"); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// exportUploads 将本地 uploads 目录打包进归档
+func exportUploads(zw *zip.Writer) (int, error) {
+	if _, err := os.Stat(util.UploadDir); os.IsNotExist(err) {
+		return 0, nil
+	}
+
+	count := 0
+	err := filepath.Walk(util.UploadDir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(util.UploadDir, p)
+		if err != nil {
+			return err
+		}
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = path.Join(uploadsDir, filepath.ToSlash(rel))
+		header.Method = zip.Deflate
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if _, err := io.Copy(w, f); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// restoreArchive 将归档恢复到数据库（及可选的 uploads 目录）
+// 所有表在同一个事务中写入，任一步失败都会整体回滚
+func restoreArchive(filePath string, withUploads, truncate bool) (*Manifest, error) {
+	zr, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	manifest, err := readManifest(files[manifestName])
+	if err != nil {
+		return nil, err
+	}
+	if manifest.FormatVersion > archiveFormatVersion {
+		return nil, fmt.Errorf("备份格式版本 %d 高于当前工具支持的版本 %d，请升级后再恢复", manifest.FormatVersion, archiveFormatVersion)
+	}
+
+	expected := make(map[string]ManifestItem, len(manifest.Tables))
+	for _, item := range manifest.Tables {
+		expected[item.Name] = item
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if truncate {
+			names := make([]string, 0, len(backupTables))
+			for _, table := range backupTables {
+				names = append(names, table.Name)
+			}
+			if err := tx.Exec("TRUNCATE TABLE " + strings.Join(names, ", ") + " RESTART IDENTITY CASCADE").Error; err != nil {
+				return err
+			}
+		} else if err := ensureEmpty(tx); err != nil {
+			return err
+		}
+
+		for _, table := range backupTables {
+			item, ok := expected[table.Name]
+			if !ok {
+				// 旧版本备份中不存在的表直接跳过
+				continue
+			}
+			rows, err := restoreTable(tx, table, files[item.File])
+			if err != nil {
+				return fmt.Errorf("恢复表 %s 失败: %w", table.Name, err)
+			}
+			if rows != item.Rows {
+				return fmt.Errorf("表 %s 行数不一致：清单 %d 行，实际写入 %d 行", table.Name, item.Rows, rows)
+			}
+			if table.HasID {
+				if err := resetSequence(tx, table.Name); err != nil {
+					return fmt.Errorf("重置表 %s 的序列失败: %w", table.Name, err)
+				}
+			}
+		}
+
+		// 重新计算全文搜索向量（与 init.sql 保持一致）
+		if err := tx.Exec("UPDATE posts SET search_tsv = setweight(to_tsvector('english', coalesce(title, '')), 'A') || setweight(to_tsvector('english', coalesce(content, '')), 'B')").Error; err != nil {
+			return err
+		}
+		return tx.Exec("UPDATE moments SET content_tsv = to_tsvector('english', content)").Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if withUploads {
+		if err := restoreUploads(zr.File); err != nil {
+			return nil, fmt.Errorf("恢复上传目录失败: %w", err)
+		}
+	}
+
+	return manifest, nil
+}
+
+// readManifest 读取归档清单
+func readManifest(f *zip.File) (*Manifest, error) {
+	if f == nil {
+		return nil, errors.New("归档中缺少 manifest.json，不是有效的备份文件")
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var manifest Manifest
+	if err := json.NewDecoder(rc).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("解析 manifest.json 失败: %w", err)
+	}
+	return &manifest, nil
+}
+
+// ensureEmpty 检查目标表是否为空，避免与已有数据发生主键冲突
+func ensureEmpty(tx *gorm.DB) error {
+	nonEmpty := make([]string, 0)
+	for _, table := range backupTables {
+		var count int64
+		if err := tx.Table(table.Name).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			nonEmpty = append(nonEmpty, fmt.Sprintf("%s(%d)", table.Name, count))
+		}
+	}
+	if len(nonEmpty) > 0 {
+		return fmt.Errorf("目标数据库不为空：%s，如需覆盖请使用 -truncate", strings.Join(nonEmpty, ", "))
+	}
+	return nil
+}
+
+// restoreTable 流式读取表的 JSON 数组并分批写入，保留原始ID
+func restoreTable(tx *gorm.DB, table tableSpec, f *zip.File) (int64, error) {
+	if f == nil {
+		return 0, errors.New("归档中缺少数据文件")
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	dec := json.NewDecoder(rc)
+	dec.UseNumber()
+	if _, err := dec.Token(); err != nil { // 读取 '['
+		return 0, err
+	}
+
+	var total int64
+	batch := make([]map[string]interface{}, 0, restoreBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := tx.Table(table.Name).Create(&batch).Error; err != nil {
+			return err
+		}
+		total += int64(len(batch))
+		batch = batch[:0]
+		return nil
+	}
+
+	for dec.More() {
+		var row map[string]interface{}
+		if err := dec.Decode(&row); err != nil {
+			return 0, err
+		}
+		for col, val := range row {
+			if num, ok := val.(json.Number); ok {
+				row[col] = numberValue(num)
+			}
+		}
+		batch = append(batch, row)
+		if len(batch) >= restoreBatchSize {
+			if err := flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// resetSequence 将自增序列调整到当前最大ID，保证恢复后新插入的数据不会主键冲突
+func resetSequence(tx *gorm.DB, table string) error {
+	return tx.Exec(fmt.Sprintf(
+		"SELECT setval(pg_get_serial_sequence('%s', 'id'), COALESCE(MAX(id), 1), MAX(id) IS NOT NULL) FROM %s",
+		table, table,
+	)).Error
+}
+
+// restoreUploads 将归档中的 uploads/ 文件解压到本地上传目录
+func restoreUploads(files []*zip.File) error {
+	root, err := filepath.Abs(util.UploadDir)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if !strings.HasPrefix(f.Name, uploadsDir+"/") || f.FileInfo().IsDir() {
+			continue
+		}
+		rel := strings.TrimPrefix(f.Name, uploadsDir+"/")
+		target := filepath.Join(root, filepath.FromSlash(rel))
+		// 防止路径穿越（zip slip）
+		if !strings.HasPrefix(target, root+string(os.PathSeparator)) {
+			return fmt.Errorf("非法的文件路径: %s", f.Name)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := extractFile(f, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractFile 解压单个文件
+func extractFile(f *zip.File, target string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, rc)
+	return err
+}
+
+// toInt64 将数据库返回的整数类型统一转换为 int64
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case int32:
+		return int64(n)
+	case int16:
+		return int64(n)
+	case int:
+		return int64(n)
+	case uint:
+		return int64(n)
+	default:
+		return 0
+	}
+}
+
+// numberValue 将 JSON 数字转换为整数（优先）或浮点数
+func numberValue(num json.Number) interface{} {
+	if i, err := num.Int64(); err == nil {
+		return i
+	}
+	if f, err := num.Float64(); err == nil {
+		return f
+	}
+	return num.String()
+}
@@ -0,0 +1,84 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：main.go
+ * 创建时间：2026-10-17 15:02:11
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：全站备份工具，将所有数据表导出为带版本号的 zip 归档（每张表一个 JSON 文件，可选包含本地 uploads 目录），
+ *          并支持将归档恢复到空数据库中，保留原有ID和关联关系
+ *
+ * 使用示例：
+ *   导出：go run ./cmd/backup -mode export -file backup.zip -uploads
+ *   恢复：go run ./cmd/backup -mode restore -file backup.zip -uploads -truncate
+ */
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"blog-backend/config"
+	"blog-backend/db"
+	"blog-backend/logger"
+)
+
+func main() {
+	mode := flag.String("mode", "export", "运行模式：export（导出备份）或 restore（从备份恢复）")
+	file := flag.String("file", "", "备份文件路径（导出时默认为 backup-<时间>.zip）")
+	withUploads := flag.Bool("uploads", false, "导出时包含本地 uploads 目录 / 恢复时还原 uploads 目录")
+	truncate := flag.Bool("truncate", false, "恢复前清空目标表（用于清除 init.sql 写入的默认数据）")
+	flag.Parse()
+
+	// 加载配置
+	if err := config.LoadConfigByEnv(); err != nil {
+		log.Fatalf("配置加载失败: %v", err)
+	}
+
+	// 初始化日志
+	isDev := config.Cfg.Env == "dev"
+	if err := logger.InitLogger(config.Cfg.Log.Level, isDev); err != nil {
+		log.Fatalf("日志初始化失败: %v", err)
+	}
+	defer logger.Sync()
+
+	// 初始化数据库
+	if err := db.InitDB(); err != nil {
+		log.Fatalf("数据库初始化失败: %v", err)
+	}
+
+	switch *mode {
+	case "export":
+		path := *file
+		if path == "" {
+			path = fmt.Sprintf("backup-%s.zip", time.Now().Format("20060102-150405"))
+		}
+		manifest, err := exportArchive(path, *withUploads)
+		if err != nil {
+			log.Fatalf("导出备份失败: %v", err)
+		}
+		for _, t := range manifest.Tables {
+			fmt.Printf("  %-26s %d 行\n", t.Name, t.Rows)
+		}
+		fmt.Printf("\n备份完成：%s（格式版本 %d，上传文件 %d 个）\n", path, manifest.FormatVersion, manifest.UploadFiles)
+
+	case "restore":
+		if *file == "" {
+			log.Fatalf("恢复模式必须通过 -file 指定备份文件")
+		}
+		manifest, err := restoreArchive(*file, *withUploads, *truncate)
+		if err != nil {
+			log.Fatalf("恢复备份失败: %v", err)
+		}
+		for _, t := range manifest.Tables {
+			fmt.Printf("  %-26s %d 行\n", t.Name, t.Rows)
+		}
+		fmt.Printf("\n恢复完成：%s（备份时间 %s）\n", *file, manifest.CreatedAt.Format("2006-01-02 15:04:05"))
+
+	default:
+		log.Fatalf("未知的运行模式: %s（可选 export / restore）", *mode)
+	}
+}
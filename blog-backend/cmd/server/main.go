@@ -50,6 +50,11 @@ func main() {
 		logger.Fatal(fmt.Sprintf("Failed to init redis: %v", err))
 	}
 
+	// 补齐缺失的内置角色（老库升级或手工删除后恢复默认权限）
+	if err := service.NewRoleService().EnsureSystemRoles(); err != nil {
+		logger.Fatal(fmt.Sprintf("Failed to init system roles: %v", err))
+	}
+
 	// 初始化上传目录
 	if err := util.InitUploadDirs(); err != nil {
 		logger.Fatal(fmt.Sprintf("Failed to init upload directories: %v", err))
@@ -60,6 +65,26 @@ func main() {
 	cleanupService.StartCleanupTasks()
 	logger.Info("Cleanup tasks started")
 
+	// 启动文章定时发布任务
+	postSchedulerService := service.NewPostSchedulerService()
+	postSchedulerService.StartScheduler()
+	logger.Info("Post scheduler started")
+
+	// 启动账号注销执行任务
+	accountService := service.NewAccountService()
+	accountService.StartScheduler()
+	logger.Info("Account deletion scheduler started")
+
+	// 启动朋友圈订阅抓取任务
+	friendCircleService := service.NewFriendCircleService()
+	friendCircleService.StartFetcher()
+	logger.Info("Friend circle fetcher started")
+
+	// 启动友链健康检查任务
+	friendLinkHealthService := service.NewFriendLinkHealthService()
+	friendLinkHealthService.StartChecker()
+	logger.Info("Friend link health checker started")
+
 	// 设置 Gin 模式
 	gin.SetMode(config.Cfg.Server.Mode)
 
@@ -14,6 +14,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/spf13/viper"
 	"github.com/subosito/gotenv"
@@ -58,8 +59,10 @@ type Config struct {
 
 	// JWT JWT令牌配置
 	JWT struct {
-		Secret      string `mapstructure:"secret"`       // JWT密钥
-		ExpireHours int    `mapstructure:"expire_hours"` // JWT过期时间（小时）
+		Secret              string `mapstructure:"secret"`                // JWT密钥
+		ExpireHours         int    `mapstructure:"expire_hours"`          // JWT过期时间（小时），未配置 access_expire_minutes 时作为访问令牌有效期
+		AccessExpireMinutes int    `mapstructure:"access_expire_minutes"` // 访问令牌有效期（分钟）
+		RefreshExpireDays   int    `mapstructure:"refresh_expire_days"`   // 刷新令牌有效期（天），默认30
 	} `mapstructure:"jwt"`
 
 	// Email 邮件服务配置
@@ -93,12 +96,67 @@ type Config struct {
 		Domain    string `mapstructure:"domain"`     // 自定义域名（可选）
 	} `mapstructure:"cos"`
 
+	// FriendCircle 朋友圈（友链订阅聚合）配置
+	FriendCircle struct {
+		IntervalMinutes int `mapstructure:"interval_minutes"`   // 抓取间隔（分钟），默认30
+		TimeoutSeconds  int `mapstructure:"timeout_seconds"`    // 单个订阅请求超时（秒），默认15
+		MaxItemsPerFeed int `mapstructure:"max_items_per_feed"` // 每个友链保留的最新文章数，默认20
+	} `mapstructure:"friend_circle"`
+
+	// FriendLinkCheck 友链健康检查配置
+	FriendLinkCheck struct {
+		IntervalMinutes int  `mapstructure:"interval_minutes"` // 检查间隔（分钟），默认360
+		TimeoutSeconds  int  `mapstructure:"timeout_seconds"`  // 单个友链请求超时（秒），默认10
+		MaxFailures     int  `mapstructure:"max_failures"`     // 连续失败多少次后自动禁用，默认5
+		CheckBacklink   bool `mapstructure:"check_backlink"`   // 是否检查对方页面包含本站链接
+	} `mapstructure:"friend_link_check"`
+
+	// LoginLock 账号登录锁定配置（按账号统计失败次数，与按IP的频率限制互补）
+	LoginLock struct {
+		MaxFailures    int `mapstructure:"max_failures"`     // 窗口期内连续失败多少次后锁定，默认5
+		WindowMinutes  int `mapstructure:"window_minutes"`   // 失败次数统计窗口（分钟），默认15
+		LockMinutes    int `mapstructure:"lock_minutes"`     // 首次锁定时长（分钟），之后每次锁定翻倍，默认5
+		MaxLockMinutes int `mapstructure:"max_lock_minutes"` // 最长锁定时长（分钟），默认1440
+	} `mapstructure:"login_lock"`
+
+	// Account 账号自助管理配置
+	Account struct {
+		DeletionGraceDays int `mapstructure:"deletion_grace_days"` // 申请注销后的冷静期（天），期间可撤销，默认7
+	} `mapstructure:"account"`
+
+	// OAuth 第三方登录配置
+	OAuth struct {
+		FrontendCallbackURL string                         `mapstructure:"frontend_callback_url"` // 前端回调页地址，后端处理完授权回调后携带结果跳转到此页
+		Providers           map[string]OAuthProviderConfig `mapstructure:"providers"`             // 按提供方名称（github、gitee）配置
+	} `mapstructure:"oauth"`
+
+	// WebAuthn 通行密钥（Passkey）登录配置
+	WebAuthn struct {
+		RPID    string   `mapstructure:"rp_id"`   // 依赖方ID，即站点域名（不含协议和端口），通行密钥与之绑定
+		RPName  string   `mapstructure:"rp_name"` // 依赖方名称，展示在浏览器和认证器的提示中
+		Origins []string `mapstructure:"origins"` // 允许发起认证的前端来源（协议+域名+端口）
+	} `mapstructure:"webauthn"`
+
 	// Security 安全配置
 	Security struct {
 		AdminIPWhitelist []string `mapstructure:"admin_ip_whitelist"` // 管理员IP白名单列表
 	} `mapstructure:"security"`
 }
 
+// OAuthProviderConfig 单个第三方登录提供方的配置
+// 各端点留空时使用提供方的官方地址，配置后可指向本地模拟的 OAuth 服务进行联调
+type OAuthProviderConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`       // 是否启用
+	ClientID     string   `mapstructure:"client_id"`     // 应用 Client ID
+	ClientSecret string   `mapstructure:"client_secret"` // 应用 Client Secret
+	RedirectURL  string   `mapstructure:"redirect_url"`  // 授权回调地址（指向后端 /api/auth/oauth/<provider>/callback）
+	AuthURL      string   `mapstructure:"auth_url"`      // 授权页地址
+	TokenURL     string   `mapstructure:"token_url"`     // 换取访问令牌地址
+	UserInfoURL  string   `mapstructure:"user_info_url"` // 获取用户信息地址
+	EmailsURL    string   `mapstructure:"emails_url"`    // 获取已验证邮箱地址
+	Scopes       []string `mapstructure:"scopes"`        // 申请的授权范围
+}
+
 // Cfg 全局配置实例
 var Cfg *Config
 
@@ -232,6 +290,18 @@ func loadEnvOverrides(env string) {
 	if v := os.Getenv("COS_DOMAIN"); v != "" {
 		Cfg.COS.Domain = v
 	}
+
+	// 第三方登录密钥覆盖（OAUTH_<PROVIDER>_CLIENT_ID / OAUTH_<PROVIDER>_CLIENT_SECRET）
+	for name, provider := range Cfg.OAuth.Providers {
+		prefix := "OAUTH_" + strings.ToUpper(name) + "_"
+		if v := os.Getenv(prefix + "CLIENT_ID"); v != "" {
+			provider.ClientID = v
+		}
+		if v := os.Getenv(prefix + "CLIENT_SECRET"); v != "" {
+			provider.ClientSecret = v
+		}
+		Cfg.OAuth.Providers[name] = provider
+	}
 }
 
 // LoadConfigByEnv 根据 config.yml 中的 env 字段加载对应环境的配置
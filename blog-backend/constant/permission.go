@@ -0,0 +1,92 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：permission.go
+ * 创建时间：2026-10-18 02:06:14
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：权限常量定义，角色以权限集合的形式保存在数据库中，接口通过 RequirePermission 声明所需权限；
+ *          这里同时给出内置角色的默认权限，用于初始化数据和启动时补齐缺失的内置角色
+ */
+package constant
+
+// 权限定义
+const (
+	// PermAdminAccess 进入管理后台（仪表盘），具备该权限的角色受"强制管理员启用两步验证"约束
+	PermAdminAccess = "admin.access"
+	// PermPostManage 管理所有文章（查看草稿、编辑和删除他人文章、导入导出、修订历史）
+	PermPostManage = "post.manage"
+	// PermCommentModerate 审核、编辑和删除任意评论
+	PermCommentModerate = "comment.moderate"
+	// PermMomentManage 管理所有说说
+	PermMomentManage = "moment.manage"
+	// PermCategoryManage 管理文章分类
+	PermCategoryManage = "category.manage"
+	// PermIPManage 管理IP黑名单和白名单
+	PermIPManage = "ip.manage"
+	// PermChatManage 管理聊天室（删除消息、广播、踢人、封禁、聊天室设置）
+	PermChatManage = "chat.manage"
+	// PermFriendLinkManage 管理友链、友链分类、友链申请和朋友圈订阅
+	PermFriendLinkManage = "friendlink.manage"
+	// PermAlbumManage 管理相册
+	PermAlbumManage = "album.manage"
+	// PermSettingsManage 管理网站、通知、注册、安全设置以及"关于我"
+	PermSettingsManage = "settings.manage"
+	// PermSettingsUpload 管理上传存储设置
+	PermSettingsUpload = "settings.upload"
+	// PermUserManage 管理用户（列表、启用禁用、删除、解除登录锁定、分配角色）
+	PermUserManage = "user.manage"
+	// PermRoleManage 管理角色及其权限
+	PermRoleManage = "role.manage"
+	// PermLogView 查看和清理操作日志、查看登录记录
+	PermLogView = "log.view"
+)
+
+// PermissionInfo 权限说明
+type PermissionInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Permissions 所有权限及其说明（按展示顺序排列）
+var Permissions = []PermissionInfo{
+	{PermAdminAccess, "进入管理后台"},
+	{PermPostManage, "管理所有文章"},
+	{PermCommentModerate, "审核和删除评论"},
+	{PermMomentManage, "管理所有说说"},
+	{PermCategoryManage, "管理文章分类"},
+	{PermIPManage, "管理IP黑白名单"},
+	{PermChatManage, "管理聊天室"},
+	{PermFriendLinkManage, "管理友链"},
+	{PermAlbumManage, "管理相册"},
+	{PermSettingsManage, "管理网站设置"},
+	{PermSettingsUpload, "管理上传设置"},
+	{PermUserManage, "管理用户"},
+	{PermRoleManage, "管理角色和权限"},
+	{PermLogView, "查看操作日志和登录记录"},
+}
+
+// IsValidPermission 判断是否为已定义的权限
+func IsValidPermission(name string) bool {
+	for _, p := range Permissions {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultRolePermissions 内置角色的默认权限（super_admin 始终拥有全部权限，不在此列出）
+var DefaultRolePermissions = map[string][]string{
+	RoleAdmin: {
+		PermAdminAccess,
+		PermPostManage,
+		PermCommentModerate,
+		PermMomentManage,
+		PermCategoryManage,
+		PermIPManage,
+		PermChatManage,
+	},
+	RoleUser: {},
+}
@@ -20,8 +20,9 @@ const (
 	RoleUser = "user"
 )
 
-// IsAdminRole 判断是否为具备管理员权限的角色
-// 包含 super_admin 和 admin 两种角色
+// IsAdminRole 判断是否为内置的管理员角色
+// 包含 super_admin 和 admin 两种角色；接口鉴权请改用 RequirePermission / RoleRepository.HasPermission，
+// 以便自定义角色同样生效
 func IsAdminRole(role string) bool {
 	return role == RoleAdmin || role == RoleSuperAdmin
 }
@@ -0,0 +1,34 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：scope.go
+ * 创建时间：2026-10-18 01:18:40
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：个人访问令牌的权限范围（scope）常量，路由分组声明所需的权限范围，令牌只能访问已声明权限范围的接口
+ */
+package constant
+
+// 个人访问令牌权限范围
+const (
+	// ScopePostsWrite 发布、修改和删除文章
+	ScopePostsWrite = "posts:write"
+	// ScopeMomentsWrite 发布、修改和删除说说
+	ScopeMomentsWrite = "moments:write"
+	// ScopeUpload 上传图片和头像
+	ScopeUpload = "upload"
+)
+
+// TokenScopes 所有可授予的权限范围及其说明
+var TokenScopes = map[string]string{
+	ScopePostsWrite:   "发布、修改和删除文章",
+	ScopeMomentsWrite: "发布、修改和删除说说",
+	ScopeUpload:       "上传图片和头像",
+}
+
+// IsValidScope 判断是否为可授予的权限范围
+func IsValidScope(scope string) bool {
+	_, ok := TokenScopes[scope]
+	return ok
+}
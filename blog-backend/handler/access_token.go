@@ -0,0 +1,90 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：access_token.go
+ * 创建时间：2026-10-18 01:47:20
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：个人访问令牌处理器，提供当前用户查看、创建和吊销个人访问令牌的接口
+ */
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"blog-backend/service"
+	"blog-backend/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccessTokenHandler 个人访问令牌处理器结构体
+type AccessTokenHandler struct {
+	service *service.AccessTokenService
+}
+
+// NewAccessTokenHandler 创建个人访问令牌处理器实例
+func NewAccessTokenHandler() *AccessTokenHandler {
+	return &AccessTokenHandler{
+		service: service.NewAccessTokenService(),
+	}
+}
+
+// Scopes 获取可授予的权限范围
+func (h *AccessTokenHandler) Scopes(c *gin.Context) {
+	util.Success(c, h.service.ListScopes())
+}
+
+// List 获取当前用户的有效令牌
+func (h *AccessTokenHandler) List(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	tokens, err := h.service.List(userID.(uint))
+	if err != nil {
+		util.ServerError(c, "获取令牌列表失败")
+		return
+	}
+
+	util.Success(c, tokens)
+}
+
+// Create 创建个人访问令牌（明文令牌只在响应中返回这一次）
+func (h *AccessTokenHandler) Create(c *gin.Context) {
+	var req service.CreateAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	token, err := h.service.Create(userID.(uint), &req)
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "令牌已创建，请立即复制保存，关闭后将无法再次查看", token)
+}
+
+// Revoke 吊销个人访问令牌
+func (h *AccessTokenHandler) Revoke(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.BadRequest(c, "无效的令牌ID")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.service.Revoke(userID.(uint), uint(id)); err != nil {
+		if errors.Is(err, service.ErrAccessTokenNotFound) {
+			util.NotFound(c, err.Error())
+			return
+		}
+		util.ServerError(c, "吊销令牌失败")
+		return
+	}
+
+	util.SuccessWithMessage(c, "令牌已吊销", nil)
+}
@@ -0,0 +1,107 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：account.go
+ * 创建时间：2026-10-18 05:14:47
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：账号自助管理处理器，提供个人数据导出、注销申请查询、申请注销和撤销注销接口
+ */
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"blog-backend/service"
+	"blog-backend/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountHandler 账号自助管理处理器结构体
+type AccountHandler struct {
+	service *service.AccountService
+}
+
+// NewAccountHandler 创建账号自助管理处理器实例
+func NewAccountHandler() *AccountHandler {
+	return &AccountHandler{
+		service: service.NewAccountService(),
+	}
+}
+
+// Export 以JSON文件形式下载当前用户的个人数据
+func (h *AccountHandler) Export(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	export, err := h.service.Export(userID.(uint))
+	if err != nil {
+		util.ServerError(c, err.Error())
+		return
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		util.ServerError(c, "导出个人数据失败")
+		return
+	}
+
+	filename := fmt.Sprintf("%s-data-%s.json", export.Profile.Username, time.Now().Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Data(200, "application/json; charset=utf-8", data)
+}
+
+// GetDeletion 获取当前用户的注销申请
+func (h *AccountHandler) GetDeletion(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	request, err := h.service.GetDeletionStatus(userID.(uint))
+	if err != nil {
+		if errors.Is(err, service.ErrAccountDeletionNotFound) {
+			util.Success(c, nil)
+			return
+		}
+		util.ServerError(c, err.Error())
+		return
+	}
+
+	util.Success(c, request)
+}
+
+// RequestDeletion 申请注销当前账号
+func (h *AccountHandler) RequestDeletion(c *gin.Context) {
+	var req service.RequestDeletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	request, err := h.service.RequestDeletion(userID.(uint), &req)
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "已提交注销申请，冷静期内可随时撤销", request)
+}
+
+// CancelDeletion 撤销注销申请
+func (h *AccountHandler) CancelDeletion(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	if err := h.service.CancelDeletion(userID.(uint)); err != nil {
+		if errors.Is(err, service.ErrAccountDeletionNotFound) {
+			util.NotFound(c, err.Error())
+			return
+		}
+		util.ServerError(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "已撤销注销申请", nil)
+}
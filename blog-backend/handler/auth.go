@@ -11,6 +11,9 @@
 package handler
 
 import (
+	"errors"
+
+	"blog-backend/model"
 	"blog-backend/service"
 	"blog-backend/util"
 
@@ -46,6 +49,12 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	// 需要管理员审核时账号暂不能登录，提示用户等待审核结果邮件
+	if user.Status == model.UserStatusPending {
+		util.SuccessWithMessage(c, "注册成功，请等待管理员审核，审核结果将通过邮件通知您", user)
+		return
+	}
+
 	util.SuccessWithMessage(c, "注册成功", user)
 }
 
@@ -60,7 +69,40 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	// 获取客户端IP
 	ip := util.GetClientIP(c)
 
-	resp, err := h.service.Login(&req, ip)
+	resp, err := h.service.Login(&req, ip, c.Request.UserAgent())
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "登录成功", resp)
+}
+
+// SendMagicLink 发送免密登录链接到邮箱
+func (h *AuthHandler) SendMagicLink(c *gin.Context) {
+	var req service.SendMagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if err := h.service.SendMagicLink(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "如果该邮箱已注册，登录链接已发送到您的邮箱，请查收（有效期15分钟）", nil)
+}
+
+// MagicLinkLogin 使用邮件中的登录链接登录
+func (h *AuthHandler) MagicLinkLogin(c *gin.Context) {
+	var req service.MagicLinkLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	resp, err := h.service.MagicLinkLogin(&req, util.GetClientIP(c), c.Request.UserAgent())
 	if err != nil {
 		util.Error(c, 400, err.Error())
 		return
@@ -69,8 +111,21 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	util.SuccessWithMessage(c, "登录成功", resp)
 }
 
-// Logout 用户登出
+// Logout 用户登出（吊销当前Token）
 func (h *AuthHandler) Logout(c *gin.Context) {
+	token := c.GetHeader("Authorization")
+	if len(token) > 7 && token[:7] == "Bearer " {
+		token = token[7:]
+	}
+
+	// Token 缺失或已失效时同样视为登出成功
+	if token != "" {
+		if err := h.service.Logout(token); err != nil {
+			util.ServerError(c, "登出失败")
+			return
+		}
+	}
+
 	util.SuccessWithMessage(c, "登出成功", nil)
 }
 
@@ -136,26 +191,25 @@ func (h *AuthHandler) UpdatePassword(c *gin.Context) {
 	util.SuccessWithMessage(c, "密码修改成功", nil)
 }
 
-// RefreshToken 刷新Token
+// RefreshToken 使用刷新令牌换取新的访问令牌和刷新令牌
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
-	token := c.GetHeader("Authorization")
-	if token == "" {
-		util.Unauthorized(c, "缺少认证信息")
+	var req service.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "缺少刷新令牌")
 		return
 	}
 
-	// 去掉 "Bearer " 前缀
-	if len(token) > 7 && token[:7] == "Bearer " {
-		token = token[7:]
-	}
-
-	newToken, err := util.RefreshToken(token)
+	tokens, err := h.service.RefreshToken(&req, util.GetClientIP(c), c.Request.UserAgent())
 	if err != nil {
-		util.Unauthorized(c, "Token刷新失败")
+		if errors.Is(err, service.ErrInvalidRefreshToken) || errors.Is(err, service.ErrRefreshTokenReused) {
+			util.Unauthorized(c, err.Error())
+			return
+		}
+		util.ServerError(c, "Token刷新失败")
 		return
 	}
 
-	util.Success(c, gin.H{"token": newToken})
+	util.Success(c, tokens)
 }
 
 // ForgotPassword 忘记密码 - 发送验证码
@@ -192,7 +246,29 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 	util.SuccessWithMessage(c, "密码重置成功，请使用新密码登录", nil)
 }
 
-// UpdateEmail 修改邮箱
+// SendEmailChangeCode 修改邮箱第一步：向新邮箱发送验证码
+func (h *AuthHandler) SendEmailChangeCode(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未登录")
+		return
+	}
+
+	var req service.SendEmailChangeCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "请输入正确的邮箱地址")
+		return
+	}
+
+	if err := h.service.SendEmailChangeCode(userID.(uint), &req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "验证码已发送至新邮箱，请查收", nil)
+}
+
+// UpdateEmail 修改邮箱第二步：提交新邮箱收到的验证码完成修改
 func (h *AuthHandler) UpdateEmail(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -214,6 +290,22 @@ func (h *AuthHandler) UpdateEmail(c *gin.Context) {
 	util.SuccessWithMessage(c, "邮箱修改成功", nil)
 }
 
+// RevertEmailChange 通过原邮箱收到的撤销链接恢复原邮箱（无需登录）
+func (h *AuthHandler) RevertEmailChange(c *gin.Context) {
+	var req service.RevertEmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "缺少撤销令牌")
+		return
+	}
+
+	if err := h.service.RevertEmailChange(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "已恢复原邮箱，账号的所有登录设备均已退出，请尽快修改密码", nil)
+}
+
 // GetEmailChangeInfo 获取邮箱修改信息
 func (h *AuthHandler) GetEmailChangeInfo(c *gin.Context) {
 	userID, exists := c.Get("user_id")
@@ -13,6 +13,7 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -20,6 +21,7 @@ import (
 	"strings"
 	"time"
 
+	"blog-backend/constant"
 	"blog-backend/db"
 	"blog-backend/model"
 	"blog-backend/repository"
@@ -42,6 +44,7 @@ var upgrader = websocket.Upgrader{
 // ChatHandler 聊天处理器
 type ChatHandler struct {
 	service  *service.ChatService
+	rooms    *service.ChatRoomService
 	hub      *service.Hub
 	settings *repository.SettingRepository
 }
@@ -53,6 +56,7 @@ func NewChatHandler(hub *service.Hub) *ChatHandler {
 	hub.SettingRepo = settingRepo
 	return &ChatHandler{
 		service:  service.NewChatService(hub),
+		rooms:    service.NewChatRoomService(hub),
 		hub:      hub,
 		settings: settingRepo,
 	}
@@ -133,6 +137,7 @@ func (h *ChatHandler) HandleWebSocket(c *gin.Context) {
 		Avatar:   avatar,
 		IP:       ip,
 		Role:     role,
+		Rooms:    make(map[uint]bool),
 	}
 
 	// 注册客户端
@@ -143,8 +148,39 @@ func (h *ChatHandler) HandleWebSocket(c *gin.Context) {
 	go client.ReadPump()
 }
 
-// GetMessages 获取消息列表
+// canManageChat 当前请求者是否具备聊天室管理权限（未登录视为无权限）
+func canManageChat(c *gin.Context) bool {
+	return util.HasPermission(c, constant.PermChatManage)
+}
+
+// resolveRoom 解析 room_id 查询参数，未指定时使用公共大厅；失败时已写入响应
+func (h *ChatHandler) resolveRoom(c *gin.Context) (*model.ChatRoom, bool) {
+	var room *model.ChatRoom
+	var err error
+	if roomIDStr := c.Query("room_id"); roomIDStr != "" {
+		roomID, parseErr := strconv.ParseUint(roomIDStr, 10, 32)
+		if parseErr != nil {
+			util.BadRequest(c, "无效的聊天室ID")
+			return nil, false
+		}
+		room, err = h.rooms.Get(uint(roomID), canManageChat(c))
+	} else {
+		room, err = h.rooms.GetLobby()
+	}
+	if err != nil {
+		util.NotFound(c, err.Error())
+		return nil, false
+	}
+	return room, true
+}
+
+// GetMessages 获取聊天室消息列表（room_id 为空时返回公共大厅）
 func (h *ChatHandler) GetMessages(c *gin.Context) {
+	room, ok := h.resolveRoom(c)
+	if !ok {
+		return
+	}
+
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "50"))
 
@@ -155,7 +191,7 @@ func (h *ChatHandler) GetMessages(c *gin.Context) {
 		pageSize = 50
 	}
 
-	messages, total, err := h.service.GetMessages(page, pageSize, false)
+	messages, total, err := h.service.GetMessages(&room.ID, page, pageSize, false)
 	if err != nil {
 		util.ServerError(c, "获取消息列表失败")
 		return
@@ -220,53 +256,98 @@ func (h *ChatHandler) DeleteMessage(c *gin.Context) {
 	util.Success(c, nil)
 }
 
-// GetOnlineInfo 获取在线信息
+// GetOnlineInfo 获取在线信息，指定 room_id 时额外返回该聊天室的在线用户
 func (h *ChatHandler) GetOnlineInfo(c *gin.Context) {
+	if c.Query("room_id") != "" {
+		room, ok := h.resolveRoom(c)
+		if !ok {
+			return
+		}
+		roomUsers := h.service.GetRoomUsers(room.ID)
+		util.Success(c, gin.H{
+			"online_count": h.service.GetOnlineCount(),
+			"online_users": h.service.GetOnlineUsers(),
+			"room_id":      room.ID,
+			"room_count":   len(roomUsers),
+			"room_users":   roomUsers,
+		})
+		return
+	}
+
 	util.Success(c, gin.H{
 		"online_count": h.service.GetOnlineCount(),
 		"online_users": h.service.GetOnlineUsers(),
 	})
 }
 
-// GetChatSettings 获取聊天室配置（公开，便于前端展示禁言状态）
+// GetChatSettings 获取聊天室公开配置（全员禁言状态、消息长度上限和撤回时限，用于前端提示）
 func (h *ChatHandler) GetChatSettings(c *gin.Context) {
+	guard := h.service.GetGuardSettings()
+	util.Success(c, gin.H{
+		"chat_mute_all":      h.chatMuteAll(),
+		"chat_max_length":    guard["chat_max_length"],
+		"chat_recall_window": guard["chat_recall_window"],
+	})
+}
+
+// AdminGetChatSettings 获取聊天室全部配置，包括防刷与敏感词过滤（仅管理员）
+func (h *ChatHandler) AdminGetChatSettings(c *gin.Context) {
+	settings := h.service.GetGuardSettings()
+	settings["chat_mute_all"] = h.chatMuteAll()
+	util.Success(c, settings)
+}
+
+// chatMuteAll 全员禁言状态，未配置时为关闭
+func (h *ChatHandler) chatMuteAll() string {
 	setting, err := h.settings.GetByKey("chat_mute_all")
 	if err != nil || setting == nil {
-		util.Success(c, gin.H{"chat_mute_all": "0"})
-		return
+		return "0"
 	}
-	util.Success(c, gin.H{"chat_mute_all": setting.Value})
+	return setting.Value
 }
 
-// UpdateChatSettings 更新聊天室配置（仅管理员）
+// UpdateChatSettings 更新聊天室配置（仅管理员），只修改传入的配置项
 func (h *ChatHandler) UpdateChatSettings(c *gin.Context) {
-	var req struct {
-		ChatMuteAll string `json:"chat_mute_all" binding:"required"`
-	}
+	var req map[string]string
 	if err := c.ShouldBindJSON(&req); err != nil {
 		util.BadRequest(c, "参数错误")
 		return
 	}
-	if req.ChatMuteAll != "0" && req.ChatMuteAll != "1" {
+
+	muteAll, hasMuteAll := req["chat_mute_all"]
+	if hasMuteAll && muteAll != "0" && muteAll != "1" {
 		util.BadRequest(c, "chat_mute_all 只能是 0 或 1")
 		return
 	}
-	if err := h.settings.BatchUpsert([]model.Setting{
-		{
-			Group: "site",
-			Key:   "chat_mute_all",
-			Value: req.ChatMuteAll,
-		},
-	}); err != nil {
-		util.Error(c, 500, "更新失败")
-		return
-	}
+	delete(req, "chat_mute_all")
 
-	// 记录操作日志（仅当设置全员禁言时记录）
-	if req.ChatMuteAll == "1" {
-		util.LogOperation(c, "update", "chat", nil, "聊天室", "开启全员禁言")
-	} else if req.ChatMuteAll == "0" {
-		util.LogOperation(c, "update", "chat", nil, "聊天室", "关闭全员禁言")
+	// 先校验并保存防刷与过滤配置，参数错误时不修改全员禁言状态
+	if len(req) > 0 {
+		if err := h.service.UpdateGuardSettings(req); err != nil {
+			util.BadRequest(c, err.Error())
+			return
+		}
+		util.LogOperation(c, "update", "chat", nil, "聊天室", "更新防刷与内容过滤设置")
+	}
+
+	if hasMuteAll {
+		if err := h.settings.BatchUpsert([]model.Setting{
+			{
+				Group: "site",
+				Key:   "chat_mute_all",
+				Value: muteAll,
+			},
+		}); err != nil {
+			util.Error(c, 500, "更新失败")
+			return
+		}
+
+		// 记录操作日志
+		if muteAll == "1" {
+			util.LogOperation(c, "update", "chat", nil, "聊天室", "开启全员禁言")
+		} else {
+			util.LogOperation(c, "update", "chat", nil, "聊天室", "关闭全员禁言")
+		}
 	}
 
 	util.SuccessWithMessage(c, "更新成功", nil)
@@ -294,7 +375,19 @@ func (h *ChatHandler) AdminListMessages(c *gin.Context) {
 		pageSize = 20
 	}
 
-	messages, total, err := h.service.GetMessages(page, pageSize, true)
+	// 可按聊天室筛选，不传时返回全部消息
+	var roomID *uint
+	if roomIDStr := c.Query("room_id"); roomIDStr != "" {
+		id, err := strconv.ParseUint(roomIDStr, 10, 32)
+		if err != nil {
+			util.BadRequest(c, "无效的聊天室ID")
+			return
+		}
+		rid := uint(id)
+		roomID = &rid
+	}
+
+	messages, total, err := h.service.GetMessages(roomID, page, pageSize, true)
 	if err != nil {
 		util.ServerError(c, "获取消息列表失败")
 		return
@@ -410,7 +503,7 @@ func (h *ChatHandler) BroadcastSystemMessage(c *gin.Context) {
 		}
 
 		data, _ := json.Marshal(wsMsg)
-		h.hub.Broadcast <- data
+		h.hub.Publish(data)
 	}
 
 	util.Success(c, nil)
@@ -453,8 +546,8 @@ func (h *ChatHandler) BanIP(c *gin.Context) {
 		return
 	}
 
-	// 获取客户端信息
-	client := h.hub.GetClientByID(req.ClientID)
+	// 获取客户端信息（连接可能在其他实例上）
+	client := h.hub.LookupClient(req.ClientID)
 	if client == nil {
 		util.Error(c, 404, "用户不在线")
 		return
@@ -471,3 +564,206 @@ func (h *ChatHandler) BanIP(c *gin.Context) {
 		"ip": client.IP,
 	})
 }
+
+// RecallMessage 撤回消息（管理员功能，不受撤回时限限制）
+func (h *ChatHandler) RecallMessage(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.BadRequest(c, "无效的消息ID")
+		return
+	}
+
+	message, err := h.service.RecallMessage(uint(id))
+	if err != nil {
+		if errors.Is(err, service.ErrChatMessageNotFound) {
+			util.NotFound(c, err.Error())
+			return
+		}
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	contentPreview := message.Content
+	if len([]rune(contentPreview)) > 50 {
+		contentPreview = string([]rune(contentPreview)[:50]) + "..."
+	}
+	messageID := message.ID
+	util.LogOperation(c, "update", "chat", &messageID, contentPreview,
+		"撤回聊天消息（发送者："+message.Username+"）："+contentPreview)
+
+	util.SuccessWithMessage(c, "已撤回", nil)
+}
+
+// ListMutes 获取禁言中的用户列表（包括自动禁言）
+func (h *ChatHandler) ListMutes(c *gin.Context) {
+	mutes, err := h.service.ListMutes()
+	if err != nil {
+		util.ServerError(c, "获取禁言列表失败")
+		return
+	}
+	util.Success(c, mutes)
+}
+
+// MuteUser 禁言用户（管理员功能），登录用户按用户ID禁言，匿名用户按IP禁言
+func (h *ChatHandler) MuteUser(c *gin.Context) {
+	var req service.ChatMuteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "参数错误")
+		return
+	}
+
+	mute, err := h.service.Mute(req)
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	name := mute.Username
+	if name == "" {
+		name = mute.Identity
+	}
+	util.LogOperation(c, "update", "chat", mute.UserID, name,
+		fmt.Sprintf("禁言聊天室用户 %s %d 分钟，原因：%s", name, req.Duration, mute.Reason))
+
+	util.Success(c, mute)
+}
+
+// UnmuteUser 解除禁言（管理员功能）
+func (h *ChatHandler) UnmuteUser(c *gin.Context) {
+	identity := c.Param("identity")
+	if identity == "" {
+		util.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.service.Unmute(identity); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.LogOperation(c, "update", "chat", nil, identity, "解除聊天室用户 "+identity+" 的禁言")
+	util.SuccessWithMessage(c, "已解除禁言", nil)
+}
+
+// ListRooms 获取可进入的聊天室列表（管理员专用聊天室仅对管理员可见）
+func (h *ChatHandler) ListRooms(c *gin.Context) {
+	rooms, err := h.rooms.List(canManageChat(c))
+	if err != nil {
+		util.ServerError(c, "获取聊天室列表失败")
+		return
+	}
+	util.Success(c, rooms)
+}
+
+// GetPostRoom 获取文章讨论室（首次访问时自动创建）
+func (h *ChatHandler) GetPostRoom(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Param("postId"), 10, 32)
+	if err != nil {
+		util.BadRequest(c, "无效的文章ID")
+		return
+	}
+
+	room, err := h.rooms.GetPostRoom(uint(postID))
+	if err != nil {
+		util.NotFound(c, err.Error())
+		return
+	}
+	util.Success(c, room)
+}
+
+// AdminListRooms 管理员获取全部聊天室（包含已归档和文章讨论室）
+func (h *ChatHandler) AdminListRooms(c *gin.Context) {
+	rooms, err := h.rooms.AdminList()
+	if err != nil {
+		util.ServerError(c, "获取聊天室列表失败")
+		return
+	}
+	util.Success(c, rooms)
+}
+
+// CreateRoom 创建聊天室（管理员功能）
+func (h *ChatHandler) CreateRoom(c *gin.Context) {
+	var req service.CreateChatRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	room, err := h.rooms.Create(userID.(uint), &req)
+	if err != nil {
+		util.ServerError(c, "创建聊天室失败")
+		return
+	}
+
+	util.LogOperation(c, "create", "chat", &room.ID, room.Name, "创建聊天室："+room.Name)
+	util.SuccessWithMessage(c, "创建成功", room)
+}
+
+// LockRoom 锁定或解锁聊天室（管理员功能）
+func (h *ChatHandler) LockRoom(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.BadRequest(c, "无效的聊天室ID")
+		return
+	}
+
+	var req struct {
+		Locked *bool `json:"locked" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	room, err := h.rooms.SetLocked(uint(id), *req.Locked)
+	if err != nil {
+		if errors.Is(err, service.ErrChatRoomNotFound) {
+			util.NotFound(c, err.Error())
+			return
+		}
+		util.ServerError(c, "更新聊天室失败")
+		return
+	}
+
+	action := "解锁聊天室："
+	if room.IsLocked {
+		action = "锁定聊天室："
+	}
+	util.LogOperation(c, "update", "chat", &room.ID, room.Name, action+room.Name)
+	util.SuccessWithMessage(c, "更新成功", room)
+}
+
+// ArchiveRoom 归档或恢复聊天室（管理员功能）
+func (h *ChatHandler) ArchiveRoom(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.BadRequest(c, "无效的聊天室ID")
+		return
+	}
+
+	var req struct {
+		Archived *bool `json:"archived" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	room, err := h.rooms.SetArchived(uint(id), *req.Archived)
+	if err != nil {
+		if errors.Is(err, service.ErrChatRoomNotFound) {
+			util.NotFound(c, err.Error())
+			return
+		}
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	action := "恢复聊天室："
+	if room.IsArchived {
+		action = "归档聊天室："
+	}
+	util.LogOperation(c, "update", "chat", &room.ID, room.Name, action+room.Name)
+	util.SuccessWithMessage(c, "更新成功", room)
+}
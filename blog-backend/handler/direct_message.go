@@ -0,0 +1,214 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：direct_message.go
+ * 创建时间：2026-10-18 06:27:45
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：私信处理器，提供会话列表、私信记录、发送私信、标记已读、未读数以及屏蔽用户等接口（需登录）
+ */
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"blog-backend/service"
+	"blog-backend/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DirectMessageHandler 私信处理器结构体
+type DirectMessageHandler struct {
+	service *service.DirectMessageService
+}
+
+// NewDirectMessageHandler 创建私信处理器实例，私信通过聊天室的 Hub 实时推送
+func NewDirectMessageHandler(hub *service.Hub) *DirectMessageHandler {
+	return &DirectMessageHandler{
+		service: service.NewDirectMessageService(hub),
+	}
+}
+
+// peerIDParam 解析路径中的对方用户ID
+func peerIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil || id == 0 {
+		util.BadRequest(c, "无效的用户ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// Contacts 获取可直接联系的站长
+func (h *DirectMessageHandler) Contacts(c *gin.Context) {
+	contacts, err := h.service.Contacts()
+	if err != nil {
+		util.ServerError(c, "获取联系人失败")
+		return
+	}
+	util.Success(c, contacts)
+}
+
+// Conversations 获取会话列表
+func (h *DirectMessageHandler) Conversations(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	userID, _ := c.Get("user_id")
+	conversations, total, err := h.service.Conversations(userID.(uint), page, pageSize)
+	if err != nil {
+		util.ServerError(c, "获取会话列表失败")
+		return
+	}
+
+	util.PageSuccess(c, conversations, total, page, pageSize)
+}
+
+// Messages 获取与某个用户的私信记录（按发送时间倒序分页）
+func (h *DirectMessageHandler) Messages(c *gin.Context) {
+	peerID, ok := peerIDParam(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "30"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 30
+	}
+
+	userID, _ := c.Get("user_id")
+	messages, total, peer, err := h.service.Messages(userID.(uint), peerID, page, pageSize)
+	if err != nil {
+		if errors.Is(err, service.ErrDirectPeerNotFound) {
+			util.NotFound(c, err.Error())
+			return
+		}
+		util.ServerError(c, "获取私信记录失败")
+		return
+	}
+
+	util.Success(c, gin.H{
+		"peer":      peer,
+		"list":      messages,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// Send 发送私信
+func (h *DirectMessageHandler) Send(c *gin.Context) {
+	peerID, ok := peerIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req service.SendDirectMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "消息内容不能为空且不超过1000字")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	msg, err := h.service.Send(userID.(uint), peerID, req.Content)
+	if err != nil {
+		if errors.Is(err, service.ErrDirectPeerNotFound) {
+			util.NotFound(c, err.Error())
+			return
+		}
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.Success(c, msg)
+}
+
+// MarkRead 将与某个用户的会话标记为已读
+func (h *DirectMessageHandler) MarkRead(c *gin.Context) {
+	peerID, ok := peerIDParam(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	count, err := h.service.MarkRead(userID.(uint), peerID)
+	if err != nil {
+		util.ServerError(c, "标记已读失败")
+		return
+	}
+
+	util.Success(c, gin.H{"count": count})
+}
+
+// UnreadCount 获取未读私信总数
+func (h *DirectMessageHandler) UnreadCount(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	count, err := h.service.UnreadCount(userID.(uint))
+	if err != nil {
+		util.ServerError(c, "获取未读数失败")
+		return
+	}
+
+	util.Success(c, gin.H{"count": count})
+}
+
+// BlockedUsers 获取屏蔽列表
+func (h *DirectMessageHandler) BlockedUsers(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	users, err := h.service.BlockedUsers(userID.(uint))
+	if err != nil {
+		util.ServerError(c, "获取屏蔽列表失败")
+		return
+	}
+
+	util.Success(c, users)
+}
+
+// Block 屏蔽用户
+func (h *DirectMessageHandler) Block(c *gin.Context) {
+	peerID, ok := peerIDParam(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.service.Block(userID.(uint), peerID); err != nil {
+		if errors.Is(err, service.ErrDirectPeerNotFound) {
+			util.NotFound(c, err.Error())
+			return
+		}
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "已屏蔽该用户", nil)
+}
+
+// Unblock 取消屏蔽
+func (h *DirectMessageHandler) Unblock(c *gin.Context) {
+	peerID, ok := peerIDParam(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.service.Unblock(userID.(uint), peerID); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "已取消屏蔽", nil)
+}
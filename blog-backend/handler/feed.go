@@ -0,0 +1,114 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：feed.go
+ * 创建时间：2026-10-17 16:52:07
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：订阅源处理器，提供 RSS 2.0、Atom、JSON Feed 输出，支持 ETag / Last-Modified 条件请求
+ */
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog-backend/service"
+	"blog-backend/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FeedHandler 订阅源处理器结构体
+type FeedHandler struct {
+	service *service.FeedService
+}
+
+// NewFeedHandler 创建订阅源处理器实例
+func NewFeedHandler() *FeedHandler {
+	return &FeedHandler{
+		service: service.NewFeedService(),
+	}
+}
+
+// RSS 输出 RSS 2.0 订阅源
+func (h *FeedHandler) RSS(c *gin.Context) {
+	h.serve(c, service.FeedFormatRSS)
+}
+
+// Atom 输出 Atom 订阅源
+func (h *FeedHandler) Atom(c *gin.Context) {
+	h.serve(c, service.FeedFormatAtom)
+}
+
+// JSONFeed 输出 JSON Feed 订阅源
+func (h *FeedHandler) JSONFeed(c *gin.Context) {
+	h.serve(c, service.FeedFormatJSON)
+}
+
+// serve 生成订阅源并处理条件请求
+func (h *FeedHandler) serve(c *gin.Context, format string) {
+	var scope service.FeedScope
+	if idStr := c.Param("category_id"); idStr != "" {
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			util.BadRequest(c, "无效的分类ID")
+			return
+		}
+		scope.CategoryID = uint(id)
+	}
+	if idStr := c.Param("tag_id"); idStr != "" {
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			util.BadRequest(c, "无效的标签ID")
+			return
+		}
+		scope.TagID = uint(id)
+	}
+
+	feed, err := h.service.Build(format, scope, c.Request.URL.Path)
+	if err != nil {
+		if errors.Is(err, service.ErrFeedCategoryNotFound) || errors.Is(err, service.ErrFeedTagNotFound) {
+			util.NotFound(c, err.Error())
+			return
+		}
+		util.ServerError(c, "生成订阅源失败")
+		return
+	}
+
+	c.Header("ETag", feed.ETag)
+	c.Header("Last-Modified", feed.LastModified.UTC().Format(http.TimeFormat))
+	c.Header("Cache-Control", "public, max-age=600")
+
+	if notModified(c, feed.ETag, feed.LastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, feed.ContentType, feed.Body)
+}
+
+// notModified 判断客户端缓存是否仍然有效
+// 按 RFC 7232，存在 If-None-Match 时忽略 If-Modified-Since
+func notModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	if inm := c.GetHeader("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := c.GetHeader("If-Modified-Since"); ims != "" {
+		if t, err := http.ParseTime(ims); err == nil {
+			return !lastModified.Truncate(time.Second).After(t)
+		}
+	}
+	return false
+}
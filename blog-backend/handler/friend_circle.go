@@ -0,0 +1,74 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：friend_circle.go
+ * 创建时间：2026-10-17 19:12:08
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：朋友圈处理器，提供朋友圈时间线查询以及订阅抓取状态查看和手动刷新
+ */
+package handler
+
+import (
+	"strconv"
+
+	"blog-backend/service"
+	"blog-backend/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FriendCircleHandler 朋友圈处理器结构体
+type FriendCircleHandler struct {
+	service *service.FriendCircleService
+}
+
+// NewFriendCircleHandler 创建朋友圈处理器实例
+func NewFriendCircleHandler() *FriendCircleHandler {
+	return &FriendCircleHandler{
+		service: service.NewFriendCircleService(),
+	}
+}
+
+// Timeline 获取朋友圈时间线（公开）
+func (h *FriendCircleHandler) Timeline(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 50 {
+		pageSize = 20
+	}
+
+	items, total, err := h.service.ListTimeline(page, pageSize)
+	if err != nil {
+		util.ServerError(c, "获取朋友圈失败")
+		return
+	}
+
+	util.PageSuccess(c, items, total, page, pageSize)
+}
+
+// ListFeeds 获取所有订阅的抓取状态（管理员用）
+func (h *FriendCircleHandler) ListFeeds(c *gin.Context) {
+	states, err := h.service.ListStates()
+	if err != nil {
+		util.ServerError(c, "获取订阅状态失败")
+		return
+	}
+
+	util.Success(c, states)
+}
+
+// Refresh 立即抓取全部订阅（忽略退避时间）
+func (h *FriendCircleHandler) Refresh(c *gin.Context) {
+	summary, err := h.service.FetchAll(true)
+	if err != nil {
+		util.ServerError(c, "刷新朋友圈失败")
+		return
+	}
+
+	util.SuccessWithMessage(c, "朋友圈刷新完成", summary)
+}
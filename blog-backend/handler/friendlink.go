@@ -21,13 +21,15 @@ import (
 
 // FriendLinkHandler 友链处理器结构体
 type FriendLinkHandler struct {
-	service *service.FriendLinkService
+	service       *service.FriendLinkService
+	healthService *service.FriendLinkHealthService
 }
 
 // NewFriendLinkHandler 创建友链处理器实例
 func NewFriendLinkHandler() *FriendLinkHandler {
 	return &FriendLinkHandler{
-		service: service.NewFriendLinkService(),
+		service:       service.NewFriendLinkService(),
+		healthService: service.NewFriendLinkHealthService(),
 	}
 }
 
@@ -134,3 +136,14 @@ func (h *FriendLinkHandler) Delete(c *gin.Context) {
 
 	util.SuccessWithMessage(c, "友链删除成功", nil)
 }
+
+// CheckHealth 立即检查所有启用的友链（管理员用）
+func (h *FriendLinkHandler) CheckHealth(c *gin.Context) {
+	summary, err := h.healthService.CheckAll()
+	if err != nil {
+		util.ServerError(c, "友链健康检查失败")
+		return
+	}
+
+	util.SuccessWithMessage(c, "友链健康检查完成", summary)
+}
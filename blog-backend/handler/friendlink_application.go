@@ -0,0 +1,153 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：friendlink_application.go
+ * 创建时间：2026-10-17 20:48:50
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：友链申请处理器，提供访客提交友链申请以及超级管理员审核友链申请的接口
+ */
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"blog-backend/service"
+	"blog-backend/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FriendLinkApplicationHandler 友链申请处理器结构体
+type FriendLinkApplicationHandler struct {
+	service *service.FriendLinkApplicationService
+}
+
+// NewFriendLinkApplicationHandler 创建友链申请处理器实例
+func NewFriendLinkApplicationHandler() *FriendLinkApplicationHandler {
+	return &FriendLinkApplicationHandler{
+		service: service.NewFriendLinkApplicationService(),
+	}
+}
+
+// Apply 提交友链申请（公开，需要图形验证码）
+func (h *FriendLinkApplicationHandler) Apply(c *gin.Context) {
+	var req service.ApplyFriendLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	app, err := h.service.Apply(&req, util.GetClientIP(c))
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "友链申请已提交，审核结果将通过邮件通知您", gin.H{"id": app.ID})
+}
+
+// List 获取友链申请列表（管理员用）
+func (h *FriendLinkApplicationHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	var status *int
+	if statusStr := c.Query("status"); statusStr != "" {
+		s, err := strconv.Atoi(statusStr)
+		if err != nil {
+			util.BadRequest(c, "无效的状态")
+			return
+		}
+		status = &s
+	}
+
+	apps, total, err := h.service.List(page, pageSize, status)
+	if err != nil {
+		util.ServerError(c, "获取友链申请列表失败")
+		return
+	}
+
+	util.PageSuccess(c, apps, total, page, pageSize)
+}
+
+// Approve 审核通过友链申请
+func (h *FriendLinkApplicationHandler) Approve(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.BadRequest(c, "无效的申请ID")
+		return
+	}
+
+	var req service.ApproveFriendLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "请选择友链分类")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	friendLink, err := h.service.Approve(uint(id), &req, userID.(uint))
+	if err != nil {
+		h.handleReviewError(c, err)
+		return
+	}
+
+	util.SuccessWithMessage(c, "已通过申请并创建友链", friendLink)
+}
+
+// Reject 拒绝友链申请
+func (h *FriendLinkApplicationHandler) Reject(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.BadRequest(c, "无效的申请ID")
+		return
+	}
+
+	var req service.RejectFriendLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "请填写拒绝原因")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.service.Reject(uint(id), &req, userID.(uint)); err != nil {
+		h.handleReviewError(c, err)
+		return
+	}
+
+	util.SuccessWithMessage(c, "已拒绝申请", nil)
+}
+
+// Delete 删除友链申请
+func (h *FriendLinkApplicationHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.BadRequest(c, "无效的申请ID")
+		return
+	}
+
+	if err := h.service.Delete(uint(id)); err != nil {
+		if errors.Is(err, service.ErrApplicationNotFound) {
+			util.NotFound(c, err.Error())
+			return
+		}
+		util.ServerError(c, "删除友链申请失败")
+		return
+	}
+
+	util.SuccessWithMessage(c, "友链申请删除成功", nil)
+}
+
+// handleReviewError 统一处理审核接口的错误响应
+func (h *FriendLinkApplicationHandler) handleReviewError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrApplicationNotFound):
+		util.NotFound(c, err.Error())
+	case errors.Is(err, service.ErrApplicationReviewed):
+		util.Error(c, 409, err.Error())
+	default:
+		util.Error(c, 400, err.Error())
+	}
+}
@@ -0,0 +1,90 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：invite_code.go
+ * 创建时间：2026-10-18 05:42:26
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：注册邀请码处理器，提供邀请码的列表、生成和删除接口（管理员用）
+ */
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"blog-backend/service"
+	"blog-backend/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InviteCodeHandler 注册邀请码处理器结构体
+type InviteCodeHandler struct {
+	service *service.InviteCodeService
+}
+
+// NewInviteCodeHandler 创建注册邀请码处理器实例
+func NewInviteCodeHandler() *InviteCodeHandler {
+	return &InviteCodeHandler{
+		service: service.NewInviteCodeService(),
+	}
+}
+
+// List 获取邀请码列表
+func (h *InviteCodeHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	codes, total, err := h.service.List(page, pageSize)
+	if err != nil {
+		util.ServerError(c, "获取邀请码列表失败")
+		return
+	}
+
+	util.PageSuccess(c, codes, total, page, pageSize)
+}
+
+// Create 生成邀请码
+func (h *InviteCodeHandler) Create(c *gin.Context) {
+	var req service.CreateInviteCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	code, err := h.service.Create(userID.(uint), &req)
+	if err != nil {
+		util.ServerError(c, err.Error())
+		return
+	}
+
+	util.LogOperation(c, "create", "invite_code", &code.ID, code.Code, "生成注册邀请码："+code.Code)
+
+	util.SuccessWithMessage(c, "邀请码已生成", code)
+}
+
+// Delete 删除邀请码
+func (h *InviteCodeHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.BadRequest(c, "无效的邀请码ID")
+		return
+	}
+
+	if err := h.service.Delete(uint(id)); err != nil {
+		if errors.Is(err, service.ErrInviteCodeNotFound) {
+			util.NotFound(c, err.Error())
+			return
+		}
+		util.ServerError(c, err.Error())
+		return
+	}
+
+	codeID := uint(id)
+	util.LogOperation(c, "delete", "invite_code", &codeID, "", "删除注册邀请码")
+
+	util.SuccessWithMessage(c, "邀请码已删除", nil)
+}
@@ -0,0 +1,84 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：login_history.go
+ * 创建时间：2026-10-18 00:52:27
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：登录历史处理器，提供当前用户查看本人登录记录和超级管理员查看全部登录记录的接口
+ */
+package handler
+
+import (
+	"strconv"
+
+	"blog-backend/repository"
+	"blog-backend/service"
+	"blog-backend/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoginHistoryHandler 登录历史处理器结构体
+type LoginHistoryHandler struct {
+	service *service.LoginHistoryService
+}
+
+// NewLoginHistoryHandler 创建登录历史处理器实例
+func NewLoginHistoryHandler() *LoginHistoryHandler {
+	return &LoginHistoryHandler{
+		service: service.NewLoginHistoryService(),
+	}
+}
+
+// ListMine 获取当前用户的登录记录
+func (h *LoginHistoryHandler) ListMine(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	userID, _ := c.Get("user_id")
+
+	list, total, err := h.service.ListByUser(userID.(uint), page, pageSize)
+	if err != nil {
+		util.ServerError(c, "获取登录记录失败")
+		return
+	}
+
+	util.PageSuccess(c, list, total, page, pageSize)
+}
+
+// List 获取全部登录记录（超级管理员）
+// 支持按 user_id、username、ip、success（true/false）筛选
+func (h *LoginHistoryHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	filter := repository.LoginHistoryFilter{
+		Username: c.Query("username"),
+		IP:       c.Query("ip"),
+	}
+	if v := c.Query("user_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			util.BadRequest(c, "无效的用户ID")
+			return
+		}
+		filter.UserID = uint(id)
+	}
+	if v := c.Query("success"); v != "" {
+		success, err := strconv.ParseBool(v)
+		if err != nil {
+			util.BadRequest(c, "success 参数值无效")
+			return
+		}
+		filter.Success = &success
+	}
+
+	list, total, err := h.service.List(page, pageSize, filter)
+	if err != nil {
+		util.ServerError(c, "获取登录记录失败")
+		return
+	}
+
+	util.PageSuccess(c, list, total, page, pageSize)
+}
@@ -198,15 +198,13 @@ func (h *MomentHandler) GetByID(c *gin.Context) {
 	}
 
 	// 权限校验：私密说说仅作者或具备管理员权限的用户可见
-	roleVal, _ := c.Get("role")
-	role, _ := roleVal.(string)
 	var userID *uint
 	if uid, exists := c.Get("user_id"); exists {
 		uidVal := uid.(uint)
 		userID = &uidVal
 	}
 	if moment.Status == 0 { // 私密
-		if !constant.IsAdminRole(role) && (userID == nil || *userID != moment.UserID) {
+		if !util.HasPermission(c, constant.PermMomentManage) && (userID == nil || *userID != moment.UserID) {
 			util.Forbidden(c, "无权查看该说说")
 			return
 		}
@@ -229,9 +227,7 @@ func (h *MomentHandler) List(c *gin.Context) {
 	}
 
 	// 权限：具备管理员权限的用户可查看全部（含私密），普通用户/游客仅公开
-	roleVal, _ := c.Get("role")
-	role, _ := roleVal.(string)
-	if !constant.IsAdminRole(role) {
+	if !util.HasPermission(c, constant.PermMomentManage) {
 		publicStatus := 1
 		status = &publicStatus
 	}
@@ -0,0 +1,162 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：oauth.go
+ * 创建时间：2026-10-18 03:30:19
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：第三方登录处理器，提供授权地址生成、授权回调、一次性票据换取令牌以及账号绑定管理接口
+ */
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"blog-backend/config"
+	"blog-backend/service"
+	"blog-backend/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OAuthHandler 第三方登录处理器结构体
+type OAuthHandler struct {
+	service *service.OAuthService
+}
+
+// NewOAuthHandler 创建第三方登录处理器实例
+func NewOAuthHandler() *OAuthHandler {
+	return &OAuthHandler{
+		service: service.NewOAuthService(),
+	}
+}
+
+// Providers 获取已启用的第三方登录方式
+func (h *OAuthHandler) Providers(c *gin.Context) {
+	util.Success(c, h.service.Providers())
+}
+
+// oauthNonceCookie 保存浏览器 nonce 的 Cookie 名称，将授权回调绑定到发起授权的浏览器
+const oauthNonceCookie = "oauth_nonce"
+
+// Authorize 获取第三方登录的授权页地址
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	provider := c.Param("provider")
+	authURL, nonce, err := h.service.AuthorizeURL(provider, 0)
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	setOAuthNonceCookie(c, provider, nonce, service.OAuthNonceMaxAge)
+	util.Success(c, gin.H{"url": authURL})
+}
+
+// Callback 处理提供方的授权回调，处理完成后携带结果跳转到前端回调页
+// 登录成功时携带一次性票据（ticket），失败时携带错误信息（error）
+func (h *OAuthHandler) Callback(c *gin.Context) {
+	frontendURL := config.Cfg.OAuth.FrontendCallbackURL
+	if frontendURL == "" {
+		util.ServerError(c, "未配置第三方登录回调页")
+		return
+	}
+
+	code := c.Query("code")
+	if providerErr := c.Query("error"); providerErr != "" {
+		code = "" // 用户在授权页拒绝授权
+	}
+
+	provider := c.Param("provider")
+	nonce, _ := c.Cookie(oauthNonceCookie)
+	setOAuthNonceCookie(c, provider, "", -1) // nonce 只能使用一次
+
+	result, err := h.service.Callback(provider, code, c.Query("state"), nonce, util.GetClientIP(c), c.Request.UserAgent())
+
+	params := url.Values{}
+	params.Set("provider", result.Provider)
+	params.Set("mode", result.Mode)
+	if err != nil {
+		params.Set("error", err.Error())
+	} else if result.Ticket != "" {
+		params.Set("ticket", result.Ticket)
+	}
+
+	sep := "?"
+	if strings.Contains(frontendURL, "?") {
+		sep = "&"
+	}
+	c.Redirect(http.StatusFound, frontendURL+sep+params.Encode())
+}
+
+// Exchange 使用一次性票据换取登录令牌
+func (h *OAuthHandler) Exchange(c *gin.Context) {
+	var req service.OAuthExchangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	resp, err := h.service.Exchange(&req, util.GetClientIP(c), c.Request.UserAgent())
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "登录成功", resp)
+}
+
+// ListBindings 获取当前用户的第三方账号绑定状态
+func (h *OAuthHandler) ListBindings(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	bindings, err := h.service.ListBindings(userID.(uint))
+	if err != nil {
+		util.ServerError(c, "获取绑定信息失败")
+		return
+	}
+
+	util.Success(c, bindings)
+}
+
+// Bind 获取绑定第三方账号的授权页地址
+func (h *OAuthHandler) Bind(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	provider := c.Param("provider")
+	authURL, nonce, err := h.service.AuthorizeURL(provider, userID.(uint))
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	setOAuthNonceCookie(c, provider, nonce, service.OAuthNonceMaxAge)
+	util.Success(c, gin.H{"url": authURL})
+}
+
+// Unbind 解除第三方账号绑定
+func (h *OAuthHandler) Unbind(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	if err := h.service.Unlink(userID.(uint), c.Param("provider")); err != nil {
+		if errors.Is(err, service.ErrOAuthBindingNotFound) {
+			util.NotFound(c, err.Error())
+			return
+		}
+		util.ServerError(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "已解除绑定", nil)
+}
+
+// setOAuthNonceCookie 写入（maxAge 为负数时清除）浏览器 nonce Cookie
+// HttpOnly 防止脚本读取；SameSite=Lax 保证从提供方跳转回来的顶层 GET 请求仍会携带；Path 限定为回调地址
+func setOAuthNonceCookie(c *gin.Context, provider, nonce string, maxAge int) {
+	secure := c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https")
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthNonceCookie, nonce, maxAge, service.OAuthCallbackPath(provider), "", secure, true)
+}
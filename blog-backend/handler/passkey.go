@@ -0,0 +1,126 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：passkey.go
+ * 创建时间：2026-10-18 04:21:08
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：通行密钥处理器，提供通行密钥的注册、免密登录以及列表和删除接口
+ */
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"blog-backend/service"
+	"blog-backend/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PasskeyHandler 通行密钥处理器结构体
+type PasskeyHandler struct {
+	service *service.PasskeyService
+}
+
+// NewPasskeyHandler 创建通行密钥处理器实例
+func NewPasskeyHandler() *PasskeyHandler {
+	return &PasskeyHandler{
+		service: service.NewPasskeyService(),
+	}
+}
+
+// BeginRegistration 发起注册，返回 navigator.credentials.create 所需的选项
+func (h *PasskeyHandler) BeginRegistration(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	options, err := h.service.BeginRegistration(userID.(uint))
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.Success(c, options)
+}
+
+// FinishRegistration 提交浏览器返回的注册结果，校验通过后保存通行密钥
+func (h *PasskeyHandler) FinishRegistration(c *gin.Context) {
+	var req service.PasskeyRegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	credential, err := h.service.FinishRegistration(userID.(uint), &req)
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "通行密钥已添加", credential)
+}
+
+// BeginLogin 发起通行密钥登录，返回 navigator.credentials.get 所需的选项
+func (h *PasskeyHandler) BeginLogin(c *gin.Context) {
+	options, err := h.service.BeginLogin()
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.Success(c, options)
+}
+
+// FinishLogin 提交浏览器返回的登录断言，校验通过后签发令牌
+func (h *PasskeyHandler) FinishLogin(c *gin.Context) {
+	var req service.PasskeyLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	resp, err := h.service.FinishLogin(&req, util.GetClientIP(c), c.Request.UserAgent())
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "登录成功", resp)
+}
+
+// List 获取本人的通行密钥列表
+func (h *PasskeyHandler) List(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	credentials, err := h.service.List(userID.(uint))
+	if err != nil {
+		util.ServerError(c, "获取通行密钥列表失败")
+		return
+	}
+
+	util.Success(c, credentials)
+}
+
+// Delete 删除通行密钥
+func (h *PasskeyHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.BadRequest(c, "无效的通行密钥ID")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.service.Delete(userID.(uint), uint(id)); err != nil {
+		if errors.Is(err, service.ErrPasskeyNotFound) {
+			util.NotFound(c, err.Error())
+			return
+		}
+		util.ServerError(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "通行密钥已删除", nil)
+}
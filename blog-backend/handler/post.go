@@ -6,13 +6,14 @@
  * 系统用户：Administrator
  * 作　　者：無以菱
  * 联系邮箱：huangjing510@126.com
- * 功能描述：文章管理处理器，提供文章的增删改查、点赞、归档、导出等功能，支持ID和slug查询
+ * 功能描述：文章管理处理器，提供文章的增删改查、点赞、归档、导入导出等功能，支持ID和slug查询
  */
 package handler
 
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"regexp"
 	"strconv"
 	"strings"
@@ -28,13 +29,15 @@ import (
 
 // PostHandler 文章处理器结构体
 type PostHandler struct {
-	service *service.PostService
+	service       *service.PostService
+	importService *service.PostImportService
 }
 
 // NewPostHandler 创建文章处理器实例
 func NewPostHandler() *PostHandler {
 	return &PostHandler{
-		service: service.NewPostService(),
+		service:       service.NewPostService(),
+		importService: service.NewPostImportService(),
 	}
 }
 
@@ -52,9 +55,9 @@ func (h *PostHandler) Create(c *gin.Context) {
 		return
 	}
 
-	// 确保 status 字段正确传递（0 或 1）
+	// 确保 status 字段正确传递（0、1 或 2）
 	// 如果前端没有传递 status，默认为 1（发布）
-	if req.Status != 0 && req.Status != 1 {
+	if req.Status != 0 && req.Status != 1 && req.Status != 2 {
 		req.Status = 1
 	}
 
@@ -189,11 +192,13 @@ func (h *PostHandler) List(c *gin.Context) {
 	keyword := c.Query("keyword")
 	status, _ := strconv.Atoi(c.DefaultQuery("status", "1"))
 
-	// 默认只返回公开文章；管理员则可以查看所有可见性
+	// 默认只返回公开文章；管理员则可以查看所有可见性和状态
+	// 非管理员只能查看已发布文章，避免通过 status 参数看到定时发布的文章
 	var visibility *int
-	if r, exists := c.Get("role"); !exists || !constant.IsAdminRole(r.(string)) {
+	if !util.HasPermission(c, constant.PermPostManage) {
 		v := 1
 		visibility = &v
+		status = 1
 	}
 
 	posts, total, err := h.service.List(page, pageSize, uint(categoryID), keyword, status, visibility)
@@ -287,16 +292,12 @@ func (h *PostHandler) GetRecentPosts(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 
 	var userID *uint
-	var role string
 	if uid, exists := c.Get("user_id"); exists {
 		id := uid.(uint)
 		userID = &id
 	}
-	if r, exists := c.Get("role"); exists {
-		role = r.(string)
-	}
 
-	posts, err := h.service.GetRecentPosts(limit, userID, role)
+	posts, err := h.service.GetRecentPosts(limit, userID, util.HasPermission(c, constant.PermPostManage))
 	if err != nil {
 		util.ServerError(c, "获取最新文章失败")
 		return
@@ -352,6 +353,146 @@ func (h *PostHandler) Export(c *gin.Context) {
 	c.String(200, buf.String())
 }
 
+// ListRevisions 获取文章修订历史列表
+func (h *PostHandler) ListRevisions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.BadRequest(c, "无效的文章ID")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	revisions, total, err := h.service.ListRevisions(uint(id), page, pageSize)
+	if err != nil {
+		util.Error(c, 404, err.Error())
+		return
+	}
+
+	util.PageSuccess(c, revisions, total, page, pageSize)
+}
+
+// GetRevision 获取单个修订版本详情
+func (h *PostHandler) GetRevision(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.BadRequest(c, "无效的文章ID")
+		return
+	}
+	revisionID, err := strconv.ParseUint(c.Param("revision_id"), 10, 32)
+	if err != nil {
+		util.BadRequest(c, "无效的修订ID")
+		return
+	}
+
+	revision, err := h.service.GetRevision(uint(id), uint(revisionID))
+	if err != nil {
+		util.Error(c, 404, err.Error())
+		return
+	}
+
+	util.Success(c, revision)
+}
+
+// DiffRevisions 对比两个修订版本
+// 查询参数 from 必填；to 为空或 0 时与文章当前内容对比
+func (h *PostHandler) DiffRevisions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.BadRequest(c, "无效的文章ID")
+		return
+	}
+	fromID, err := strconv.ParseUint(c.Query("from"), 10, 32)
+	if err != nil || fromID == 0 {
+		util.BadRequest(c, "无效的起始修订ID")
+		return
+	}
+	toID, _ := strconv.ParseUint(c.DefaultQuery("to", "0"), 10, 32)
+
+	diff, err := h.service.DiffRevisions(uint(id), uint(fromID), uint(toID))
+	if err != nil {
+		util.Error(c, 404, err.Error())
+		return
+	}
+
+	util.Success(c, diff)
+}
+
+// RestoreRevision 恢复指定修订版本为文章当前内容
+func (h *PostHandler) RestoreRevision(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.BadRequest(c, "无效的文章ID")
+		return
+	}
+	revisionID, err := strconv.ParseUint(c.Param("revision_id"), 10, 32)
+	if err != nil {
+		util.BadRequest(c, "无效的修订ID")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	role, _ := c.Get("role")
+
+	post, err := h.service.RestoreRevision(uint(id), uint(revisionID), userID.(uint), role.(string))
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	// 记录操作日志
+	postID := post.ID
+	util.LogOperation(c, "restore", "post", &postID, post.Title, fmt.Sprintf("恢复文章修订版本 #%d：%s", revisionID, post.Title))
+
+	util.SuccessWithMessage(c, "修订版本恢复成功", post)
+}
+
+// Import 导入 Markdown 文章（与 Export 格式互通）
+// 表单字段：
+//   - file: 单个 .md 文件或包含多个 .md 文件的 .zip 压缩包
+//   - default_category_id: 可选，Front Matter 未指定分类时使用的分类ID
+func (h *PostHandler) Import(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		util.BadRequest(c, "请选择要导入的文件")
+		return
+	}
+	if file.Size > service.MaxImportArchiveSize {
+		util.BadRequest(c, "文件大小超过限制（最大 20MB）")
+		return
+	}
+
+	defaultCategoryID, _ := strconv.ParseUint(c.DefaultPostForm("default_category_id", "0"), 10, 32)
+
+	f, err := file.Open()
+	if err != nil {
+		util.ServerError(c, "读取文件失败")
+		return
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, service.MaxImportArchiveSize+1))
+	if err != nil {
+		util.ServerError(c, "读取文件失败")
+		return
+	}
+
+	report, err := h.importService.Import(userID.(uint), file.Filename, data, uint(defaultCategoryID))
+	if err != nil {
+		util.BadRequest(c, err.Error())
+		return
+	}
+
+	// 记录操作日志
+	util.LogOperation(c, "import", "post", nil, file.Filename,
+		fmt.Sprintf("导入文章：%s（创建 %d，跳过 %d，失败 %d）", file.Filename, report.Created, report.Skipped, report.Failed))
+
+	util.SuccessWithMessage(c, "导入完成", report)
+}
+
 // escapeYAML 简单转义引号
 func escapeYAML(s string) string {
 	return strings.ReplaceAll(s, "\"", "\\\"")
@@ -0,0 +1,123 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：role.go
+ * 创建时间：2026-10-18 02:38:52
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：角色管理处理器，提供权限列表以及自定义角色的增删改查接口
+ */
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"blog-backend/service"
+	"blog-backend/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleHandler 角色处理器结构体
+type RoleHandler struct {
+	service *service.RoleService
+}
+
+// NewRoleHandler 创建角色处理器实例
+func NewRoleHandler() *RoleHandler {
+	return &RoleHandler{
+		service: service.NewRoleService(),
+	}
+}
+
+// Permissions 获取所有可分配的权限
+func (h *RoleHandler) Permissions(c *gin.Context) {
+	util.Success(c, h.service.ListPermissions())
+}
+
+// List 获取角色列表
+func (h *RoleHandler) List(c *gin.Context) {
+	roles, err := h.service.List()
+	if err != nil {
+		util.ServerError(c, "获取角色列表失败")
+		return
+	}
+
+	util.Success(c, roles)
+}
+
+// Create 创建自定义角色
+func (h *RoleHandler) Create(c *gin.Context) {
+	var req service.RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	role, _ := c.Get("role")
+	created, err := h.service.Create(role.(string), &req)
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.LogOperation(c, "create", "role", &created.ID, created.Name, "创建角色："+created.DisplayName+"（"+created.Name+"）")
+
+	util.SuccessWithMessage(c, "角色创建成功", created)
+}
+
+// Update 更新角色信息和权限
+func (h *RoleHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.BadRequest(c, "无效的角色ID")
+		return
+	}
+
+	var req service.RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	role, _ := c.Get("role")
+	updated, err := h.service.Update(role.(string), uint(id), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrRoleNotFound) {
+			util.NotFound(c, err.Error())
+			return
+		}
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.LogOperation(c, "update", "role", &updated.ID, updated.Name, "更新角色："+updated.DisplayName+"（"+updated.Name+"）")
+
+	util.SuccessWithMessage(c, "角色更新成功", updated)
+}
+
+// Delete 删除自定义角色
+func (h *RoleHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.BadRequest(c, "无效的角色ID")
+		return
+	}
+
+	role, _ := c.Get("role")
+	deleted, err := h.service.Delete(role.(string), uint(id))
+	if err != nil {
+		if errors.Is(err, service.ErrRoleNotFound) {
+			util.NotFound(c, err.Error())
+			return
+		}
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.LogOperation(c, "delete", "role", &deleted.ID, deleted.Name, "删除角色："+deleted.DisplayName+"（"+deleted.Name+"）")
+
+	util.SuccessWithMessage(c, "角色删除成功", nil)
+}
@@ -0,0 +1,86 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：session.go
+ * 创建时间：2026-10-17 22:14:37
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：登录会话处理器，提供当前用户查看登录设备列表以及吊销单个或全部会话的功能
+ */
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"blog-backend/service"
+	"blog-backend/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionHandler 登录会话处理器结构体
+type SessionHandler struct {
+	service *service.SessionService
+}
+
+// NewSessionHandler 创建登录会话处理器实例
+func NewSessionHandler() *SessionHandler {
+	return &SessionHandler{
+		service: service.NewSessionService(),
+	}
+}
+
+// List 获取当前用户的登录会话列表
+func (h *SessionHandler) List(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	sessions, err := h.service.ListSessions(userID.(uint), c.GetUint("session_id"))
+	if err != nil {
+		util.ServerError(c, "获取会话列表失败")
+		return
+	}
+
+	util.Success(c, sessions)
+}
+
+// Revoke 吊销指定会话
+func (h *SessionHandler) Revoke(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.BadRequest(c, "无效的会话ID")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.service.RevokeSession(userID.(uint), uint(id)); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			util.NotFound(c, err.Error())
+			return
+		}
+		util.ServerError(c, "吊销会话失败")
+		return
+	}
+
+	util.SuccessWithMessage(c, "会话已吊销", nil)
+}
+
+// RevokeAll 吊销当前用户的所有会话
+// 查询参数 keep_current=true 时保留当前会话（即"退出其他设备"）
+func (h *SessionHandler) RevokeAll(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var exceptID uint
+	if c.Query("keep_current") == "true" {
+		exceptID = c.GetUint("session_id")
+	}
+
+	count, err := h.service.RevokeAllSessions(userID.(uint), exceptID)
+	if err != nil {
+		util.ServerError(c, "吊销会话失败")
+		return
+	}
+
+	util.SuccessWithMessage(c, "会话已吊销", gin.H{"revoked": count})
+}
@@ -208,6 +208,33 @@ func (h *SettingHandler) UpdateRegisterSettings(c *gin.Context) {
 	util.SuccessWithMessage(c, "更新成功", nil)
 }
 
+// GetSecuritySettings 获取安全配置（仅超级管理员）
+func (h *SettingHandler) GetSecuritySettings(c *gin.Context) {
+	settings, err := h.service.GetSecuritySettings()
+	if err != nil {
+		util.Error(c, 500, "获取安全配置失败")
+		return
+	}
+
+	util.Success(c, settings)
+}
+
+// UpdateSecuritySettings 更新安全配置（仅超级管理员）
+func (h *SettingHandler) UpdateSecuritySettings(c *gin.Context) {
+	var req map[string]string
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.service.UpdateSecuritySettings(req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "更新成功", nil)
+}
+
 // GetAboutInfo 获取关于我信息（仅管理员）
 func (h *SettingHandler) GetAboutInfo(c *gin.Context) {
 	content, err := h.service.GetAboutInfo()
@@ -0,0 +1,67 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：sitemap.go
+ * 创建时间：2026-10-17 17:41:18
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：站点地图处理器，提供 sitemap.xml、站点地图分片和 robots.txt 输出
+ */
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"blog-backend/service"
+	"blog-backend/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SitemapHandler 站点地图处理器结构体
+type SitemapHandler struct {
+	service *service.SitemapService
+}
+
+// NewSitemapHandler 创建站点地图处理器实例
+func NewSitemapHandler() *SitemapHandler {
+	return &SitemapHandler{
+		service: service.NewSitemapService(),
+	}
+}
+
+// Sitemap 输出 /sitemap.xml（文章较多时为站点地图索引）
+func (h *SitemapHandler) Sitemap(c *gin.Context) {
+	data, err := h.service.Sitemap()
+	if err != nil {
+		util.ServerError(c, "生成站点地图失败")
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", data)
+}
+
+// SitemapPart 输出站点地图索引中的分片（pages.xml / posts-N.xml）
+func (h *SitemapHandler) SitemapPart(c *gin.Context) {
+	data, err := h.service.Part(c.Param("name"))
+	if err != nil {
+		if errors.Is(err, service.ErrSitemapNotFound) {
+			util.NotFound(c, err.Error())
+			return
+		}
+		util.ServerError(c, "生成站点地图失败")
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", data)
+}
+
+// Robots 输出 /robots.txt
+func (h *SitemapHandler) Robots(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.String(http.StatusOK, h.service.Robots())
+}
@@ -0,0 +1,126 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：two_factor.go
+ * 创建时间：2026-10-17 23:21:06
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：两步验证处理器，提供TOTP绑定、启用、停用、恢复码重新生成以及登录第二步验证接口
+ */
+package handler
+
+import (
+	"blog-backend/service"
+	"blog-backend/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorHandler 两步验证处理器结构体
+type TwoFactorHandler struct {
+	service *service.TwoFactorService
+}
+
+// NewTwoFactorHandler 创建两步验证处理器实例
+func NewTwoFactorHandler() *TwoFactorHandler {
+	return &TwoFactorHandler{
+		service: service.NewTwoFactorService(),
+	}
+}
+
+// Login 登录第二步：提交挑战令牌和验证码（或恢复码）
+func (h *TwoFactorHandler) Login(c *gin.Context) {
+	var req service.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	resp, err := h.service.CompleteLogin(&req, util.GetClientIP(c), c.Request.UserAgent())
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "登录成功", resp)
+}
+
+// Status 获取当前用户的两步验证状态
+func (h *TwoFactorHandler) Status(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	status, err := h.service.GetStatus(userID.(uint))
+	if err != nil {
+		util.ServerError(c, err.Error())
+		return
+	}
+
+	util.Success(c, status)
+}
+
+// Setup 生成TOTP密钥和配置链接
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	setup, err := h.service.Setup(userID.(uint))
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.Success(c, setup)
+}
+
+// Enable 提交首个验证码启用两步验证，返回恢复码
+func (h *TwoFactorHandler) Enable(c *gin.Context) {
+	var req service.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "请输入验证码")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	codes, err := h.service.Enable(userID.(uint), &req)
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "两步验证已启用，请妥善保存恢复码", gin.H{"recovery_codes": codes})
+}
+
+// Disable 停用两步验证
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var req service.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "请输入密码和验证码")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.service.Disable(userID.(uint), &req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "两步验证已停用", nil)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req service.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "请输入验证码")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	codes, err := h.service.RegenerateRecoveryCodes(userID.(uint), &req)
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "恢复码已重新生成，原有恢复码已失效", gin.H{"recovery_codes": codes})
+}
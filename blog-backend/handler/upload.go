@@ -35,10 +35,8 @@ func (h *UploadHandler) UploadAvatar(c *gin.Context) {
 	}
 
 	var fileURL string
-	// 获取用户角色，普通用户强制使用本地存储
-	roleVal, exists := c.Get("role")
-	role, _ := roleVal.(string)
-	if exists && constant.IsAdminRole(role) {
+	// 普通用户强制使用本地存储
+	if util.HasPermission(c, constant.PermAdminAccess) {
 		// 具备管理员权限的用户使用配置的存储方式（本地/OSS/COS）
 		fileURL, err = util.UploadFile(file, util.AvatarDir)
 		if err != nil {
@@ -71,10 +69,8 @@ func (h *UploadHandler) UploadImage(c *gin.Context) {
 	}
 
 	var fileURL string
-	// 获取用户角色，普通用户强制使用本地存储
-	roleVal, exists := c.Get("role")
-	role, _ := roleVal.(string)
-	if exists && constant.IsAdminRole(role) {
+	// 普通用户强制使用本地存储
+	if util.HasPermission(c, constant.PermAdminAccess) {
 		// 具备管理员权限的用户使用配置的存储方式（本地/OSS/COS）
 		fileURL, err = util.UploadFile(file, util.UploadDir)
 		if err != nil {
@@ -53,7 +53,17 @@ func (h *UserHandler) List(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
 
-	users, total, err := h.service.List(page, pageSize)
+	var status *int
+	if statusStr := c.Query("status"); statusStr != "" {
+		s, err := strconv.Atoi(statusStr)
+		if err != nil {
+			util.BadRequest(c, "无效的状态")
+			return
+		}
+		status = &s
+	}
+
+	users, total, err := h.service.List(page, pageSize, status)
 	if err != nil {
 		util.ServerError(c, "获取用户列表失败")
 		return
@@ -101,7 +111,8 @@ func (h *UserHandler) UpdateStatus(c *gin.Context) {
 		statusText = "禁用"
 	}
 
-	if err := h.service.UpdateStatus(uint(id), *req.Status); err != nil {
+	role, _ := c.Get("role")
+	if err := h.service.UpdateStatus(role.(string), uint(id), *req.Status); err != nil {
 		util.Error(c, 400, err.Error())
 		return
 	}
@@ -136,7 +147,8 @@ func (h *UserHandler) UpdateRole(c *gin.Context) {
 		username = user.Username
 	}
 
-	if err := h.service.UpdateRole(uint(id), req.Role); err != nil {
+	role, _ := c.Get("role")
+	if err := h.service.UpdateRole(role.(string), uint(id), req.Role); err != nil {
 		util.Error(c, 400, err.Error())
 		return
 	}
@@ -148,6 +160,93 @@ func (h *UserHandler) UpdateRole(c *gin.Context) {
 	util.SuccessWithMessage(c, "角色更新成功", nil)
 }
 
+// Unlock 解除账号的登录锁定
+func (h *UserHandler) Unlock(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.BadRequest(c, "无效的用户ID")
+		return
+	}
+
+	// 先获取用户信息用于日志记录
+	user, _ := h.service.GetByID(uint(id))
+	var username string
+	if user != nil {
+		username = user.Username
+	}
+
+	if err := h.service.Unlock(uint(id)); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	// 记录操作日志
+	userID := uint(id)
+	util.LogOperation(c, "update", "user", &userID, username, "解除账号登录锁定："+username)
+
+	util.SuccessWithMessage(c, "已解除锁定", nil)
+}
+
+// Approve 审核通过待审核用户
+func (h *UserHandler) Approve(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.BadRequest(c, "无效的用户ID")
+		return
+	}
+
+	user, _ := h.service.GetByID(uint(id))
+	var username string
+	if user != nil {
+		username = user.Username
+	}
+
+	if err := h.service.Approve(uint(id)); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	// 记录操作日志
+	userID := uint(id)
+	util.LogOperation(c, "update", "user", &userID, username, "注册审核通过："+username)
+
+	util.SuccessWithMessage(c, "已通过审核", nil)
+}
+
+// Reject 拒绝待审核用户（删除账号并邮件通知）
+func (h *UserHandler) Reject(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.BadRequest(c, "无效的用户ID")
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason" binding:"max=200"` // 拒绝原因（可选，会写入通知邮件）
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	user, _ := h.service.GetByID(uint(id))
+	var username string
+	if user != nil {
+		username = user.Username
+	}
+
+	if err := h.service.Reject(uint(id), req.Reason); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	// 记录操作日志
+	userID := uint(id)
+	util.LogOperation(c, "delete", "user", &userID, username, "注册审核未通过："+username)
+
+	util.SuccessWithMessage(c, "已拒绝注册申请", nil)
+}
+
 // Delete 删除用户
 func (h *UserHandler) Delete(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -163,7 +262,8 @@ func (h *UserHandler) Delete(c *gin.Context) {
 		username = user.Username
 	}
 
-	if err := h.service.Delete(uint(id)); err != nil {
+	role, _ := c.Get("role")
+	if err := h.service.Delete(role.(string), uint(id)); err != nil {
 		util.Error(c, 400, err.Error())
 		return
 	}
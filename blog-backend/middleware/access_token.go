@@ -0,0 +1,78 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：access_token.go
+ * 创建时间：2026-10-18 01:41:33
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：个人访问令牌认证，校验令牌状态、所属用户状态和接口要求的权限范围
+ */
+package middleware
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"blog-backend/logger"
+	"blog-backend/repository"
+	"blog-backend/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// authenticateAccessToken 使用个人访问令牌认证请求，成功时将用户信息和令牌权限范围存入上下文
+// 失败时直接写入错误响应并中止请求
+// 参数:
+//   - token: 请求携带的个人访问令牌
+//   - scopes: 接口要求的权限范围（令牌需全部具备）；为空表示该接口不接受个人访问令牌
+//
+// 返回:
+//   - bool: 是否认证通过
+func authenticateAccessToken(c *gin.Context, token string, scopes []string) bool {
+	if len(scopes) == 0 {
+		util.Forbidden(c, "该接口不支持使用个人访问令牌访问")
+		c.Abort()
+		return false
+	}
+
+	pat, err := repository.NewAccessTokenRepository().GetByHash(util.HashPersonalAccessToken(token))
+	if err != nil || pat.RevokedAt != nil || time.Now().After(pat.ExpiresAt) {
+		util.Unauthorized(c, "无效的认证信息")
+		c.Abort()
+		return false
+	}
+
+	var missing []string
+	for _, scope := range scopes {
+		if !pat.HasScope(scope) {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) > 0 {
+		util.Forbidden(c, "令牌缺少权限范围: "+strings.Join(missing, ", "))
+		c.Abort()
+		return false
+	}
+
+	// 角色以数据库为准，账号被禁用后令牌立即失效
+	user, err := repository.NewUserRepository().GetByID(pat.UserID)
+	if err != nil || user.Status != 1 {
+		util.Unauthorized(c, "无效的认证信息")
+		c.Abort()
+		return false
+	}
+
+	if err := repository.NewAccessTokenRepository().Touch(pat.ID, util.GetClientIP(c)); err != nil {
+		logger.Error(fmt.Sprintf("更新个人访问令牌 %d 的使用记录失败: %v", pat.ID, err))
+	}
+
+	c.Set("user_id", user.ID)
+	c.Set("username", user.Username)
+	c.Set("role", user.Role)
+	c.Set("session_id", uint(0))
+	c.Set("token_id", pat.ID)
+	c.Set("token_scopes", pat.ScopeList())
+	return checkAdminTwoFactor(c, user.ID)
+}
@@ -6,15 +6,17 @@
  * 系统用户：Administrator
  * 作　　者：無以菱
  * 联系邮箱：huangjing510@126.com
- * 功能描述：认证中间件，提供JWT认证、可选认证和管理员权限验证功能
+ * 功能描述：认证中间件，提供JWT认证、可选认证和基于角色权限的访问控制
  */
 package middleware
 
 import (
 	"errors"
+	"net/http"
 	"strings"
 
 	"blog-backend/constant"
+	"blog-backend/model"
 	"blog-backend/repository"
 	"blog-backend/util"
 
@@ -22,12 +24,18 @@ import (
 	"gorm.io/gorm"
 )
 
+// currentUserKey 上下文中已校验的当前用户键名
+const currentUserKey = "current_user"
+
 // AuthMiddleware JWT认证中间件
 // 功能说明：验证请求中的JWT Token，解析用户信息并存入上下文
 // 要求：请求头必须包含有效的Authorization: Bearer <token>
+// 参数:
+//   - scopes: 允许使用个人访问令牌访问时令牌需要具备的权限范围；为空时只接受JWT
+//
 // 返回:
 //   - gin.HandlerFunc: Gin中间件处理函数
-func AuthMiddleware() gin.HandlerFunc {
+func AuthMiddleware(scopes ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 获取Authorization请求头
 		authHeader := c.GetHeader("Authorization")
@@ -45,8 +53,16 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// 解析Token
-		claims, err := util.ParseToken(parts[1])
+		// 个人访问令牌只能访问声明了所需权限范围的接口
+		if util.IsPersonalAccessToken(parts[1]) {
+			if authenticateAccessToken(c, parts[1], scopes) {
+				c.Next()
+			}
+			return
+		}
+
+		// 解析Token（同时检查是否已被吊销）
+		claims, err := util.ParseValidToken(parts[1])
 		if err != nil {
 			util.Unauthorized(c, "无效的认证信息")
 			c.Abort()
@@ -57,6 +73,11 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("role", claims.Role)
+		c.Set("session_id", claims.SessionID)
+
+		if !checkAdminTwoFactor(c, claims.UserID) {
+			return
+		}
 
 		c.Next()
 	}
@@ -87,12 +108,13 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 
 		// 如果有token，尝试解析
 		if token != "" {
-			claims, err := util.ParseToken(token)
+			claims, err := util.ParseValidToken(token)
 			if err == nil {
 				// 认证成功，存储用户信息
 				c.Set("user_id", claims.UserID)
 				c.Set("username", claims.Username)
 				c.Set("role", claims.Role)
+				c.Set("session_id", claims.SessionID)
 			}
 		}
 
@@ -101,94 +123,128 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 	}
 }
 
-// RoleRequiredMiddleware 角色权限中间件
-// 功能说明：验证当前用户是否属于指定角色之一
-// 要求：必须在 AuthMiddleware 或 OptionalAuthMiddleware 之后使用
+// RequirePermission 权限中间件
+// 功能说明：验证当前用户的角色是否拥有全部指定权限，角色及其权限集合保存在数据库中
+// 要求：必须在 AuthMiddleware 之后使用
 // 参数:
-//   - roles: 允许访问的角色列表
+//   - permissions: 需要的权限（constant.Perm*）
 //
 // 返回:
 //   - gin.HandlerFunc: Gin中间件处理函数
+//
 // 注意：此中间件会从数据库验证用户的实际角色，确保角色变更后旧token立即失效
-func RoleRequiredMiddleware(roles ...string) gin.HandlerFunc {
+func RequirePermission(permissions ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 获取用户ID（从AuthMiddleware或OptionalAuthMiddleware设置）
-		userIDVal, exists := c.Get("user_id")
-		if !exists {
-			util.Forbidden(c, "权限不足")
-			c.Abort()
-			return
-		}
-
-		userID, ok := userIDVal.(uint)
+		user, ok := loadCurrentUser(c)
 		if !ok {
-			util.Forbidden(c, "用户信息异常")
-			c.Abort()
 			return
 		}
 
-		// 从数据库查询用户的实际角色和状态（确保使用最新数据）
-		userRepo := repository.NewUserRepository()
-		user, err := userRepo.GetByID(userID)
-		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				util.Unauthorized(c, "用户不存在，请重新登录")
+		// 权限集合在同一请求内只加载一次，嵌套的 RequirePermission 和处理器直接复用
+		for _, permission := range permissions {
+			if !util.HasPermission(c, permission) {
+				util.Forbidden(c, "需要更高权限")
 				c.Abort()
 				return
 			}
-			util.Error(c, 500, "获取用户信息失败")
-			c.Abort()
-			return
 		}
 
-		// 检查用户状态（如果被禁用，拒绝访问）
-		if user.Status != 1 {
-			util.Unauthorized(c, "账号已被禁用，请重新登录")
-			c.Abort()
-			return
-		}
+		// 管理员两步验证要求已由 AuthMiddleware 按同一角色检查（角色变更的Token在 loadCurrentUser 中被拒绝）
 
-		// 获取token中的角色（用于对比）
-		tokenRoleVal, exists := c.Get("role")
-		if !exists {
-			util.Forbidden(c, "权限不足")
-			c.Abort()
-			return
-		}
+		// 更新上下文中的角色为数据库中的最新角色（确保后续使用最新数据）
+		c.Set("role", user.Role)
+		c.Next()
+	}
+}
 
-		tokenRole, ok := tokenRoleVal.(string)
-		if !ok {
-			util.Forbidden(c, "权限信息异常")
-			c.Abort()
-			return
+// loadCurrentUser 从数据库加载当前请求的用户，并校验账号状态和Token中的角色是否仍然有效
+// 校验通过的用户保存在上下文中，同一请求内再次调用时不再查询数据库
+// 校验失败时直接写入错误响应并中止请求
+func loadCurrentUser(c *gin.Context) (*model.User, bool) {
+	if val, exists := c.Get(currentUserKey); exists {
+		if user, ok := val.(*model.User); ok {
+			return user, true
 		}
+	}
+
+	// 获取用户ID（从AuthMiddleware或OptionalAuthMiddleware设置）
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		util.Forbidden(c, "权限不足")
+		c.Abort()
+		return nil, false
+	}
 
-		// 验证token中的角色与数据库中的角色是否一致
-		// 如果不一致，说明用户角色已被修改，要求重新登录
-		if tokenRole != user.Role {
-			util.Unauthorized(c, "用户权限已变更，请重新登录")
+	userID, ok := userIDVal.(uint)
+	if !ok {
+		util.Forbidden(c, "用户信息异常")
+		c.Abort()
+		return nil, false
+	}
+
+	// 从数据库查询用户的实际角色和状态（确保使用最新数据）
+	user, err := repository.NewUserRepository().GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			util.Unauthorized(c, "用户不存在，请重新登录")
 			c.Abort()
-			return
+			return nil, false
 		}
+		util.Error(c, 500, "获取用户信息失败")
+		c.Abort()
+		return nil, false
+	}
 
-		// 使用数据库中的实际角色进行权限检查
-		currentRole := user.Role
-		for _, allowed := range roles {
-			if currentRole == allowed {
-				// 更新上下文中的角色为数据库中的最新角色（确保后续使用最新数据）
-				c.Set("role", currentRole)
-				c.Next()
-				return
-			}
-		}
+	// 检查用户状态（如果被禁用，拒绝访问）
+	if user.Status != 1 {
+		util.Unauthorized(c, "账号已被禁用，请重新登录")
+		c.Abort()
+		return nil, false
+	}
 
-		util.Forbidden(c, "需要更高权限")
+	// 验证token中的角色与数据库中的角色是否一致
+	// 如果不一致，说明用户角色已被修改，要求重新登录
+	tokenRole, _ := c.Get("role")
+	if role, ok := tokenRole.(string); !ok || role != user.Role {
+		util.Unauthorized(c, "用户权限已变更，请重新登录")
 		c.Abort()
+		return nil, false
+	}
+
+	c.Set(currentUserKey, user)
+	return user, true
+}
+
+// checkAdminTwoFactor 站点强制管理员启用两步验证时，未启用的管理员只能访问个人账号和两步验证相关接口
+// 不满足要求时直接写入错误响应并中止请求
+func checkAdminTwoFactor(c *gin.Context, userID uint) bool {
+	if !util.HasPermission(c, constant.PermAdminAccess) || !twoFactorSetupPending(userID) {
+		return true
 	}
+	if twoFactorSetupExempt(c) {
+		return true
+	}
+	util.Forbidden(c, "站点要求管理员启用两步验证，请先在个人中心完成设置")
+	c.Abort()
+	return false
 }
 
-// AdminMiddleware 兼容保留：管理员权限中间件
-// 目前视为具备后台管理权限的角色（包含 super_admin 和 admin）
-func AdminMiddleware() gin.HandlerFunc {
-	return RoleRequiredMiddleware(constant.RoleSuperAdmin, constant.RoleAdmin)
+// twoFactorSetupExempt 当前请求是否为未启用两步验证的管理员仍可访问的接口
+// 允许 /api/auth 下的个人账号和两步验证接口，但不允许创建个人访问令牌（令牌可绕过两步验证长期访问）
+func twoFactorSetupExempt(c *gin.Context) bool {
+	path := c.FullPath()
+	if !strings.HasPrefix(path, "/api/auth/") {
+		return false
+	}
+	return !(c.Request.Method == http.MethodPost && path == "/api/auth/tokens")
+}
+
+// twoFactorSetupPending 站点开启了"强制管理员启用两步验证"且该用户尚未启用
+func twoFactorSetupPending(userID uint) bool {
+	setting, err := repository.NewSettingRepository().GetByKey("force_admin_2fa")
+	if err != nil || setting.Value != "1" {
+		return false
+	}
+	enabled, err := repository.NewTwoFactorRepository().IsEnabled(userID)
+	return err == nil && !enabled
 }
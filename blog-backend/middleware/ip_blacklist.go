@@ -15,6 +15,7 @@ import (
 	"blog-backend/constant"
 	"blog-backend/db"
 	"blog-backend/model"
+	"blog-backend/repository"
 	"blog-backend/util"
 	"net"
 	"strings"
@@ -343,11 +344,8 @@ func shouldSkipRateLimit(c *gin.Context, ip string) bool {
 // 通过解析 JWT Token 获取用户角色
 func isAdminUser(c *gin.Context) bool {
 	// 优先从上下文获取（如果 OptionalAuthMiddleware 已执行）
-	if role, exists := c.Get("role"); exists {
-		if r, ok := role.(string); ok {
-			return constant.IsAdminRole(r)
-		}
-		return false
+	if _, exists := c.Get("role"); exists {
+		return util.HasPermission(c, constant.PermAdminAccess)
 	}
 
 	// 如果上下文中没有，尝试从 Header 解析 Token
@@ -363,13 +361,13 @@ func isAdminUser(c *gin.Context) bool {
 	}
 
 	// 解析 Token
-	claims, err := util.ParseToken(parts[1])
+	claims, err := util.ParseValidToken(parts[1])
 	if err != nil {
 		return false
 	}
 
-	// 检查是否是具备管理员权限的角色
-	return constant.IsAdminRole(claims.Role)
+	// 检查是否是可以进入管理后台的角色
+	return repository.NewRoleRepository().HasPermission(claims.Role, constant.PermAdminAccess)
 }
 
 // isIPInWhitelist 检查 IP 是否在白名单中（配置文件 + 数据库）
@@ -11,6 +11,7 @@
 package model
 
 import (
+	"strings"
 	"time"
 )
 
@@ -24,10 +25,51 @@ type User struct {
 	Nickname  string    `json:"nickname" gorm:"size:50"`
 	Avatar    string    `json:"avatar" gorm:"size:255"`
 	Bio       string    `json:"bio" gorm:"size:500"`
-	Role      string    `json:"role" gorm:"default:user;size:20"` // admin, user
-	Status    int       `json:"status" gorm:"default:1"`          // 1:正常 0:禁用
+	Role      string    `json:"role" gorm:"default:user;size:20"` // 角色名称，对应 roles.name
+	Status    int       `json:"status" gorm:"default:1"`          // 1:正常 0:禁用 2:待审核
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Permissions 当前角色拥有的权限（不入库，仅在登录和获取个人信息时返回）
+	Permissions []string `json:"permissions,omitempty" gorm:"-"`
+}
+
+// 用户状态
+const (
+	UserStatusDisabled = 0 // 已禁用
+	UserStatusActive   = 1 // 正常
+	UserStatusPending  = 2 // 待审核（注册需管理员审核时）
+)
+
+// Role 角色模型
+// 功能说明：角色即权限集合；内置角色（super_admin、admin、user）不能删除，super_admin 始终拥有全部权限
+type Role struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"uniqueIndex;not null;size:20"` // 角色标识，写入 users.role 和 JWT
+	DisplayName string    `json:"display_name" gorm:"not null;size:50"`
+	Description string    `json:"description" gorm:"size:255"`
+	IsSystem    bool      `json:"is_system" gorm:"default:false"` // 是否为内置角色
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// 关联关系
+	Permissions []RolePermission `json:"-" gorm:"foreignKey:RoleID"`
+}
+
+// TableName 指定Role模型的数据库表名
+func (Role) TableName() string {
+	return "roles"
+}
+
+// RolePermission 角色权限模型
+type RolePermission struct {
+	RoleID     uint   `json:"role_id" gorm:"primaryKey;autoIncrement:false"`
+	Permission string `json:"permission" gorm:"primaryKey;size:50"`
+}
+
+// TableName 指定RolePermission模型的数据库表名
+func (RolePermission) TableName() string {
+	return "role_permissions"
 }
 
 // Post 文章模型
@@ -39,14 +81,14 @@ type Post struct {
 	Content     string     `json:"content" gorm:"type:text"`
 	Summary     string     `json:"summary" gorm:"size:500"`
 	Cover       string     `json:"cover" gorm:"size:255"`
-	Status      int        `json:"status" gorm:"default:1;index"`     // 1:发布 0:草稿 -1:删除
+	Status      int        `json:"status" gorm:"default:1;index"`     // 1:发布 0:草稿 2:定时发布 -1:删除
 	Visibility  int        `json:"visibility" gorm:"default:1;index"` // 1:公开 0:私密
 	IsTop       bool       `json:"is_top" gorm:"default:false"`
 	ViewCount   int        `json:"view_count" gorm:"default:0"`
 	LikeCount   int        `json:"like_count" gorm:"default:0"`
 	UserID      uint       `json:"user_id" gorm:"index"`
 	CategoryID  uint       `json:"category_id" gorm:"index"`
-	PublishedAt *time.Time `json:"published_at"`
+	PublishedAt *time.Time `json:"published_at"` // 发布时间（定时发布时为预定发布时间）
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 
@@ -58,6 +100,22 @@ type Post struct {
 	Liked    bool      `json:"liked" gorm:"-"` // 当前用户是否点赞（不存储到数据库）
 }
 
+// PostRevision 文章修订历史模型
+// 功能说明：每次更新文章内容前保存旧版本快照，用于查看历史、对比差异和恢复
+type PostRevision struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	PostID    uint      `json:"post_id" gorm:"index;not null"`
+	Title     string    `json:"title" gorm:"size:200"`
+	Content   string    `json:"content,omitempty" gorm:"type:text"`
+	Summary   string    `json:"summary" gorm:"size:500"`
+	UserID    *uint     `json:"user_id" gorm:"index"`   // 产生该修订的编辑者ID（用户删除后为NULL）
+	Remark    string    `json:"remark" gorm:"size:255"` // 修订说明（如：更新文章、恢复前备份）
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+
+	// 关联关系
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
 // Category 分类模型
 // 功能说明：存储文章分类信息，用于对文章进行分类管理
 type Category struct {
@@ -188,14 +246,23 @@ type IPWhitelist struct {
 	UpdatedAt time.Time  `json:"updated_at"`
 }
 
+// 验证码用途（PasswordResetToken.Purpose），校验时必须匹配，避免一种用途的验证码被用于其他操作
+const (
+	TokenPurposeRegister      = "register"       // 注册
+	TokenPurposeResetPassword = "reset_password" // 重置密码
+	TokenPurposeChangeEmail   = "change_email"   // 修改邮箱（验证码发送到新邮箱）
+	TokenPurposeMagicLogin    = "magic_login"    // 免密登录链接（Token 字段保存链接令牌的哈希）
+)
+
 // PasswordResetToken 密码重置令牌模型
-// 功能说明：存储密码重置和注册验证的令牌信息，包含验证码和过期时间
+// 功能说明：存储密码重置、注册验证和修改邮箱验证的令牌信息，包含验证码和过期时间
 type PasswordResetToken struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
-	UserID    *uint     `json:"user_id" gorm:"index"` // 注册时为NULL，密码重置时为实际用户ID
+	UserID    *uint     `json:"user_id" gorm:"index"` // 注册时为NULL，密码重置和修改邮箱时为实际用户ID
 	Email     string    `json:"email" gorm:"size:100;index;not null"`
 	Token     string    `json:"token" gorm:"uniqueIndex;size:100;not null"`
-	Code      string    `json:"code" gorm:"size:6;not null"` // 6位验证码
+	Code      string    `json:"code" gorm:"size:6;not null"`     // 6位验证码
+	Purpose   string    `json:"purpose" gorm:"size:20;not null"` // 用途：register / reset_password / change_email / magic_login
 	ExpireAt  time.Time `json:"expire_at" gorm:"not null"`
 	IsUsed    bool      `json:"is_used" gorm:"default:false"`
 	CreatedAt time.Time `json:"created_at"`
@@ -204,31 +271,297 @@ type PasswordResetToken struct {
 // EmailChangeRecord 邮箱修改记录模型
 // 功能说明：记录用户邮箱修改历史，用于追踪和审计
 type EmailChangeRecord struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"index;not null"`
+	OldEmail    string     `json:"old_email" gorm:"size:100;not null"`
+	NewEmail    string     `json:"new_email" gorm:"size:100;not null"`
+	RevertToken string     `json:"-" gorm:"size:64;index"` // 撤销链接令牌的SHA-256哈希（发送到原邮箱）
+	RevertedAt  *time.Time `json:"reverted_at"`            // 撤销时间，不为空表示已通过撤销链接恢复原邮箱
+	ChangedAt   time.Time  `json:"changed_at" gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// UserSession 用户登录会话模型
+// 功能说明：每次登录创建一个会话，记录设备、IP和User-Agent，会话通过轮换的刷新令牌续期
+type UserSession struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	UserID       uint       `json:"user_id" gorm:"index;not null"`
+	Device       string     `json:"device" gorm:"size:100"`       // 设备描述（由User-Agent解析，如 Chrome on Windows）
+	IP           string     `json:"ip" gorm:"size:50"`            // 最近一次使用的IP
+	UserAgent    string     `json:"user_agent" gorm:"type:text"`  // 最近一次使用的User-Agent
+	LastUsedAt   time.Time  `json:"last_used_at"`                 // 最近一次登录或刷新时间
+	ExpiresAt    time.Time  `json:"expires_at" gorm:"index"`      // 会话过期时间（随刷新顺延）
+	RevokedAt    *time.Time `json:"revoked_at"`                   // 吊销时间
+	RevokeReason string     `json:"revoke_reason" gorm:"size:50"` // 吊销原因：logout / user / password / reuse_detected 等
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// TableName 指定UserSession模型的数据库表名
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+// RefreshToken 刷新令牌模型
+// 功能说明：只保存令牌的SHA-256哈希；令牌使用后即被轮换，已使用的令牌保留到过期用于检测重放
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	SessionID uint       `json:"session_id" gorm:"index;not null"`
+	TokenHash string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"index"`
+	UsedAt    *time.Time `json:"used_at"` // 轮换时间，不为空表示已使用
+	CreatedAt time.Time  `json:"created_at"`
+
+	// 关联关系
+	Session *UserSession `json:"session,omitempty" gorm:"foreignKey:SessionID"`
+}
+
+// TableName 指定RefreshToken模型的数据库表名
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// UserTwoFactor 用户两步验证（TOTP）模型
+// 功能说明：Enabled 为 false 时表示已生成密钥但尚未通过首个验证码确认
+type UserTwoFactor struct {
+	UserID       uint       `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Secret       string     `json:"-" gorm:"size:64;not null"`    // Base32编码的TOTP密钥
+	Enabled      bool       `json:"enabled" gorm:"default:false"` // 是否已启用
+	LastUsedStep int64      `json:"-" gorm:"default:0"`           // 最近一次验证成功的时间步（防止验证码重放）
+	EnabledAt    *time.Time `json:"enabled_at"`                   // 启用时间
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName 指定UserTwoFactor模型的数据库表名
+func (UserTwoFactor) TableName() string {
+	return "user_two_factors"
+}
+
+// TwoFactorRecoveryCode 两步验证恢复码模型
+// 功能说明：只保存恢复码的SHA-256哈希，每个恢复码只能使用一次
+type TwoFactorRecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index;not null"`
+	CodeHash  string     `json:"-" gorm:"size:64;not null"`
+	UsedAt    *time.Time `json:"used_at"` // 使用时间，不为空表示已使用
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName 指定TwoFactorRecoveryCode模型的数据库表名
+func (TwoFactorRecoveryCode) TableName() string {
+	return "two_factor_recovery_codes"
+}
+
+// PersonalAccessToken 个人访问令牌模型
+// 功能说明：供脚本和CI调用接口使用，只保存令牌的SHA-256哈希；Scopes 为逗号分隔的权限范围
+type PersonalAccessToken struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"index;not null"`
+	Name       string     `json:"name" gorm:"size:100;not null"`
+	TokenHash  string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	TokenHint  string     `json:"token_hint" gorm:"size:20"` // 令牌末尾几位，便于用户辨认
+	Scopes     string     `json:"-" gorm:"size:255;not null"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"index"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip" gorm:"size:45"`
+	RevokedAt  *time.Time `json:"revoked_at"` // 吊销时间，不为空表示已吊销
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// TableName 指定PersonalAccessToken模型的数据库表名
+func (PersonalAccessToken) TableName() string {
+	return "personal_access_tokens"
+}
+
+// ScopeList 返回令牌的权限范围列表
+func (t *PersonalAccessToken) ScopeList() []string {
+	if t.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(t.Scopes, ",")
+}
+
+// HasScope 判断令牌是否具有指定权限范围
+func (t *PersonalAccessToken) HasScope(scope string) bool {
+	for _, s := range t.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// UserOAuthBinding 第三方账号绑定模型
+// 功能说明：记录用户绑定的第三方登录账号，同一第三方账号只能绑定一个用户，每个用户在同一提供方只能绑定一个账号
+type UserOAuthBinding struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	UserID           uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_user_oauth_bindings_user_provider"`
+	Provider         string    `json:"provider" gorm:"size:20;not null;uniqueIndex:idx_user_oauth_bindings_user_provider;uniqueIndex:idx_user_oauth_bindings_provider_uid"` // 提供方：github、gitee
+	ProviderUserID   string    `json:"-" gorm:"size:100;not null;uniqueIndex:idx_user_oauth_bindings_provider_uid"`                                                         // 提供方内的用户ID
+	ProviderUsername string    `json:"provider_username" gorm:"size:100"`                                                                                                   // 提供方内的登录名
+	Avatar           string    `json:"avatar" gorm:"size:255"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// TableName 指定UserOAuthBinding模型的数据库表名
+func (UserOAuthBinding) TableName() string {
+	return "user_oauth_bindings"
+}
+
+// WebAuthnCredential 通行密钥模型
+// 功能说明：存储用户注册的 WebAuthn 凭据（通行密钥），用于免密登录；公钥为 COSE 格式，签名计数器用于发现被克隆的认证器
+type WebAuthnCredential struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	UserID       uint       `json:"user_id" gorm:"index;not null"`
+	Name         string     `json:"name" gorm:"size:50;not null"`
+	CredentialID string     `json:"-" gorm:"size:255;uniqueIndex;not null"` // 凭据ID（base64url）
+	PublicKey    string     `json:"-" gorm:"type:text;not null"`            // COSE 格式公钥（base64url）
+	Algorithm    int        `json:"algorithm" gorm:"not null"`              // COSE 算法：-7 ES256、-8 EdDSA、-257 RS256
+	SignCount    int64      `json:"-" gorm:"not null;default:0"`
+	Transports   string     `json:"transports" gorm:"size:100"` // 认证器传输方式，逗号分隔：internal、hybrid、usb 等
+	LastUsedAt   *time.Time `json:"last_used_at"`
+	LastUsedIP   string     `json:"last_used_ip" gorm:"size:45"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// TableName 指定WebAuthnCredential模型的数据库表名
+func (WebAuthnCredential) TableName() string {
+	return "webauthn_credentials"
+}
+
+// InviteCode 注册邀请码模型
+// 功能说明：站点设置为仅限邀请注册时，注册需填写有效的邀请码；每个邀请码可限制使用次数和有效期
+type InviteCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	Code      string     `json:"code" gorm:"size:32;uniqueIndex;not null"`
+	MaxUses   int        `json:"max_uses" gorm:"not null;default:1"`   // 最多可使用次数
+	UsedCount int        `json:"used_count" gorm:"not null;default:0"` // 已使用次数
+	ExpireAt  *time.Time `json:"expire_at"`                            // 过期时间，为空表示长期有效
+	Note      string     `json:"note" gorm:"size:100"`                 // 备注（如发给谁）
+	CreatedBy *uint      `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName 指定InviteCode模型的数据库表名
+func (InviteCode) TableName() string {
+	return "invite_codes"
+}
+
+// AccountDeletionRequest 账号注销申请模型
+// 功能说明：用户申请注销账号后进入冷静期，期满由定时任务删除账号并清理个人数据，冷静期内可撤销
+type AccountDeletionRequest struct {
+	UserID      uint      `json:"user_id" gorm:"primaryKey"`
+	Reason      string    `json:"reason" gorm:"size:255"` // 注销原因（可选）
+	RequestedAt time.Time `json:"requested_at"`
+	ScheduledAt time.Time `json:"scheduled_at" gorm:"index"` // 计划执行删除的时间
+}
+
+// TableName 指定AccountDeletionRequest模型的数据库表名
+func (AccountDeletionRequest) TableName() string {
+	return "account_deletion_requests"
+}
+
+// 登录失败原因
+const (
+	LoginFailPassword  = "password"   // 用户名或密码错误
+	LoginFailLocked    = "locked"     // 账号因多次登录失败被临时锁定
+	LoginFailDisabled  = "disabled"   // 账号已被禁用
+	LoginFailPending   = "pending"    // 账号注册待审核
+	LoginFailTwoFactor = "two_factor" // 两步验证码错误
+)
+
+// LoginHistory 登录历史模型
+// 功能说明：记录每次登录尝试的结果、IP和客户端信息；用户名不存在时 UserID 为空
+type LoginHistory struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
-	UserID    uint      `json:"user_id" gorm:"index;not null"`
-	OldEmail  string    `json:"old_email" gorm:"size:100;not null"`
-	NewEmail  string    `json:"new_email" gorm:"size:100;not null"`
-	ChangedAt time.Time `json:"changed_at" gorm:"default:CURRENT_TIMESTAMP"`
+	UserID    *uint     `json:"user_id" gorm:"index"`
+	Username  string    `json:"username" gorm:"size:50;not null"` // 登录时提交的用户名
+	Success   bool      `json:"success" gorm:"not null"`
+	Reason    string    `json:"reason" gorm:"size:20"` // 失败原因，成功时为空
+	IP        string    `json:"ip" gorm:"size:45"`
+	UserAgent string    `json:"user_agent" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName 指定LoginHistory模型的数据库表名
+func (LoginHistory) TableName() string {
+	return "login_history"
 }
 
 // ChatMessage 聊天消息模型
 // 功能说明：存储聊天室消息和系统公告信息，支持匿名用户和登录用户
 type ChatMessage struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	Content         string     `json:"content" gorm:"not null;type:text"`
+	RoomID          *uint      `json:"room_id" gorm:"index"`                        // 所属聊天室ID，系统广播为空（投递到所有聊天室）
+	UserID          *uint      `json:"user_id" gorm:"index"`                        // 登录用户ID，可为空（匿名用户）
+	Username        string     `json:"username" gorm:"size:50;not null"`            // 用户名（登录用户为真实用户名，匿名用户为临时昵称）
+	Avatar          string     `json:"avatar" gorm:"size:255"`                      // 头像URL
+	IP              string     `json:"ip" gorm:"size:45"`                           // IP地址
+	ClientID        string     `json:"-" gorm:"size:36"`                            // 发送连接的标识，用于确认匿名用户撤回和编辑的是本连接发送的消息
+	Priority        int        `json:"priority" gorm:"default:0"`                   // 优先级：0-普通，1-置顶
+	Target          string     `json:"target" gorm:"size:20;default:announcement"`  // 投递目标：announcement / chat / both
+	IsBroadcast     bool       `json:"is_broadcast" gorm:"default:false;index"`     // 是否为系统广播
+	Status          int        `json:"status" gorm:"default:1;index"`               // 1:正常 0:删除
+	IsRecalled      bool       `json:"is_recalled" gorm:"default:false"`            // 是否已撤回
+	RecalledBy      string     `json:"recalled_by,omitempty" gorm:"size:10"`        // 撤回者：sender（发送者本人）/ admin（管理员）
+	RecalledAt      *time.Time `json:"recalled_at,omitempty"`                       // 撤回时间
+	EditedAt        *time.Time `json:"edited_at,omitempty"`                         // 最后编辑时间，为空表示未编辑
+	OriginalContent string     `json:"original_content,omitempty" gorm:"type:text"` // 首次编辑前的原始内容，仅管理端返回
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+
+	// 关联关系
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// 聊天消息撤回者
+const (
+	ChatRecalledBySender = "sender" // 发送者本人撤回
+	ChatRecalledByAdmin  = "admin"  // 管理员撤回
+)
+
+// 聊天室类型
+const (
+	ChatRoomTypeLobby  = "lobby"  // 公共大厅，连接后默认加入，全站唯一
+	ChatRoomTypePublic = "public" // 管理员创建的公开聊天室
+	ChatRoomTypePost   = "post"   // 文章讨论室，首次进入时自动创建
+	ChatRoomTypeAdmin  = "admin"  // 管理员专用聊天室
+)
+
+// ChatRoom 聊天室模型
+// 功能说明：存储聊天室信息，聊天消息按聊天室隔离，支持锁定（仅管理员可发言）和归档（不可再进入）
+type ChatRoom struct {
 	ID          uint      `json:"id" gorm:"primaryKey"`
-	Content     string    `json:"content" gorm:"not null;type:text"`
-	UserID      *uint     `json:"user_id" gorm:"index"`                       // 登录用户ID，可为空（匿名用户）
-	Username    string    `json:"username" gorm:"size:50;not null"`           // 用户名（登录用户为真实用户名，匿名用户为临时昵称）
-	Avatar      string    `json:"avatar" gorm:"size:255"`                     // 头像URL
-	IP          string    `json:"ip" gorm:"size:45"`                          // IP地址
-	Priority    int       `json:"priority" gorm:"default:0"`                  // 优先级：0-普通，1-置顶
-	Target      string    `json:"target" gorm:"size:20;default:announcement"` // 投递目标：announcement / chat / both
-	IsBroadcast bool      `json:"is_broadcast" gorm:"default:false;index"`    // 是否为系统广播
-	Status      int       `json:"status" gorm:"default:1;index"`              // 1:正常 0:删除
+	Name        string    `json:"name" gorm:"size:50;not null"`
+	Type        string    `json:"type" gorm:"size:20;not null;index"` // 类型：lobby/public/post/admin
+	PostID      *uint     `json:"post_id" gorm:"uniqueIndex"`         // 文章讨论室对应的文章ID
+	Description string    `json:"description" gorm:"size:200"`
+	IsLocked    bool      `json:"is_locked" gorm:"default:false"`   // 锁定后仅管理员可发言
+	IsArchived  bool      `json:"is_archived" gorm:"default:false"` // 归档后不可进入和发言
+	CreatedBy   *uint     `json:"created_by"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+}
 
-	// 关联关系
-	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+// DirectMessage 私信消息模型
+// 功能说明：存储登录用户之间的一对一私信，会话由双方用户ID确定，接收方阅读后标记为已读
+type DirectMessage struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	SenderID   uint       `json:"sender_id" gorm:"not null;index"`
+	ReceiverID uint       `json:"receiver_id" gorm:"not null;index"`
+	Content    string     `json:"content" gorm:"type:text;not null"`
+	IsRead     bool       `json:"is_read" gorm:"default:false"`
+	ReadAt     *time.Time `json:"read_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// UserBlock 用户屏蔽关系模型
+// 功能说明：用户屏蔽对方后，对方不能再向其发送私信
+type UserBlock struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_user_blocks_pair"`    // 发起屏蔽的用户
+	BlockedID uint      `json:"blocked_id" gorm:"not null;uniqueIndex:idx_user_blocks_pair"` // 被屏蔽的用户
+	CreatedAt time.Time `json:"created_at"`
 }
 
 // TableName 指定User模型的数据库表名
@@ -241,6 +574,11 @@ func (Post) TableName() string {
 	return "posts"
 }
 
+// TableName 指定PostRevision模型的数据库表名
+func (PostRevision) TableName() string {
+	return "post_revisions"
+}
+
 // TableName 指定Category模型的数据库表名
 func (Category) TableName() string {
 	return "categories"
@@ -301,6 +639,21 @@ func (ChatMessage) TableName() string {
 	return "chat_messages"
 }
 
+// TableName 指定ChatRoom模型的数据库表名
+func (ChatRoom) TableName() string {
+	return "chat_rooms"
+}
+
+// TableName 指定DirectMessage模型的数据库表名
+func (DirectMessage) TableName() string {
+	return "direct_messages"
+}
+
+// TableName 指定UserBlock模型的数据库表名
+func (UserBlock) TableName() string {
+	return "user_blocks"
+}
+
 // FriendLinkCategory 友链分类模型
 // 功能说明：存储友链分类信息，用于对友链进行分类管理
 type FriendLinkCategory struct {
@@ -338,6 +691,7 @@ type FriendLink struct {
 
 	// 关联关系
 	Category FriendLinkCategory `json:"category" gorm:"foreignKey:CategoryID"`
+	Health   *FriendLinkHealth  `json:"health,omitempty" gorm:"foreignKey:FriendLinkID"` // 健康检查结果（仅管理员列表加载）
 }
 
 // TableName 指定FriendLink模型的数据库表名
@@ -345,6 +699,94 @@ func (FriendLink) TableName() string {
 	return "friend_links"
 }
 
+// FriendLinkHealth 友链健康检查结果模型
+type FriendLinkHealth struct {
+	FriendLinkID        uint       `json:"friend_link_id" gorm:"primaryKey;autoIncrement:false"`
+	StatusCode          int        `json:"status_code"`                           // 最后一次检查的HTTP状态码（请求失败时为0）
+	LatencyMs           int        `json:"latency_ms"`                            // 最后一次检查的响应耗时（毫秒）
+	HasBacklink         *bool      `json:"has_backlink"`                          // 对方页面是否包含本站链接（未检查时为nil）
+	ConsecutiveFailures int        `json:"consecutive_failures" gorm:"default:0"` // 连续失败次数
+	LastError           string     `json:"last_error" gorm:"size:500"`
+	LastCheckedAt       *time.Time `json:"last_checked_at"`
+	LastSuccessAt       *time.Time `json:"last_success_at"`
+	AutoDisabledAt      *time.Time `json:"auto_disabled_at"` // 因连续失败被自动禁用的时间
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// TableName 指定FriendLinkHealth模型的数据库表名
+func (FriendLinkHealth) TableName() string {
+	return "friend_link_health"
+}
+
+// FriendLinkApplication 友链申请模型
+type FriendLinkApplication struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	Name         string     `json:"name" gorm:"not null;size:100"`
+	URL          string     `json:"url" gorm:"not null;size:255"`
+	Icon         string     `json:"icon" gorm:"size:255"`
+	Description  string     `json:"description" gorm:"type:text"`
+	AtomURL      string     `json:"atom_url" gorm:"size:255"`
+	Email        string     `json:"email" gorm:"not null;size:100"` // 申请人联系邮箱（接收审核结果）
+	Status       int        `json:"status" gorm:"default:0;index"`  // 0:待审核 1:已通过 2:已拒绝
+	RejectReason string     `json:"reject_reason" gorm:"size:500"`  // 拒绝原因
+	FriendLinkID *uint      `json:"friend_link_id"`                 // 审核通过后创建的友链ID
+	IP           string     `json:"ip" gorm:"size:50"`              // 申请人IP
+	ReviewerID   *uint      `json:"reviewer_id"`                    // 审核人ID
+	ReviewedAt   *time.Time `json:"reviewed_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName 指定FriendLinkApplication模型的数据库表名
+func (FriendLinkApplication) TableName() string {
+	return "friend_link_applications"
+}
+
+// FriendFeedItem 朋友圈文章模型
+// 功能说明：存储从友链 RSS/Atom 订阅中抓取的文章，按 (friend_link_id, guid) 去重
+type FriendFeedItem struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	FriendLinkID uint      `json:"friend_link_id" gorm:"not null;uniqueIndex:idx_friend_feed_items_guid"`
+	GUID         string    `json:"-" gorm:"column:guid;not null;size:500;uniqueIndex:idx_friend_feed_items_guid"` // 条目唯一标识（guid/id，缺失时使用链接）
+	Title        string    `json:"title" gorm:"not null;size:300"`
+	Link         string    `json:"link" gorm:"not null;size:500"`
+	Summary      string    `json:"summary" gorm:"type:text"`
+	Author       string    `json:"author" gorm:"size:100"`
+	PublishedAt  time.Time `json:"published_at" gorm:"index"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// 关联关系
+	FriendLink *FriendLink `json:"friend_link,omitempty" gorm:"foreignKey:FriendLinkID"`
+}
+
+// TableName 指定FriendFeedItem模型的数据库表名
+func (FriendFeedItem) TableName() string {
+	return "friend_feed_items"
+}
+
+// FriendFeedState 友链订阅抓取状态模型
+// 功能说明：记录每个友链订阅的抓取结果、连续失败次数和退避时间，以及用于条件请求的 ETag/Last-Modified
+type FriendFeedState struct {
+	FriendLinkID  uint       `json:"friend_link_id" gorm:"primaryKey;autoIncrement:false"`
+	FeedURL       string     `json:"feed_url" gorm:"size:255"` // 抓取时使用的订阅地址（变更后重置状态）
+	ETag          string     `json:"-" gorm:"column:etag;size:255"`
+	LastModified  string     `json:"-" gorm:"size:100"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+	LastSuccessAt *time.Time `json:"last_success_at"`
+	ErrorCount    int        `json:"error_count" gorm:"default:0"` // 连续失败次数
+	LastError     string     `json:"last_error" gorm:"size:500"`
+	NextFetchAt   time.Time  `json:"next_fetch_at" gorm:"index"` // 下次允许抓取的时间（失败后指数退避）
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// 关联关系
+	FriendLink *FriendLink `json:"friend_link,omitempty" gorm:"foreignKey:FriendLinkID"`
+}
+
+// TableName 指定FriendFeedState模型的数据库表名
+func (FriendFeedState) TableName() string {
+	return "friend_feed_states"
+}
+
 // Album 相册模型
 // 功能说明：存储相册照片信息，用于展示个人相册
 type Album struct {
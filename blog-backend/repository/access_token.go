@@ -0,0 +1,87 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：access_token.go
+ * 创建时间：2026-10-18 01:27:52
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：个人访问令牌数据访问层，提供令牌的创建、按哈希查询、使用记录更新和吊销
+ */
+package repository
+
+import (
+	"time"
+
+	"blog-backend/db"
+	"blog-backend/model"
+)
+
+// accessTokenTouchInterval 最近使用时间的更新间隔（避免每个请求都写库）
+const accessTokenTouchInterval = time.Minute
+
+// AccessTokenRepository 个人访问令牌数据访问层结构体
+type AccessTokenRepository struct{}
+
+// NewAccessTokenRepository 创建个人访问令牌数据访问层实例
+func NewAccessTokenRepository() *AccessTokenRepository {
+	return &AccessTokenRepository{}
+}
+
+// Create 创建个人访问令牌
+func (r *AccessTokenRepository) Create(token *model.PersonalAccessToken) error {
+	return db.DB.Create(token).Error
+}
+
+// GetByHash 根据令牌哈希获取个人访问令牌
+func (r *AccessTokenRepository) GetByHash(hash string) (*model.PersonalAccessToken, error) {
+	var token model.PersonalAccessToken
+	err := db.DB.Where("token_hash = ?", hash).First(&token).Error
+	return &token, err
+}
+
+// ListActiveByUser 获取用户未吊销且未过期的令牌
+func (r *AccessTokenRepository) ListActiveByUser(userID uint) ([]model.PersonalAccessToken, error) {
+	var tokens []model.PersonalAccessToken
+	err := db.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// CountActiveByUser 统计用户未吊销且未过期的令牌数
+func (r *AccessTokenRepository) CountActiveByUser(userID uint) (int64, error) {
+	var count int64
+	err := db.DB.Model(&model.PersonalAccessToken{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Count(&count).Error
+	return count, err
+}
+
+// Touch 记录令牌的最近使用时间和IP（距上次记录不足一分钟时跳过）
+func (r *AccessTokenRepository) Touch(id uint, ip string) error {
+	now := time.Now()
+	return db.DB.Model(&model.PersonalAccessToken{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-accessTokenTouchInterval)).
+		Updates(map[string]interface{}{
+			"last_used_at": now,
+			"last_used_ip": ip,
+		}).Error
+}
+
+// Revoke 吊销用户的指定令牌
+// 返回:
+//   - bool: 令牌存在、属于该用户且此前未被吊销时返回 true
+func (r *AccessTokenRepository) Revoke(userID, id uint) (bool, error) {
+	result := db.DB.Model(&model.PersonalAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// RevokeAllByUser 吊销用户的所有令牌
+func (r *AccessTokenRepository) RevokeAllByUser(userID uint) error {
+	return db.DB.Model(&model.PersonalAccessToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,68 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：account_deletion.go
+ * 创建时间：2026-10-18 04:46:02
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：账号注销申请数据访问层，提供注销申请的查询、创建、撤销和到期查询
+ */
+package repository
+
+import (
+	"time"
+
+	"blog-backend/db"
+	"blog-backend/model"
+
+	"gorm.io/gorm/clause"
+)
+
+// AccountDeletionRepository 账号注销申请数据访问层结构体
+type AccountDeletionRepository struct{}
+
+// NewAccountDeletionRepository 创建账号注销申请数据访问层实例
+func NewAccountDeletionRepository() *AccountDeletionRepository {
+	return &AccountDeletionRepository{}
+}
+
+// Get 获取用户的注销申请
+func (r *AccountDeletionRepository) Get(userID uint) (*model.AccountDeletionRequest, error) {
+	var request model.AccountDeletionRequest
+	err := db.DB.Where("user_id = ?", userID).First(&request).Error
+	return &request, err
+}
+
+// Save 保存注销申请，已存在时重新计算冷静期
+func (r *AccountDeletionRepository) Save(request *model.AccountDeletionRequest) error {
+	return db.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "requested_at", "scheduled_at"}),
+	}).Create(request).Error
+}
+
+// Delete 撤销用户的注销申请
+// 返回:
+//   - bool: 是否存在并撤销了注销申请
+func (r *AccountDeletionRepository) Delete(userID uint) (bool, error) {
+	result := db.DB.Where("user_id = ?", userID).Delete(&model.AccountDeletionRequest{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Postpone 推迟注销申请的执行时间（申请已被撤销时不做任何操作）
+func (r *AccountDeletionRepository) Postpone(userID uint, scheduledAt time.Time) error {
+	return db.DB.Model(&model.AccountDeletionRequest{}).
+		Where("user_id = ?", userID).
+		Update("scheduled_at", scheduledAt).Error
+}
+
+// ListDue 获取冷静期已满的注销申请（按计划时间升序）
+func (r *AccountDeletionRepository) ListDue(now time.Time, limit int) ([]model.AccountDeletionRequest, error) {
+	var requests []model.AccountDeletionRequest
+	err := db.DB.Where("scheduled_at <= ?", now).
+		Order("scheduled_at ASC").
+		Limit(limit).
+		Find(&requests).Error
+	return requests, err
+}
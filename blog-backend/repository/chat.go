@@ -11,8 +11,12 @@
 package repository
 
 import (
+	"time"
+
 	"blog-backend/db"
 	"blog-backend/model"
+
+	"gorm.io/gorm"
 )
 
 // ChatRepository 聊天消息数据访问层结构体
@@ -29,30 +33,38 @@ func (r *ChatRepository) Create(message *model.ChatMessage) error {
 }
 
 // GetMessages 获取消息列表（分页）
-// includeAnnouncementOnly 表示是否包含仅投递到公告栏的广播
-func (r *ChatRepository) GetMessages(page, pageSize int, includeAnnouncementOnly bool) ([]model.ChatMessage, int64, error) {
+// roomID 不为空时只返回该聊天室的消息；includeAnnouncementOnly 表示管理端查询，
+// 包含仅投递到公告栏的广播，且按聊天室筛选时不附带系统广播
+func (r *ChatRepository) GetMessages(roomID *uint, page, pageSize int, includeAnnouncementOnly bool) ([]model.ChatMessage, int64, error) {
 	var messages []model.ChatMessage
 	var total int64
 
 	offset := (page - 1) * pageSize
 
-	// 获取总数
-	totalQuery := db.DB.Model(&model.ChatMessage{}).Where("status = ?", 1)
-	if !includeAnnouncementOnly {
-		totalQuery = totalQuery.Where("(is_broadcast = ? OR target IN ? OR target IS NULL OR target = '')",
-			false, []string{"chat", "both"})
+	scope := func(query *gorm.DB) *gorm.DB {
+		query = query.Where("status = ?", 1)
+		if !includeAnnouncementOnly {
+			query = query.Where("(is_broadcast = ? OR target IN ? OR target IS NULL OR target = '')",
+				false, []string{"chat", "both"})
+		}
+		if roomID != nil {
+			if includeAnnouncementOnly {
+				query = query.Where("room_id = ?", *roomID)
+			} else {
+				// 系统广播投递到所有聊天室
+				query = query.Where("(room_id = ? OR is_broadcast = ?)", *roomID, true)
+			}
+		}
+		return query
 	}
-	if err := totalQuery.Count(&total).Error; err != nil {
+
+	// 获取总数
+	if err := scope(db.DB.Model(&model.ChatMessage{})).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// 获取消息列表
-	query := db.DB.Where("status = ?", 1)
-	if !includeAnnouncementOnly {
-		query = query.Where("(is_broadcast = ? OR target IN ? OR target IS NULL OR target = '')",
-			false, []string{"chat", "both"})
-	}
-	err := query.Order("created_at DESC").
+	err := scope(db.DB).Order("created_at DESC").
 		Offset(offset).
 		Limit(pageSize).
 		Find(&messages).Error
@@ -64,12 +76,13 @@ func (r *ChatRepository) GetMessages(page, pageSize int, includeAnnouncementOnly
 	return messages, total, nil
 }
 
-// GetRecentMessages 获取最近的消息
-func (r *ChatRepository) GetRecentMessages(limit int) ([]model.ChatMessage, error) {
+// GetRecentMessages 获取聊天室最近的消息（包含投递到聊天室的系统广播）
+func (r *ChatRepository) GetRecentMessages(roomID uint, limit int) ([]model.ChatMessage, error) {
 	var messages []model.ChatMessage
 
 	err := db.DB.Where("status = ? AND (is_broadcast = ? OR target IN ? OR target IS NULL OR target = '')",
 		1, false, []string{"chat", "both"}).
+		Where("(room_id = ? OR is_broadcast = ?)", roomID, true).
 		Order("created_at DESC").
 		Limit(limit).
 		Find(&messages).Error
@@ -91,6 +104,30 @@ func (r *ChatRepository) Delete(id uint) error {
 	return db.DB.Model(&model.ChatMessage{}).Where("id = ?", id).Update("status", 0).Error
 }
 
+// Recall 撤回消息，返回是否撤回成功（消息不存在、已删除或已撤回时返回 false）
+func (r *ChatRepository) Recall(id uint, recalledBy string, recalledAt time.Time) (bool, error) {
+	result := db.DB.Model(&model.ChatMessage{}).
+		Where("id = ? AND status = ? AND is_recalled = ?", id, 1, false).
+		Updates(map[string]interface{}{
+			"is_recalled": true,
+			"recalled_by": recalledBy,
+			"recalled_at": recalledAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Edit 编辑消息内容，首次编辑时保留原始内容，返回是否编辑成功（消息不存在、已删除或已撤回时返回 false）
+func (r *ChatRepository) Edit(id uint, content string, editedAt time.Time) (bool, error) {
+	result := db.DB.Model(&model.ChatMessage{}).
+		Where("id = ? AND status = ? AND is_recalled = ?", id, 1, false).
+		Updates(map[string]interface{}{
+			"original_content": gorm.Expr("COALESCE(NULLIF(original_content, ''), content)"),
+			"content":          content,
+			"edited_at":        editedAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
 // GetByID 根据ID获取消息
 func (r *ChatRepository) GetByID(id uint) (*model.ChatMessage, error) {
 	var message model.ChatMessage
@@ -0,0 +1,98 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：chat_room.go
+ * 创建时间：2026-10-18 05:57:31
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：聊天室数据访问层，提供聊天室的查询、创建以及锁定、归档状态更新等数据库操作
+ */
+package repository
+
+import (
+	"blog-backend/db"
+	"blog-backend/model"
+
+	"gorm.io/gorm/clause"
+)
+
+// ChatRoomRepository 聊天室数据访问层结构体
+type ChatRoomRepository struct{}
+
+// NewChatRoomRepository 创建聊天室数据访问层实例
+func NewChatRoomRepository() *ChatRoomRepository {
+	return &ChatRoomRepository{}
+}
+
+// List 获取聊天室列表（大厅排在最前，其余按创建时间排序）
+// includeArchived 是否包含已归档的聊天室，includeAdmin 是否包含管理员专用聊天室，includePost 是否包含文章讨论室
+func (r *ChatRoomRepository) List(includeArchived, includeAdmin, includePost bool) ([]model.ChatRoom, error) {
+	var rooms []model.ChatRoom
+	query := db.DB.Model(&model.ChatRoom{})
+	if !includeArchived {
+		query = query.Where("is_archived = ?", false)
+	}
+	if !includeAdmin {
+		query = query.Where("type <> ?", model.ChatRoomTypeAdmin)
+	}
+	if !includePost {
+		query = query.Where("type <> ?", model.ChatRoomTypePost)
+	}
+	err := query.Order("CASE WHEN type = 'lobby' THEN 0 ELSE 1 END, created_at ASC").Find(&rooms).Error
+	return rooms, err
+}
+
+// GetByID 根据ID获取聊天室
+func (r *ChatRoomRepository) GetByID(id uint) (*model.ChatRoom, error) {
+	var room model.ChatRoom
+	err := db.DB.First(&room, id).Error
+	return &room, err
+}
+
+// GetLobby 获取公共大厅
+func (r *ChatRoomRepository) GetLobby() (*model.ChatRoom, error) {
+	var room model.ChatRoom
+	err := db.DB.Where("type = ?", model.ChatRoomTypeLobby).Order("id ASC").First(&room).Error
+	return &room, err
+}
+
+// GetOrCreatePostRoom 获取文章讨论室，不存在时创建（并发创建时以先写入的为准）
+func (r *ChatRoomRepository) GetOrCreatePostRoom(post *model.Post) (*model.ChatRoom, error) {
+	room := &model.ChatRoom{
+		Name:   post.Title,
+		Type:   model.ChatRoomTypePost,
+		PostID: &post.ID,
+	}
+	if len([]rune(room.Name)) > 50 {
+		room.Name = string([]rune(room.Name)[:50])
+	}
+
+	if err := db.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "post_id"}},
+		DoNothing: true,
+	}).Create(room).Error; err != nil {
+		return nil, err
+	}
+
+	var existing model.ChatRoom
+	err := db.DB.Where("post_id = ?", post.ID).First(&existing).Error
+	return &existing, err
+}
+
+// Create 创建聊天室
+func (r *ChatRoomRepository) Create(room *model.ChatRoom) error {
+	return db.DB.Create(room).Error
+}
+
+// SetLocked 设置聊天室锁定状态，返回是否找到该聊天室
+func (r *ChatRoomRepository) SetLocked(id uint, locked bool) (bool, error) {
+	result := db.DB.Model(&model.ChatRoom{}).Where("id = ?", id).Update("is_locked", locked)
+	return result.RowsAffected > 0, result.Error
+}
+
+// SetArchived 设置聊天室归档状态，返回是否找到该聊天室
+func (r *ChatRoomRepository) SetArchived(id uint, archived bool) (bool, error) {
+	result := db.DB.Model(&model.ChatRoom{}).Where("id = ?", id).Update("is_archived", archived)
+	return result.RowsAffected > 0, result.Error
+}
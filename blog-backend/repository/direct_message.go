@@ -0,0 +1,202 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：direct_message.go
+ * 创建时间：2026-10-18 06:13:26
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：私信数据访问层，提供私信的保存、会话列表、历史消息、未读计数以及用户屏蔽关系的数据库操作
+ */
+package repository
+
+import (
+	"time"
+
+	"blog-backend/constant"
+	"blog-backend/db"
+	"blog-backend/model"
+
+	"gorm.io/gorm/clause"
+)
+
+// DirectMessageRepository 私信数据访问层结构体
+type DirectMessageRepository struct{}
+
+// NewDirectMessageRepository 创建私信数据访问层实例
+func NewDirectMessageRepository() *DirectMessageRepository {
+	return &DirectMessageRepository{}
+}
+
+// DirectPeer 私信对象的公开资料
+type DirectPeer struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Nickname string `json:"nickname"`
+	Avatar   string `json:"avatar"`
+}
+
+// DirectConversation 会话列表中的一条会话（与某个用户的最新一条消息及未读数）
+type DirectConversation struct {
+	PeerID        uint      `json:"peer_id"`
+	PeerUsername  string    `json:"peer_username"`
+	PeerNickname  string    `json:"peer_nickname"`
+	PeerAvatar    string    `json:"peer_avatar"`
+	LastMessageID uint      `json:"last_message_id"`
+	LastContent   string    `json:"last_content"`
+	LastSenderID  uint      `json:"last_sender_id"`
+	LastCreatedAt time.Time `json:"last_created_at"`
+	UnreadCount   int64     `json:"unread_count"`
+	IsBlocked     bool      `json:"is_blocked"` // 当前用户是否已屏蔽对方
+}
+
+// BlockedUser 屏蔽列表中的一条记录
+type BlockedUser struct {
+	DirectPeer
+	BlockedAt time.Time `json:"blocked_at"`
+}
+
+// Create 保存私信
+func (r *DirectMessageRepository) Create(msg *model.DirectMessage) error {
+	return db.DB.Create(msg).Error
+}
+
+// GetPeer 获取可接收私信的用户（仅正常状态的用户）
+func (r *DirectMessageRepository) GetPeer(userID uint) (*DirectPeer, error) {
+	var peer DirectPeer
+	err := db.DB.Model(&model.User{}).
+		Select("id, username, COALESCE(nickname, '') AS nickname, COALESCE(avatar, '') AS avatar").
+		Where("id = ? AND status = ?", userID, model.UserStatusActive).
+		Take(&peer).Error
+	return &peer, err
+}
+
+// ListContacts 获取可直接联系的站长（超级管理员）
+func (r *DirectMessageRepository) ListContacts() ([]DirectPeer, error) {
+	var peers []DirectPeer
+	err := db.DB.Model(&model.User{}).
+		Select("id, username, COALESCE(nickname, '') AS nickname, COALESCE(avatar, '') AS avatar").
+		Where("role = ? AND status = ?", constant.RoleSuperAdmin, model.UserStatusActive).
+		Order("id ASC").
+		Scan(&peers).Error
+	return peers, err
+}
+
+// ListConversations 分页获取用户的会话列表，按最新消息倒序
+func (r *DirectMessageRepository) ListConversations(userID uint, page, pageSize int) ([]DirectConversation, int64, error) {
+	var total int64
+	if err := db.DB.Raw(`
+		SELECT COUNT(DISTINCT CASE WHEN sender_id = @uid THEN receiver_id ELSE sender_id END)
+		FROM direct_messages
+		WHERE sender_id = @uid OR receiver_id = @uid`,
+		map[string]interface{}{"uid": userID}).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 先取出与每个对象的最新一条消息，再关联用户资料、未读数和屏蔽状态
+	var conversations []DirectConversation
+	err := db.DB.Raw(`
+		WITH latest AS (
+			SELECT DISTINCT ON (peer_id) peer_id, id, sender_id, content, created_at
+			FROM (
+				SELECT id, sender_id, content, created_at,
+					CASE WHEN sender_id = @uid THEN receiver_id ELSE sender_id END AS peer_id
+				FROM direct_messages
+				WHERE sender_id = @uid OR receiver_id = @uid
+			) m
+			ORDER BY peer_id, id DESC
+		)
+		SELECT latest.peer_id,
+			u.username AS peer_username,
+			COALESCE(u.nickname, '') AS peer_nickname,
+			COALESCE(u.avatar, '') AS peer_avatar,
+			latest.id AS last_message_id,
+			latest.content AS last_content,
+			latest.sender_id AS last_sender_id,
+			latest.created_at AS last_created_at,
+			(SELECT COUNT(*) FROM direct_messages d
+				WHERE d.receiver_id = @uid AND d.sender_id = latest.peer_id AND d.is_read = FALSE) AS unread_count,
+			EXISTS (SELECT 1 FROM user_blocks b
+				WHERE b.user_id = @uid AND b.blocked_id = latest.peer_id) AS is_blocked
+		FROM latest
+		JOIN users u ON u.id = latest.peer_id
+		ORDER BY latest.id DESC
+		LIMIT @limit OFFSET @offset`,
+		map[string]interface{}{
+			"uid":    userID,
+			"limit":  pageSize,
+			"offset": (page - 1) * pageSize,
+		}).Scan(&conversations).Error
+	return conversations, total, err
+}
+
+// ListMessages 分页获取两个用户之间的私信，按发送时间倒序
+func (r *DirectMessageRepository) ListMessages(userID, peerID uint, page, pageSize int) ([]model.DirectMessage, int64, error) {
+	var messages []model.DirectMessage
+	var total int64
+
+	query := db.DB.Model(&model.DirectMessage{}).
+		Where("(sender_id = ? AND receiver_id = ?) OR (sender_id = ? AND receiver_id = ?)", userID, peerID, peerID, userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&messages).Error
+	return messages, total, err
+}
+
+// MarkRead 将对方发来的未读私信标记为已读，返回标记的条数
+func (r *DirectMessageRepository) MarkRead(userID, peerID uint) (int64, error) {
+	result := db.DB.Model(&model.DirectMessage{}).
+		Where("receiver_id = ? AND sender_id = ? AND is_read = ?", userID, peerID, false).
+		Updates(map[string]interface{}{
+			"is_read": true,
+			"read_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// CountUnread 统计用户收到的未读私信总数
+func (r *DirectMessageRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := db.DB.Model(&model.DirectMessage{}).
+		Where("receiver_id = ? AND is_read = ?", userID, false).
+		Count(&count).Error
+	return count, err
+}
+
+// IsBlocked 判断 userID 是否屏蔽了 blockedID
+func (r *DirectMessageRepository) IsBlocked(userID, blockedID uint) (bool, error) {
+	var count int64
+	err := db.DB.Model(&model.UserBlock{}).
+		Where("user_id = ? AND blocked_id = ?", userID, blockedID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Block 屏蔽用户，重复屏蔽时忽略
+func (r *DirectMessageRepository) Block(userID, blockedID uint) error {
+	return db.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "blocked_id"}},
+		DoNothing: true,
+	}).Create(&model.UserBlock{UserID: userID, BlockedID: blockedID}).Error
+}
+
+// Unblock 取消屏蔽，返回是否存在该屏蔽记录
+func (r *DirectMessageRepository) Unblock(userID, blockedID uint) (bool, error) {
+	result := db.DB.Where("user_id = ? AND blocked_id = ?", userID, blockedID).Delete(&model.UserBlock{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListBlocked 获取用户屏蔽的所有用户
+func (r *DirectMessageRepository) ListBlocked(userID uint) ([]BlockedUser, error) {
+	var users []BlockedUser
+	err := db.DB.Table("user_blocks").
+		Select("users.id, users.username, COALESCE(users.nickname, '') AS nickname, COALESCE(users.avatar, '') AS avatar, user_blocks.created_at AS blocked_at").
+		Joins("JOIN users ON users.id = user_blocks.blocked_id").
+		Where("user_blocks.user_id = ?", userID).
+		Order("user_blocks.created_at DESC").
+		Scan(&users).Error
+	return users, err
+}
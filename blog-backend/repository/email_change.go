@@ -11,10 +11,13 @@
 package repository
 
 import (
+	"errors"
 	"time"
 
 	"blog-backend/db"
 	"blog-backend/model"
+
+	"gorm.io/gorm"
 )
 
 // EmailChangeRepository 邮箱修改记录数据访问层结构体
@@ -30,16 +33,83 @@ func (r *EmailChangeRepository) Create(record *model.EmailChangeRecord) error {
 	return db.DB.Create(record).Error
 }
 
-// CountByUserIDInYear 统计用户一年内的邮箱修改次数
+// CountByUserIDInYear 统计用户一年内的邮箱修改次数（已撤销的修改不计入）
 func (r *EmailChangeRepository) CountByUserIDInYear(userID uint) (int64, error) {
 	var count int64
 	oneYearAgo := time.Now().AddDate(-1, 0, 0) // 一年前
 	err := db.DB.Model(&model.EmailChangeRecord{}).
-		Where("user_id = ? AND changed_at >= ?", userID, oneYearAgo).
+		Where("user_id = ? AND changed_at >= ? AND reverted_at IS NULL", userID, oneYearAgo).
 		Count(&count).Error
 	return count, err
 }
 
+// ApplyChange 在同一事务中修改用户邮箱并写入修改记录
+// 返回:
+//   - bool: 用户当前邮箱已不是 record.OldEmail（并发修改）时返回 false
+func (r *EmailChangeRepository) ApplyChange(record *model.EmailChangeRecord) (bool, error) {
+	changed := false
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.User{}).
+			Where("id = ? AND email = ?", record.UserID, record.OldEmail).
+			Update("email", record.NewEmail)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+		changed = true
+		return nil
+	})
+	return changed, err
+}
+
+// GetByRevertToken 根据撤销令牌哈希获取修改记录
+func (r *EmailChangeRepository) GetByRevertToken(tokenHash string) (*model.EmailChangeRecord, error) {
+	var record model.EmailChangeRecord
+	err := db.DB.Where("revert_token = ?", tokenHash).First(&record).Error
+	return &record, err
+}
+
+// Revert 在同一事务中撤销邮箱修改：恢复原邮箱并标记记录为已撤销
+// 返回:
+//   - bool: 记录已撤销或用户邮箱已再次修改时返回 false
+func (r *EmailChangeRepository) Revert(record *model.EmailChangeRecord) (bool, error) {
+	reverted := false
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.EmailChangeRecord{}).
+			Where("id = ? AND reverted_at IS NULL", record.ID).
+			Update("reverted_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		result = tx.Model(&model.User{}).
+			Where("id = ? AND email = ?", record.UserID, record.NewEmail).
+			Update("email", record.OldEmail)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// 邮箱已再次修改，回滚撤销标记
+			return gorm.ErrRecordNotFound
+		}
+		reverted = true
+		return nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return reverted, err
+}
+
 // GetRecordsByUserID 获取用户的邮箱修改记录
 func (r *EmailChangeRepository) GetRecordsByUserID(userID uint, limit int) ([]model.EmailChangeRecord, error) {
 	var records []model.EmailChangeRecord
@@ -0,0 +1,88 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：friend_feed.go
+ * 创建时间：2026-10-17 18:36:02
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：朋友圈数据访问层，提供友链订阅文章的去重写入、时间线查询以及订阅抓取状态的读写
+ */
+package repository
+
+import (
+	"blog-backend/db"
+	"blog-backend/model"
+
+	"gorm.io/gorm/clause"
+)
+
+// FriendFeedRepository 朋友圈数据访问层结构体
+type FriendFeedRepository struct{}
+
+// NewFriendFeedRepository 创建朋友圈数据访问层实例
+func NewFriendFeedRepository() *FriendFeedRepository {
+	return &FriendFeedRepository{}
+}
+
+// InsertItems 批量写入订阅文章，(friend_link_id, guid) 已存在的条目会被忽略
+// 返回:
+//   - int64: 实际新增的条目数
+func (r *FriendFeedRepository) InsertItems(items []model.FriendFeedItem) (int64, error) {
+	if len(items) == 0 {
+		return 0, nil
+	}
+	result := db.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "friend_link_id"}, {Name: "guid"}},
+		DoNothing: true,
+	}).Create(&items)
+	return result.RowsAffected, result.Error
+}
+
+// PruneItems 只保留友链最新的 keep 条文章
+func (r *FriendFeedRepository) PruneItems(friendLinkID uint, keep int) error {
+	return db.DB.Exec(`
+		DELETE FROM friend_feed_items
+		WHERE friend_link_id = ? AND id NOT IN (
+			SELECT id FROM friend_feed_items
+			WHERE friend_link_id = ?
+			ORDER BY published_at DESC, id DESC
+			LIMIT ?
+		)`, friendLinkID, friendLinkID, keep).Error
+}
+
+// ListTimeline 获取朋友圈时间线（只包含启用的友链，按发布时间倒序）
+func (r *FriendFeedRepository) ListTimeline(page, pageSize int) ([]model.FriendFeedItem, int64, error) {
+	var items []model.FriendFeedItem
+	var total int64
+
+	query := db.DB.Model(&model.FriendFeedItem{}).
+		Joins("JOIN friend_links ON friend_links.id = friend_feed_items.friend_link_id").
+		Where("friend_links.status = ?", 1)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Preload("FriendLink").
+		Order("friend_feed_items.published_at DESC, friend_feed_items.id DESC").
+		Offset(offset).Limit(pageSize).
+		Find(&items).Error
+
+	return items, total, err
+}
+
+// ListStates 获取所有订阅抓取状态
+func (r *FriendFeedRepository) ListStates() ([]model.FriendFeedState, error) {
+	var states []model.FriendFeedState
+	err := db.DB.Preload("FriendLink").Order("friend_link_id ASC").Find(&states).Error
+	return states, err
+}
+
+// SaveState 保存订阅抓取状态（不存在时创建）
+func (r *FriendFeedRepository) SaveState(state *model.FriendFeedState) error {
+	return db.DB.Omit("FriendLink").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "friend_link_id"}},
+		UpdateAll: true,
+	}).Create(state).Error
+}
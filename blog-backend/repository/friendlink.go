@@ -11,6 +11,8 @@
 package repository
 
 import (
+	"time"
+
 	"blog-backend/db"
 	"blog-backend/model"
 )
@@ -46,7 +48,7 @@ func (r *FriendLinkRepository) List(page, pageSize int) ([]model.FriendLink, int
 		return nil, 0, err
 	}
 
-	err := db.DB.Preload("Category").
+	err := db.DB.Preload("Category").Preload("Health").
 		Order("category_id ASC, sort_order DESC, id DESC").
 		Offset(offset).Limit(pageSize).
 		Find(&friendLinks).Error
@@ -64,6 +66,46 @@ func (r *FriendLinkRepository) ListPublic() ([]model.FriendLink, error) {
 	return friendLinks, err
 }
 
+// GetLatestUpdatedAt 获取启用友链的最后更新时间（没有启用的友链时返回nil）
+func (r *FriendLinkRepository) GetLatestUpdatedAt() (*time.Time, error) {
+	var latest *time.Time
+	err := db.DB.Model(&model.FriendLink{}).Where("status = ?", 1).Select("MAX(updated_at)").Scan(&latest).Error
+	return latest, err
+}
+
+// ListWithFeed 获取已启用且配置了订阅地址的友链
+func (r *FriendLinkRepository) ListWithFeed() ([]model.FriendLink, error) {
+	var friendLinks []model.FriendLink
+	err := db.DB.Where("status = ? AND atom_url <> ''", 1).Order("id ASC").Find(&friendLinks).Error
+	return friendLinks, err
+}
+
+// ListEnabled 获取所有启用的友链（健康检查用）
+func (r *FriendLinkRepository) ListEnabled() ([]model.FriendLink, error) {
+	var friendLinks []model.FriendLink
+	err := db.DB.Where("status = ?", 1).Order("id ASC").Find(&friendLinks).Error
+	return friendLinks, err
+}
+
+// Disable 禁用友链（仅当友链仍为启用状态时生效）
+// 返回:
+//   - bool: 是否实际禁用了友链
+func (r *FriendLinkRepository) Disable(id uint) (bool, error) {
+	result := db.DB.Model(&model.FriendLink{}).
+		Where("id = ? AND status = ?", id, 1).
+		Update("status", 0)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ExistsByURL 检查是否已存在相同网站地址的友链（忽略大小写和末尾斜杠）
+func (r *FriendLinkRepository) ExistsByURL(url string) (bool, error) {
+	var count int64
+	err := db.DB.Model(&model.FriendLink{}).
+		Where("RTRIM(LOWER(url), '/') = RTRIM(LOWER(?), '/')", url).
+		Count(&count).Error
+	return count > 0, err
+}
+
 // ListByCategory 根据分类ID获取友链列表
 func (r *FriendLinkRepository) ListByCategory(categoryID uint) ([]model.FriendLink, error) {
 	var friendLinks []model.FriendLink
@@ -0,0 +1,137 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：friendlink_application.go
+ * 创建时间：2026-10-17 20:15:32
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：友链申请数据访问层，提供友链申请的创建、查询以及审核时的状态流转
+ */
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"blog-backend/db"
+	"blog-backend/model"
+
+	"gorm.io/gorm"
+)
+
+// errApplicationReviewed 申请已不是待审核状态（用于回滚审核事务）
+var errApplicationReviewed = errors.New("application already reviewed")
+
+// FriendLinkApplicationRepository 友链申请数据访问层结构体
+type FriendLinkApplicationRepository struct{}
+
+// NewFriendLinkApplicationRepository 创建友链申请数据访问层实例
+func NewFriendLinkApplicationRepository() *FriendLinkApplicationRepository {
+	return &FriendLinkApplicationRepository{}
+}
+
+// Create 创建友链申请
+func (r *FriendLinkApplicationRepository) Create(app *model.FriendLinkApplication) error {
+	return db.DB.Create(app).Error
+}
+
+// GetByID 根据ID获取友链申请
+func (r *FriendLinkApplicationRepository) GetByID(id uint) (*model.FriendLinkApplication, error) {
+	var app model.FriendLinkApplication
+	err := db.DB.First(&app, id).Error
+	return &app, err
+}
+
+// List 获取友链申请列表
+// 参数:
+//   - status: 状态筛选，nil 表示全部
+func (r *FriendLinkApplicationRepository) List(page, pageSize int, status *int) ([]model.FriendLinkApplication, int64, error) {
+	var apps []model.FriendLinkApplication
+	var total int64
+
+	query := db.DB.Model(&model.FriendLinkApplication{})
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(pageSize).Find(&apps).Error
+	return apps, total, err
+}
+
+// ExistsPendingByURL 检查同一网站地址是否已有待审核的申请
+func (r *FriendLinkApplicationRepository) ExistsPendingByURL(url string) (bool, error) {
+	var count int64
+	err := db.DB.Model(&model.FriendLinkApplication{}).
+		Where("RTRIM(LOWER(url), '/') = RTRIM(LOWER(?), '/') AND status = ?", url, 0).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CountRecentByIP 统计IP在指定时间之后提交的申请数
+func (r *FriendLinkApplicationRepository) CountRecentByIP(ip string, since time.Time) (int64, error) {
+	var count int64
+	err := db.DB.Model(&model.FriendLinkApplication{}).
+		Where("ip = ? AND created_at > ?", ip, since).
+		Count(&count).Error
+	return count, err
+}
+
+// Approve 审核通过：在同一事务中创建友链并将申请标记为已通过
+// 返回:
+//   - bool: 申请已不是待审核状态（被并发审核）时返回 false，此时不会创建友链
+func (r *FriendLinkApplicationRepository) Approve(app *model.FriendLinkApplication, friendLink *model.FriendLink, reviewerID uint) (bool, error) {
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(friendLink).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		result := tx.Model(&model.FriendLinkApplication{}).
+			Where("id = ? AND status = ?", app.ID, 0).
+			Updates(map[string]interface{}{
+				"status":         1,
+				"friend_link_id": friendLink.ID,
+				"reviewer_id":    reviewerID,
+				"reviewed_at":    now,
+				"updated_at":     now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errApplicationReviewed
+		}
+		return nil
+	})
+	if errors.Is(err, errApplicationReviewed) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Reject 审核拒绝
+// 返回:
+//   - bool: 申请已不是待审核状态时返回 false
+func (r *FriendLinkApplicationRepository) Reject(id uint, reason string, reviewerID uint) (bool, error) {
+	now := time.Now()
+	result := db.DB.Model(&model.FriendLinkApplication{}).
+		Where("id = ? AND status = ?", id, 0).
+		Updates(map[string]interface{}{
+			"status":        2,
+			"reject_reason": reason,
+			"reviewer_id":   reviewerID,
+			"reviewed_at":   now,
+			"updated_at":    now,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Delete 删除友链申请
+func (r *FriendLinkApplicationRepository) Delete(id uint) error {
+	return db.DB.Delete(&model.FriendLinkApplication{}, id).Error
+}
@@ -0,0 +1,48 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：friendlink_health.go
+ * 创建时间：2026-10-17 19:40:27
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：友链健康检查数据访问层，提供检查结果的读写
+ */
+package repository
+
+import (
+	"blog-backend/db"
+	"blog-backend/model"
+
+	"gorm.io/gorm/clause"
+)
+
+// FriendLinkHealthRepository 友链健康检查数据访问层结构体
+type FriendLinkHealthRepository struct{}
+
+// NewFriendLinkHealthRepository 创建友链健康检查数据访问层实例
+func NewFriendLinkHealthRepository() *FriendLinkHealthRepository {
+	return &FriendLinkHealthRepository{}
+}
+
+// List 获取所有友链的检查结果
+func (r *FriendLinkHealthRepository) List() ([]model.FriendLinkHealth, error) {
+	var list []model.FriendLinkHealth
+	err := db.DB.Order("friend_link_id ASC").Find(&list).Error
+	return list, err
+}
+
+// Save 保存检查结果（不存在时创建）
+func (r *FriendLinkHealthRepository) Save(health *model.FriendLinkHealth) error {
+	return db.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "friend_link_id"}},
+		UpdateAll: true,
+	}).Create(health).Error
+}
+
+// ResetFailures 清零连续失败次数和自动禁用标记（管理员重新启用友链时调用）
+func (r *FriendLinkHealthRepository) ResetFailures(friendLinkID uint) error {
+	return db.DB.Model(&model.FriendLinkHealth{}).
+		Where("friend_link_id = ?", friendLinkID).
+		Updates(map[string]interface{}{"consecutive_failures": 0, "auto_disabled_at": nil}).Error
+}
@@ -0,0 +1,84 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：invite_code.go
+ * 创建时间：2026-10-18 05:31:09
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：注册邀请码数据访问层，提供邀请码的查询、创建、删除，以及使用邀请码注册时的原子扣减
+ */
+package repository
+
+import (
+	"time"
+
+	"blog-backend/db"
+	"blog-backend/model"
+
+	"gorm.io/gorm"
+)
+
+// InviteCodeRepository 注册邀请码数据访问层结构体
+type InviteCodeRepository struct{}
+
+// NewInviteCodeRepository 创建注册邀请码数据访问层实例
+func NewInviteCodeRepository() *InviteCodeRepository {
+	return &InviteCodeRepository{}
+}
+
+// List 获取邀请码列表（按创建时间倒序）
+func (r *InviteCodeRepository) List(page, pageSize int) ([]model.InviteCode, int64, error) {
+	var codes []model.InviteCode
+	var total int64
+
+	if err := db.DB.Model(&model.InviteCode{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := db.DB.Order("created_at DESC, id DESC").Offset(offset).Limit(pageSize).Find(&codes).Error
+	return codes, total, err
+}
+
+// GetByCode 根据邀请码获取记录
+func (r *InviteCodeRepository) GetByCode(code string) (*model.InviteCode, error) {
+	var inviteCode model.InviteCode
+	err := db.DB.Where("code = ?", code).First(&inviteCode).Error
+	return &inviteCode, err
+}
+
+// Create 创建邀请码
+func (r *InviteCodeRepository) Create(inviteCode *model.InviteCode) error {
+	return db.DB.Create(inviteCode).Error
+}
+
+// Delete 删除邀请码
+// 返回:
+//   - bool: 是否存在并删除了邀请码
+func (r *InviteCodeRepository) Delete(id uint) (bool, error) {
+	result := db.DB.Delete(&model.InviteCode{}, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CreateUserWithCode 在同一事务中扣减邀请码使用次数并创建用户
+// 只有邀请码未过期且仍有剩余次数时才扣减，并发注册不会超出次数限制
+// 返回:
+//   - bool: 邀请码是否有效（无效时不创建用户）
+func (r *InviteCodeRepository) CreateUserWithCode(user *model.User, code string) (bool, error) {
+	valid := false
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.InviteCode{}).
+			Where("code = ? AND used_count < max_uses AND (expire_at IS NULL OR expire_at > ?)", code, time.Now()).
+			UpdateColumn("used_count", gorm.Expr("used_count + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		valid = true
+		return tx.Create(user).Error
+	})
+	return valid, err
+}
@@ -0,0 +1,71 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：login_history.go
+ * 创建时间：2026-10-18 00:32:18
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：登录历史数据访问层，提供登录记录的写入、分页查询和过期清理
+ */
+package repository
+
+import (
+	"time"
+
+	"blog-backend/db"
+	"blog-backend/model"
+)
+
+// LoginHistoryRepository 登录历史数据访问层结构体
+type LoginHistoryRepository struct{}
+
+// NewLoginHistoryRepository 创建登录历史数据访问层实例
+func NewLoginHistoryRepository() *LoginHistoryRepository {
+	return &LoginHistoryRepository{}
+}
+
+// LoginHistoryFilter 登录历史筛选条件
+type LoginHistoryFilter struct {
+	UserID   uint   // 用户ID，0 表示不限
+	Username string // 用户名（模糊匹配）
+	IP       string // 客户端IP
+	Success  *bool  // 登录结果，nil 表示全部
+}
+
+// Create 写入登录记录
+func (r *LoginHistoryRepository) Create(history *model.LoginHistory) error {
+	return db.DB.Create(history).Error
+}
+
+// List 分页获取登录记录（按时间倒序）
+func (r *LoginHistoryRepository) List(page, pageSize int, filter LoginHistoryFilter) ([]model.LoginHistory, int64, error) {
+	var list []model.LoginHistory
+	var total int64
+
+	query := db.DB.Model(&model.LoginHistory{})
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Username != "" {
+		query = query.Where("username LIKE ?", "%"+filter.Username+"%")
+	}
+	if filter.IP != "" {
+		query = query.Where("ip = ?", filter.IP)
+	}
+	if filter.Success != nil {
+		query = query.Where("success = ?", *filter.Success)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(pageSize).Find(&list).Error
+	return list, total, err
+}
+
+// DeleteBefore 删除指定时间之前的登录记录
+func (r *LoginHistoryRepository) DeleteBefore(before time.Time) error {
+	return db.DB.Where("created_at < ?", before).Delete(&model.LoginHistory{}).Error
+}
@@ -11,6 +11,8 @@
 package repository
 
 import (
+	"time"
+
 	"blog-backend/db"
 	"blog-backend/model"
 
@@ -129,6 +131,13 @@ func (r *MomentRepository) GetRecent(limit int) ([]model.Moment, error) {
 	return moments, err
 }
 
+// GetLatestUpdatedAt 获取公开说说的最后更新时间（没有公开说说时返回nil）
+func (r *MomentRepository) GetLatestUpdatedAt() (*time.Time, error) {
+	var latest *time.Time
+	err := db.DB.Model(&model.Moment{}).Where("status = ?", 1).Select("MAX(updated_at)").Scan(&latest).Error
+	return latest, err
+}
+
 // CreateLike 创建点赞记录
 func (r *MomentRepository) CreateLike(like *model.MomentLike) error {
 	return db.DB.Create(like).Error
@@ -0,0 +1,64 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：oauth_binding.go
+ * 创建时间：2026-10-18 03:14:02
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：第三方账号绑定数据访问层，提供绑定关系的查询、创建和解除
+ */
+package repository
+
+import (
+	"blog-backend/db"
+	"blog-backend/model"
+
+	"gorm.io/gorm"
+)
+
+// OAuthBindingRepository 第三方账号绑定数据访问层结构体
+type OAuthBindingRepository struct{}
+
+// NewOAuthBindingRepository 创建第三方账号绑定数据访问层实例
+func NewOAuthBindingRepository() *OAuthBindingRepository {
+	return &OAuthBindingRepository{}
+}
+
+// GetByProviderUser 根据提供方和提供方内的用户ID获取绑定
+func (r *OAuthBindingRepository) GetByProviderUser(provider, providerUserID string) (*model.UserOAuthBinding, error) {
+	var binding model.UserOAuthBinding
+	err := db.DB.Where("provider = ? AND provider_user_id = ?", provider, providerUserID).First(&binding).Error
+	return &binding, err
+}
+
+// ListByUser 获取用户的全部绑定
+func (r *OAuthBindingRepository) ListByUser(userID uint) ([]model.UserOAuthBinding, error) {
+	var bindings []model.UserOAuthBinding
+	err := db.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&bindings).Error
+	return bindings, err
+}
+
+// Create 创建绑定
+func (r *OAuthBindingRepository) Create(binding *model.UserOAuthBinding) error {
+	return db.DB.Create(binding).Error
+}
+
+// CreateUserWithBinding 在同一事务中创建用户及其第三方账号绑定
+func (r *OAuthBindingRepository) CreateUserWithBinding(user *model.User, binding *model.UserOAuthBinding) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		binding.UserID = user.ID
+		return tx.Create(binding).Error
+	})
+}
+
+// Delete 解除用户在指定提供方的绑定
+// 返回:
+//   - bool: 是否存在并解除了绑定
+func (r *OAuthBindingRepository) Delete(userID uint, provider string) (bool, error) {
+	result := db.DB.Where("user_id = ? AND provider = ?", userID, provider).Delete(&model.UserOAuthBinding{})
+	return result.RowsAffected > 0, result.Error
+}
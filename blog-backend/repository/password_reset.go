@@ -30,16 +30,37 @@ func (r *PasswordResetRepository) Create(token *model.PasswordResetToken) error
 	return db.DB.Create(token).Error
 }
 
-// GetValidToken 获取有效的重置令牌
-func (r *PasswordResetRepository) GetValidToken(email, code string) (*model.PasswordResetToken, error) {
+// GetValidToken 获取指定用途的有效令牌
+// 参数:
+//   - purpose: 验证码用途（model.TokenPurposeRegister 等），不同用途的验证码不能混用
+func (r *PasswordResetRepository) GetValidToken(email, code, purpose string) (*model.PasswordResetToken, error) {
 	var token model.PasswordResetToken
-	err := db.DB.Where("email = ? AND code = ? AND is_used = ? AND expire_at > ?",
-		email, code, false, time.Now()).
+	err := db.DB.Where("email = ? AND code = ? AND purpose = ? AND is_used = ? AND expire_at > ?",
+		email, code, purpose, false, time.Now()).
 		Order("created_at DESC").
 		First(&token).Error
 	return &token, err
 }
 
+// GetValidByToken 根据令牌获取指定用途的有效令牌（用于通过邮件链接验证的场景）
+func (r *PasswordResetRepository) GetValidByToken(token, purpose string) (*model.PasswordResetToken, error) {
+	var resetToken model.PasswordResetToken
+	err := db.DB.Where("token = ? AND purpose = ? AND is_used = ? AND expire_at > ?",
+		token, purpose, false, time.Now()).
+		First(&resetToken).Error
+	return &resetToken, err
+}
+
+// MarkUsed 将令牌标记为已使用（仅当令牌仍未使用时生效，防止并发重复使用）
+// 返回:
+//   - bool: 是否由本次调用完成标记
+func (r *PasswordResetRepository) MarkUsed(id uint) (bool, error) {
+	result := db.DB.Model(&model.PasswordResetToken{}).
+		Where("id = ? AND is_used = ?", id, false).
+		Update("is_used", true)
+	return result.RowsAffected > 0, result.Error
+}
+
 // Update 更新令牌
 func (r *PasswordResetRepository) Update(token *model.PasswordResetToken) error {
 	return db.DB.Save(token).Error
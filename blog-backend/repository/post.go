@@ -11,7 +11,8 @@
 package repository
 
 import (
-	"blog-backend/constant"
+	"time"
+
 	"blog-backend/db"
 	"blog-backend/model"
 
@@ -97,7 +98,8 @@ func (r *PostRepository) GetPublishedCountByCategory(categoryID uint) (int64, er
 }
 
 // List 获取文章列表
-func (r *PostRepository) List(page, pageSize int, categoryID uint, keyword string, status int, visibility *int) ([]model.Post, int64, error) {
+// tagID 大于0时只返回包含该标签的文章
+func (r *PostRepository) List(page, pageSize int, categoryID, tagID uint, keyword string, status int, visibility *int) ([]model.Post, int64, error) {
 	var posts []model.Post
 	var total int64
 
@@ -114,6 +116,9 @@ func (r *PostRepository) List(page, pageSize int, categoryID uint, keyword strin
 	if categoryID > 0 {
 		query = query.Where("category_id = ?", categoryID)
 	}
+	if tagID > 0 {
+		query = query.Where("id IN (SELECT post_id FROM post_tags WHERE tag_id = ?)", tagID)
+	}
 	if keyword != "" {
 		// 使用PostgreSQL全文搜索（优先）+ ILIKE后备
 		// 如果search_tsv字段不存在或为NULL，查询会忽略该条件，只使用ILIKE
@@ -134,6 +139,38 @@ func (r *PostRepository) List(page, pageSize int, categoryID uint, keyword strin
 	return posts, total, err
 }
 
+// CountPublic 统计公开的已发布文章数量
+func (r *PostRepository) CountPublic() (int64, error) {
+	var count int64
+	err := db.DB.Model(&model.Post{}).Where("status = 1 AND visibility = 1").Count(&count).Error
+	return count, err
+}
+
+// IsPublic 判断文章是否为公开的已发布文章
+func (r *PostRepository) IsPublic(id uint) (bool, error) {
+	var count int64
+	err := db.DB.Model(&model.Post{}).Where("id = ? AND status = 1 AND visibility = 1", id).Count(&count).Error
+	return count > 0, err
+}
+
+// ListPublicForSitemap 分页获取公开的已发布文章（只查询站点地图需要的字段，按ID排序）
+func (r *PostRepository) ListPublicForSitemap(offset, limit int) ([]model.Post, error) {
+	var posts []model.Post
+	err := db.DB.Select("id", "slug", "updated_at").
+		Where("status = 1 AND visibility = 1").
+		Order("id ASC").
+		Offset(offset).Limit(limit).
+		Find(&posts).Error
+	return posts, err
+}
+
+// GetLatestPublicUpdatedAt 获取公开的已发布文章的最后更新时间（没有文章时返回nil）
+func (r *PostRepository) GetLatestPublicUpdatedAt() (*time.Time, error) {
+	var latest *time.Time
+	err := db.DB.Model(&model.Post{}).Where("status = 1 AND visibility = 1").Select("MAX(updated_at)").Scan(&latest).Error
+	return latest, err
+}
+
 // GetByTag 根据标签获取文章列表
 func (r *PostRepository) GetByTag(tagID uint, page, pageSize int) ([]model.Post, int64, error) {
 	var posts []model.Post
@@ -229,16 +266,16 @@ func (r *PostRepository) GetHotPosts(limit int) ([]model.Post, error) {
 }
 
 // GetRecentPosts 获取最新文章
-// - 普通用户/游客：仅公开
-// - 管理员（包含 super_admin）：公开 + 自己的私密
-func (r *PostRepository) GetRecentPosts(limit int, userID *uint, role string) ([]model.Post, error) {
+// - ownerID 为空：仅公开（普通用户/游客）
+// - ownerID 不为空：公开 + 该用户自己的私密（由调用方判断是否具备 post.manage 权限）
+func (r *PostRepository) GetRecentPosts(limit int, ownerID *uint) ([]model.Post, error) {
 	var posts []model.Post
 
 	query := db.DB.Preload("User").Preload("Category").Where("status = 1")
 
-	if constant.IsAdminRole(role) && userID != nil {
-		// 管理员（包含 super_admin）可见自己的私密文章，其余仍需公开
-		query = query.Where("visibility = 1 OR user_id = ?", *userID)
+	if ownerID != nil {
+		// 文章管理员可见自己的私密文章，其余仍需公开
+		query = query.Where("visibility = 1 OR user_id = ?", *ownerID)
 	} else {
 		// 普通用户/游客仅公开文章
 		query = query.Where("visibility = 1")
@@ -352,6 +389,34 @@ func (r *PostRepository) UpdateTx(tx *gorm.DB, post *model.Post) error {
 	return nil
 }
 
+// UpdateIfStatusTx 在事务中更新文章，仅当数据库中的状态仍为 expectedStatus 时才写入
+// 避免编辑期间文章被定时任务发布后，又被编辑请求中读取到的旧状态覆盖
+// 返回:
+//   - bool: 是否更新成功（状态已被修改时返回 false）
+func (r *PostRepository) UpdateIfStatusTx(tx *gorm.DB, post *model.Post, expectedStatus int) (bool, error) {
+	result := tx.Model(post).
+		Where("status = ?", expectedStatus).
+		Select("title", "slug", "content", "summary", "cover", "category_id", "status", "visibility", "is_top", "published_at", "updated_at").
+		Updates(post)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+
+	// 更新全文搜索向量
+	tx.Exec(
+		"UPDATE posts SET search_tsv = setweight(to_tsvector('english', coalesce(title, '')), 'A') || setweight(to_tsvector('english', coalesce(content, '')), 'B') WHERE id = ?",
+		post.ID,
+	)
+
+	return true, nil
+}
+
+// SetTimestampsTx 在事务中设置文章的创建和更新时间（用于导入时保留原始日期）
+func (r *PostRepository) SetTimestampsTx(tx *gorm.DB, id uint, createdAt, updatedAt time.Time) error {
+	return tx.Model(&model.Post{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"created_at": createdAt, "updated_at": updatedAt}).Error
+}
+
 // UpdateTagsTx 在事务中更新文章标签
 func (r *PostRepository) UpdateTagsTx(tx *gorm.DB, postID uint, tagIDs []uint) error {
 	var post model.Post
@@ -367,6 +432,27 @@ func (r *PostRepository) UpdateTagsTx(tx *gorm.DB, postID uint, tagIDs []uint) e
 	return tx.Model(&post).Association("Tags").Replace(tags)
 }
 
+// ListDueScheduled 获取已到发布时间的定时发布文章（status=2）
+func (r *PostRepository) ListDueScheduled(now time.Time, limit int) ([]model.Post, error) {
+	var posts []model.Post
+	err := db.DB.Preload("Tags").
+		Where("status = 2 AND published_at <= ?", now).
+		Order("published_at ASC").
+		Limit(limit).Find(&posts).Error
+	return posts, err
+}
+
+// PublishScheduledTx 在事务中将定时文章切换为已发布
+// 使用 status=2 作为条件更新，多个实例同时执行时只有一个能更新成功
+// 返回:
+//   - bool: 是否由本次调用完成发布
+func (r *PostRepository) PublishScheduledTx(tx *gorm.DB, id uint) (bool, error) {
+	result := tx.Model(&model.Post{}).
+		Where("id = ? AND status = 2", id).
+		Updates(map[string]interface{}{"status": 1, "updated_at": time.Now()})
+	return result.RowsAffected == 1, result.Error
+}
+
 // CreateLikeTx 在事务中创建点赞记录
 func (r *PostRepository) CreateLikeTx(tx *gorm.DB, like *model.PostLike) error {
 	return tx.Create(like).Error
@@ -0,0 +1,58 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：post_revision.go
+ * 创建时间：2026-10-17 10:12:40
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：文章修订历史数据访问层，提供修订快照的写入和查询功能
+ */
+package repository
+
+import (
+	"blog-backend/db"
+	"blog-backend/model"
+
+	"gorm.io/gorm"
+)
+
+// PostRevisionRepository 文章修订历史数据访问层结构体
+type PostRevisionRepository struct{}
+
+// NewPostRevisionRepository 创建文章修订历史数据访问层实例
+func NewPostRevisionRepository() *PostRevisionRepository {
+	return &PostRevisionRepository{}
+}
+
+// CreateTx 在事务中创建修订记录
+func (r *PostRevisionRepository) CreateTx(tx *gorm.DB, revision *model.PostRevision) error {
+	return tx.Create(revision).Error
+}
+
+// ListByPostID 获取文章的修订列表（不返回正文，按时间倒序）
+func (r *PostRevisionRepository) ListByPostID(postID uint, page, pageSize int) ([]model.PostRevision, int64, error) {
+	var revisions []model.PostRevision
+	var total int64
+
+	query := db.DB.Model(&model.PostRevision{}).Where("post_id = ?", postID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Select("id", "post_id", "title", "summary", "user_id", "remark", "created_at").
+		Preload("User").
+		Order("created_at DESC, id DESC").
+		Offset(offset).Limit(pageSize).
+		Find(&revisions).Error
+
+	return revisions, total, err
+}
+
+// GetByID 根据ID获取修订记录（限定所属文章，防止跨文章访问）
+func (r *PostRevisionRepository) GetByID(postID, id uint) (*model.PostRevision, error) {
+	var revision model.PostRevision
+	err := db.DB.Preload("User").Where("post_id = ?", postID).First(&revision, id).Error
+	return &revision, err
+}
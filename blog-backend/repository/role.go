@@ -0,0 +1,238 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：role.go
+ * 创建时间：2026-10-18 02:19:48
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：角色数据访问层，提供角色及其权限集合的增删改查，以及带短期进程内缓存的权限判定
+ */
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"blog-backend/constant"
+	"blog-backend/db"
+	"blog-backend/model"
+
+	"gorm.io/gorm"
+)
+
+// rolePermissionCacheTTL 角色权限缓存有效期
+// 本实例修改角色后立即失效；多实例部署时其他实例最多延迟该时长生效
+const rolePermissionCacheTTL = 30 * time.Second
+
+// rolePermissionCache 角色名 -> 权限集合的进程内缓存（权限判定在每个管理接口上都会执行）
+var rolePermissionCache = struct {
+	sync.RWMutex
+	perms    map[string]map[string]bool
+	loadedAt time.Time
+}{}
+
+// RoleRepository 角色数据访问层结构体
+type RoleRepository struct{}
+
+// NewRoleRepository 创建角色数据访问层实例
+func NewRoleRepository() *RoleRepository {
+	return &RoleRepository{}
+}
+
+// List 获取所有角色（含权限）
+func (r *RoleRepository) List() ([]model.Role, error) {
+	var roles []model.Role
+	err := db.DB.Preload("Permissions").Order("is_system DESC, id ASC").Find(&roles).Error
+	return roles, err
+}
+
+// GetByID 根据ID获取角色（含权限）
+func (r *RoleRepository) GetByID(id uint) (*model.Role, error) {
+	var role model.Role
+	err := db.DB.Preload("Permissions").First(&role, id).Error
+	return &role, err
+}
+
+// GetByName 根据角色标识获取角色
+func (r *RoleRepository) GetByName(name string) (*model.Role, error) {
+	var role model.Role
+	err := db.DB.Where("name = ?", name).First(&role).Error
+	return &role, err
+}
+
+// CountUsers 统计各角色的用户数
+func (r *RoleRepository) CountUsers() (map[string]int64, error) {
+	var rows []struct {
+		Role  string
+		Count int64
+	}
+	if err := db.DB.Model(&model.User{}).Select("role, COUNT(*) AS count").Group("role").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Role] = row.Count
+	}
+	return counts, nil
+}
+
+// Create 创建角色及其权限
+func (r *RoleRepository) Create(role *model.Role, permissions []string) error {
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Permissions").Create(role).Error; err != nil {
+			return err
+		}
+		return replaceRolePermissions(tx, role.ID, permissions)
+	})
+	if err == nil {
+		invalidateRolePermissionCache()
+	}
+	return err
+}
+
+// Update 更新角色信息并整体替换其权限
+func (r *RoleRepository) Update(role *model.Role, permissions []string) error {
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Role{}).Where("id = ?", role.ID).Updates(map[string]interface{}{
+			"display_name": role.DisplayName,
+			"description":  role.Description,
+			"updated_at":   time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		return replaceRolePermissions(tx, role.ID, permissions)
+	})
+	if err == nil {
+		invalidateRolePermissionCache()
+	}
+	return err
+}
+
+// Delete 删除角色（仍有用户使用该角色时不删除）
+// 返回:
+//   - bool: 是否删除成功（角色不存在、为内置角色或仍有用户时返回 false）
+func (r *RoleRepository) Delete(role *model.Role) (bool, error) {
+	result := db.DB.Where("id = ? AND is_system = ? AND NOT EXISTS (SELECT 1 FROM users WHERE users.role = ?)", role.ID, false, role.Name).
+		Delete(&model.Role{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	invalidateRolePermissionCache()
+	return true, nil
+}
+
+// EnsureSystemRoles 补齐缺失的内置角色及其默认权限（已存在的角色保持不变）
+func (r *RoleRepository) EnsureSystemRoles(roles []model.Role) error {
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		for _, role := range roles {
+			var count int64
+			if err := tx.Model(&model.Role{}).Where("name = ?", role.Name).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+
+			created := role
+			created.Permissions = nil
+			if err := tx.Create(&created).Error; err != nil {
+				return err
+			}
+			permissions := make([]string, 0, len(role.Permissions))
+			for _, p := range role.Permissions {
+				permissions = append(permissions, p.Permission)
+			}
+			if err := replaceRolePermissions(tx, created.ID, permissions); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		invalidateRolePermissionCache()
+	}
+	return err
+}
+
+// GetPermissions 获取角色拥有的权限集合（super_admin 始终拥有全部权限）
+func (r *RoleRepository) GetPermissions(roleName string) (map[string]bool, error) {
+	if roleName == constant.RoleSuperAdmin {
+		all := make(map[string]bool, len(constant.Permissions))
+		for _, p := range constant.Permissions {
+			all[p.Name] = true
+		}
+		return all, nil
+	}
+
+	rolePermissionCache.RLock()
+	if rolePermissionCache.perms != nil && time.Since(rolePermissionCache.loadedAt) < rolePermissionCacheTTL {
+		perms := rolePermissionCache.perms[roleName]
+		rolePermissionCache.RUnlock()
+		return perms, nil
+	}
+	rolePermissionCache.RUnlock()
+
+	var rows []struct {
+		Name       string
+		Permission string
+	}
+	err := db.DB.Table("role_permissions").
+		Select("roles.name, role_permissions.permission").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	perms := make(map[string]map[string]bool)
+	for _, row := range rows {
+		if perms[row.Name] == nil {
+			perms[row.Name] = make(map[string]bool)
+		}
+		perms[row.Name][row.Permission] = true
+	}
+
+	rolePermissionCache.Lock()
+	rolePermissionCache.perms = perms
+	rolePermissionCache.loadedAt = time.Now()
+	rolePermissionCache.Unlock()
+
+	return perms[roleName], nil
+}
+
+// HasPermission 判断角色是否拥有指定权限（查询失败时视为没有权限）
+func (r *RoleRepository) HasPermission(roleName, permission string) bool {
+	if roleName == "" {
+		return false
+	}
+	perms, err := r.GetPermissions(roleName)
+	return err == nil && perms[permission]
+}
+
+// invalidateRolePermissionCache 使角色权限缓存失效
+func invalidateRolePermissionCache() {
+	rolePermissionCache.Lock()
+	rolePermissionCache.perms = nil
+	rolePermissionCache.Unlock()
+}
+
+// replaceRolePermissions 整体替换角色的权限
+func replaceRolePermissions(tx *gorm.DB, roleID uint, permissions []string) error {
+	if err := tx.Where("role_id = ?", roleID).Delete(&model.RolePermission{}).Error; err != nil {
+		return err
+	}
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	records := make([]model.RolePermission, 0, len(permissions))
+	for _, p := range permissions {
+		records = append(records, model.RolePermission{RoleID: roleID, Permission: p})
+	}
+	return tx.Create(&records).Error
+}
@@ -0,0 +1,140 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：session.go
+ * 创建时间：2026-10-17 21:45:16
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：登录会话数据访问层，提供会话和刷新令牌的创建、轮换、吊销及过期清理
+ */
+package repository
+
+import (
+	"time"
+
+	"blog-backend/db"
+	"blog-backend/model"
+
+	"gorm.io/gorm"
+)
+
+// SessionRepository 登录会话数据访问层结构体
+type SessionRepository struct{}
+
+// NewSessionRepository 创建登录会话数据访问层实例
+func NewSessionRepository() *SessionRepository {
+	return &SessionRepository{}
+}
+
+// Create 在同一事务中创建会话及其第一个刷新令牌
+func (r *SessionRepository) Create(session *model.UserSession, token *model.RefreshToken) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		token.SessionID = session.ID
+		return tx.Create(token).Error
+	})
+}
+
+// GetByID 根据ID获取会话
+func (r *SessionRepository) GetByID(id uint) (*model.UserSession, error) {
+	var session model.UserSession
+	err := db.DB.First(&session, id).Error
+	return &session, err
+}
+
+// GetRefreshToken 根据令牌哈希获取刷新令牌（包含所属会话）
+func (r *SessionRepository) GetRefreshToken(tokenHash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := db.DB.Preload("Session").Where("token_hash = ?", tokenHash).First(&token).Error
+	return &token, err
+}
+
+// Rotate 轮换刷新令牌：将旧令牌标记为已使用、写入新令牌并更新会话信息
+// 返回:
+//   - bool: 旧令牌已被使用（并发重放）时返回 false，此时不会写入新令牌
+func (r *SessionRepository) Rotate(oldTokenID uint, newToken *model.RefreshToken, session *model.UserSession) (bool, error) {
+	rotated := false
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&model.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", oldTokenID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Create(newToken).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(session).Updates(map[string]interface{}{
+			"ip":           session.IP,
+			"user_agent":   session.UserAgent,
+			"last_used_at": session.LastUsedAt,
+			"expires_at":   session.ExpiresAt,
+		}).Error; err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	return rotated, err
+}
+
+// ListActiveByUser 获取用户未吊销且未过期的会话（最近使用的在前）
+func (r *SessionRepository) ListActiveByUser(userID uint) ([]model.UserSession, error) {
+	var sessions []model.UserSession
+	err := db.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Revoke 吊销会话
+// 返回:
+//   - bool: 会话此前未被吊销时返回 true
+func (r *SessionRepository) Revoke(id uint, reason string) (bool, error) {
+	result := db.DB.Model(&model.UserSession{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoke_reason": reason})
+	return result.RowsAffected > 0, result.Error
+}
+
+// RevokeAllByUser 吊销用户所有未吊销的会话
+// 参数:
+//   - exceptID: 保留的会话ID（0 表示全部吊销）
+//
+// 返回:
+//   - []uint: 被吊销的会话ID
+func (r *SessionRepository) RevokeAllByUser(userID, exceptID uint, reason string) ([]uint, error) {
+	var ids []uint
+	query := db.DB.Model(&model.UserSession{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+	if exceptID != 0 {
+		query = query.Where("id <> ?", exceptID)
+	}
+	if err := query.Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	err := db.DB.Model(&model.UserSession{}).
+		Where("id IN ? AND revoked_at IS NULL", ids).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoke_reason": reason}).Error
+	return ids, err
+}
+
+// DeleteExpired 删除过期的刷新令牌和会话（定期清理）
+func (r *SessionRepository) DeleteExpired() error {
+	now := time.Now()
+	if err := db.DB.Where("expires_at < ?", now).Delete(&model.RefreshToken{}).Error; err != nil {
+		return err
+	}
+	return db.DB.Where("expires_at < ?", now).Delete(&model.UserSession{}).Error
+}
@@ -0,0 +1,151 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：two_factor.go
+ * 创建时间：2026-10-17 22:52:18
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：两步验证数据访问层，提供TOTP密钥的保存、启用、停用，验证码防重放记录以及恢复码的生成和核销
+ */
+package repository
+
+import (
+	"time"
+
+	"blog-backend/db"
+	"blog-backend/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TwoFactorRepository 两步验证数据访问层结构体
+type TwoFactorRepository struct{}
+
+// NewTwoFactorRepository 创建两步验证数据访问层实例
+func NewTwoFactorRepository() *TwoFactorRepository {
+	return &TwoFactorRepository{}
+}
+
+// Get 获取用户的两步验证配置
+func (r *TwoFactorRepository) Get(userID uint) (*model.UserTwoFactor, error) {
+	var tf model.UserTwoFactor
+	err := db.DB.Where("user_id = ?", userID).First(&tf).Error
+	return &tf, err
+}
+
+// IsEnabled 检查用户是否已启用两步验证
+func (r *TwoFactorRepository) IsEnabled(userID uint) (bool, error) {
+	var count int64
+	err := db.DB.Model(&model.UserTwoFactor{}).
+		Where("user_id = ? AND enabled = ?", userID, true).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// SavePending 保存待确认的密钥（覆盖之前未确认的密钥）
+func (r *TwoFactorRepository) SavePending(userID uint, secret string) error {
+	tf := &model.UserTwoFactor{UserID: userID, Secret: secret}
+	return db.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"secret":         secret,
+			"enabled":        false,
+			"last_used_step": 0,
+			"enabled_at":     nil,
+			"updated_at":     time.Now(),
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Eq{Column: clause.Column{Table: "user_two_factors", Name: "enabled"}, Value: false},
+		}},
+	}).Create(tf).Error
+}
+
+// Enable 启用两步验证并写入新的恢复码（原有恢复码作废）
+// 返回:
+//   - bool: 配置不存在或已启用时返回 false
+func (r *TwoFactorRepository) Enable(userID uint, step int64, codes []model.TwoFactorRecoveryCode) (bool, error) {
+	enabled := false
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&model.UserTwoFactor{}).
+			Where("user_id = ? AND enabled = ?", userID, false).
+			Updates(map[string]interface{}{
+				"enabled":        true,
+				"enabled_at":     now,
+				"last_used_step": step,
+				"updated_at":     now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := replaceRecoveryCodes(tx, userID, codes); err != nil {
+			return err
+		}
+		enabled = true
+		return nil
+	})
+	return enabled, err
+}
+
+// Disable 停用两步验证，删除密钥和全部恢复码
+func (r *TwoFactorRepository) Disable(userID uint) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&model.UserTwoFactor{}).Error
+	})
+}
+
+// MarkStepUsed 记录验证成功的时间步，同一时间步（或更早）的验证码不能再次使用
+// 返回:
+//   - bool: 该时间步已被使用（并发重放）时返回 false
+func (r *TwoFactorRepository) MarkStepUsed(userID uint, step int64) (bool, error) {
+	result := db.DB.Model(&model.UserTwoFactor{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReplaceRecoveryCodes 重新生成恢复码（原有恢复码全部作废）
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(userID uint, codes []model.TwoFactorRecoveryCode) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+// UseRecoveryCode 核销恢复码
+// 返回:
+//   - bool: 恢复码不存在或已使用时返回 false
+func (r *TwoFactorRepository) UseRecoveryCode(userID uint, codeHash string) (bool, error) {
+	result := db.DB.Model(&model.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// CountUnusedRecoveryCodes 统计剩余可用的恢复码数量
+func (r *TwoFactorRepository) CountUnusedRecoveryCodes(userID uint) (int64, error) {
+	var count int64
+	err := db.DB.Model(&model.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// replaceRecoveryCodes 在事务中删除用户原有恢复码并写入新恢复码
+func replaceRecoveryCodes(tx *gorm.DB, userID uint, codes []model.TwoFactorRecoveryCode) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&model.TwoFactorRecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return tx.Create(&codes).Error
+}
@@ -61,17 +61,23 @@ func (r *UserRepository) Delete(id uint) error {
 }
 
 // List 获取用户列表
-func (r *UserRepository) List(page, pageSize int) ([]model.User, int64, error) {
+// 参数:
+//   - status: 按状态筛选，为 nil 时不筛选
+func (r *UserRepository) List(page, pageSize int, status *int) ([]model.User, int64, error) {
 	var users []model.User
 	var total int64
 
 	offset := (page - 1) * pageSize
 
-	if err := db.DB.Model(&model.User{}).Count(&total).Error; err != nil {
+	query := db.DB.Model(&model.User{})
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	err := db.DB.Offset(offset).Limit(pageSize).Find(&users).Error
+	err := query.Offset(offset).Limit(pageSize).Find(&users).Error
 	return users, total, err
 }
 
@@ -80,14 +86,28 @@ func (r *UserRepository) UpdateStatus(id uint, status int) error {
 	return db.DB.Model(&model.User{}).Where("id = ?", id).Update("status", status).Error
 }
 
+// Approve 将待审核用户设为正常状态
+// 返回:
+//   - bool: 用户是否仍处于待审核状态并被更新（避免重复审核）
+func (r *UserRepository) Approve(id uint) (bool, error) {
+	result := db.DB.Model(&model.User{}).
+		Where("id = ? AND status = ?", id, model.UserStatusPending).
+		Update("status", model.UserStatusActive)
+	return result.RowsAffected > 0, result.Error
+}
+
 // UpdateRole 更新用户角色
 func (r *UserRepository) UpdateRole(id uint, role string) error {
 	return db.DB.Model(&model.User{}).Where("id = ?", id).Update("role", role).Error
 }
 
-// GetAdmins 获取所有管理员用户（包含 super_admin 和 admin）
+// GetAdmins 获取所有管理员用户（super_admin 以及拥有评论审核权限的角色）
 func (r *UserRepository) GetAdmins() ([]model.User, error) {
 	var admins []model.User
-	err := db.DB.Where("role IN ? AND status = ?", []string{constant.RoleAdmin, constant.RoleSuperAdmin}, 1).Find(&admins).Error
+	err := db.DB.Where("status = ? AND (role = ? OR role IN (?))", 1, constant.RoleSuperAdmin,
+		db.DB.Table("roles").Select("roles.name").
+			Joins("JOIN role_permissions ON role_permissions.role_id = roles.id").
+			Where("role_permissions.permission = ?", constant.PermCommentModerate)).
+		Find(&admins).Error
 	return admins, err
 }
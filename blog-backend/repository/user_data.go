@@ -0,0 +1,250 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：user_data.go
+ * 创建时间：2026-10-18 04:52:37
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：用户个人数据访问层，提供个人数据导出查询，以及删除账号时在同一事务中清理所有关联记录
+ */
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"blog-backend/db"
+	"blog-backend/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserDataRepository 用户个人数据访问层结构体
+type UserDataRepository struct{}
+
+// NewUserDataRepository 创建用户个人数据访问层实例
+func NewUserDataRepository() *UserDataRepository {
+	return &UserDataRepository{}
+}
+
+// ExportComment 导出的评论记录
+type ExportComment struct {
+	ID          uint      `json:"id"`
+	Content     string    `json:"content"`
+	CommentType string    `json:"comment_type"`
+	PostID      *uint     `json:"post_id"`
+	TargetID    *uint     `json:"target_id"`
+	ParentID    *uint     `json:"parent_id"`
+	Status      int       `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ExportPostLike 导出的文章点赞记录
+type ExportPostLike struct {
+	PostID    uint      `json:"post_id"`
+	PostTitle string    `json:"post_title"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ExportMomentLike 导出的说说点赞记录
+type ExportMomentLike struct {
+	MomentID  uint      `json:"moment_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ExportChatMessage 导出的聊天消息
+type ExportChatMessage struct {
+	ID        uint      `json:"id"`
+	Content   string    `json:"content"`
+	Target    string    `json:"target"`
+	Status    int       `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ExportDirectMessage 导出的私信（包括发出和收到的）
+type ExportDirectMessage struct {
+	ID         uint      `json:"id"`
+	SenderID   uint      `json:"sender_id"`
+	ReceiverID uint      `json:"receiver_id"`
+	Content    string    `json:"content"`
+	IsRead     bool      `json:"is_read"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ListComments 获取用户发表的全部评论
+func (r *UserDataRepository) ListComments(userID uint) ([]ExportComment, error) {
+	var comments []ExportComment
+	err := db.DB.Model(&model.Comment{}).
+		Select("id, content, comment_type, post_id, target_id, parent_id, status, created_at").
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Scan(&comments).Error
+	return comments, err
+}
+
+// ListPostLikes 获取用户点赞过的文章
+func (r *UserDataRepository) ListPostLikes(userID uint) ([]ExportPostLike, error) {
+	var likes []ExportPostLike
+	err := db.DB.Table("post_likes").
+		Select("post_likes.post_id, COALESCE(posts.title, '') AS post_title, post_likes.created_at").
+		Joins("LEFT JOIN posts ON posts.id = post_likes.post_id").
+		Where("post_likes.user_id = ?", userID).
+		Order("post_likes.created_at ASC").
+		Scan(&likes).Error
+	return likes, err
+}
+
+// ListMomentLikes 获取用户点赞过的说说
+func (r *UserDataRepository) ListMomentLikes(userID uint) ([]ExportMomentLike, error) {
+	var likes []ExportMomentLike
+	err := db.DB.Model(&model.MomentLike{}).
+		Select("moment_id, created_at").
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Scan(&likes).Error
+	return likes, err
+}
+
+// ListChatMessages 获取用户发送的聊天消息
+func (r *UserDataRepository) ListChatMessages(userID uint) ([]ExportChatMessage, error) {
+	var messages []ExportChatMessage
+	err := db.DB.Model(&model.ChatMessage{}).
+		Select("id, content, target, status, created_at").
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Scan(&messages).Error
+	return messages, err
+}
+
+// ListDirectMessages 获取用户发出和收到的私信
+func (r *UserDataRepository) ListDirectMessages(userID uint) ([]ExportDirectMessage, error) {
+	var messages []ExportDirectMessage
+	err := db.DB.Model(&model.DirectMessage{}).
+		Select("id, sender_id, receiver_id, content, is_read, created_at").
+		Where("sender_id = ? OR receiver_id = ?", userID, userID).
+		Order("created_at ASC").
+		Scan(&messages).Error
+	return messages, err
+}
+
+// ListEmailChanges 获取用户的邮箱修改记录
+func (r *UserDataRepository) ListEmailChanges(userID uint) ([]model.EmailChangeRecord, error) {
+	var records []model.EmailChangeRecord
+	err := db.DB.Where("user_id = ?", userID).Order("changed_at ASC").Find(&records).Error
+	return records, err
+}
+
+// CountAuthoredContent 统计用户发布的文章和说说数量
+// 文章随用户级联删除，删除账号前需要先转移或删除这些内容
+func (r *UserDataRepository) CountAuthoredContent(userID uint) (posts int64, moments int64, err error) {
+	if err = db.DB.Model(&model.Post{}).Where("user_id = ?", userID).Count(&posts).Error; err != nil {
+		return
+	}
+	err = db.DB.Model(&model.Moment{}).Where("user_id = ? AND status <> -1", userID).Count(&moments).Error
+	return
+}
+
+// PurgeUser 在同一事务中删除用户并清理所有关联记录
+// 处理方式：
+//   - 评论：他人对该用户评论的回复上移到被回复评论的父级，再删除该用户的评论
+//   - 点赞：扣减文章和说说的点赞数后删除点赞记录
+//   - 阅读记录、密码重置令牌：删除
+//   - 聊天消息：删除普通消息，系统广播保留内容但去除用户信息
+//   - 私信、屏蔽关系：随用户级联删除
+//   - 会话、两步验证、访问令牌、第三方绑定、通行密钥、登录历史等：随用户级联删除
+//   - 文章历史版本、友链申请审核人：随用户删除置空
+//
+// 返回:
+//   - bool: 用户是否存在并被删除
+func (r *UserDataRepository) PurgeUser(userID uint) (bool, error) {
+	return r.purgeUser(userID, nil)
+}
+
+// PurgeUserInStatus 仅当用户处于指定状态时删除用户并清理关联记录（如拒绝待审核用户）
+// 状态检查与删除在同一事务中并锁定用户行，与并发的审核通过等状态变更互斥
+// 返回:
+//   - bool: 用户是否存在、处于该状态并被删除
+func (r *UserDataRepository) PurgeUserInStatus(userID uint, status int) (bool, error) {
+	return r.purgeUser(userID, &status)
+}
+
+// purgeUser 删除用户并清理关联记录，status 不为空时要求用户处于该状态
+func (r *UserDataRepository) purgeUser(userID uint, status *int) (bool, error) {
+	deleted := false
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id, email, status").Where("id = ?", userID).First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		if status != nil && user.Status != *status {
+			return nil
+		}
+
+		// 逐层上移他人的回复，直到没有回复挂在该用户的评论下（该用户连续回复时需要多轮）
+		for {
+			result := tx.Exec(`
+				UPDATE comments c SET parent_id = p.parent_id
+				FROM comments p
+				WHERE c.parent_id = p.id AND p.user_id = ? AND c.user_id <> ?`, userID, userID)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				break
+			}
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&model.Comment{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`
+			UPDATE posts SET like_count = GREATEST(like_count - 1, 0)
+			WHERE id IN (SELECT post_id FROM post_likes WHERE user_id = ?)`, userID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&model.PostLike{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`
+			UPDATE moments SET like_count = GREATEST(like_count - 1, 0)
+			WHERE id IN (SELECT moment_id FROM moment_likes WHERE user_id = ?)`, userID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&model.MomentLike{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&model.PostView{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ? OR email = ?", userID, user.Email).Delete(&model.PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ? AND is_broadcast = ?", userID, false).Delete(&model.ChatMessage{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.ChatMessage{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+			"user_id":  nil,
+			"username": "已注销用户",
+			"avatar":   "",
+			"ip":       "",
+		}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Delete(&model.User{}, userID)
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected > 0
+		return nil
+	})
+	return deleted, err
+}
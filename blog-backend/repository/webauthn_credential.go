@@ -0,0 +1,75 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：webauthn_credential.go
+ * 创建时间：2026-10-18 04:06:15
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：通行密钥数据访问层，提供 WebAuthn 凭据的查询、注册、使用记录更新和删除
+ */
+package repository
+
+import (
+	"time"
+
+	"blog-backend/db"
+	"blog-backend/model"
+)
+
+// WebAuthnCredentialRepository 通行密钥数据访问层结构体
+type WebAuthnCredentialRepository struct{}
+
+// NewWebAuthnCredentialRepository 创建通行密钥数据访问层实例
+func NewWebAuthnCredentialRepository() *WebAuthnCredentialRepository {
+	return &WebAuthnCredentialRepository{}
+}
+
+// ListByUser 获取用户的全部通行密钥（按注册时间倒序）
+func (r *WebAuthnCredentialRepository) ListByUser(userID uint) ([]model.WebAuthnCredential, error) {
+	var credentials []model.WebAuthnCredential
+	err := db.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&credentials).Error
+	return credentials, err
+}
+
+// CountByUser 统计用户的通行密钥数量
+func (r *WebAuthnCredentialRepository) CountByUser(userID uint) (int64, error) {
+	var count int64
+	err := db.DB.Model(&model.WebAuthnCredential{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// GetByCredentialID 根据凭据ID获取通行密钥
+func (r *WebAuthnCredentialRepository) GetByCredentialID(credentialID string) (*model.WebAuthnCredential, error) {
+	var credential model.WebAuthnCredential
+	err := db.DB.Where("credential_id = ?", credentialID).First(&credential).Error
+	return &credential, err
+}
+
+// Create 保存新注册的通行密钥
+func (r *WebAuthnCredentialRepository) Create(credential *model.WebAuthnCredential) error {
+	return db.DB.Create(credential).Error
+}
+
+// UpdateUsage 登录成功后更新签名计数和最近使用信息
+// 只有签名计数未被其他请求更新时才写入，避免并发使用同一断言
+// 返回:
+//   - bool: 是否更新成功
+func (r *WebAuthnCredentialRepository) UpdateUsage(id uint, oldSignCount, newSignCount int64, ip string) (bool, error) {
+	result := db.DB.Model(&model.WebAuthnCredential{}).
+		Where("id = ? AND sign_count = ?", id, oldSignCount).
+		Updates(map[string]interface{}{
+			"sign_count":   newSignCount,
+			"last_used_at": time.Now(),
+			"last_used_ip": ip,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Delete 删除用户的通行密钥
+// 返回:
+//   - bool: 是否存在并删除了通行密钥
+func (r *WebAuthnCredentialRepository) Delete(userID, id uint) (bool, error) {
+	result := db.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&model.WebAuthnCredential{})
+	return result.RowsAffected > 0, result.Error
+}
@@ -51,11 +51,20 @@ func SetupRouter() *gin.Engine {
 
 	// 初始化所有业务处理器
 	authHandler := handler.NewAuthHandler()
+	sessionHandler := handler.NewSessionHandler()
+	twoFactorHandler := handler.NewTwoFactorHandler()
+	loginHistoryHandler := handler.NewLoginHistoryHandler()
+	roleHandler := handler.NewRoleHandler()
+	accessTokenHandler := handler.NewAccessTokenHandler()
+	oauthHandler := handler.NewOAuthHandler()
+	passkeyHandler := handler.NewPasskeyHandler()
+	accountHandler := handler.NewAccountHandler()
 	postHandler := handler.NewPostHandler()
 	categoryHandler := handler.NewCategoryHandler()
 	tagHandler := handler.NewTagHandler()
 	commentHandler := handler.NewCommentHandler()
 	userHandler := handler.NewUserHandler()
+	inviteCodeHandler := handler.NewInviteCodeHandler()
 	uploadHandler := handler.NewUploadHandler()
 	settingHandler := handler.NewSettingHandler()
 	dashboardHandler := handler.NewDashboardHandler()
@@ -64,6 +73,7 @@ func SetupRouter() *gin.Engine {
 	ipWhitelistHandler := handler.NewIPWhitelistHandler()
 	captchaHandler := handler.NewCaptchaHandler()
 	chatHandler := handler.NewChatHandler(chatHub)
+	directMessageHandler := handler.NewDirectMessageHandler(chatHub)
 	blogHandler := handler.NewBlogHandler()
 	announcementHandler := handler.NewAnnouncementHandler()
 	friendLinkHandler := handler.NewFriendLinkHandler()
@@ -71,49 +81,110 @@ func SetupRouter() *gin.Engine {
 	calendarHandler := handler.NewCalendarHandler()
 	albumHandler := handler.NewAlbumHandler()
 	operationLogHandler := handler.NewOperationLogHandler()
+	feedHandler := handler.NewFeedHandler()
+	sitemapHandler := handler.NewSitemapHandler()
+	friendCircleHandler := handler.NewFriendCircleHandler()
+	friendLinkApplicationHandler := handler.NewFriendLinkApplicationHandler()
 
 	// 健康检查接口（用于服务监控和负载均衡器健康检查）
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	// 订阅源（挂在根路径下，便于订阅器直接访问）
+	setupFeedRoutes(r, feedHandler)
+
+	// 站点地图和 robots.txt（供搜索引擎抓取）
+	setupSitemapRoutes(r, sitemapHandler)
+
 	// API 路由组（所有API接口都以 /api 为前缀）
 	api := r.Group("/api")
 	{
 		// 配置各个功能模块的路由
-		setupAuthRoutes(api, authHandler)                                                                                                                                                                                                 // 认证相关路由
-		setupCaptchaRoutes(api, captchaHandler)                                                                                                                                                                                           // 验证码路由
-		setupBlogRoutes(api, blogHandler, announcementHandler, friendLinkHandler, friendLinkCategoryHandler, albumHandler)                                                                                                                // 博客公开接口路由
-		setupCalendarRoutes(api, calendarHandler)                                                                                                                                                                                         // 日历路由
-		setupPostRoutes(api, postHandler)                                                                                                                                                                                                 // 文章路由
-		setupCategoryRoutes(api, categoryHandler)                                                                                                                                                                                         // 分类路由
-		setupTagRoutes(api, tagHandler)                                                                                                                                                                                                   // 标签路由
-		setupCommentRoutes(api, commentHandler)                                                                                                                                                                                           // 评论路由
-		setupUploadRoutes(api, uploadHandler)                                                                                                                                                                                             // 文件上传路由
-		setupSettingRoutes(api, settingHandler)                                                                                                                                                                                           // 系统设置路由
-		setupMomentRoutes(api, momentHandler)                                                                                                                                                                                             // 说说路由
-		setupChatRoutes(api, chatHandler)                                                                                                                                                                                                 // 聊天室路由
-		setupAdminRoutes(api, userHandler, postHandler, commentHandler, dashboardHandler, momentHandler, ipBlacklistHandler, ipWhitelistHandler, chatHandler, friendLinkHandler, friendLinkCategoryHandler, settingHandler, albumHandler, operationLogHandler) // 管理后台路由
+		setupAuthRoutes(api, authHandler, sessionHandler, twoFactorHandler, loginHistoryHandler, accessTokenHandler, oauthHandler, passkeyHandler, accountHandler)                                                                                                                                                                                                     // 认证相关路由
+		setupCaptchaRoutes(api, captchaHandler)                                                                                                                                                                                                                                                                                                                        // 验证码路由
+		setupBlogRoutes(api, blogHandler, announcementHandler, friendLinkHandler, friendLinkCategoryHandler, friendLinkApplicationHandler, friendCircleHandler, albumHandler)                                                                                                                                                                                          // 博客公开接口路由
+		setupCalendarRoutes(api, calendarHandler)                                                                                                                                                                                                                                                                                                                      // 日历路由
+		setupPostRoutes(api, postHandler)                                                                                                                                                                                                                                                                                                                              // 文章路由
+		setupCategoryRoutes(api, categoryHandler)                                                                                                                                                                                                                                                                                                                      // 分类路由
+		setupTagRoutes(api, tagHandler)                                                                                                                                                                                                                                                                                                                                // 标签路由
+		setupCommentRoutes(api, commentHandler)                                                                                                                                                                                                                                                                                                                        // 评论路由
+		setupUploadRoutes(api, uploadHandler)                                                                                                                                                                                                                                                                                                                          // 文件上传路由
+		setupSettingRoutes(api, settingHandler)                                                                                                                                                                                                                                                                                                                        // 系统设置路由
+		setupMomentRoutes(api, momentHandler)                                                                                                                                                                                                                                                                                                                          // 说说路由
+		setupChatRoutes(api, chatHandler, directMessageHandler)                                                                                                                                                                                                                                                                                                        // 聊天室路由
+		setupAdminRoutes(api, userHandler, postHandler, commentHandler, dashboardHandler, momentHandler, ipBlacklistHandler, ipWhitelistHandler, chatHandler, friendLinkHandler, friendLinkCategoryHandler, friendLinkApplicationHandler, friendCircleHandler, settingHandler, albumHandler, operationLogHandler, loginHistoryHandler, roleHandler, inviteCodeHandler) // 管理后台路由
 	}
 
 	return r
 }
 
+// setupFeedRoutes 配置订阅源路由
+// 功能说明：配置全站及按分类、标签的 RSS 2.0 / Atom / JSON Feed 订阅源
+// 参数:
+//   - r: Gin路由引擎
+//   - h: 订阅源处理器实例
+func setupFeedRoutes(r *gin.Engine, h *handler.FeedHandler) {
+	r.GET("/feed.xml", h.RSS)
+	r.GET("/atom.xml", h.Atom)
+	r.GET("/feed.json", h.JSONFeed)
+
+	// 分类订阅源
+	r.GET("/category/:category_id/feed.xml", h.RSS)
+	r.GET("/category/:category_id/atom.xml", h.Atom)
+	r.GET("/category/:category_id/feed.json", h.JSONFeed)
+
+	// 标签订阅源
+	r.GET("/tag/:tag_id/feed.xml", h.RSS)
+	r.GET("/tag/:tag_id/atom.xml", h.Atom)
+	r.GET("/tag/:tag_id/feed.json", h.JSONFeed)
+}
+
+// setupSitemapRoutes 配置站点地图路由
+// 功能说明：配置 sitemap.xml、站点地图分片和 robots.txt
+// 参数:
+//   - r: Gin路由引擎
+//   - h: 站点地图处理器实例
+func setupSitemapRoutes(r *gin.Engine, h *handler.SitemapHandler) {
+	r.GET("/sitemap.xml", h.Sitemap)
+	r.GET("/sitemaps/:name", h.SitemapPart) // pages.xml / posts-N.xml
+	r.GET("/robots.txt", h.Robots)
+}
+
 // setupAuthRoutes 配置认证相关路由
 // 功能说明：配置用户注册、登录、登出、密码重置、个人信息管理等路由
 // 参数:
 //   - api: API路由组
 //   - h: 认证处理器实例
-func setupAuthRoutes(api *gin.RouterGroup, h *handler.AuthHandler) {
+//   - sh: 登录会话处理器实例
+//   - tfh: 两步验证处理器实例
+//   - lh: 登录历史处理器实例
+//   - ath: 个人访问令牌处理器实例
+//   - oh: 第三方登录处理器实例
+//   - ph: 通行密钥处理器实例
+//   - ach: 账号自助管理处理器实例
+func setupAuthRoutes(api *gin.RouterGroup, h *handler.AuthHandler, sh *handler.SessionHandler, tfh *handler.TwoFactorHandler, lh *handler.LoginHistoryHandler, ath *handler.AccessTokenHandler, oh *handler.OAuthHandler, ph *handler.PasskeyHandler, ach *handler.AccountHandler) {
 	auth := api.Group("/auth")
 	{
 		auth.POST("/register", h.Register)
 		auth.POST("/send-register-code", h.SendRegisterCode) // 发送注册验证码
 		auth.POST("/login", h.Login)
+		auth.POST("/login/2fa", tfh.Login)               // 登录第二步：提交两步验证码
+		auth.POST("/magic-link", h.SendMagicLink)        // 发送免密登录链接
+		auth.POST("/magic-link/login", h.MagicLinkLogin) // 使用登录链接登录
+		auth.POST("/passkeys/login/begin", ph.BeginLogin)
+		auth.POST("/passkeys/login/finish", ph.FinishLogin)
 		auth.POST("/logout", h.Logout)
-		auth.POST("/refresh", h.RefreshToken)
+		auth.POST("/refresh", h.RefreshToken)           // 使用刷新令牌换取新令牌
 		auth.POST("/forgot-password", h.ForgotPassword) // 忘记密码 - 发送验证码
 		auth.POST("/reset-password", h.ResetPassword)   // 重置密码
+		auth.POST("/email/revert", h.RevertEmailChange) // 通过原邮箱收到的链接撤销邮箱修改
+
+		// 第三方登录（授权码 + state + PKCE）
+		auth.GET("/oauth/providers", oh.Providers)
+		auth.GET("/oauth/:provider/authorize", oh.Authorize) // 获取授权页地址
+		auth.GET("/oauth/:provider/callback", oh.Callback)   // 提供方授权回调，处理后跳转到前端回调页
+		auth.POST("/oauth/exchange", oh.Exchange)            // 使用一次性票据换取登录令牌
 
 		// 需要认证的接口
 		authRequired := auth.Group("")
@@ -122,8 +193,47 @@ func setupAuthRoutes(api *gin.RouterGroup, h *handler.AuthHandler) {
 			authRequired.GET("/profile", h.GetProfile)
 			authRequired.PUT("/profile", h.UpdateProfile)
 			authRequired.PUT("/password", h.UpdatePassword)
-			authRequired.PUT("/email", h.UpdateEmail)                    // 修改邮箱
+			authRequired.POST("/email/send-code", h.SendEmailChangeCode) // 修改邮箱：向新邮箱发送验证码
+			authRequired.PUT("/email", h.UpdateEmail)                    // 修改邮箱：提交验证码完成修改
 			authRequired.GET("/email-change-info", h.GetEmailChangeInfo) // 获取邮箱修改信息
+
+			// 登录会话（设备）管理
+			authRequired.GET("/sessions", sh.List)
+			authRequired.DELETE("/sessions/:id", sh.Revoke)
+			authRequired.DELETE("/sessions", sh.RevokeAll)
+
+			// 本人的登录记录
+			authRequired.GET("/login-history", lh.ListMine)
+
+			// 个人访问令牌（令牌本身不能用于管理令牌）
+			authRequired.GET("/tokens", ath.List)
+			authRequired.GET("/tokens/scopes", ath.Scopes)
+			authRequired.POST("/tokens", ath.Create)
+			authRequired.DELETE("/tokens/:id", ath.Revoke)
+
+			// 第三方账号绑定
+			authRequired.GET("/oauth/bindings", oh.ListBindings)
+			authRequired.POST("/oauth/bindings/:provider", oh.Bind) // 获取绑定用的授权页地址
+			authRequired.DELETE("/oauth/bindings/:provider", oh.Unbind)
+
+			// 通行密钥管理
+			authRequired.GET("/passkeys", ph.List)
+			authRequired.POST("/passkeys/register/begin", ph.BeginRegistration)
+			authRequired.POST("/passkeys/register/finish", ph.FinishRegistration)
+			authRequired.DELETE("/passkeys/:id", ph.Delete)
+
+			// 个人数据导出与账号注销
+			authRequired.GET("/account/export", ach.Export)
+			authRequired.GET("/account/deletion", ach.GetDeletion)
+			authRequired.POST("/account/deletion", ach.RequestDeletion) // 申请注销（需验证密码，冷静期满后删除）
+			authRequired.DELETE("/account/deletion", ach.CancelDeletion)
+
+			// 两步验证（TOTP）
+			authRequired.GET("/2fa", tfh.Status)
+			authRequired.POST("/2fa/setup", tfh.Setup)
+			authRequired.POST("/2fa/enable", tfh.Enable)
+			authRequired.POST("/2fa/disable", tfh.Disable)
+			authRequired.POST("/2fa/recovery-codes", tfh.RegenerateRecoveryCodes)
 		}
 	}
 }
@@ -148,8 +258,10 @@ func setupCaptchaRoutes(api *gin.RouterGroup, h *handler.CaptchaHandler) {
 //   - a: 公告处理器实例
 //   - fl: 友链处理器实例
 //   - flc: 友链分类处理器实例
+//   - fla: 友链申请处理器实例
+//   - fc: 朋友圈处理器实例
 //   - al: 相册处理器实例
-func setupBlogRoutes(api *gin.RouterGroup, h *handler.BlogHandler, a *handler.AnnouncementHandler, fl *handler.FriendLinkHandler, flc *handler.FriendLinkCategoryHandler, al *handler.AlbumHandler) {
+func setupBlogRoutes(api *gin.RouterGroup, h *handler.BlogHandler, a *handler.AnnouncementHandler, fl *handler.FriendLinkHandler, flc *handler.FriendLinkCategoryHandler, fla *handler.FriendLinkApplicationHandler, fc *handler.FriendCircleHandler, al *handler.AlbumHandler) {
 	blog := api.Group("/blog")
 	{
 		// 获取博主资料和统计数据
@@ -165,7 +277,10 @@ func setupBlogRoutes(api *gin.RouterGroup, h *handler.BlogHandler, a *handler.An
 		blog.GET("/announcements/:id", a.GetAnnouncementDetail)
 		// 友链（公开接口）
 		blog.GET("/friend-links", fl.ListPublic)
-		blog.GET("/friend-link-categories", flc.List) // 公开获取分类列表
+		blog.GET("/friend-link-categories", flc.List)     // 公开获取分类列表
+		blog.POST("/friend-link-applications", fla.Apply) // 提交友链申请（需要图形验证码）
+		// 朋友圈（友链订阅聚合，公开接口）
+		blog.GET("/friend-circle", fc.Timeline)
 		// 相册（公开接口）
 		blog.GET("/albums", al.ListPublic)
 	}
@@ -201,9 +316,9 @@ func setupPostRoutes(api *gin.RouterGroup, h *handler.PostHandler) {
 		posts.GET("/recent", h.GetRecentPosts)
 		posts.POST("/:id/like", h.Like)
 
-		// 需要认证的接口
+		// 需要认证的接口（个人访问令牌需具备 posts:write 权限范围）
 		postsAuth := posts.Group("")
-		postsAuth.Use(middleware.AuthMiddleware())
+		postsAuth.Use(middleware.AuthMiddleware(constant.ScopePostsWrite))
 		{
 			postsAuth.POST("", h.Create)
 			postsAuth.PUT("/:id", h.Update)
@@ -226,7 +341,7 @@ func setupCategoryRoutes(api *gin.RouterGroup, h *handler.CategoryHandler) {
 
 		// 需要管理员权限的接口
 		categoriesAdmin := categories.Group("")
-		categoriesAdmin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+		categoriesAdmin.Use(middleware.AuthMiddleware(), middleware.RequirePermission(constant.PermCategoryManage))
 		{
 			categoriesAdmin.POST("", h.Create)
 			categoriesAdmin.PUT("/:id", h.Update)
@@ -289,7 +404,8 @@ func setupCommentRoutes(api *gin.RouterGroup, h *handler.CommentHandler) {
 //   - h: 文件上传处理器实例
 func setupUploadRoutes(api *gin.RouterGroup, h *handler.UploadHandler) {
 	upload := api.Group("/upload")
-	upload.Use(middleware.AuthMiddleware())
+	// 个人访问令牌需具备 upload 权限范围
+	upload.Use(middleware.AuthMiddleware(constant.ScopeUpload))
 	{
 		upload.POST("/avatar", h.UploadAvatar)
 		upload.POST("/image", h.UploadImage)
@@ -308,20 +424,30 @@ func setupSettingRoutes(api *gin.RouterGroup, h *handler.SettingHandler) {
 		settings.GET("/public", h.GetPublicSettings)
 		settings.GET("/friendlink-info", h.GetFriendLinkInfo)
 
-		// 需要超级管理员（super_admin）权限：系统级配置
+		// 系统级配置（需要 settings.manage 权限）
 		settingsAdmin := settings.Group("")
-		settingsAdmin.Use(middleware.AuthMiddleware(), middleware.RoleRequiredMiddleware(constant.RoleSuperAdmin))
+		settingsAdmin.Use(middleware.AuthMiddleware(), middleware.RequirePermission(constant.PermSettingsManage))
 		{
 			settingsAdmin.GET("/site", h.GetSiteSettings)
 			settingsAdmin.PUT("/site", h.UpdateSiteSettings)
-			settingsAdmin.GET("/upload", h.GetUploadSettings)
-			settingsAdmin.PUT("/upload", h.UpdateUploadSettings)
 			settingsAdmin.GET("/notification", h.GetNotificationSettings)
 			settingsAdmin.PUT("/notification", h.UpdateNotificationSettings)
 			settingsAdmin.GET("/register", h.GetRegisterSettings)
 			settingsAdmin.PUT("/register", h.UpdateRegisterSettings)
-			settingsAdmin.PUT("/friendlink-info", h.UpdateFriendLinkInfo)
+			settingsAdmin.GET("/security", h.GetSecuritySettings)
+			settingsAdmin.PUT("/security", h.UpdateSecuritySettings)
 		}
+
+		// 上传存储配置（需要 settings.upload 权限）
+		uploadSettings := settings.Group("/upload")
+		uploadSettings.Use(middleware.AuthMiddleware(), middleware.RequirePermission(constant.PermSettingsUpload))
+		{
+			uploadSettings.GET("", h.GetUploadSettings)
+			uploadSettings.PUT("", h.UpdateUploadSettings)
+		}
+
+		// 友链页展示信息（需要 friendlink.manage 权限）
+		settings.PUT("/friendlink-info", middleware.AuthMiddleware(), middleware.RequirePermission(constant.PermFriendLinkManage), h.UpdateFriendLinkInfo)
 	}
 }
 
@@ -339,9 +465,9 @@ func setupMomentRoutes(api *gin.RouterGroup, h *handler.MomentHandler) {
 		moments.GET("/recent", h.GetRecent)
 		moments.POST("/:id/like", h.Like)
 
-		// 需要认证的接口
+		// 需要认证的接口（个人访问令牌需具备 moments:write 权限范围）
 		momentsAuth := moments.Group("")
-		momentsAuth.Use(middleware.AuthMiddleware())
+		momentsAuth.Use(middleware.AuthMiddleware(constant.ScopeMomentsWrite))
 		{
 			momentsAuth.POST("", h.Create)
 			momentsAuth.PUT("/:id", h.Update)
@@ -351,26 +477,44 @@ func setupMomentRoutes(api *gin.RouterGroup, h *handler.MomentHandler) {
 }
 
 // setupChatRoutes 配置聊天室路由
-// 功能说明：配置WebSocket连接、消息查询、在线信息等路由，支持认证和匿名访问
+// 功能说明：配置WebSocket连接、消息查询、在线信息等路由，支持认证和匿名访问；私信接口需要登录
 // 参数:
 //   - api: API路由组
 //   - h: 聊天室处理器实例
-func setupChatRoutes(api *gin.RouterGroup, h *handler.ChatHandler) {
+//   - dm: 私信处理器实例
+func setupChatRoutes(api *gin.RouterGroup, h *handler.ChatHandler, dm *handler.DirectMessageHandler) {
 	chat := api.Group("/chat")
 	{
 		// WebSocket连接（支持认证和匿名，使用可选认证中间件）
 		chat.GET("/ws", middleware.OptionalAuthMiddleware(), h.HandleWebSocket)
 
-		// 公开接口
-		chat.GET("/messages", h.GetMessages)
-		chat.GET("/online", h.GetOnlineInfo)
+		// 公开接口（可选认证，用于识别可访问管理员专用聊天室的用户）
+		chat.GET("/messages", middleware.OptionalAuthMiddleware(), h.GetMessages)
+		chat.GET("/online", middleware.OptionalAuthMiddleware(), h.GetOnlineInfo)
 		chat.GET("/settings", h.GetChatSettings)
+		chat.GET("/rooms", middleware.OptionalAuthMiddleware(), h.ListRooms)
+		chat.GET("/rooms/post/:postId", h.GetPostRoom) // 文章讨论室
+
+		// 私信（需要登录，对方在线时通过WebSocket实时推送）
+		direct := chat.Group("/direct")
+		direct.Use(middleware.AuthMiddleware())
+		{
+			direct.GET("/contacts", dm.Contacts) // 可直接联系的站长
+			direct.GET("/conversations", dm.Conversations)
+			direct.GET("/unread", dm.UnreadCount)
+			direct.GET("/conversations/:userId/messages", dm.Messages)
+			direct.POST("/conversations/:userId/messages", dm.Send)
+			direct.PUT("/conversations/:userId/read", dm.MarkRead)
+			direct.GET("/blocks", dm.BlockedUsers)
+			direct.POST("/blocks/:userId", dm.Block)
+			direct.DELETE("/blocks/:userId", dm.Unblock)
+		}
 	}
 }
 
 // setupAdminRoutes 配置管理后台路由
 // 功能说明：配置所有管理后台功能的路由，包括仪表盘、用户管理、文章管理、评论管理、IP管理、聊天室管理等
-// 所有接口都需要管理员权限（AuthMiddleware + AdminMiddleware）
+// 所有接口都需要进入管理后台的权限，各功能再按所需权限（RequirePermission）分组校验
 // 参数:
 //   - api: API路由组
 //   - userHandler: 用户处理器实例
@@ -383,97 +527,173 @@ func setupChatRoutes(api *gin.RouterGroup, h *handler.ChatHandler) {
 //   - chatHandler: 聊天室处理器实例
 //   - friendLinkHandler: 友链处理器实例
 //   - friendLinkCategoryHandler: 友链分类处理器实例
+//   - friendLinkApplicationHandler: 友链申请处理器实例
+//   - friendCircleHandler: 朋友圈处理器实例
 //   - settingHandler: 系统设置处理器实例
 //   - albumHandler: 相册处理器实例
 //   - operationLogHandler: 操作日志处理器实例
-func setupAdminRoutes(api *gin.RouterGroup, userHandler *handler.UserHandler, postHandler *handler.PostHandler, commentHandler *handler.CommentHandler, dashboardHandler *handler.DashboardHandler, momentHandler *handler.MomentHandler, ipBlacklistHandler *handler.IPBlacklistHandler, ipWhitelistHandler *handler.IPWhitelistHandler, chatHandler *handler.ChatHandler, friendLinkHandler *handler.FriendLinkHandler, friendLinkCategoryHandler *handler.FriendLinkCategoryHandler, settingHandler *handler.SettingHandler, albumHandler *handler.AlbumHandler, operationLogHandler *handler.OperationLogHandler) {
+//   - loginHistoryHandler: 登录历史处理器实例
+//   - roleHandler: 角色处理器实例
+//   - inviteCodeHandler: 注册邀请码处理器实例
+func setupAdminRoutes(api *gin.RouterGroup, userHandler *handler.UserHandler, postHandler *handler.PostHandler, commentHandler *handler.CommentHandler, dashboardHandler *handler.DashboardHandler, momentHandler *handler.MomentHandler, ipBlacklistHandler *handler.IPBlacklistHandler, ipWhitelistHandler *handler.IPWhitelistHandler, chatHandler *handler.ChatHandler, friendLinkHandler *handler.FriendLinkHandler, friendLinkCategoryHandler *handler.FriendLinkCategoryHandler, friendLinkApplicationHandler *handler.FriendLinkApplicationHandler, friendCircleHandler *handler.FriendCircleHandler, settingHandler *handler.SettingHandler, albumHandler *handler.AlbumHandler, operationLogHandler *handler.OperationLogHandler, loginHistoryHandler *handler.LoginHistoryHandler, roleHandler *handler.RoleHandler, inviteCodeHandler *handler.InviteCodeHandler) {
 	admin := api.Group("/admin")
-	// admin 路由基础权限：admin 或 super_admin
-	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	// admin 路由基础权限：进入管理后台（admin.access），具体功能再按权限逐组校验
+	admin.Use(middleware.AuthMiddleware(), middleware.RequirePermission(constant.PermAdminAccess))
 	{
 		// 仪表盘
 		admin.GET("/dashboard/stats", dashboardHandler.GetStats)
 		admin.GET("/dashboard/category-stats", dashboardHandler.GetCategoryStats)
 		admin.GET("/dashboard/visit-stats", dashboardHandler.GetVisitStats)
 
-		// super_admin 专属路由组：系统级高危操作（用户管理/关于我/友链/相册等）
-		super := admin.Group("")
-		super.Use(middleware.RoleRequiredMiddleware(constant.RoleSuperAdmin))
+		// 用户管理
+		users := admin.Group("/users")
+		users.Use(middleware.RequirePermission(constant.PermUserManage))
+		{
+			users.GET("", userHandler.List)
+			users.GET("/:id", userHandler.GetByID)
+			users.PUT("/:id/status", userHandler.UpdateStatus)
+			users.PUT("/:id/role", userHandler.UpdateRole) // 更新用户角色
+			users.DELETE("/:id", userHandler.Delete)
+			users.POST("/:id/unlock", userHandler.Unlock)   // 解除账号登录锁定
+			users.POST("/:id/approve", userHandler.Approve) // 注册审核通过
+			users.POST("/:id/reject", userHandler.Reject)   // 注册审核未通过（删除账号）
+		}
+
+		// 注册邀请码管理
+		inviteCodes := admin.Group("/invite-codes")
+		inviteCodes.Use(middleware.RequirePermission(constant.PermUserManage))
 		{
-			// 用户管理（仅超级管理员）
-			super.GET("/users", userHandler.List)
-			super.GET("/users/:id", userHandler.GetByID)
-			super.PUT("/users/:id/status", userHandler.UpdateStatus)
-			super.PUT("/users/:id/role", userHandler.UpdateRole) // 更新用户角色
-			super.DELETE("/users/:id", userHandler.Delete)
-
-			// 关于我信息管理（仅超级管理员）
-			super.GET("/about", settingHandler.GetAboutInfo)
-			super.PUT("/about", settingHandler.UpdateAboutInfo)
-
-			// 友链管理（仅超级管理员）
-			super.GET("/friend-links", friendLinkHandler.List)
-			super.GET("/friend-links/:id", friendLinkHandler.GetByID)
-			super.POST("/friend-links", friendLinkHandler.Create)
-			super.PUT("/friend-links/:id", friendLinkHandler.Update)
-			super.DELETE("/friend-links/:id", friendLinkHandler.Delete)
-
-			// 友链分类管理（仅超级管理员）
-			super.GET("/friend-link-categories", friendLinkCategoryHandler.List)
-			super.GET("/friend-link-categories/:id", friendLinkCategoryHandler.GetByID)
-			super.POST("/friend-link-categories", friendLinkCategoryHandler.Create)
-			super.PUT("/friend-link-categories/:id", friendLinkCategoryHandler.Update)
-			super.DELETE("/friend-link-categories/:id", friendLinkCategoryHandler.Delete)
-
-			// 相册管理（仅超级管理员）
-			super.GET("/albums", albumHandler.List)
-			super.GET("/albums/:id", albumHandler.GetByID)
-			super.POST("/albums", albumHandler.Create)
-			super.PUT("/albums/:id", albumHandler.Update)
-			super.DELETE("/albums/:id", albumHandler.Delete)
-
-			// 注册设置管理（仅超级管理员）
-			super.GET("/settings/register", settingHandler.GetRegisterSettings)
-			super.PUT("/settings/register", settingHandler.UpdateRegisterSettings)
+			inviteCodes.GET("", inviteCodeHandler.List)
+			inviteCodes.POST("", inviteCodeHandler.Create)
+			inviteCodes.DELETE("/:id", inviteCodeHandler.Delete)
+		}
+
+		// 角色和权限管理
+		roles := admin.Group("")
+		roles.Use(middleware.RequirePermission(constant.PermRoleManage))
+		{
+			roles.GET("/permissions", roleHandler.Permissions)
+			roles.GET("/roles", roleHandler.List)
+			roles.POST("/roles", roleHandler.Create)
+			roles.PUT("/roles/:id", roleHandler.Update)
+			roles.DELETE("/roles/:id", roleHandler.Delete)
+		}
+
+		// 网站设置：关于我、注册设置、安全设置
+		siteSettings := admin.Group("")
+		siteSettings.Use(middleware.RequirePermission(constant.PermSettingsManage))
+		{
+			siteSettings.GET("/about", settingHandler.GetAboutInfo)
+			siteSettings.PUT("/about", settingHandler.UpdateAboutInfo)
+			siteSettings.GET("/settings/register", settingHandler.GetRegisterSettings)
+			siteSettings.PUT("/settings/register", settingHandler.UpdateRegisterSettings)
+			siteSettings.GET("/settings/security", settingHandler.GetSecuritySettings)
+			siteSettings.PUT("/settings/security", settingHandler.UpdateSecuritySettings)
+		}
+
+		// 友链管理：友链、友链分类、友链申请审核、朋友圈订阅
+		friendLinks := admin.Group("")
+		friendLinks.Use(middleware.RequirePermission(constant.PermFriendLinkManage))
+		{
+			friendLinks.GET("/friend-links", friendLinkHandler.List)
+			friendLinks.POST("/friend-links/health-check", friendLinkHandler.CheckHealth)
+			friendLinks.GET("/friend-links/:id", friendLinkHandler.GetByID)
+			friendLinks.POST("/friend-links", friendLinkHandler.Create)
+			friendLinks.PUT("/friend-links/:id", friendLinkHandler.Update)
+			friendLinks.DELETE("/friend-links/:id", friendLinkHandler.Delete)
+
+			friendLinks.GET("/friend-link-categories", friendLinkCategoryHandler.List)
+			friendLinks.GET("/friend-link-categories/:id", friendLinkCategoryHandler.GetByID)
+			friendLinks.POST("/friend-link-categories", friendLinkCategoryHandler.Create)
+			friendLinks.PUT("/friend-link-categories/:id", friendLinkCategoryHandler.Update)
+			friendLinks.DELETE("/friend-link-categories/:id", friendLinkCategoryHandler.Delete)
+
+			friendLinks.GET("/friend-link-applications", friendLinkApplicationHandler.List)
+			friendLinks.POST("/friend-link-applications/:id/approve", friendLinkApplicationHandler.Approve)
+			friendLinks.POST("/friend-link-applications/:id/reject", friendLinkApplicationHandler.Reject)
+			friendLinks.DELETE("/friend-link-applications/:id", friendLinkApplicationHandler.Delete)
+
+			friendLinks.GET("/friend-circle/feeds", friendCircleHandler.ListFeeds)
+			friendLinks.POST("/friend-circle/refresh", friendCircleHandler.Refresh)
+		}
+
+		// 相册管理
+		albums := admin.Group("/albums")
+		albums.Use(middleware.RequirePermission(constant.PermAlbumManage))
+		{
+			albums.GET("", albumHandler.List)
+			albums.GET("/:id", albumHandler.GetByID)
+			albums.POST("", albumHandler.Create)
+			albums.PUT("/:id", albumHandler.Update)
+			albums.DELETE("/:id", albumHandler.Delete)
 		}
 
 		// 文章管理
-		admin.GET("/posts", postHandler.List)
-		admin.GET("/posts/:id/export", postHandler.Export)
+		posts := admin.Group("/posts")
+		posts.Use(middleware.RequirePermission(constant.PermPostManage))
+		{
+			posts.GET("", postHandler.List)
+			posts.POST("/import", postHandler.Import) // 导入 Markdown（单文件或 zip）
+			posts.GET("/:id/export", postHandler.Export)
+			posts.GET("/:id/revisions", postHandler.ListRevisions)                         // 修订历史列表
+			posts.GET("/:id/revisions/diff", postHandler.DiffRevisions)                    // 修订版本差异对比
+			posts.GET("/:id/revisions/:revision_id", postHandler.GetRevision)              // 修订版本详情
+			posts.POST("/:id/revisions/:revision_id/restore", postHandler.RestoreRevision) // 恢复修订版本
+		}
 
 		// 评论管理
-		admin.GET("/comments", commentHandler.List)
-		admin.PUT("/comments/:id/status", commentHandler.UpdateStatus)
+		comments := admin.Group("/comments")
+		comments.Use(middleware.RequirePermission(constant.PermCommentModerate))
+		{
+			comments.GET("", commentHandler.List)
+			comments.PUT("/:id/status", commentHandler.UpdateStatus)
+		}
 
 		// 说说管理
-		admin.GET("/moments", momentHandler.AdminList)
-
-		// IP黑名单管理
-		admin.GET("/ip-blacklist", ipBlacklistHandler.List)
-		admin.POST("/ip-blacklist", ipBlacklistHandler.Add)
-		admin.DELETE("/ip-blacklist/:id", ipBlacklistHandler.Delete)
-		admin.GET("/ip-blacklist/check", ipBlacklistHandler.Check)
-		admin.POST("/ip-blacklist/clean-expired", ipBlacklistHandler.CleanExpired)
-
-		// IP白名单管理
-		admin.GET("/ip-whitelist", ipWhitelistHandler.List)
-		admin.POST("/ip-whitelist", ipWhitelistHandler.Add)
-		admin.DELETE("/ip-whitelist/:id", ipWhitelistHandler.Delete)
-		admin.GET("/ip-whitelist/check", ipWhitelistHandler.Check)
-		admin.POST("/ip-whitelist/clean-expired", ipWhitelistHandler.CleanExpired)
+		admin.GET("/moments", middleware.RequirePermission(constant.PermMomentManage), momentHandler.AdminList)
+
+		// IP黑白名单管理
+		ipLists := admin.Group("")
+		ipLists.Use(middleware.RequirePermission(constant.PermIPManage))
+		{
+			ipLists.GET("/ip-blacklist", ipBlacklistHandler.List)
+			ipLists.POST("/ip-blacklist", ipBlacklistHandler.Add)
+			ipLists.DELETE("/ip-blacklist/:id", ipBlacklistHandler.Delete)
+			ipLists.GET("/ip-blacklist/check", ipBlacklistHandler.Check)
+			ipLists.POST("/ip-blacklist/clean-expired", ipBlacklistHandler.CleanExpired)
+
+			ipLists.GET("/ip-whitelist", ipWhitelistHandler.List)
+			ipLists.POST("/ip-whitelist", ipWhitelistHandler.Add)
+			ipLists.DELETE("/ip-whitelist/:id", ipWhitelistHandler.Delete)
+			ipLists.GET("/ip-whitelist/check", ipWhitelistHandler.Check)
+			ipLists.POST("/ip-whitelist/clean-expired", ipWhitelistHandler.CleanExpired)
+		}
 
 		// 聊天室管理
-		admin.GET("/chat/messages", chatHandler.AdminListMessages)
-		admin.DELETE("/chat/messages/:id", chatHandler.DeleteMessage)
-		admin.POST("/chat/broadcast", chatHandler.BroadcastSystemMessage)
-		admin.POST("/chat/kick", chatHandler.KickUser) // 踢出用户
-		admin.POST("/chat/ban", chatHandler.BanIP)     // 封禁IP
-		admin.GET("/chat/settings", chatHandler.GetChatSettings)
-		admin.PUT("/chat/settings", chatHandler.UpdateChatSettings)
-
-		// 操作日志管理（仅超级管理员）
+		chat := admin.Group("/chat")
+		chat.Use(middleware.RequirePermission(constant.PermChatManage))
+		{
+			chat.GET("/messages", chatHandler.AdminListMessages)
+			chat.DELETE("/messages/:id", chatHandler.DeleteMessage)
+			chat.POST("/messages/:id/recall", chatHandler.RecallMessage) // 撤回消息
+			chat.POST("/broadcast", chatHandler.BroadcastSystemMessage)
+			chat.POST("/kick", chatHandler.KickUser) // 踢出用户
+			chat.POST("/ban", chatHandler.BanIP)     // 封禁IP
+			chat.GET("/mutes", chatHandler.ListMutes)
+			chat.POST("/mutes", chatHandler.MuteUser)               // 定时禁言
+			chat.DELETE("/mutes/:identity", chatHandler.UnmuteUser) // 解除禁言
+			chat.GET("/settings", chatHandler.AdminGetChatSettings)
+			chat.PUT("/settings", chatHandler.UpdateChatSettings)
+			chat.GET("/rooms", chatHandler.AdminListRooms)
+			chat.POST("/rooms", chatHandler.CreateRoom)
+			chat.PUT("/rooms/:id/lock", chatHandler.LockRoom)       // 锁定/解锁
+			chat.PUT("/rooms/:id/archive", chatHandler.ArchiveRoom) // 归档/恢复
+		}
+
+		// 登录历史和操作日志
+		admin.GET("/login-history", middleware.RequirePermission(constant.PermLogView), loginHistoryHandler.List)
 		operationLogs := admin.Group("/operation-logs")
-		operationLogs.Use(middleware.RoleRequiredMiddleware(constant.RoleSuperAdmin))
+		operationLogs.Use(middleware.RequirePermission(constant.PermLogView))
 		{
 			operationLogs.GET("", operationLogHandler.List)
 			operationLogs.GET("/:id", operationLogHandler.GetByID)
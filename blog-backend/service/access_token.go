@@ -0,0 +1,157 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：access_token.go
+ * 创建时间：2026-10-18 01:34:16
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：个人访问令牌业务逻辑层，提供带权限范围和有效期的令牌创建、列表和吊销功能，
+ *          令牌用于脚本和CI在不经过验证码登录的情况下调用接口
+ */
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"blog-backend/constant"
+	"blog-backend/logger"
+	"blog-backend/model"
+	"blog-backend/repository"
+	"blog-backend/util"
+)
+
+// maxAccessTokensPerUser 每个用户最多持有的有效令牌数
+const maxAccessTokensPerUser = 20
+
+// ErrAccessTokenNotFound 个人访问令牌不存在
+var ErrAccessTokenNotFound = errors.New("令牌不存在或已吊销")
+
+// AccessTokenService 个人访问令牌业务逻辑层结构体
+type AccessTokenService struct {
+	repo *repository.AccessTokenRepository
+}
+
+// NewAccessTokenService 创建个人访问令牌业务逻辑层实例
+func NewAccessTokenService() *AccessTokenService {
+	return &AccessTokenService{
+		repo: repository.NewAccessTokenRepository(),
+	}
+}
+
+// CreateAccessTokenRequest 创建个人访问令牌请求
+type CreateAccessTokenRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"required,min=1,max=365"` // 有效期（天）
+}
+
+// AccessTokenInfo 个人访问令牌列表项
+type AccessTokenInfo struct {
+	model.PersonalAccessToken
+	Scopes []string `json:"scopes"`
+}
+
+// CreatedAccessToken 新创建的个人访问令牌（明文令牌只返回这一次）
+type CreatedAccessToken struct {
+	AccessTokenInfo
+	Token string `json:"token"`
+}
+
+// ScopeOption 可授予的权限范围
+type ScopeOption struct {
+	Scope       string `json:"scope"`
+	Description string `json:"description"`
+}
+
+// ListScopes 获取所有可授予的权限范围
+func (s *AccessTokenService) ListScopes() []ScopeOption {
+	options := make([]ScopeOption, 0, len(constant.TokenScopes))
+	for scope, description := range constant.TokenScopes {
+		options = append(options, ScopeOption{Scope: scope, Description: description})
+	}
+	sort.Slice(options, func(i, j int) bool { return options[i].Scope < options[j].Scope })
+	return options
+}
+
+// List 获取用户的有效令牌
+func (s *AccessTokenService) List(userID uint) ([]AccessTokenInfo, error) {
+	tokens, err := s.repo.ListActiveByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]AccessTokenInfo, 0, len(tokens))
+	for _, token := range tokens {
+		list = append(list, AccessTokenInfo{PersonalAccessToken: token, Scopes: token.ScopeList()})
+	}
+	return list, nil
+}
+
+// Create 创建个人访问令牌
+// 返回:
+//   - *CreatedAccessToken: 令牌信息及明文令牌（数据库只保存哈希，之后无法再次查看）
+//   - error: 名称为空、权限范围无效或超出数量限制时返回错误
+func (s *AccessTokenService) Create(userID uint, req *CreateAccessTokenRequest) (*CreatedAccessToken, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("令牌名称不能为空")
+	}
+
+	scopes := make([]string, 0, len(req.Scopes))
+	seen := make(map[string]bool)
+	for _, scope := range req.Scopes {
+		scope = strings.TrimSpace(scope)
+		if !constant.IsValidScope(scope) {
+			return nil, fmt.Errorf("无效的权限范围: %s", scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	sort.Strings(scopes)
+
+	count, err := s.repo.CountActiveByUser(userID)
+	if err != nil {
+		return nil, errors.New("创建令牌失败")
+	}
+	if count >= maxAccessTokensPerUser {
+		return nil, fmt.Errorf("最多只能持有 %d 个有效令牌，请先吊销不再使用的令牌", maxAccessTokensPerUser)
+	}
+
+	raw := util.GeneratePersonalAccessToken()
+	token := &model.PersonalAccessToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: util.HashPersonalAccessToken(raw),
+		TokenHint: util.PersonalAccessTokenPrefix + "..." + raw[len(raw)-4:],
+		Scopes:    strings.Join(scopes, ","),
+		ExpiresAt: time.Now().AddDate(0, 0, req.ExpiresInDays),
+	}
+	if err := s.repo.Create(token); err != nil {
+		return nil, errors.New("创建令牌失败")
+	}
+
+	logger.Info(fmt.Sprintf("用户 %d 创建了个人访问令牌 %d（%s）", userID, token.ID, token.Scopes))
+	return &CreatedAccessToken{
+		AccessTokenInfo: AccessTokenInfo{PersonalAccessToken: *token, Scopes: scopes},
+		Token:           raw,
+	}, nil
+}
+
+// Revoke 吊销用户的指定令牌
+func (s *AccessTokenService) Revoke(userID, id uint) error {
+	revoked, err := s.repo.Revoke(userID, id)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrAccessTokenNotFound
+	}
+	return nil
+}
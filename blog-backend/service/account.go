@@ -0,0 +1,270 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：account.go
+ * 创建时间：2026-10-18 05:03:18
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：账号自助管理业务逻辑层，提供个人数据导出、申请/撤销注销，
+ *          以及冷静期满后删除账号并清理个人数据的定时任务
+ */
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"blog-backend/config"
+	"blog-backend/constant"
+	"blog-backend/db"
+	"blog-backend/logger"
+	"blog-backend/model"
+	"blog-backend/repository"
+	"blog-backend/util"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// accountDeletionLockKey 注销任务分布式锁键，避免多个实例同时处理
+	accountDeletionLockKey = "account:deletion:lock"
+	// accountDeletionBatchSize 每轮最多删除的账号数量
+	accountDeletionBatchSize = 50
+	// accountDeletionRetryDelay 暂不能执行的注销申请推迟多久后再检查，避免其长期占据每轮的处理名额
+	accountDeletionRetryDelay = 24 * time.Hour
+)
+
+// ErrAccountDeletionNotFound 当前账号没有注销申请
+var ErrAccountDeletionNotFound = errors.New("没有待处理的注销申请")
+
+// AccountService 账号自助管理业务逻辑层结构体
+type AccountService struct {
+	repo        *repository.AccountDeletionRepository
+	dataRepo    *repository.UserDataRepository
+	userRepo    *repository.UserRepository
+	settingRepo *repository.SettingRepository
+}
+
+// NewAccountService 创建账号自助管理业务逻辑层实例
+func NewAccountService() *AccountService {
+	return &AccountService{
+		repo:        repository.NewAccountDeletionRepository(),
+		dataRepo:    repository.NewUserDataRepository(),
+		userRepo:    repository.NewUserRepository(),
+		settingRepo: repository.NewSettingRepository(),
+	}
+}
+
+// RequestDeletionRequest 申请注销账号请求
+type RequestDeletionRequest struct {
+	Password string `json:"password" binding:"required"`
+	Reason   string `json:"reason" binding:"max=255"`
+}
+
+// AccountExport 个人数据导出内容
+type AccountExport struct {
+	ExportedAt     time.Time                        `json:"exported_at"`
+	Profile        *model.User                      `json:"profile"`
+	Comments       []repository.ExportComment       `json:"comments"`
+	PostLikes      []repository.ExportPostLike      `json:"post_likes"`
+	MomentLikes    []repository.ExportMomentLike    `json:"moment_likes"`
+	ChatMessages   []repository.ExportChatMessage   `json:"chat_messages"`
+	DirectMessages []repository.ExportDirectMessage `json:"direct_messages"`
+	EmailChanges   []model.EmailChangeRecord        `json:"email_changes"`
+}
+
+// Export 导出用户的个人数据
+func (s *AccountService) Export(userID uint) (*AccountExport, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	export := &AccountExport{ExportedAt: time.Now(), Profile: user}
+	if export.Comments, err = s.dataRepo.ListComments(userID); err != nil {
+		return nil, errors.New("导出评论失败")
+	}
+	if export.PostLikes, err = s.dataRepo.ListPostLikes(userID); err != nil {
+		return nil, errors.New("导出文章点赞失败")
+	}
+	if export.MomentLikes, err = s.dataRepo.ListMomentLikes(userID); err != nil {
+		return nil, errors.New("导出说说点赞失败")
+	}
+	if export.ChatMessages, err = s.dataRepo.ListChatMessages(userID); err != nil {
+		return nil, errors.New("导出聊天消息失败")
+	}
+	if export.DirectMessages, err = s.dataRepo.ListDirectMessages(userID); err != nil {
+		return nil, errors.New("导出私信失败")
+	}
+	if export.EmailChanges, err = s.dataRepo.ListEmailChanges(userID); err != nil {
+		return nil, errors.New("导出邮箱修改记录失败")
+	}
+
+	logger.Info(fmt.Sprintf("用户 %d 导出了个人数据", userID))
+	return export, nil
+}
+
+// GetDeletionStatus 获取当前账号的注销申请
+func (s *AccountService) GetDeletionStatus(userID uint) (*model.AccountDeletionRequest, error) {
+	request, err := s.repo.Get(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAccountDeletionNotFound
+		}
+		return nil, errors.New("获取注销申请失败")
+	}
+	return request, nil
+}
+
+// RequestDeletion 申请注销账号（需验证密码），冷静期满后删除账号
+// 重复申请会重新计算冷静期
+func (s *AccountService) RequestDeletion(userID uint, req *RequestDeletionRequest) (*model.AccountDeletionRequest, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	if !util.CheckPassword(req.Password, user.Password) {
+		return nil, errors.New("密码错误")
+	}
+	if err := s.checkDeletable(user); err != nil {
+		return nil, err
+	}
+
+	graceDays := deletionGraceDays()
+	now := time.Now()
+	request := &model.AccountDeletionRequest{
+		UserID:      userID,
+		Reason:      req.Reason,
+		RequestedAt: now,
+		ScheduledAt: now.AddDate(0, 0, graceDays),
+	}
+	if err := s.repo.Save(request); err != nil {
+		return nil, errors.New("申请注销失败")
+	}
+
+	cancelURL := loadSiteInfo(s.settingRepo)["site_url"] + "/profile"
+	scheduledAt := request.ScheduledAt.Format("2006-01-02 15:04")
+	sendEmailAsync(s.settingRepo, "注销申请通知邮件", user.Email, func(cfg util.EmailConfig) error {
+		return util.SendAccountDeletionEmail(cfg, user.Email, user.Username, scheduledAt, cancelURL, graceDays)
+	})
+
+	logger.Info(fmt.Sprintf("用户 %d 申请注销账号，计划于 %s 删除", userID, request.ScheduledAt.Format("2006-01-02 15:04:05")))
+	return request, nil
+}
+
+// CancelDeletion 撤销注销申请
+func (s *AccountService) CancelDeletion(userID uint) error {
+	deleted, err := s.repo.Delete(userID)
+	if err != nil {
+		return errors.New("撤销注销失败")
+	}
+	if !deleted {
+		return ErrAccountDeletionNotFound
+	}
+
+	logger.Info(fmt.Sprintf("用户 %d 撤销了注销申请", userID))
+	return nil
+}
+
+// StartScheduler 启动注销执行任务（每10分钟检查一次）
+func (s *AccountService) StartScheduler() {
+	go s.purgePeriodically(10 * time.Minute)
+}
+
+// purgePeriodically 定期删除冷静期已满的账号
+func (s *AccountService) purgePeriodically(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.runOnce(interval)
+
+	for range ticker.C {
+		s.runOnce(interval)
+	}
+}
+
+// runOnce 获取分布式锁后执行一轮删除
+func (s *AccountService) runOnce(interval time.Duration) {
+	ctx := context.Background()
+	token := uuid.NewString()
+
+	ok, err := db.RDB.SetNX(ctx, accountDeletionLockKey, token, interval-5*time.Second).Result()
+	if err != nil || !ok {
+		return
+	}
+	defer db.RDB.Eval(ctx, releaseLockScript, []string{accountDeletionLockKey}, token)
+
+	requests, err := s.repo.ListDue(time.Now(), accountDeletionBatchSize)
+	if err != nil {
+		logger.Error(fmt.Sprintf("查询到期注销申请失败: %v", err))
+		return
+	}
+
+	for _, request := range requests {
+		user, err := s.userRepo.GetByID(request.UserID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 账号已被管理员删除，申请随之失效
+			_, _ = s.repo.Delete(request.UserID)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		// 申请后成为超级管理员或发布了新内容时暂不删除，保留申请等待用户处理，
+		// 并推迟下次检查时间，使后面到期的申请不会被卡住
+		if err := s.checkDeletable(user); err != nil {
+			logger.Info(fmt.Sprintf("用户 %d 的注销申请暂不执行: %s", user.ID, err.Error()))
+			if err := s.repo.Postpone(user.ID, time.Now().Add(accountDeletionRetryDelay)); err != nil {
+				logger.Error(fmt.Sprintf("推迟用户 %d 的注销申请失败: %v", user.ID, err))
+			}
+			continue
+		}
+		if err := PurgeUser(user.ID); err != nil {
+			logger.Error(fmt.Sprintf("删除用户 %d 失败: %v", user.ID, err))
+			continue
+		}
+		logger.Info(fmt.Sprintf("用户 %d（%s）注销冷静期已满，账号及个人数据已删除", user.ID, user.Username))
+	}
+}
+
+// checkDeletable 检查账号是否可以删除
+// 超级管理员不能删除；文章随用户级联删除，发布过文章或说说的账号需要先处理这些内容
+func (s *AccountService) checkDeletable(user *model.User) error {
+	if user.Role == constant.RoleSuperAdmin {
+		return errors.New("超级管理员账号不能注销")
+	}
+	return checkNoAuthoredContent(s.dataRepo, user.ID)
+}
+
+// checkNoAuthoredContent 检查用户是否还有未处理的文章或说说
+func checkNoAuthoredContent(dataRepo *repository.UserDataRepository, userID uint) error {
+	posts, moments, err := dataRepo.CountAuthoredContent(userID)
+	if err != nil {
+		return errors.New("检查账号内容失败")
+	}
+	if posts > 0 || moments > 0 {
+		return fmt.Errorf("账号下还有 %d 篇文章和 %d 条说说，请先删除或转移这些内容", posts, moments)
+	}
+	return nil
+}
+
+// PurgeUser 删除用户并清理所有关联的个人数据，随后吊销该用户已签发的Token
+func PurgeUser(userID uint) error {
+	if _, err := repository.NewUserDataRepository().PurgeUser(userID); err != nil {
+		return err
+	}
+	_ = util.RevokeUserTokens(userID)
+	return nil
+}
+
+// deletionGraceDays 获取注销冷静期天数，未配置时默认7天
+func deletionGraceDays() int {
+	if days := config.Cfg.Account.DeletionGraceDays; days > 0 {
+		return days
+	}
+	return 7
+}
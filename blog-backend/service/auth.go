@@ -12,13 +12,17 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"blog-backend/config"
 	"blog-backend/constant"
 	"blog-backend/db"
+	"blog-backend/logger"
 	"blog-backend/model"
 	"blog-backend/repository"
 	"blog-backend/util"
@@ -26,30 +30,50 @@ import (
 	"gorm.io/gorm"
 )
 
+// emailChangeRevertDays 邮箱修改撤销链接的有效天数
+const emailChangeRevertDays = 7
+
+// 注册方式（站点设置 register_mode）
+const (
+	registerModeSettingKey = "register_mode"
+	RegisterModeOpen       = "open"     // 开放注册
+	RegisterModeInvite     = "invite"   // 仅限邀请码注册
+	RegisterModeApproval   = "approval" // 注册后需管理员审核才能登录
+)
+
 // AuthService 认证业务逻辑层结构体
 type AuthService struct {
-	userRepo        *repository.UserRepository
-	resetTokenRepo  *repository.PasswordResetRepository
-	emailChangeRepo *repository.EmailChangeRepository
-	settingRepo     *repository.SettingRepository
+	userRepo            *repository.UserRepository
+	inviteCodeRepo      *repository.InviteCodeRepository
+	resetTokenRepo      *repository.PasswordResetRepository
+	emailChangeRepo     *repository.EmailChangeRepository
+	settingRepo         *repository.SettingRepository
+	sessionService      *SessionService
+	twoFactorService    *TwoFactorService
+	loginHistoryService *LoginHistoryService
 }
 
 // NewAuthService 创建认证业务逻辑层实例
 func NewAuthService() *AuthService {
 	return &AuthService{
-		userRepo:        repository.NewUserRepository(),
-		resetTokenRepo:  repository.NewPasswordResetRepository(),
-		emailChangeRepo: repository.NewEmailChangeRepository(),
-		settingRepo:     repository.NewSettingRepository(),
+		userRepo:            repository.NewUserRepository(),
+		inviteCodeRepo:      repository.NewInviteCodeRepository(),
+		resetTokenRepo:      repository.NewPasswordResetRepository(),
+		emailChangeRepo:     repository.NewEmailChangeRepository(),
+		settingRepo:         repository.NewSettingRepository(),
+		sessionService:      NewSessionService(),
+		twoFactorService:    NewTwoFactorService(),
+		loginHistoryService: NewLoginHistoryService(),
 	}
 }
 
 // RegisterRequest 注册请求
 type RegisterRequest struct {
-	Username string `json:"username" binding:"required"`
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
-	Code     string `json:"code" binding:"required,len=6"`
+	Username   string `json:"username" binding:"required"`
+	Email      string `json:"email" binding:"required,email"`
+	Password   string `json:"password" binding:"required,min=6"`
+	Code       string `json:"code" binding:"required,len=6"`
+	InviteCode string `json:"invite_code"` // 邀请码（站点仅限邀请注册时必填）
 }
 
 // LoginRequest 登录请求
@@ -60,21 +84,32 @@ type LoginRequest struct {
 	Captcha   string `json:"captcha" binding:"required"`
 }
 
-// LoginResponse 登录响应
+// LoginResponse 登录响应（访问令牌、刷新令牌和用户信息）
+// 已启用两步验证时只返回挑战令牌，需调用 /auth/login/2fa 提交验证码后才签发令牌
 type LoginResponse struct {
-	Token string      `json:"token"`
-	User  *model.User `json:"user"`
+	*TokenPair
+	User                   *model.User `json:"user,omitempty"`
+	TwoFactorRequired      bool        `json:"two_factor_required,omitempty"`       // 是否需要进行两步验证
+	ChallengeToken         string      `json:"challenge_token,omitempty"`           // 两步验证挑战令牌（5分钟内有效）
+	TwoFactorSetupRequired bool        `json:"two_factor_setup_required,omitempty"` // 站点强制启用两步验证但当前管理员尚未启用
 }
 
 // Register 用户注册
+// 站点仅限邀请注册时需要有效的邀请码；需要审核时账号创建为待审核状态，审核通过前不能登录
 func (s *AuthService) Register(req *RegisterRequest, ip string) (*model.User, error) {
 	// 检查注册功能是否被禁用
 	if isRegisterDisabled, err := s.isRegisterDisabled(); err == nil && isRegisterDisabled {
 		return nil, errors.New("用户注册功能已关闭")
 	}
 
+	mode := loadRegisterMode(s.settingRepo)
+	inviteCode := strings.ToUpper(strings.TrimSpace(req.InviteCode))
+	if mode == RegisterModeInvite && inviteCode == "" {
+		return nil, errors.New("本站仅限邀请注册，请填写邀请码")
+	}
+
 	// 验证邮箱验证码
-	resetToken, err := s.resetTokenRepo.GetValidToken(req.Email, req.Code)
+	resetToken, err := s.resetTokenRepo.GetValidToken(req.Email, req.Code, model.TokenPurposeRegister)
 	if err != nil {
 		return nil, errors.New("验证码无效或已过期")
 	}
@@ -121,10 +156,22 @@ func (s *AuthService) Register(req *RegisterRequest, ip string) (*model.User, er
 		Password: hashedPassword,
 		Nickname: req.Username,
 		Role:     constant.RoleUser,
-		Status:   1,
+		Status:   model.UserStatusActive,
+	}
+	if mode == RegisterModeApproval {
+		user.Status = model.UserStatusPending
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
+	if mode == RegisterModeInvite {
+		// 扣减邀请码次数与创建用户在同一事务中完成
+		valid, err := s.inviteCodeRepo.CreateUserWithCode(user, inviteCode)
+		if err != nil {
+			return nil, errors.New("用户创建失败")
+		}
+		if !valid {
+			return nil, errors.New("邀请码无效、已过期或已用完")
+		}
+	} else if err := s.userRepo.Create(user); err != nil {
 		return nil, errors.New("用户创建失败")
 	}
 
@@ -135,8 +182,8 @@ func (s *AuthService) Register(req *RegisterRequest, ip string) (*model.User, er
 	return user, nil
 }
 
-// Login 用户登录
-func (s *AuthService) Login(req *LoginRequest, ip string) (*LoginResponse, error) {
+// Login 用户登录，成功后创建登录会话
+func (s *AuthService) Login(req *LoginRequest, ip, userAgent string) (*LoginResponse, error) {
 	// 验证验证码
 	if err := util.VerifyCaptcha(req.CaptchaID, req.Captcha, ip); err != nil {
 		return nil, err
@@ -146,33 +193,170 @@ func (s *AuthService) Login(req *LoginRequest, ip string) (*LoginResponse, error
 	user, err := s.userRepo.GetByUsername(req.Username)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.loginHistoryService.Record(nil, req.Username, model.LoginFailPassword, ip, userAgent)
 			return nil, errors.New("用户名或密码错误")
 		}
 		return nil, errors.New("登录失败")
 	}
 
+	// 账号因多次登录失败被锁定时不再校验密码
+	if remaining := checkLoginLock(user.ID); remaining > 0 {
+		s.loginHistoryService.Record(user, req.Username, model.LoginFailLocked, ip, userAgent)
+		return nil, loginLockError(remaining)
+	}
+
 	// 检查用户状态
-	if user.Status != 1 {
-		return nil, errors.New("账号已被禁用")
+	if err := s.checkUserStatus(user, req.Username, ip, userAgent); err != nil {
+		return nil, err
 	}
 
-	// 验证密码
+	// 验证密码（失败次数按账号累计，与请求来自哪个IP无关）
 	if !util.CheckPassword(req.Password, user.Password) {
+		s.loginHistoryService.Record(user, req.Username, model.LoginFailPassword, ip, userAgent)
+		if lockFor := recordLoginFailure(user.ID); lockFor > 0 {
+			return nil, loginLockError(lockFor)
+		}
 		return nil, errors.New("用户名或密码错误")
 	}
 
-	// 生成 Token
-	token, err := util.GenerateToken(user.ID, user.Username, user.Role)
+	return s.completeLogin(user, req.Username, ip, userAgent)
+}
+
+// completeLogin 身份验证通过后完成登录：已启用两步验证时签发挑战令牌，否则创建会话并签发令牌
+// 密码登录和第三方登录共用此流程，保证两步验证、登录记录等行为一致
+// 参数:
+//   - user: 已通过身份验证且状态正常的用户
+//   - username: 写入登录记录的用户名
+func (s *AuthService) completeLogin(user *model.User, username, ip, userAgent string) (*LoginResponse, error) {
+	// 已启用两步验证：签发挑战令牌，验证码通过后再创建会话
+	twoFactorEnabled, err := s.twoFactorService.IsEnabled(user.ID)
+	if err != nil {
+		return nil, errors.New("登录失败")
+	}
+	if twoFactorEnabled {
+		challenge, err := s.twoFactorService.CreateChallenge(user.ID)
+		if err != nil {
+			return nil, errors.New("登录失败")
+		}
+		return &LoginResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+		}, nil
+	}
+
+	// 创建会话并签发令牌
+	tokens, err := s.sessionService.CreateSession(user, ip, userAgent)
 	if err != nil {
 		return nil, errors.New("Token 生成失败")
 	}
+	clearLoginFailures(user.ID)
+	s.loginHistoryService.Record(user, username, "", ip, userAgent)
+	user.Permissions = NewRoleService().GetPermissions(user.Role)
 
 	return &LoginResponse{
-		Token: token,
-		User:  user,
+		TokenPair:              tokens,
+		User:                   user,
+		TwoFactorSetupRequired: s.twoFactorService.IsRequired(user.Role),
 	}, nil
 }
 
+// magicLinkExpireMinutes 免密登录链接有效期（分钟）
+const magicLinkExpireMinutes = 15
+
+// SendMagicLink 向用户邮箱发送免密登录链接
+// 邮箱未注册或账号已被禁用时同样返回成功，避免被用来探测邮箱是否注册
+func (s *AuthService) SendMagicLink(req *SendMagicLinkRequest) error {
+	user, err := s.userRepo.GetByEmail(req.Email)
+	if err != nil || user.Status != 1 {
+		return nil
+	}
+
+	// 检查发送频率限制（1分钟内只能发送一次，与其他验证码共用）
+	if recent, err := s.resetTokenRepo.GetRecentByEmail(req.Email, 1*60*1000000000); err == nil && recent != nil {
+		remainingTime := 60 - int(time.Since(recent.CreatedAt).Seconds())
+		if remainingTime > 0 {
+			return errors.New(fmt.Sprintf("发送过于频繁，请%d秒后再试", remainingTime))
+		}
+	}
+
+	// 链接令牌只保存哈希；验证码字段不会发送给用户，登录只能通过链接完成
+	token := util.GenerateRandomString(48)
+	resetToken := &model.PasswordResetToken{
+		UserID:   &user.ID,
+		Email:    req.Email,
+		Token:    hashEmailRevertToken(token),
+		Code:     util.GenerateVerificationCode(),
+		Purpose:  model.TokenPurposeMagicLogin,
+		ExpireAt: util.GetTimeAfterMinutes(magicLinkExpireMinutes),
+		IsUsed:   false,
+	}
+	if err := s.resetTokenRepo.Create(resetToken); err != nil {
+		return errors.New("系统错误，请稍后重试")
+	}
+
+	loginURL := loadSiteInfo(s.settingRepo)["site_url"] + "/auth/magic-login?token=" + token
+
+	sendEmailAsync(s.settingRepo, "免密登录邮件", req.Email, func(cfg util.EmailConfig) error {
+		return util.SendMagicLinkEmail(cfg, req.Email, user.Username, loginURL, magicLinkExpireMinutes)
+	})
+
+	return nil
+}
+
+// MagicLinkLogin 使用邮件中的链接令牌登录，令牌只能使用一次
+// 已启用两步验证时与密码登录一样返回挑战令牌
+func (s *AuthService) MagicLinkLogin(req *MagicLinkLoginRequest, ip, userAgent string) (*LoginResponse, error) {
+	resetToken, err := s.resetTokenRepo.GetValidByToken(hashEmailRevertToken(req.Token), model.TokenPurposeMagicLogin)
+	if err != nil || resetToken.UserID == nil {
+		return nil, errors.New("登录链接无效或已过期，请重新获取")
+	}
+	if used, err := s.resetTokenRepo.MarkUsed(resetToken.ID); err != nil || !used {
+		return nil, errors.New("登录链接无效或已过期，请重新获取")
+	}
+
+	user, err := s.userRepo.GetByID(*resetToken.UserID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	// 链接发出后修改了邮箱的，旧邮箱收到的链接作废
+	if user.Email != resetToken.Email {
+		return nil, errors.New("登录链接无效或已过期，请重新获取")
+	}
+	if remaining := checkLoginLock(user.ID); remaining > 0 {
+		s.loginHistoryService.Record(user, user.Username, model.LoginFailLocked, ip, userAgent)
+		return nil, loginLockError(remaining)
+	}
+	if err := s.checkUserStatus(user, user.Username, ip, userAgent); err != nil {
+		return nil, err
+	}
+
+	return s.completeLogin(user, user.Username, ip, userAgent)
+}
+
+// Logout 登出，吊销当前Token及其所在的登录会话
+// 参数:
+//   - token: 当前使用的Token（无效或已过期的Token直接忽略）
+//
+// 返回:
+//   - error: 写入吊销记录失败时返回错误
+func (s *AuthService) Logout(token string) error {
+	claims, err := util.ParseToken(token)
+	if err != nil {
+		return nil
+	}
+	return s.sessionService.Logout(claims)
+}
+
+// RefreshTokenRequest 刷新令牌请求
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// RefreshToken 使用刷新令牌换取新的令牌对
+func (s *AuthService) RefreshToken(req *RefreshTokenRequest, ip, userAgent string) (*TokenPair, error) {
+	return s.sessionService.Refresh(req.RefreshToken, ip, userAgent)
+}
+
 // GetProfile 获取用户信息
 func (s *AuthService) GetProfile(userID uint) (*model.User, error) {
 	user, err := s.userRepo.GetByID(userID)
@@ -182,6 +366,7 @@ func (s *AuthService) GetProfile(userID uint) (*model.User, error) {
 		}
 		return nil, errors.New("获取用户信息失败")
 	}
+	user.Permissions = NewRoleService().GetPermissions(user.Role)
 	return user, nil
 }
 
@@ -257,6 +442,9 @@ func (s *AuthService) UpdatePassword(userID uint, req *UpdatePasswordRequest) er
 		return errors.New("密码修改失败")
 	}
 
+	// 修改密码后，吊销所有登录会话和此前签发的Token（需要重新登录）
+	revokeAllUserSessions(user.ID, SessionRevokePassword)
+
 	return nil
 }
 
@@ -265,6 +453,16 @@ type ForgotPasswordRequest struct {
 	Email string `json:"email" binding:"required,email"`
 }
 
+// SendMagicLinkRequest 发送免密登录链接请求
+type SendMagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// MagicLinkLoginRequest 免密登录链接登录请求
+type MagicLinkLoginRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
 // ResetPasswordRequest 重置密码请求
 type ResetPasswordRequest struct {
 	Email       string `json:"email" binding:"required,email"`
@@ -300,6 +498,7 @@ func (s *AuthService) ForgotPassword(req *ForgotPasswordRequest, ip string) erro
 		Email:    req.Email,
 		Token:    token,
 		Code:     code,
+		Purpose:  model.TokenPurposeResetPassword,
 		ExpireAt: util.GetTimeAfterMinutes(15), // 15分钟有效期
 		IsUsed:   false,
 	}
@@ -335,7 +534,7 @@ func (s *AuthService) ForgotPassword(req *ForgotPasswordRequest, ip string) erro
 // ResetPassword 重置密码
 func (s *AuthService) ResetPassword(req *ResetPasswordRequest) error {
 	// 查找有效的重置令牌
-	resetToken, err := s.resetTokenRepo.GetValidToken(req.Email, req.Code)
+	resetToken, err := s.resetTokenRepo.GetValidToken(req.Email, req.Code, model.TokenPurposeResetPassword)
 	if err != nil {
 		return errors.New("验证码无效或已过期")
 	}
@@ -375,67 +574,171 @@ func (s *AuthService) ResetPassword(req *ResetPasswordRequest) error {
 	resetToken.IsUsed = true
 	s.resetTokenRepo.Update(resetToken)
 
+	// 重置密码后，吊销所有登录会话和此前签发的Token
+	revokeAllUserSessions(user.ID, SessionRevokePassword)
+
 	return nil
 }
 
-// UpdateEmailRequest 修改邮箱请求
+// SendEmailChangeCodeRequest 发送修改邮箱验证码请求
+type SendEmailChangeCodeRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email"`
+}
+
+// UpdateEmailRequest 修改邮箱请求（需提供发送到新邮箱的验证码）
 type UpdateEmailRequest struct {
 	NewEmail string `json:"new_email" binding:"required,email"`
+	Code     string `json:"code" binding:"required,len=6"`
+}
+
+// RevertEmailChangeRequest 撤销邮箱修改请求
+type RevertEmailChangeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// SendEmailChangeCode 修改邮箱第一步：向新邮箱发送验证码
+func (s *AuthService) SendEmailChangeCode(userID uint, req *SendEmailChangeCodeRequest) error {
+	user, err := s.checkEmailChange(userID, req.NewEmail)
+	if err != nil {
+		return err
+	}
+
+	// 检查发送频率限制（1分钟内只能发送一次）
+	if recent, err := s.resetTokenRepo.GetRecentByEmail(req.NewEmail, 1*60*1000000000); err == nil && recent != nil {
+		remainingTime := 60 - int(time.Since(recent.CreatedAt).Seconds())
+		if remainingTime > 0 {
+			return errors.New(fmt.Sprintf("验证码发送过于频繁，请%d秒后再试", remainingTime))
+		}
+	}
+
+	code := util.GenerateVerificationCode()
+	resetToken := &model.PasswordResetToken{
+		UserID:   &user.ID,
+		Email:    req.NewEmail,
+		Token:    util.GenerateRandomString(32),
+		Code:     code,
+		Purpose:  model.TokenPurposeChangeEmail,
+		ExpireAt: util.GetTimeAfterMinutes(15), // 15分钟有效期
+		IsUsed:   false,
+	}
+	if err := s.resetTokenRepo.Create(resetToken); err != nil {
+		return errors.New("系统错误，请稍后重试")
+	}
+
+	sendEmailAsync(s.settingRepo, "修改邮箱验证码邮件", req.NewEmail, func(cfg util.EmailConfig) error {
+		return util.SendEmailChangeCodeEmail(cfg, req.NewEmail, user.Username, code)
+	})
+
+	return nil
 }
 
-// UpdateEmail 修改邮箱
+// UpdateEmail 修改邮箱第二步：校验新邮箱收到的验证码后修改邮箱，并通知原邮箱（附带撤销链接）
 func (s *AuthService) UpdateEmail(userID uint, req *UpdateEmailRequest) error {
-	// 获取用户信息
+	user, err := s.checkEmailChange(userID, req.NewEmail)
+	if err != nil {
+		return err
+	}
+
+	// 验证码必须是发给该用户的修改邮箱验证码
+	resetToken, err := s.resetTokenRepo.GetValidToken(req.NewEmail, req.Code, model.TokenPurposeChangeEmail)
+	if err != nil || resetToken.UserID == nil || *resetToken.UserID != userID {
+		return errors.New("验证码无效或已过期")
+	}
+
+	revertToken := util.GenerateRandomString(48)
+	record := &model.EmailChangeRecord{
+		UserID:      userID,
+		OldEmail:    user.Email,
+		NewEmail:    req.NewEmail,
+		RevertToken: hashEmailRevertToken(revertToken),
+		ChangedAt:   time.Now(),
+	}
+	changed, err := s.emailChangeRepo.ApplyChange(record)
+	if err != nil {
+		return errors.New("邮箱修改失败")
+	}
+	if !changed {
+		return errors.New("邮箱已被修改，请刷新后重试")
+	}
+
+	// 标记验证码为已使用
+	resetToken.IsUsed = true
+	s.resetTokenRepo.Update(resetToken)
+
+	// 通知原邮箱，附带"这不是我"撤销链接
+	revertURL := loadSiteInfo(s.settingRepo)["site_url"] + "/auth/revert-email?token=" + revertToken
+	changedAt := record.ChangedAt.Format("2006-01-02 15:04:05")
+	sendEmailAsync(s.settingRepo, "邮箱修改通知邮件", record.OldEmail, func(cfg util.EmailConfig) error {
+		return util.SendEmailChangedNoticeEmail(cfg, record.OldEmail, user.Username, req.NewEmail, changedAt, revertURL, emailChangeRevertDays)
+	})
+
+	return nil
+}
+
+// RevertEmailChange 通过原邮箱收到的撤销链接恢复原邮箱，并吊销该用户的所有登录会话
+func (s *AuthService) RevertEmailChange(req *RevertEmailChangeRequest) error {
+	record, err := s.emailChangeRepo.GetByRevertToken(hashEmailRevertToken(req.Token))
+	if err != nil {
+		return errors.New("撤销链接无效")
+	}
+	if record.RevertedAt != nil {
+		return errors.New("该邮箱修改已撤销")
+	}
+	if time.Since(record.ChangedAt) > emailChangeRevertDays*24*time.Hour {
+		return errors.New("撤销链接已过期，请联系管理员")
+	}
+	if other, err := s.userRepo.GetByEmail(record.OldEmail); err == nil && other.ID != record.UserID {
+		return errors.New("原邮箱已被其他用户使用，请联系管理员")
+	}
+
+	reverted, err := s.emailChangeRepo.Revert(record)
+	if err != nil {
+		return errors.New("撤销失败，请稍后重试")
+	}
+	if !reverted {
+		return errors.New("账号邮箱已再次修改，无法撤销，请联系管理员")
+	}
+
+	logger.Info(fmt.Sprintf("用户 %d 通过撤销链接恢复了原邮箱 %s", record.UserID, record.OldEmail))
+
+	// 邮箱可能被他人修改，退出所有登录设备
+	revokeAllUserSessions(record.UserID, SessionRevokeAccount)
+
+	return nil
+}
+
+// checkEmailChange 检查用户能否将邮箱修改为 newEmail
+func (s *AuthService) checkEmailChange(userID uint, newEmail string) (*model.User, error) {
 	user, err := s.userRepo.GetByID(userID)
 	if err != nil {
-		return errors.New("用户不存在")
+		return nil, errors.New("用户不存在")
 	}
 
 	// 验证新邮箱格式
-	if !util.ValidateEmail(req.NewEmail) {
-		return errors.New("邮箱格式不正确")
+	if !util.ValidateEmail(newEmail) {
+		return nil, errors.New("邮箱格式不正确")
 	}
 
 	// 检查新邮箱是否与当前邮箱相同
-	if user.Email == req.NewEmail {
-		return errors.New("新邮箱与当前邮箱相同")
+	if user.Email == newEmail {
+		return nil, errors.New("新邮箱与当前邮箱相同")
 	}
 
 	// 检查新邮箱是否已被使用
-	if _, err := s.userRepo.GetByEmail(req.NewEmail); err == nil {
-		return errors.New("该邮箱已被其他用户使用")
+	if _, err := s.userRepo.GetByEmail(newEmail); err == nil {
+		return nil, errors.New("该邮箱已被其他用户使用")
 	}
 
 	// 检查一年内的修改次数
 	count, err := s.emailChangeRepo.CountByUserIDInYear(userID)
 	if err != nil {
-		return errors.New("系统错误，请稍后重试")
+		return nil, errors.New("系统错误，请稍后重试")
 	}
 	if count >= 2 {
-		return errors.New("一年内只能修改两次邮箱，您已达到上限")
-	}
-
-	// 记录旧邮箱
-	oldEmail := user.Email
-
-	// 更新邮箱
-	user.Email = req.NewEmail
-	if err := s.userRepo.Update(user); err != nil {
-		return errors.New("邮箱修改失败")
+		return nil, errors.New("一年内只能修改两次邮箱，您已达到上限")
 	}
 
-	// 记录修改历史
-	record := &model.EmailChangeRecord{
-		UserID:   userID,
-		OldEmail: oldEmail,
-		NewEmail: req.NewEmail,
-	}
-	if err := s.emailChangeRepo.Create(record); err != nil {
-		// 记录失败不影响主流程，只记录日志
-		println("邮箱修改记录创建失败:", err.Error())
-	}
-
-	return nil
+	return user, nil
 }
 
 // GetEmailChangeCount 获取用户一年内的邮箱修改次数
@@ -483,6 +786,7 @@ func (s *AuthService) SendRegisterCode(req *SendRegisterCodeRequest, ip string)
 		Email:    req.Email,
 		Token:    token,
 		Code:     code,
+		Purpose:  model.TokenPurposeRegister,
 		ExpireAt: util.GetTimeAfterMinutes(15), // 15分钟有效期
 		IsUsed:   false,
 	}
@@ -531,6 +835,40 @@ func (s *AuthService) getSiteName() string {
 	return ""
 }
 
+// hashEmailRevertToken 计算邮件链接令牌（邮箱修改撤销、免密登录）的 SHA-256 哈希
+func hashEmailRevertToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// checkUserStatus 检查账号状态是否允许登录，不允许时写入登录记录并返回错误
+func (s *AuthService) checkUserStatus(user *model.User, username, ip, userAgent string) error {
+	switch user.Status {
+	case model.UserStatusActive:
+		return nil
+	case model.UserStatusPending:
+		s.loginHistoryService.Record(user, username, model.LoginFailPending, ip, userAgent)
+		return errors.New("账号正在等待管理员审核，审核通过后会邮件通知您")
+	default:
+		s.loginHistoryService.Record(user, username, model.LoginFailDisabled, ip, userAgent)
+		return errors.New("账号已被禁用")
+	}
+}
+
+// loadRegisterMode 获取站点的注册方式，未配置或配置无效时为开放注册
+func loadRegisterMode(repo *repository.SettingRepository) string {
+	setting, err := repo.GetByKey(registerModeSettingKey)
+	if err != nil {
+		return RegisterModeOpen
+	}
+	switch setting.Value {
+	case RegisterModeInvite, RegisterModeApproval:
+		return setting.Value
+	default:
+		return RegisterModeOpen
+	}
+}
+
 // isRegisterDisabled 检查注册功能是否被禁用
 func (s *AuthService) isRegisterDisabled() (bool, error) {
 	setting, err := s.settingRepo.GetByKey("disable_register")
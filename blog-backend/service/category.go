@@ -123,6 +123,10 @@ func (s *CategoryService) Update(id uint, req *UpdateCategoryRequest) (*model.Ca
 		ctx := context.Background()
 		db.RDB.Del(ctx, "category:list")
 		db.RDB.Del(ctx, "blog:author_profile")
+
+		// 订阅源和站点地图中包含分类名称和地址
+		clearFeedCaches()
+		clearSitemapCaches()
 	}()
 
 	return category, nil
@@ -149,6 +153,10 @@ func (s *CategoryService) Delete(id uint) error {
 		ctx := context.Background()
 		db.RDB.Del(ctx, "category:list")
 		db.RDB.Del(ctx, "blog:author_profile")
+
+		// 订阅源和站点地图中包含分类名称和地址
+		clearFeedCaches()
+		clearSitemapCaches()
 	}()
 
 	return nil
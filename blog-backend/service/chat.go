@@ -6,7 +6,7 @@
  * 系统用户：Administrator
  * 作　　者：無以菱
  * 联系邮箱：huangjing510@126.com
- * 功能描述：聊天室业务逻辑层，提供WebSocket实时聊天、消息管理、在线用户管理等功能
+ * 功能描述：聊天室业务逻辑层，提供WebSocket实时聊天、多聊天室、消息管理、在线用户管理等功能
  */
 package service
 
@@ -15,11 +15,12 @@ import (
 	"blog-backend/model"
 	"blog-backend/repository"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"log"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
@@ -34,29 +35,59 @@ type Client struct {
 	Avatar   string          // 头像
 	IP       string          // IP地址
 	Role     string          // 角色：admin/user/guest
+	Rooms    map[uint]bool   // 已加入的聊天室，由 Hub 的读写锁保护
+
+	bucket *tokenBucket // 本连接的发言令牌桶，只在 ReadPump 中使用
 }
 
-// Hub WebSocket Hub，管理所有客户端
+// Hub WebSocket Hub，管理本实例的客户端，多实例之间通过Redis转发消息
 type Hub struct {
-	Clients     map[*Client]bool // 注册的客户端
-	Broadcast   chan []byte      // 广播消息通道
-	Register    chan *Client     // 注册客户端通道
-	Unregister  chan *Client     // 注销客户端通道
-	mutex       sync.RWMutex     // 读写锁
-	Repo        *repository.ChatRepository
-	SettingRepo *repository.SettingRepository
+	InstanceID        string               // 实例唯一标识，用于区分各实例的在线名单
+	Clients           map[*Client]bool     // 注册的客户端
+	Broadcast         chan []byte          // 本实例广播消息通道（跨实例广播请使用 Publish）
+	roomBroadcast     chan roomMessage     // 本实例聊天室消息通道（跨实例请使用 PublishToRoom）
+	userBroadcast     chan userMessage     // 本实例私信投递通道（跨实例请使用 PublishToUser）
+	identityBroadcast chan identityMessage // 本实例按发言者身份投递的通道（跨实例请使用 PublishToIdentity）
+	Register          chan *Client         // 注册客户端通道
+	Unregister        chan *Client         // 注销客户端通道
+	mutex             sync.RWMutex         // 读写锁
+	Repo              *repository.ChatRepository
+	RoomRepo          *repository.ChatRoomRepository
+	PostRepo          *repository.PostRepository
+	SettingRepo       *repository.SettingRepository
+	guard             *chatGuard // 防刷与内容过滤
+}
+
+// roomMessage 投递给某个聊天室成员的消息
+type roomMessage struct {
+	roomID uint
+	data   []byte
+}
+
+// userMessage 投递给某个登录用户所有连接的消息
+type userMessage struct {
+	userID uint
+	data   []byte
+}
+
+// identityMessage 投递给某个发言者身份所有连接的消息
+type identityMessage struct {
+	identity string
+	data     []byte
 }
 
 // WebSocketMessage WebSocket消息结构
 type WebSocketMessage struct {
-	Type      string      `json:"type"`      // 消息类型：message, user_join, user_leave, user_list, history
-	Data      interface{} `json:"data"`      // 消息内容
-	Timestamp int64       `json:"timestamp"` // 时间戳
+	Type      string      `json:"type"`              // 消息类型：message, message_recalled, message_edited, muted, unmuted, user_join, user_leave, user_list, room_users, room_joined, room_left, room_updated, history, direct_message, direct_read
+	Data      interface{} `json:"data"`              // 消息内容
+	RoomID    uint        `json:"room_id,omitempty"` // 所属聊天室，为空表示全站消息
+	Timestamp int64       `json:"timestamp"`         // 时间戳
 }
 
 // UserInfo 用户信息
 type UserInfo struct {
 	ID       string `json:"id"`
+	UserID   *uint  `json:"user_id,omitempty"` // 登录用户ID，用于发起私信
 	Username string `json:"username"`
 	Avatar   string `json:"avatar"`
 }
@@ -64,65 +95,267 @@ type UserInfo struct {
 // NewHub 创建新的Hub
 func NewHub() *Hub {
 	return &Hub{
-		Clients:     make(map[*Client]bool),
-		Broadcast:   make(chan []byte, 256),
-		Register:    make(chan *Client),
-		Unregister:  make(chan *Client),
-		Repo:        repository.NewChatRepository(),
-		SettingRepo: repository.NewSettingRepository(),
+		InstanceID:        uuid.NewString(),
+		Clients:           make(map[*Client]bool),
+		Broadcast:         make(chan []byte, 256),
+		roomBroadcast:     make(chan roomMessage, 256),
+		userBroadcast:     make(chan userMessage, 256),
+		identityBroadcast: make(chan identityMessage, 256),
+		Register:          make(chan *Client),
+		Unregister:        make(chan *Client),
+		Repo:              repository.NewChatRepository(),
+		RoomRepo:          repository.NewChatRoomRepository(),
+		PostRepo:          repository.NewPostRepository(),
+		SettingRepo:       repository.NewSettingRepository(),
+		guard:             &chatGuard{repo: repository.NewSettingRepository()},
 	}
 }
 
 // Run 启动Hub
 func (h *Hub) Run() {
+	// 登记本实例并开始接收其他实例的事件
+	h.refreshPresence()
+	go h.subscribe()
+	go h.heartbeat()
+
 	for {
 		select {
 		case client := <-h.Register:
 			h.mutex.Lock()
 			h.Clients[client] = true
 			h.mutex.Unlock()
+			h.addPresence(client)
 
-			// 发送历史消息给新连接的客户端
-			go h.sendHistory(client)
+			// 新连接默认进入公共大厅（发送大厅历史消息并通知大厅成员）
+			go h.joinLobby(client)
 
-			// 广播用户加入消息
-			h.broadcastUserJoin(client)
+			// 仍在禁言期内的用户重连后恢复禁言状态
+			go h.sendMuteState(client)
 
 			// 广播最新的在线用户列表给所有人
 			go h.broadcastUserList()
 
 		case client := <-h.Unregister:
+			var rooms []uint
 			h.mutex.Lock()
-			if _, ok := h.Clients[client]; ok {
+			_, ok := h.Clients[client]
+			if ok {
 				delete(h.Clients, client)
 				close(client.Send)
-
-				// 广播用户离开消息
-				h.broadcastUserLeave(client)
+				for roomID := range client.Rooms {
+					rooms = append(rooms, roomID)
+				}
 			}
 			h.mutex.Unlock()
 
+			if ok {
+				h.removePresence(client)
+
+				// 通知所在聊天室用户离开
+				for _, roomID := range rooms {
+					h.broadcastUserLeave(client, roomID)
+					go h.broadcastRoomUsers(roomID)
+				}
+			}
+
 			// 广播最新的在线用户列表给所有人
 			go h.broadcastUserList()
 
 		case message := <-h.Broadcast:
-			h.mutex.RLock()
+			// 发送缓冲已满的客户端会被移除，需要写锁
+			h.mutex.Lock()
+			for client := range h.Clients {
+				h.deliver(client, message)
+			}
+			h.mutex.Unlock()
+
+		case message := <-h.roomBroadcast:
+			h.mutex.Lock()
+			for client := range h.Clients {
+				if client.Rooms[message.roomID] {
+					h.deliver(client, message.data)
+				}
+			}
+			h.mutex.Unlock()
+
+		case message := <-h.userBroadcast:
+			h.mutex.Lock()
 			for client := range h.Clients {
-				select {
-				case client.Send <- message:
-				default:
-					close(client.Send)
-					delete(h.Clients, client)
+				if client.UserID != nil && *client.UserID == message.userID {
+					h.deliver(client, message.data)
 				}
 			}
-			h.mutex.RUnlock()
+			h.mutex.Unlock()
+
+		case message := <-h.identityBroadcast:
+			h.mutex.Lock()
+			for client := range h.Clients {
+				if client.identity() == message.identity {
+					h.deliver(client, message.data)
+				}
+			}
+			h.mutex.Unlock()
 		}
 	}
 }
 
-// sendHistory 发送历史消息
-func (h *Hub) sendHistory(client *Client) {
-	messages, err := h.Repo.GetRecentMessages(50)
+// deliver 向客户端投递消息，发送缓冲已满时断开该客户端（调用方需持有写锁）
+func (h *Hub) deliver(client *Client, message []byte) {
+	select {
+	case client.Send <- message:
+	default:
+		close(client.Send)
+		delete(h.Clients, client)
+	}
+}
+
+// sendTo 向单个客户端发送消息，客户端已断开时忽略
+func (h *Hub) sendTo(client *Client, wsMsg WebSocketMessage) {
+	data, err := json.Marshal(wsMsg)
+	if err != nil {
+		return
+	}
+
+	// 持有读锁确认连接仍在，避免向已关闭的发送通道写入
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	if _, ok := h.Clients[client]; !ok {
+		return
+	}
+	select {
+	case client.Send <- data:
+	default:
+	}
+}
+
+// sendSystem 向单个客户端发送系统提示
+func (h *Hub) sendSystem(client *Client, message string, roomID uint) {
+	h.sendTo(client, WebSocketMessage{
+		Type: "system",
+		Data: map[string]interface{}{
+			"message": message,
+		},
+		RoomID:    roomID,
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// CanManage 客户端是否具备聊天室管理权限
+func (c *Client) CanManage() bool {
+	return repository.NewRoleRepository().HasPermission(c.Role, constant.PermChatManage)
+}
+
+// inRoom 客户端是否已加入聊天室
+func (h *Hub) inRoom(client *Client, roomID uint) bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return client.Rooms[roomID]
+}
+
+// joinLobby 进入公共大厅
+func (h *Hub) joinLobby(client *Client) {
+	lobby, err := h.RoomRepo.GetLobby()
+	if err != nil {
+		log.Printf("获取公共大厅失败: %v", err)
+		return
+	}
+	if err := h.joinRoom(client, lobby); err != nil {
+		log.Printf("进入公共大厅失败: %v", err)
+	}
+}
+
+// JoinRoom 进入聊天室
+func (h *Hub) JoinRoom(client *Client, roomID uint) error {
+	room, err := h.RoomRepo.GetByID(roomID)
+	if err != nil {
+		return errors.New("聊天室不存在")
+	}
+	return h.joinRoom(client, room)
+}
+
+// joinRoom 校验权限后加入聊天室，发送该聊天室的历史消息并通知其他成员
+func (h *Hub) joinRoom(client *Client, room *model.ChatRoom) error {
+	if room.IsArchived {
+		return errors.New("该聊天室已归档")
+	}
+	if room.Type == model.ChatRoomTypeAdmin && !client.CanManage() {
+		return errors.New("该聊天室仅管理员可进入")
+	}
+	if !postRoomVisible(h.PostRepo, room) && !client.CanManage() {
+		return errors.New("文章不存在或未公开")
+	}
+
+	h.mutex.Lock()
+	_, online := h.Clients[client]
+	joined := client.Rooms[room.ID]
+	if online {
+		client.Rooms[room.ID] = true
+	}
+	h.mutex.Unlock()
+
+	// 连接已断开
+	if !online {
+		return nil
+	}
+
+	h.sendTo(client, WebSocketMessage{
+		Type:      "room_joined",
+		Data:      room,
+		RoomID:    room.ID,
+		Timestamp: time.Now().Unix(),
+	})
+	h.sendHistory(client, room.ID)
+
+	if !joined {
+		h.addPresence(client)
+		h.broadcastUserJoin(client, room.ID)
+	}
+	h.broadcastRoomUsers(room.ID)
+	return nil
+}
+
+// LeaveRoom 离开聊天室
+func (h *Hub) LeaveRoom(client *Client, roomID uint) {
+	h.mutex.Lock()
+	joined := client.Rooms[roomID]
+	delete(client.Rooms, roomID)
+	h.mutex.Unlock()
+
+	if !joined {
+		return
+	}
+
+	h.sendTo(client, WebSocketMessage{
+		Type:      "room_left",
+		Data:      nil,
+		RoomID:    roomID,
+		Timestamp: time.Now().Unix(),
+	})
+	h.addPresence(client)
+	h.broadcastUserLeave(client, roomID)
+	h.broadcastRoomUsers(roomID)
+}
+
+// NotifyRoomUpdated 通知客户端聊天室信息变化（锁定、归档等），管理员专用聊天室只通知其成员
+func (h *Hub) NotifyRoomUpdated(room *model.ChatRoom) {
+	wsMsg := WebSocketMessage{
+		Type:      "room_updated",
+		Data:      room,
+		RoomID:    room.ID,
+		Timestamp: time.Now().Unix(),
+	}
+
+	data, _ := json.Marshal(wsMsg)
+	if room.Type == model.ChatRoomTypeAdmin {
+		h.PublishToRoom(room.ID, data)
+		return
+	}
+	h.Publish(data)
+}
+
+// sendHistory 发送聊天室的历史消息
+func (h *Hub) sendHistory(client *Client, roomID uint) {
+	messages, err := h.Repo.GetRecentMessages(roomID, 50)
 	if err != nil {
 		log.Printf("获取历史消息失败: %v", err)
 		return
@@ -131,8 +364,10 @@ func (h *Hub) sendHistory(client *Client) {
 	// 为历史消息添加client_id字段（设为nil，因为用户可能已离线）
 	messagesWithClientID := make([]map[string]interface{}, len(messages))
 	for i, msg := range messages {
+		publicChatMessage(&msg)
 		messagesWithClientID[i] = map[string]interface{}{
 			"id":           msg.ID,
+			"room_id":      msg.RoomID,
 			"content":      msg.Content,
 			"user_id":      msg.UserID,
 			"username":     msg.Username,
@@ -141,83 +376,80 @@ func (h *Hub) sendHistory(client *Client) {
 			"is_broadcast": msg.IsBroadcast,
 			"target":       msg.Target,
 			"status":       msg.Status,
+			"is_recalled":  msg.IsRecalled,
+			"recalled_by":  msg.RecalledBy,
+			"edited_at":    msg.EditedAt,
 			"created_at":   msg.CreatedAt,
 			"updated_at":   msg.UpdatedAt,
 		}
 	}
 
-	wsMsg := WebSocketMessage{
+	h.sendTo(client, WebSocketMessage{
 		Type:      "history",
 		Data:      messagesWithClientID,
+		RoomID:    roomID,
 		Timestamp: time.Now().Unix(),
-	}
-
-	data, err := json.Marshal(wsMsg)
-	if err != nil {
-		log.Printf("序列化历史消息失败: %v", err)
-		return
-	}
-
-	select {
-	case client.Send <- data:
-	default:
-	}
+	})
 }
 
-// broadcastUserJoin 广播用户加入
-func (h *Hub) broadcastUserJoin(client *Client) {
+// broadcastUserJoin 广播用户加入聊天室
+func (h *Hub) broadcastUserJoin(client *Client, roomID uint) {
 	wsMsg := WebSocketMessage{
 		Type: "user_join",
 		Data: UserInfo{
 			ID:       client.ID,
+			UserID:   client.UserID,
 			Username: client.Username,
 			Avatar:   client.Avatar,
 		},
+		RoomID:    roomID,
 		Timestamp: time.Now().Unix(),
 	}
 
 	data, _ := json.Marshal(wsMsg)
-	h.Broadcast <- data
+	h.PublishToRoom(roomID, data)
 }
 
-// broadcastUserLeave 广播用户离开
-func (h *Hub) broadcastUserLeave(client *Client) {
+// broadcastUserLeave 广播用户离开聊天室
+func (h *Hub) broadcastUserLeave(client *Client, roomID uint) {
 	wsMsg := WebSocketMessage{
 		Type: "user_leave",
 		Data: UserInfo{
 			ID:       client.ID,
 			Username: client.Username,
 		},
+		RoomID:    roomID,
 		Timestamp: time.Now().Unix(),
 	}
 
 	data, _ := json.Marshal(wsMsg)
-	h.Broadcast <- data
+	h.PublishToRoom(roomID, data)
 }
 
-// sendUserList 发送在线用户列表给单个客户端（去重后）
-func (h *Hub) sendUserList(client *Client) {
-	// 使用 GetOnlineUsers 获取去重后的用户列表
-	userList := h.GetOnlineUsers()
-
+// broadcastRoomUsers 向聊天室成员广播该聊天室的在线用户列表（去重后）
+func (h *Hub) broadcastRoomUsers(roomID uint) {
 	wsMsg := WebSocketMessage{
-		Type:      "user_list",
-		Data:      userList,
+		Type:      "room_users",
+		Data:      h.GetRoomUsers(roomID),
+		RoomID:    roomID,
 		Timestamp: time.Now().Unix(),
 	}
 
-	data, err := json.Marshal(wsMsg)
-	if err != nil {
-		return
-	}
+	data, _ := json.Marshal(wsMsg)
+	h.PublishToRoom(roomID, data)
+}
 
-	select {
-	case client.Send <- data:
-	default:
-	}
+// sendUserList 发送在线用户列表给单个客户端（去重后）
+func (h *Hub) sendUserList(client *Client) {
+	// 使用 GetOnlineUsers 获取去重后的用户列表
+	h.sendTo(client, WebSocketMessage{
+		Type:      "user_list",
+		Data:      h.GetOnlineUsers(),
+		Timestamp: time.Now().Unix(),
+	})
 }
 
-// broadcastUserList 广播在线用户列表给所有客户端（去重后）
+// broadcastUserList 广播全站在线用户列表给所有客户端（去重后）
 func (h *Hub) broadcastUserList() {
 	// 使用 GetOnlineUsers 获取去重后的用户列表
 	userList := h.GetOnlineUsers()
@@ -229,54 +461,46 @@ func (h *Hub) broadcastUserList() {
 	}
 
 	data, _ := json.Marshal(wsMsg)
-	h.Broadcast <- data
+	h.Publish(data)
 }
 
-// GetOnlineCount 获取在线人数（按用户去重）
+// GetOnlineCount 获取所有实例的在线人数（按用户去重）
 func (h *Hub) GetOnlineCount() int {
-	h.mutex.RLock()
-	defer h.mutex.RUnlock()
+	return len(h.GetOnlineUsers())
+}
 
-	// 使用 map 去重，key 为 user_id（登录用户）或 username（匿名用户）
-	uniqueUsers := make(map[string]bool)
+// GetOnlineUsers 获取所有实例的在线用户列表（按用户去重）
+func (h *Hub) GetOnlineUsers() []UserInfo {
+	return uniqueUsers(h.onlineClients())
+}
 
-	for client := range h.Clients {
-		var key string
-		if client.UserID != nil {
-			// 登录用户，使用 user_id 作为唯一标识
-			key = fmt.Sprintf("user_%d", *client.UserID)
-		} else {
-			// 匿名用户，使用 username 作为唯一标识
-			key = fmt.Sprintf("anonymous_%s", client.Username)
+// GetRoomUsers 获取聊天室在所有实例上的在线用户列表（按用户去重）
+func (h *Hub) GetRoomUsers(roomID uint) []UserInfo {
+	var members []OnlineClient
+	for _, client := range h.onlineClients() {
+		for _, id := range client.Rooms {
+			if id == roomID {
+				members = append(members, client)
+				break
+			}
 		}
-		uniqueUsers[key] = true
 	}
-
-	return len(uniqueUsers)
+	return uniqueUsers(members)
 }
 
-// GetOnlineUsers 获取在线用户列表（按用户去重）
-func (h *Hub) GetOnlineUsers() []UserInfo {
-	h.mutex.RLock()
-	defer h.mutex.RUnlock()
-
-	// 使用 map 去重
+// uniqueUsers 将在线连接按用户去重
+func uniqueUsers(clients []OnlineClient) []UserInfo {
+	// 使用 map 去重，key 为 user_id（登录用户）或 username（匿名用户）
 	uniqueUsersMap := make(map[string]UserInfo)
 
-	for client := range h.Clients {
-		var key string
-		if client.UserID != nil {
-			// 登录用户，使用 user_id 作为唯一标识
-			key = fmt.Sprintf("user_%d", *client.UserID)
-		} else {
-			// 匿名用户，使用 username 作为唯一标识
-			key = fmt.Sprintf("anonymous_%s", client.Username)
-		}
+	for _, client := range clients {
+		key := userKey(client.UserID, client.Username)
 
 		// 如果已存在，保留第一个连接的信息（或者可以更新为最新的）
 		if _, exists := uniqueUsersMap[key]; !exists {
 			uniqueUsersMap[key] = UserInfo{
-				ID:       client.ID,
+				ID:       client.ClientID,
+				UserID:   client.UserID,
 				Username: client.Username,
 				Avatar:   client.Avatar,
 			}
@@ -301,8 +525,25 @@ func (h *Hub) IsChatMuted() bool {
 	return setting.Value == "1"
 }
 
-// KickClient 踢出客户端
+// KickClient 踢出客户端，连接不在本实例时通知其所在实例处理
 func (h *Hub) KickClient(clientID string, reason string) bool {
+	if h.kickLocal(clientID, reason) {
+		return true
+	}
+
+	client := h.LookupClient(clientID)
+	if client == nil {
+		return false
+	}
+	if err := h.publishEvent(chatEvent{Kind: chatEventKick, ClientID: clientID, Reason: reason}); err != nil {
+		log.Printf("发布踢出指令失败: %v", err)
+		return false
+	}
+	return true
+}
+
+// kickLocal 踢出本实例上的客户端
+func (h *Hub) kickLocal(clientID string, reason string) bool {
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
 
@@ -334,7 +575,7 @@ func (h *Hub) KickClient(clientID string, reason string) bool {
 	return false
 }
 
-// GetClientByID 根据ID获取客户端
+// GetClientByID 根据ID获取本实例的客户端
 func (h *Hub) GetClientByID(clientID string) *Client {
 	h.mutex.RLock()
 	defer h.mutex.RUnlock()
@@ -379,27 +620,64 @@ func (c *Client) ReadPump() {
 		// 处理不同类型的消息
 		msgType, _ := msg["type"].(string)
 		switch msgType {
+		case "join":
+			roomID := roomIDOf(msg)
+			if err := c.Hub.JoinRoom(c, roomID); err != nil {
+				c.Hub.sendSystem(c, err.Error(), roomID)
+			}
+
+		case "leave":
+			c.Hub.LeaveRoom(c, roomIDOf(msg))
+
+		case "recall":
+			if err := c.Hub.recallByClient(c, messageIDOf(msg)); err != nil {
+				c.Hub.sendSystem(c, err.Error(), roomIDOf(msg))
+			}
+
+		case "edit":
+			content, _ := msg["content"].(string)
+			if err := c.Hub.editByClient(c, messageIDOf(msg), content); err != nil {
+				c.Hub.sendSystem(c, err.Error(), roomIDOf(msg))
+			}
+
 		case "message":
 			content, _ := msg["content"].(string)
 			if content == "" {
 				continue
 			}
 
+			// 未指定聊天室时发送到公共大厅，兼容旧版客户端
+			var room *model.ChatRoom
+			if roomID := roomIDOf(msg); roomID > 0 {
+				room, err = c.Hub.RoomRepo.GetByID(roomID)
+			} else {
+				room, err = c.Hub.RoomRepo.GetLobby()
+			}
+			if err != nil || !c.Hub.inRoom(c, room.ID) {
+				c.Hub.sendSystem(c, "请先进入该聊天室", roomIDOf(msg))
+				continue
+			}
+			if room.IsArchived {
+				c.Hub.sendSystem(c, "该聊天室已归档，无法发言", room.ID)
+				continue
+			}
+
+			canManage := c.CanManage()
+			if room.IsLocked && !canManage {
+				c.Hub.sendSystem(c, "该聊天室已锁定，只有管理员可发言", room.ID)
+				continue
+			}
+
 			// 全员禁言校验：仅具备管理员权限的用户可发言
-			if !constant.IsAdminRole(c.Role) && c.Hub.IsChatMuted() {
-				wsMsg := WebSocketMessage{
-					Type: "system",
-					Data: map[string]interface{}{
-						"message": "当前已开启全员禁言，只有管理员可发言",
-					},
-					Timestamp: time.Now().Unix(),
-				}
-				if data, err := json.Marshal(wsMsg); err == nil {
-					select {
-					case c.Send <- data:
-					default:
-					}
-				}
+			if !canManage && c.Hub.IsChatMuted() {
+				c.Hub.sendSystem(c, "当前已开启全员禁言，只有管理员可发言", room.ID)
+				continue
+			}
+
+			// 防刷与内容过滤（禁言、长度、频率、重复消息、敏感词）
+			content, err = c.Hub.guard.Check(c, content)
+			if err != nil {
+				c.Hub.sendSystem(c, err.Error(), room.ID)
 				continue
 			}
 
@@ -413,10 +691,12 @@ func (c *Client) ReadPump() {
 
 			chatMsg := &model.ChatMessage{
 				Content:  content,
+				RoomID:   &room.ID,
 				UserID:   c.UserID,
 				Username: c.Username,
 				Avatar:   c.Avatar,
 				IP:       ip,
+				ClientID: c.ID,
 				Status:   1,
 			}
 
@@ -428,6 +708,7 @@ func (c *Client) ReadPump() {
 			// 构建包含client_id的消息响应
 			messageData := map[string]interface{}{
 				"id":         chatMsg.ID,
+				"room_id":    chatMsg.RoomID,
 				"content":    chatMsg.Content,
 				"user_id":    chatMsg.UserID,
 				"username":   chatMsg.Username,
@@ -438,19 +719,38 @@ func (c *Client) ReadPump() {
 				"updated_at": chatMsg.UpdatedAt,
 			}
 
-			// 广播消息
+			// 广播消息给聊天室成员
 			wsMsg := WebSocketMessage{
 				Type:      "message",
 				Data:      messageData,
+				RoomID:    room.ID,
 				Timestamp: time.Now().Unix(),
 			}
 
 			data, _ := json.Marshal(wsMsg)
-			c.Hub.Broadcast <- data
+			c.Hub.PublishToRoom(room.ID, data)
 		}
 	}
 }
 
+// roomIDOf 读取客户端消息中的聊天室ID，缺失或不合法时返回0
+func roomIDOf(msg map[string]interface{}) uint {
+	id, _ := msg["room_id"].(float64)
+	if id < 1 {
+		return 0
+	}
+	return uint(id)
+}
+
+// messageIDOf 读取客户端消息中的聊天消息ID，缺失或不合法时返回0
+func messageIDOf(msg map[string]interface{}) uint {
+	id, _ := msg["message_id"].(float64)
+	if id < 1 {
+		return 0
+	}
+	return uint(id)
+}
+
 // WritePump 向客户端写入消息
 func (c *Client) WritePump() {
 	ticker := time.NewTicker(54 * time.Second)
@@ -508,9 +808,17 @@ func NewChatService(hub *Hub) *ChatService {
 	}
 }
 
-// GetMessages 获取消息列表（分页）
-func (s *ChatService) GetMessages(page, pageSize int, includeAnnouncementOnly bool) ([]model.ChatMessage, int64, error) {
-	return s.repo.GetMessages(page, pageSize, includeAnnouncementOnly)
+// GetMessages 获取消息列表（分页），roomID 为空时不按聊天室筛选
+// 非管理端查询时隐藏已撤回消息的内容和编辑前的原始内容
+func (s *ChatService) GetMessages(roomID *uint, page, pageSize int, includeAnnouncementOnly bool) ([]model.ChatMessage, int64, error) {
+	messages, total, err := s.repo.GetMessages(roomID, page, pageSize, includeAnnouncementOnly)
+	if err != nil || includeAnnouncementOnly {
+		return messages, total, err
+	}
+	for i := range messages {
+		publicChatMessage(&messages[i])
+	}
+	return messages, total, nil
 }
 
 // DeleteMessage 删除消息
@@ -527,3 +835,8 @@ func (s *ChatService) GetOnlineCount() int {
 func (s *ChatService) GetOnlineUsers() []UserInfo {
 	return s.hub.GetOnlineUsers()
 }
+
+// GetRoomUsers 获取聊天室在线用户列表
+func (s *ChatService) GetRoomUsers(roomID uint) []UserInfo {
+	return s.hub.GetRoomUsers(roomID)
+}
@@ -0,0 +1,320 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：chat_cluster.go
+ * 创建时间：2026-10-18 05:49:13
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：聊天室多实例支持，通过Redis发布订阅在各实例间转发消息和踢人指令，并维护带心跳过期的共享在线名单
+ */
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"blog-backend/db"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// chatEventChannel 聊天室事件频道，所有实例都订阅该频道
+	chatEventChannel = "chat:events"
+	// chatInstancesKey 存活实例集合（ZSET，分数为最近一次心跳的时间戳）
+	chatInstancesKey = "chat:instances"
+	// chatPresenceKeyPrefix 每个实例的在线连接表（HASH，field 为客户端ID）
+	chatPresenceKeyPrefix = "chat:presence:"
+	// chatPresenceTTL 在线名单过期时间，实例异常退出后其连接最多保留这么久
+	chatPresenceTTL = 60 * time.Second
+	// chatHeartbeatInterval 心跳间隔，需明显小于 chatPresenceTTL
+	chatHeartbeatInterval = 20 * time.Second
+)
+
+// 实例间事件类型
+const (
+	chatEventBroadcast = "broadcast" // 向所有实例的客户端（或指定聊天室的成员）投递消息
+	chatEventKick      = "kick"      // 由持有该连接的实例踢出客户端
+	chatEventUser      = "user"      // 向某个登录用户在所有实例上的连接投递消息（私信）
+	chatEventIdentity  = "identity"  // 向某个发言者身份在所有实例上的连接投递消息（禁言通知）
+)
+
+// chatEvent 实例间通过Redis传递的事件
+type chatEvent struct {
+	Origin   string          `json:"origin"`              // 发出事件的实例ID
+	Kind     string          `json:"kind"`                // 事件类型
+	RoomID   uint            `json:"room_id,omitempty"`   // 广播事件的目标聊天室，为空表示全站
+	UserID   uint            `json:"user_id,omitempty"`   // 私信事件的目标用户
+	Identity string          `json:"identity,omitempty"`  // 身份事件的目标发言者身份
+	ClientID string          `json:"client_id,omitempty"` // 踢人事件的目标客户端
+	Reason   string          `json:"reason,omitempty"`    // 踢人原因
+	Payload  json.RawMessage `json:"payload,omitempty"`   // 广播给客户端的原始消息
+}
+
+// OnlineClient 共享在线名单中的一条连接记录
+type OnlineClient struct {
+	ClientID string `json:"client_id"`
+	UserID   *uint  `json:"user_id"`
+	Username string `json:"username"`
+	Avatar   string `json:"avatar"`
+	IP       string `json:"ip"`
+	Role     string `json:"role"`
+	Rooms    []uint `json:"rooms"`    // 已加入的聊天室
+	Instance string `json:"instance"` // 连接所在的实例ID
+}
+
+// presenceKey 当前实例的在线连接表键名
+func (h *Hub) presenceKey() string {
+	return chatPresenceKeyPrefix + h.InstanceID
+}
+
+// onlineClientOf 将本实例的客户端转换为在线名单记录（调用方需持有读锁或写锁）
+func (h *Hub) onlineClientOf(client *Client) OnlineClient {
+	rooms := make([]uint, 0, len(client.Rooms))
+	for roomID := range client.Rooms {
+		rooms = append(rooms, roomID)
+	}
+	return OnlineClient{
+		ClientID: client.ID,
+		UserID:   client.UserID,
+		Username: client.Username,
+		Avatar:   client.Avatar,
+		IP:       client.IP,
+		Role:     client.Role,
+		Rooms:    rooms,
+		Instance: h.InstanceID,
+	}
+}
+
+// userKey 在线用户去重键：登录用户按 user_id，匿名用户按 username
+func userKey(userID *uint, username string) string {
+	if userID != nil {
+		return fmt.Sprintf("user_%d", *userID)
+	}
+	return fmt.Sprintf("anonymous_%s", username)
+}
+
+// Publish 将消息广播给所有实例上的客户端，Redis不可用时退化为仅本实例广播
+func (h *Hub) Publish(data []byte) {
+	h.PublishToRoom(0, data)
+}
+
+// PublishToRoom 将消息投递给所有实例上该聊天室的成员，roomID 为0时投递给所有客户端
+func (h *Hub) PublishToRoom(roomID uint, data []byte) {
+	if err := h.publishEvent(chatEvent{Kind: chatEventBroadcast, RoomID: roomID, Payload: data}); err != nil {
+		log.Printf("发布聊天室消息失败，仅在本实例广播: %v", err)
+		h.deliverLocal(roomID, data)
+	}
+}
+
+// PublishToUser 将消息投递给某个登录用户在所有实例上的连接，Redis不可用时退化为仅本实例投递
+func (h *Hub) PublishToUser(userID uint, data []byte) {
+	if err := h.publishEvent(chatEvent{Kind: chatEventUser, UserID: userID, Payload: data}); err != nil {
+		log.Printf("发布私信消息失败，仅在本实例投递: %v", err)
+		h.userBroadcast <- userMessage{userID: userID, data: data}
+	}
+}
+
+// PublishToIdentity 将消息投递给某个发言者身份（登录用户或匿名用户的IP）在所有实例上的连接，Redis不可用时退化为仅本实例投递
+func (h *Hub) PublishToIdentity(identity string, data []byte) {
+	if err := h.publishEvent(chatEvent{Kind: chatEventIdentity, Identity: identity, Payload: data}); err != nil {
+		log.Printf("发布聊天室身份消息失败，仅在本实例投递: %v", err)
+		h.identityBroadcast <- identityMessage{identity: identity, data: data}
+	}
+}
+
+// deliverLocal 将消息交给本实例的 Run 循环投递
+func (h *Hub) deliverLocal(roomID uint, data []byte) {
+	if roomID == 0 {
+		h.Broadcast <- data
+		return
+	}
+	h.roomBroadcast <- roomMessage{roomID: roomID, data: data}
+}
+
+// publishEvent 向聊天室事件频道发布事件
+func (h *Hub) publishEvent(event chatEvent) error {
+	event.Origin = h.InstanceID
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return db.RDB.Publish(context.Background(), chatEventChannel, data).Err()
+}
+
+// subscribe 订阅聊天室事件频道，将其他实例（包括自身）发布的事件应用到本实例
+func (h *Hub) subscribe() {
+	pubsub := db.RDB.Subscribe(context.Background(), chatEventChannel)
+	defer pubsub.Close()
+
+	// 断线后 go-redis 会自动重连并重新订阅
+	for msg := range pubsub.Channel() {
+		var event chatEvent
+		if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+			log.Printf("解析聊天室事件失败: %v", err)
+			continue
+		}
+
+		switch event.Kind {
+		case chatEventBroadcast:
+			h.deliverLocal(event.RoomID, []byte(event.Payload))
+		case chatEventKick:
+			h.kickLocal(event.ClientID, event.Reason)
+		case chatEventUser:
+			h.userBroadcast <- userMessage{userID: event.UserID, data: []byte(event.Payload)}
+		case chatEventIdentity:
+			h.identityBroadcast <- identityMessage{identity: event.Identity, data: []byte(event.Payload)}
+		}
+	}
+}
+
+// heartbeat 定期刷新本实例的在线名单，并清理已失联的实例
+func (h *Hub) heartbeat() {
+	ticker := time.NewTicker(chatHeartbeatInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		h.refreshPresence()
+	}
+}
+
+// refreshPresence 用本实例当前的连接重建在线名单并续期
+func (h *Hub) refreshPresence() {
+	ctx := context.Background()
+
+	h.mutex.RLock()
+	fields := make(map[string]interface{}, len(h.Clients))
+	for client := range h.Clients {
+		if data, err := json.Marshal(h.onlineClientOf(client)); err == nil {
+			fields[client.ID] = data
+		}
+	}
+	h.mutex.RUnlock()
+
+	now := time.Now()
+	pipe := db.RDB.TxPipeline()
+	pipe.Del(ctx, h.presenceKey())
+	if len(fields) > 0 {
+		pipe.HSet(ctx, h.presenceKey(), fields)
+		pipe.Expire(ctx, h.presenceKey(), chatPresenceTTL)
+	}
+	pipe.ZAdd(ctx, chatInstancesKey, redis.Z{Score: float64(now.Unix()), Member: h.InstanceID})
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("刷新聊天室在线名单失败: %v", err)
+		return
+	}
+
+	// 清理超过TTL未心跳的实例，只有真正删除了记录的实例负责通知客户端刷新名单
+	maxScore := strconv.FormatInt(now.Add(-chatPresenceTTL).Unix(), 10)
+	removed, err := db.RDB.ZRemRangeByScore(ctx, chatInstancesKey, "-inf", "("+maxScore).Result()
+	if err != nil {
+		log.Printf("清理失联的聊天室实例失败: %v", err)
+		return
+	}
+	if removed > 0 {
+		h.broadcastUserList()
+	}
+}
+
+// addPresence 将连接写入共享在线名单（加入或离开聊天室后也用于更新记录）
+func (h *Hub) addPresence(client *Client) {
+	ctx := context.Background()
+	h.mutex.RLock()
+	data, err := json.Marshal(h.onlineClientOf(client))
+	h.mutex.RUnlock()
+	if err != nil {
+		return
+	}
+
+	pipe := db.RDB.TxPipeline()
+	pipe.HSet(ctx, h.presenceKey(), client.ID, data)
+	pipe.Expire(ctx, h.presenceKey(), chatPresenceTTL)
+	pipe.ZAdd(ctx, chatInstancesKey, redis.Z{Score: float64(time.Now().Unix()), Member: h.InstanceID})
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("写入聊天室在线名单失败: %v", err)
+	}
+}
+
+// removePresence 将断开的连接移出共享在线名单
+func (h *Hub) removePresence(client *Client) {
+	if err := db.RDB.HDel(context.Background(), h.presenceKey(), client.ID).Err(); err != nil {
+		log.Printf("移除聊天室在线名单失败: %v", err)
+	}
+}
+
+// listPresence 读取所有存活实例上的在线连接
+func (h *Hub) listPresence() ([]OnlineClient, error) {
+	ctx := context.Background()
+	minScore := strconv.FormatInt(time.Now().Add(-chatPresenceTTL).Unix(), 10)
+	instances, err := db.RDB.ZRangeByScore(ctx, chatInstancesKey, &redis.ZRangeBy{Min: minScore, Max: "+inf"}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := db.RDB.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(instances))
+	for i, instance := range instances {
+		cmds[i] = pipe.HGetAll(ctx, chatPresenceKeyPrefix+instance)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	var clients []OnlineClient
+	for _, cmd := range cmds {
+		for _, raw := range cmd.Val() {
+			var oc OnlineClient
+			if err := json.Unmarshal([]byte(raw), &oc); err == nil {
+				clients = append(clients, oc)
+			}
+		}
+	}
+	return clients, nil
+}
+
+// localPresence 本实例的在线连接，Redis不可用时作为退化结果
+func (h *Hub) localPresence() []OnlineClient {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	clients := make([]OnlineClient, 0, len(h.Clients))
+	for client := range h.Clients {
+		clients = append(clients, h.onlineClientOf(client))
+	}
+	return clients
+}
+
+// onlineClients 所有实例上的在线连接
+func (h *Hub) onlineClients() []OnlineClient {
+	clients, err := h.listPresence()
+	if err != nil {
+		log.Printf("读取聊天室在线名单失败，仅返回本实例连接: %v", err)
+		return h.localPresence()
+	}
+	return clients
+}
+
+// LookupClient 在所有实例的在线名单中查找连接
+func (h *Hub) LookupClient(clientID string) *OnlineClient {
+	h.mutex.RLock()
+	for client := range h.Clients {
+		if client.ID == clientID {
+			oc := h.onlineClientOf(client)
+			h.mutex.RUnlock()
+			return &oc
+		}
+	}
+	h.mutex.RUnlock()
+
+	for _, oc := range h.onlineClients() {
+		if oc.ClientID == clientID {
+			return &oc
+		}
+	}
+	return nil
+}
@@ -0,0 +1,455 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：chat_guard.go
+ * 创建时间：2026-10-18 06:52:19
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：聊天室防刷与内容过滤，提供按连接和按身份的令牌桶限流、消息长度限制、重复消息拦截、敏感词过滤，
+ *          以及违规次数达到阈值后的自动限时禁言；违规计数和禁言状态保存在Redis中，多实例共享
+ */
+package service
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"blog-backend/db"
+	"blog-backend/model"
+	"blog-backend/repository"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// chatBucketKeyPrefix 按身份的令牌桶（HASH：tokens、ts）
+	chatBucketKeyPrefix = "chat:bucket:"
+	// chatDuplicateKeyPrefix 重复消息标记，键名包含身份和消息摘要
+	chatDuplicateKeyPrefix = "chat:dup:"
+	// chatViolationKeyPrefix 窗口期内的违规次数
+	chatViolationKeyPrefix = "chat:violations:"
+	// chatMuteKeyPrefix 禁言标记，键的剩余有效期即剩余禁言时长
+	chatMuteKeyPrefix = "chat:mute:"
+	// chatGuardSettingsTTL 防刷配置的本地缓存时间，其他实例修改配置后最多延迟这么久生效
+	chatGuardSettingsTTL = 10 * time.Second
+)
+
+// 敏感词处理方式
+const (
+	ChatSensitiveActionMask   = "mask"   // 将敏感词替换为 *
+	ChatSensitiveActionReject = "reject" // 拒绝发送
+)
+
+// chatBucketScript 按身份的令牌桶：按流逝时间补充令牌，有令牌时扣减一个并返回1，否则返回0
+var chatBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local data = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(data[1])
+local ts = tonumber(data[2])
+if tokens == nil or ts == nil then
+  tokens = capacity
+  ts = now
+end
+tokens = math.min(capacity, tokens + (now - ts) / interval)
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil(capacity * interval) + 1000)
+return allowed
+`)
+
+// ChatGuardSettings 聊天室防刷与过滤配置
+type ChatGuardSettings struct {
+	MaxLength         int    // 单条消息最大字数，0 表示不限制
+	RateCapacity      int    // 令牌桶容量（允许连续发送的条数），0 表示不限流
+	RateInterval      int    // 每补充一个令牌所需秒数
+	DuplicateWindow   int    // 相同内容在多少秒内不能重复发送，0 表示不拦截
+	SensitiveWords    string // 敏感词，每行一个
+	SensitiveAction   string // 命中敏感词的处理方式：mask / reject
+	AutoMuteThreshold int    // 窗口期内违规多少次后自动禁言，0 表示不自动禁言
+	AutoMuteWindow    int    // 违规计数窗口（秒）
+	AutoMuteDuration  int    // 自动禁言时长（秒）
+	RecallWindow      int    // 发送后多少秒内可撤回或编辑，0 表示不允许
+
+	sensitivePattern *regexp.Regexp
+}
+
+// chatGuardSetting 单个配置项的键名、默认值、取值范围和说明
+type chatGuardSetting struct {
+	key      string
+	label    string
+	def      int
+	min, max int
+	field    func(s *ChatGuardSettings) *int
+}
+
+// chatGuardIntSettings 数值类配置项
+var chatGuardIntSettings = []chatGuardSetting{
+	{"chat_max_length", "单条消息最大字数", 500, 0, 5000, func(s *ChatGuardSettings) *int { return &s.MaxLength }},
+	{"chat_rate_capacity", "连续发送条数上限", 5, 0, 100, func(s *ChatGuardSettings) *int { return &s.RateCapacity }},
+	{"chat_rate_interval", "恢复一次发送机会的秒数", 2, 1, 3600, func(s *ChatGuardSettings) *int { return &s.RateInterval }},
+	{"chat_duplicate_window", "重复消息拦截时间（秒）", 30, 0, 86400, func(s *ChatGuardSettings) *int { return &s.DuplicateWindow }},
+	{"chat_auto_mute_threshold", "自动禁言违规次数", 5, 0, 100, func(s *ChatGuardSettings) *int { return &s.AutoMuteThreshold }},
+	{"chat_auto_mute_window", "违规计数时间窗口（秒）", 60, 1, 86400, func(s *ChatGuardSettings) *int { return &s.AutoMuteWindow }},
+	{"chat_auto_mute_duration", "自动禁言时长（秒）", 600, 1, 30 * 86400, func(s *ChatGuardSettings) *int { return &s.AutoMuteDuration }},
+	{"chat_recall_window", "撤回和编辑时限（秒）", 120, 0, 86400, func(s *ChatGuardSettings) *int { return &s.RecallWindow }},
+}
+
+const (
+	chatSensitiveWordsKey  = "chat_sensitive_words"
+	chatSensitiveActionKey = "chat_sensitive_action"
+)
+
+// chatGuard 聊天室防刷与过滤，配置从数据库读取并在本地短暂缓存
+type chatGuard struct {
+	repo     *repository.SettingRepository
+	mu       sync.Mutex
+	settings *ChatGuardSettings
+	loadedAt time.Time
+}
+
+// tokenBucket 单个连接的令牌桶（只在该连接的读协程中使用，无需加锁）
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// allow 按流逝时间补充令牌，有令牌时扣减一个
+func (b *tokenBucket) allow(capacity int, interval time.Duration) bool {
+	now := time.Now()
+	if b.last.IsZero() {
+		b.tokens = float64(capacity)
+	} else {
+		b.tokens += float64(now.Sub(b.last)) / float64(interval)
+		if b.tokens > float64(capacity) {
+			b.tokens = float64(capacity)
+		}
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// chatIdentity 发言者身份：登录用户按用户ID，匿名用户按IP（改昵称或重连都无法绕过限制）
+func chatIdentity(userID *uint, ip string) string {
+	if userID != nil {
+		return fmt.Sprintf("user_%d", *userID)
+	}
+	return "ip_" + ip
+}
+
+// identity 客户端的发言者身份
+func (c *Client) identity() string {
+	return chatIdentity(c.UserID, c.IP)
+}
+
+// Settings 获取当前配置（缓存过期后从数据库重新读取）
+func (g *chatGuard) Settings() *ChatGuardSettings {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.settings != nil && time.Since(g.loadedAt) < chatGuardSettingsTTL {
+		return g.settings
+	}
+	g.settings = loadChatGuardSettings(g.repo)
+	g.loadedAt = time.Now()
+	return g.settings
+}
+
+// invalidate 清除本地缓存，配置修改后立即生效
+func (g *chatGuard) invalidate() {
+	g.mu.Lock()
+	g.settings = nil
+	g.mu.Unlock()
+}
+
+// loadChatGuardSettings 从数据库读取配置，缺失或不合法的配置项使用默认值
+func loadChatGuardSettings(repo *repository.SettingRepository) *ChatGuardSettings {
+	values := make(map[string]string)
+	if settings, err := repo.GetByGroup("chat"); err == nil {
+		for _, setting := range settings {
+			values[setting.Key] = setting.Value
+		}
+	} else {
+		log.Printf("读取聊天室防刷配置失败，使用默认值: %v", err)
+	}
+
+	s := &ChatGuardSettings{
+		SensitiveWords:  values[chatSensitiveWordsKey],
+		SensitiveAction: values[chatSensitiveActionKey],
+	}
+	for _, item := range chatGuardIntSettings {
+		value, err := strconv.Atoi(values[item.key])
+		if err != nil || value < item.min || value > item.max {
+			value = item.def
+		}
+		*item.field(s) = value
+	}
+	if s.SensitiveAction != ChatSensitiveActionReject {
+		s.SensitiveAction = ChatSensitiveActionMask
+	}
+	s.sensitivePattern = compileSensitiveWords(s.SensitiveWords)
+	return s
+}
+
+// compileSensitiveWords 将敏感词列表编译为不区分大小写的正则，没有敏感词时返回 nil
+func compileSensitiveWords(words string) *regexp.Regexp {
+	var parts []string
+	for _, word := range strings.Split(words, "\n") {
+		if word = strings.TrimSpace(word); word != "" {
+			parts = append(parts, regexp.QuoteMeta(word))
+		}
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	pattern, err := regexp.Compile("(?i)" + strings.Join(parts, "|"))
+	if err != nil {
+		log.Printf("编译敏感词失败: %v", err)
+		return nil
+	}
+	return pattern
+}
+
+// ToMap 转换为接口返回的配置项
+func (s *ChatGuardSettings) ToMap() map[string]string {
+	result := map[string]string{
+		chatSensitiveWordsKey:  s.SensitiveWords,
+		chatSensitiveActionKey: s.SensitiveAction,
+	}
+	for _, item := range chatGuardIntSettings {
+		result[item.key] = strconv.Itoa(*item.field(s))
+	}
+	return result
+}
+
+// Check 发送前检查消息，返回过滤后的内容
+// 管理员不受限制；被禁言、超长、发送过快、重复发送或命中敏感词（reject 模式）时返回提示给用户的错误，
+// 其中发送过快、重复发送和命中敏感词计为违规，违规次数达到阈值后自动禁言
+func (g *chatGuard) Check(client *Client, content string) (string, error) {
+	if client.CanManage() {
+		return content, nil
+	}
+
+	settings := g.Settings()
+	identity := client.identity()
+
+	if remaining := chatMuteRemaining(identity); remaining > 0 {
+		return "", errors.New(chatMuteMessage(remaining))
+	}
+
+	if settings.MaxLength > 0 && utf8.RuneCountInString(content) > settings.MaxLength {
+		return "", fmt.Errorf("消息不能超过 %d 字", settings.MaxLength)
+	}
+
+	if settings.RateCapacity > 0 {
+		interval := time.Duration(settings.RateInterval) * time.Second
+		if client.bucket == nil {
+			client.bucket = &tokenBucket{}
+		}
+		if !client.bucket.allow(settings.RateCapacity, interval) || !allowIdentity(identity, settings.RateCapacity, interval) {
+			return "", g.violation(client, settings, "发送太频繁，请稍后再试")
+		}
+	}
+
+	if settings.DuplicateWindow > 0 && isDuplicate(identity, content, time.Duration(settings.DuplicateWindow)*time.Second) {
+		return "", g.violation(client, settings, "请不要重复发送相同的消息")
+	}
+
+	if settings.sensitivePattern != nil && settings.sensitivePattern.MatchString(content) {
+		if settings.SensitiveAction == ChatSensitiveActionReject {
+			return "", g.violation(client, settings, "消息包含敏感词，无法发送")
+		}
+		// 屏蔽模式下消息照常发送，但仍计为一次违规
+		content = settings.sensitivePattern.ReplaceAllStringFunc(content, func(word string) string {
+			return strings.Repeat("*", utf8.RuneCountInString(word))
+		})
+		if err := g.violation(client, settings, ""); err != nil {
+			return "", err
+		}
+	}
+
+	return content, nil
+}
+
+// violation 记录一次违规，达到阈值时自动禁言，返回提示给用户的错误
+func (g *chatGuard) violation(client *Client, settings *ChatGuardSettings, reason string) error {
+	if settings.AutoMuteThreshold > 0 {
+		duration := time.Duration(settings.AutoMuteDuration) * time.Second
+		if recordChatViolation(client, settings.AutoMuteThreshold, time.Duration(settings.AutoMuteWindow)*time.Second, duration) {
+			log.Printf("聊天室用户 %s 违规次数过多，自动禁言 %v", client.identity(), duration)
+			return errors.New("违规次数过多，" + chatMuteMessage(duration))
+		}
+	}
+	if reason == "" {
+		return nil
+	}
+	return errors.New(reason)
+}
+
+// allowIdentity 按身份的令牌桶（多实例共享），Redis 不可用时放行（仍受按连接限流约束）
+func allowIdentity(identity string, capacity int, interval time.Duration) bool {
+	allowed, err := chatBucketScript.Run(context.Background(), db.RDB,
+		[]string{chatBucketKeyPrefix + identity},
+		capacity, interval.Milliseconds(), time.Now().UnixMilli()).Int()
+	if err != nil {
+		log.Printf("聊天室限流检查失败: %v", err)
+		return true
+	}
+	return allowed == 1
+}
+
+// isDuplicate 同一身份在窗口期内是否已发送过相同内容（不区分首尾空白和大小写）
+func isDuplicate(identity, content string, window time.Duration) bool {
+	sum := sha1.Sum([]byte(strings.ToLower(strings.TrimSpace(content))))
+	key := chatDuplicateKeyPrefix + identity + ":" + hex.EncodeToString(sum[:])
+	fresh, err := db.RDB.SetNX(context.Background(), key, 1, window).Result()
+	if err != nil {
+		log.Printf("聊天室重复消息检查失败: %v", err)
+		return false
+	}
+	return !fresh
+}
+
+// recordChatViolation 记录一次违规，窗口期内达到阈值时禁言并清零计数，返回是否触发了禁言
+func recordChatViolation(client *Client, threshold int, window, duration time.Duration) bool {
+	ctx := context.Background()
+	identity := client.identity()
+	key := chatViolationKeyPrefix + identity
+
+	count, err := db.RDB.Incr(ctx, key).Result()
+	if err != nil {
+		log.Printf("记录聊天室违规次数失败: %v", err)
+		return false
+	}
+	if count == 1 {
+		db.RDB.Expire(ctx, key, window)
+	}
+	if count < int64(threshold) {
+		return false
+	}
+
+	record := chatMuteRecord{
+		UserID:   client.UserID,
+		Username: client.Username,
+		Reason:   "违规次数过多",
+		Source:   ChatMuteSourceAuto,
+		MutedAt:  time.Now(),
+	}
+	if err := setChatMute(identity, record, duration, key); err != nil {
+		log.Printf("自动禁言失败: %v", err)
+		return false
+	}
+	return true
+}
+
+// chatMuteRemaining 获取身份的剩余禁言时长，未禁言或 Redis 不可用时返回 0
+func chatMuteRemaining(identity string) time.Duration {
+	ttl, err := db.RDB.PTTL(context.Background(), chatMuteKeyPrefix+identity).Result()
+	if err != nil || ttl <= 0 {
+		return 0
+	}
+	return ttl
+}
+
+// chatMuteMessage 禁言提示
+func chatMuteMessage(remaining time.Duration) string {
+	if remaining < time.Minute {
+		return fmt.Sprintf("你已被禁言，请 %d 秒后再试", int((remaining+time.Second-1)/time.Second))
+	}
+	return fmt.Sprintf("你已被禁言，请 %d 分钟后再试", int((remaining+time.Minute-1)/time.Minute))
+}
+
+// GetGuardSettings 获取聊天室防刷与过滤配置
+func (s *ChatService) GetGuardSettings() map[string]string {
+	return loadChatGuardSettings(s.hub.SettingRepo).ToMap()
+}
+
+// UpdateGuardSettings 更新聊天室防刷与过滤配置，只修改传入的配置项
+func (s *ChatService) UpdateGuardSettings(data map[string]string) error {
+	var settings []model.Setting
+	now := time.Now()
+
+	for _, item := range chatGuardIntSettings {
+		raw, ok := data[item.key]
+		if !ok {
+			continue
+		}
+		value, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil || value < item.min || value > item.max {
+			return fmt.Errorf("%s 必须是 %d 到 %d 之间的整数", item.key, item.min, item.max)
+		}
+		settings = append(settings, model.Setting{
+			Group:     "chat",
+			Key:       item.key,
+			Value:     strconv.Itoa(value),
+			Type:      "text",
+			Label:     item.label,
+			UpdatedAt: now,
+		})
+	}
+
+	if words, ok := data[chatSensitiveWordsKey]; ok {
+		// 统一为每行一个、去掉空行和重复项
+		seen := make(map[string]bool)
+		var lines []string
+		for _, word := range strings.Split(strings.ReplaceAll(words, "\r\n", "\n"), "\n") {
+			word = strings.TrimSpace(word)
+			if word != "" && !seen[strings.ToLower(word)] {
+				seen[strings.ToLower(word)] = true
+				lines = append(lines, word)
+			}
+		}
+		settings = append(settings, model.Setting{
+			Group:     "chat",
+			Key:       chatSensitiveWordsKey,
+			Value:     strings.Join(lines, "\n"),
+			Type:      "text",
+			Label:     "敏感词",
+			UpdatedAt: now,
+		})
+	}
+
+	if action, ok := data[chatSensitiveActionKey]; ok {
+		if action != ChatSensitiveActionMask && action != ChatSensitiveActionReject {
+			return errors.New("chat_sensitive_action 值只能是 mask 或 reject")
+		}
+		settings = append(settings, model.Setting{
+			Group:     "chat",
+			Key:       chatSensitiveActionKey,
+			Value:     action,
+			Type:      "text",
+			Label:     "敏感词处理方式",
+			UpdatedAt: now,
+		})
+	}
+
+	if len(settings) == 0 {
+		return nil
+	}
+	if err := s.hub.SettingRepo.BatchUpsert(settings); err != nil {
+		return err
+	}
+	s.hub.guard.invalidate()
+	return nil
+}
@@ -0,0 +1,435 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：chat_moderation.go
+ * 创建时间：2026-10-18 07:08:42
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：聊天室消息管理，提供按用户（匿名用户按IP）的定时禁言、消息撤回和编辑，变更通过WebSocket实时推送
+ */
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog-backend/constant"
+	"blog-backend/db"
+	"blog-backend/model"
+	"blog-backend/repository"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 禁言来源
+const (
+	ChatMuteSourceAdmin = "admin" // 管理员禁言
+	ChatMuteSourceAuto  = "auto"  // 违规次数过多自动禁言
+)
+
+// ChatMuteMaxMinutes 管理员禁言的最长时间（分钟），30天
+const ChatMuteMaxMinutes = 30 * 24 * 60
+
+// ErrChatMessageNotFound 聊天消息不存在、已删除或已撤回
+var ErrChatMessageNotFound = errors.New("消息不存在或已撤回")
+
+// chatMuteRecord 禁言记录，以JSON形式存放在禁言标记的值中
+type chatMuteRecord struct {
+	UserID   *uint     `json:"user_id,omitempty"`
+	Username string    `json:"username"`
+	Reason   string    `json:"reason"`
+	Source   string    `json:"source"`
+	MutedAt  time.Time `json:"muted_at"`
+}
+
+// ChatMute 禁言列表中的一条记录
+type ChatMute struct {
+	Identity  string    `json:"identity"` // 发言者身份：user_<用户ID> 或 ip_<IP>
+	UserID    *uint     `json:"user_id,omitempty"`
+	Username  string    `json:"username"`
+	Reason    string    `json:"reason"`
+	Source    string    `json:"source"` // admin / auto
+	MutedAt   time.Time `json:"muted_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ChatMuteRequest 管理员禁言请求，client_id、user_id、ip 三选一
+type ChatMuteRequest struct {
+	ClientID string `json:"client_id"`                         // 在线连接ID（登录用户按用户禁言，匿名用户按IP禁言）
+	UserID   uint   `json:"user_id"`                           // 登录用户ID
+	IP       string `json:"ip"`                                // 匿名用户IP
+	Duration int    `json:"duration" binding:"required,min=1"` // 禁言时长（分钟）
+	Reason   string `json:"reason" binding:"max=200"`
+}
+
+// setChatMute 写入禁言标记，同时删除 clearKeys（如违规计数）
+func setChatMute(identity string, record chatMuteRecord, duration time.Duration, clearKeys ...string) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	pipe := db.RDB.TxPipeline()
+	pipe.Set(ctx, chatMuteKeyPrefix+identity, value, duration)
+	if len(clearKeys) > 0 {
+		pipe.Del(ctx, clearKeys...)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// getChatMute 读取身份的禁言记录，未禁言时返回 nil
+func getChatMute(identity string) (*ChatMute, error) {
+	ctx := context.Background()
+	key := chatMuteKeyPrefix + identity
+
+	pipe := db.RDB.Pipeline()
+	getCmd := pipe.Get(ctx, key)
+	ttlCmd := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if ttlCmd.Val() <= 0 {
+		return nil, nil
+	}
+
+	mute := &ChatMute{Identity: identity, ExpiresAt: time.Now().Add(ttlCmd.Val())}
+	var record chatMuteRecord
+	if err := json.Unmarshal([]byte(getCmd.Val()), &record); err != nil {
+		// 兼容只记录了来源的旧禁言标记
+		record.Source = getCmd.Val()
+	}
+	mute.UserID = record.UserID
+	mute.Username = record.Username
+	mute.Reason = record.Reason
+	mute.Source = record.Source
+	mute.MutedAt = record.MutedAt
+	if mute.UserID == nil && strings.HasPrefix(identity, "user_") {
+		if id, err := strconv.ParseUint(strings.TrimPrefix(identity, "user_"), 10, 32); err == nil {
+			userID := uint(id)
+			mute.UserID = &userID
+		}
+	}
+	return mute, nil
+}
+
+// canManageRole 角色是否具备聊天室管理权限
+func canManageRole(role string) bool {
+	return repository.NewRoleRepository().HasPermission(role, constant.PermChatManage)
+}
+
+// Mute 管理员按身份禁言，禁言期间该用户在所有聊天室都不能发言（包括换昵称或重连的匿名用户）
+func (s *ChatService) Mute(req ChatMuteRequest) (*ChatMute, error) {
+	if req.Duration > ChatMuteMaxMinutes {
+		return nil, fmt.Errorf("禁言时长不能超过 %d 分钟", ChatMuteMaxMinutes)
+	}
+
+	record := chatMuteRecord{
+		Reason:  strings.TrimSpace(req.Reason),
+		Source:  ChatMuteSourceAdmin,
+		MutedAt: time.Now(),
+	}
+	if record.Reason == "" {
+		record.Reason = "违反聊天室规则"
+	}
+
+	var identity string
+	switch {
+	case req.ClientID != "":
+		client := s.hub.LookupClient(req.ClientID)
+		if client == nil {
+			return nil, errors.New("用户不在线")
+		}
+		if canManageRole(client.Role) {
+			return nil, errors.New("不能禁言管理员")
+		}
+		identity = chatIdentity(client.UserID, client.IP)
+		record.UserID = client.UserID
+		record.Username = client.Username
+	case req.UserID > 0:
+		user, err := repository.NewUserRepository().GetByID(req.UserID)
+		if err != nil {
+			return nil, errors.New("用户不存在")
+		}
+		if canManageRole(user.Role) {
+			return nil, errors.New("不能禁言管理员")
+		}
+		identity = chatIdentity(&user.ID, "")
+		record.UserID = &user.ID
+		record.Username = user.Username
+	case strings.TrimSpace(req.IP) != "":
+		identity = chatIdentity(nil, strings.TrimSpace(req.IP))
+	default:
+		return nil, errors.New("请指定要禁言的用户")
+	}
+
+	duration := time.Duration(req.Duration) * time.Minute
+	if err := setChatMute(identity, record, duration, chatViolationKeyPrefix+identity); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(duration)
+	s.hub.notifyMute(identity, &expiresAt, record.Reason)
+	return &ChatMute{
+		Identity:  identity,
+		UserID:    record.UserID,
+		Username:  record.Username,
+		Reason:    record.Reason,
+		Source:    record.Source,
+		MutedAt:   record.MutedAt,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Unmute 解除禁言，同时清空违规计数
+func (s *ChatService) Unmute(identity string) error {
+	ctx := context.Background()
+	found, err := db.RDB.Del(ctx, chatMuteKeyPrefix+identity).Result()
+	if err != nil {
+		return err
+	}
+	if found == 0 {
+		return errors.New("该用户未被禁言")
+	}
+	db.RDB.Del(ctx, chatViolationKeyPrefix+identity)
+	s.hub.notifyMute(identity, nil, "")
+	return nil
+}
+
+// ListMutes 获取所有禁言中的身份（包括自动禁言），按到期时间排序
+func (s *ChatService) ListMutes() ([]ChatMute, error) {
+	ctx := context.Background()
+	mutes := make([]ChatMute, 0)
+
+	iter := db.RDB.Scan(ctx, 0, chatMuteKeyPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		mute, err := getChatMute(strings.TrimPrefix(iter.Val(), chatMuteKeyPrefix))
+		if err != nil {
+			return nil, err
+		}
+		if mute != nil {
+			mutes = append(mutes, *mute)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(mutes, func(i, j int) bool {
+		return mutes[i].ExpiresAt.Before(mutes[j].ExpiresAt)
+	})
+	return mutes, nil
+}
+
+// notifyMute 通知被禁言（expiresAt 为空表示解除禁言）的身份的所有连接，客户端据此禁用或恢复输入框
+func (h *Hub) notifyMute(identity string, expiresAt *time.Time, reason string) {
+	wsMsg := WebSocketMessage{Type: "unmuted", Data: map[string]interface{}{}, Timestamp: time.Now().Unix()}
+	if expiresAt != nil {
+		wsMsg.Type = "muted"
+		wsMsg.Data = map[string]interface{}{
+			"expires_at": expiresAt,
+			"reason":     reason,
+		}
+	}
+
+	data, err := json.Marshal(wsMsg)
+	if err != nil {
+		return
+	}
+	h.PublishToIdentity(identity, data)
+}
+
+// sendMuteState 客户端连接时若仍在禁言期内，发送禁言状态
+func (h *Hub) sendMuteState(client *Client) {
+	if client.CanManage() {
+		return
+	}
+	mute, err := getChatMute(client.identity())
+	if err != nil || mute == nil {
+		return
+	}
+	h.sendTo(client, WebSocketMessage{
+		Type: "muted",
+		Data: map[string]interface{}{
+			"expires_at": mute.ExpiresAt,
+			"reason":     mute.Reason,
+		},
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// publicChatMessage 隐藏已撤回消息的内容和编辑前的原始内容，用于向聊天室用户返回消息
+func publicChatMessage(msg *model.ChatMessage) {
+	if msg.IsRecalled {
+		msg.Content = ""
+	}
+	msg.OriginalContent = ""
+}
+
+// owns 消息是否由该客户端发送：登录用户按用户ID，匿名用户按发送连接
+// 匿名用户没有稳定身份，同一出口IP下可能有多人使用相同昵称，因此只能撤回和编辑本连接发送的消息
+func (c *Client) owns(msg *model.ChatMessage) bool {
+	if c.UserID != nil {
+		return msg.UserID != nil && *msg.UserID == *c.UserID
+	}
+	return msg.UserID == nil && msg.ClientID != "" && msg.ClientID == c.ID
+}
+
+// checkSenderWindow 检查发送者本人是否还能撤回或编辑消息
+func (h *Hub) checkSenderWindow(c *Client, msg *model.ChatMessage, action string) error {
+	if !c.owns(msg) {
+		return fmt.Errorf("只能%s自己的消息", action)
+	}
+	window := h.guard.Settings().RecallWindow
+	if window <= 0 {
+		return fmt.Errorf("当前不允许%s消息", action)
+	}
+	if time.Since(msg.CreatedAt) > time.Duration(window)*time.Second {
+		if window < 60 {
+			return fmt.Errorf("只能%s %d 秒内发送的消息", action, window)
+		}
+		return fmt.Errorf("只能%s %d 分钟内发送的消息", action, window/60)
+	}
+	return nil
+}
+
+// chatMessageOf 获取可撤回或编辑的聊天消息（系统广播只能删除）
+func (h *Hub) chatMessageOf(id uint) (*model.ChatMessage, error) {
+	if id == 0 {
+		return nil, ErrChatMessageNotFound
+	}
+	msg, err := h.Repo.GetByID(id)
+	if err != nil || msg.IsRecalled {
+		return nil, ErrChatMessageNotFound
+	}
+	if msg.IsBroadcast || msg.RoomID == nil {
+		return nil, errors.New("系统广播不能撤回或编辑，请在管理后台删除")
+	}
+	return msg, nil
+}
+
+// recallByClient 处理客户端的撤回请求：发送者可撤回时限内的消息，管理员可撤回任意消息
+func (h *Hub) recallByClient(c *Client, id uint) error {
+	msg, err := h.chatMessageOf(id)
+	if err != nil {
+		return err
+	}
+
+	recalledBy := model.ChatRecalledBySender
+	if !c.CanManage() {
+		if err := h.checkSenderWindow(c, msg, "撤回"); err != nil {
+			return err
+		}
+	} else if !c.owns(msg) {
+		recalledBy = model.ChatRecalledByAdmin
+		log.Printf("管理员 %s 撤回了聊天消息 %d（发送者：%s）", c.Username, msg.ID, msg.Username)
+	}
+	return h.recall(msg, recalledBy)
+}
+
+// editByClient 处理客户端的编辑请求：仅发送者本人可在时限内编辑，新内容同样经过防刷与内容过滤
+func (h *Hub) editByClient(c *Client, id uint, content string) error {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return errors.New("消息内容不能为空")
+	}
+
+	msg, err := h.chatMessageOf(id)
+	if err != nil {
+		return err
+	}
+	if err := h.checkSenderWindow(c, msg, "编辑"); err != nil {
+		return err
+	}
+
+	room, err := h.RoomRepo.GetByID(*msg.RoomID)
+	if err != nil || room.IsArchived {
+		return errors.New("该聊天室已归档，无法编辑消息")
+	}
+	if !c.CanManage() && (room.IsLocked || h.IsChatMuted()) {
+		return errors.New("当前只有管理员可发言，无法编辑消息")
+	}
+
+	content, err = h.guard.Check(c, content)
+	if err != nil {
+		return err
+	}
+	if content == msg.Content {
+		return nil
+	}
+
+	editedAt := time.Now()
+	ok, err := h.Repo.Edit(msg.ID, content, editedAt)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrChatMessageNotFound
+	}
+
+	h.publishMessageEvent(*msg.RoomID, "message_edited", map[string]interface{}{
+		"id":        msg.ID,
+		"room_id":   msg.RoomID,
+		"content":   content,
+		"edited_at": editedAt,
+	})
+	return nil
+}
+
+// recall 撤回消息并通知聊天室成员
+func (h *Hub) recall(msg *model.ChatMessage, recalledBy string) error {
+	recalledAt := time.Now()
+	ok, err := h.Repo.Recall(msg.ID, recalledBy, recalledAt)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrChatMessageNotFound
+	}
+
+	h.publishMessageEvent(*msg.RoomID, "message_recalled", map[string]interface{}{
+		"id":          msg.ID,
+		"room_id":     msg.RoomID,
+		"recalled_by": recalledBy,
+		"recalled_at": recalledAt,
+	})
+	return nil
+}
+
+// publishMessageEvent 向聊天室成员推送消息变更事件
+func (h *Hub) publishMessageEvent(roomID uint, eventType string, data map[string]interface{}) {
+	payload, err := json.Marshal(WebSocketMessage{
+		Type:      eventType,
+		Data:      data,
+		RoomID:    roomID,
+		Timestamp: time.Now().Unix(),
+	})
+	if err != nil {
+		return
+	}
+	h.PublishToRoom(roomID, payload)
+}
+
+// RecallMessage 管理员撤回消息（不受撤回时限限制），返回被撤回的消息
+func (s *ChatService) RecallMessage(id uint) (*model.ChatMessage, error) {
+	msg, err := s.hub.chatMessageOf(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.hub.recall(msg, model.ChatRecalledByAdmin); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
@@ -0,0 +1,159 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：chat_room.go
+ * 创建时间：2026-10-18 06:04:52
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：聊天室管理业务逻辑层，提供聊天室列表、文章讨论室、创建聊天室以及锁定、归档等功能
+ */
+package service
+
+import (
+	"errors"
+
+	"blog-backend/model"
+	"blog-backend/repository"
+)
+
+// ErrChatRoomNotFound 聊天室不存在（或无权访问）
+var ErrChatRoomNotFound = errors.New("聊天室不存在")
+
+// ChatRoomService 聊天室管理业务逻辑层结构体
+type ChatRoomService struct {
+	repo     *repository.ChatRoomRepository
+	postRepo *repository.PostRepository
+	hub      *Hub
+}
+
+// NewChatRoomService 创建聊天室管理业务逻辑层实例
+func NewChatRoomService(hub *Hub) *ChatRoomService {
+	return &ChatRoomService{
+		repo:     repository.NewChatRoomRepository(),
+		postRepo: repository.NewPostRepository(),
+		hub:      hub,
+	}
+}
+
+// CreateChatRoomRequest 创建聊天室请求
+type CreateChatRoomRequest struct {
+	Name        string `json:"name" binding:"required,max=50"`
+	Type        string `json:"type" binding:"omitempty,oneof=public admin"` // 默认 public
+	Description string `json:"description" binding:"max=200"`
+}
+
+// List 获取可进入的聊天室列表（不含文章讨论室），管理员专用聊天室仅对管理员可见
+func (s *ChatRoomService) List(canManage bool) ([]model.ChatRoom, error) {
+	return s.repo.List(false, canManage, false)
+}
+
+// AdminList 获取全部聊天室（包含已归档和文章讨论室）
+func (s *ChatRoomService) AdminList() ([]model.ChatRoom, error) {
+	return s.repo.List(true, true, true)
+}
+
+// Get 获取聊天室，无权访问的管理员专用聊天室视为不存在
+func (s *ChatRoomService) Get(id uint, canManage bool) (*model.ChatRoom, error) {
+	room, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, ErrChatRoomNotFound
+	}
+	if room.Type == model.ChatRoomTypeAdmin && !canManage {
+		return nil, ErrChatRoomNotFound
+	}
+	if !canManage && !postRoomVisible(s.postRepo, room) {
+		return nil, ErrChatRoomNotFound
+	}
+	return room, nil
+}
+
+// GetLobby 获取公共大厅
+func (s *ChatRoomService) GetLobby() (*model.ChatRoom, error) {
+	room, err := s.repo.GetLobby()
+	if err != nil {
+		return nil, ErrChatRoomNotFound
+	}
+	return room, nil
+}
+
+// GetPostRoom 获取文章讨论室，首次访问时自动创建，仅已发布的公开文章可用
+func (s *ChatRoomService) GetPostRoom(postID uint) (*model.ChatRoom, error) {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil || post.Status != 1 || post.Visibility != 1 {
+		return nil, errors.New("文章不存在或未公开")
+	}
+	return s.repo.GetOrCreatePostRoom(post)
+}
+
+// postRoomVisible 文章讨论室对应的文章是否仍为公开的已发布文章，其他类型的聊天室始终返回 true
+// 文章下线、转为私密或被删除后，讨论室随之对普通用户不可见
+func postRoomVisible(postRepo *repository.PostRepository, room *model.ChatRoom) bool {
+	if room.Type != model.ChatRoomTypePost {
+		return true
+	}
+	if room.PostID == nil {
+		return false
+	}
+	public, err := postRepo.IsPublic(*room.PostID)
+	return err == nil && public
+}
+
+// Create 创建聊天室
+func (s *ChatRoomService) Create(creatorID uint, req *CreateChatRoomRequest) (*model.ChatRoom, error) {
+	roomType := req.Type
+	if roomType == "" {
+		roomType = model.ChatRoomTypePublic
+	}
+
+	room := &model.ChatRoom{
+		Name:        req.Name,
+		Type:        roomType,
+		Description: req.Description,
+		CreatedBy:   &creatorID,
+	}
+	if err := s.repo.Create(room); err != nil {
+		return nil, err
+	}
+
+	s.hub.NotifyRoomUpdated(room)
+	return room, nil
+}
+
+// SetLocked 锁定或解锁聊天室，锁定后仅管理员可发言
+func (s *ChatRoomService) SetLocked(id uint, locked bool) (*model.ChatRoom, error) {
+	found, err := s.repo.SetLocked(id, locked)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrChatRoomNotFound
+	}
+	return s.reload(id)
+}
+
+// SetArchived 归档或恢复聊天室，归档后不可进入和发言，公共大厅不能归档
+func (s *ChatRoomService) SetArchived(id uint, archived bool) (*model.ChatRoom, error) {
+	room, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, ErrChatRoomNotFound
+	}
+	if archived && room.Type == model.ChatRoomTypeLobby {
+		return nil, errors.New("公共大厅不能归档")
+	}
+
+	if _, err := s.repo.SetArchived(id, archived); err != nil {
+		return nil, err
+	}
+	return s.reload(id)
+}
+
+// reload 重新读取聊天室并通知在线客户端
+func (s *ChatRoomService) reload(id uint) (*model.ChatRoom, error) {
+	room, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	s.hub.NotifyRoomUpdated(room)
+	return room, nil
+}
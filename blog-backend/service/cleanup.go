@@ -19,19 +19,23 @@ import (
 
 // CleanupService 清理任务业务逻辑层结构体
 type CleanupService struct {
-	resetTokenRepo *repository.PasswordResetRepository
+	resetTokenRepo   *repository.PasswordResetRepository
+	sessionRepo      *repository.SessionRepository
+	loginHistoryRepo *repository.LoginHistoryRepository
 }
 
 // NewCleanupService 创建清理任务业务逻辑层实例
 func NewCleanupService() *CleanupService {
 	return &CleanupService{
-		resetTokenRepo: repository.NewPasswordResetRepository(),
+		resetTokenRepo:   repository.NewPasswordResetRepository(),
+		sessionRepo:      repository.NewSessionRepository(),
+		loginHistoryRepo: repository.NewLoginHistoryRepository(),
 	}
 }
 
 // StartCleanupTasks 启动定期清理任务
 func (s *CleanupService) StartCleanupTasks() {
-	// 每小时清理一次过期的密码重置令牌
+	// 每小时清理一次过期的密码重置令牌、刷新令牌、登录会话和登录记录
 	go s.cleanupExpiredTokensPeriodically(1 * time.Hour)
 }
 
@@ -56,4 +60,12 @@ func (s *CleanupService) cleanupExpiredTokens() {
 	} else {
 		fmt.Printf("过期令牌清理完成: %s\n", time.Now().Format("2006-01-02 15:04:05"))
 	}
+
+	if err := s.sessionRepo.DeleteExpired(); err != nil {
+		fmt.Printf("清理过期会话失败: %v\n", err)
+	}
+
+	if err := s.loginHistoryRepo.DeleteBefore(time.Now().AddDate(0, 0, -loginHistoryRetentionDays)); err != nil {
+		fmt.Printf("清理过期登录记录失败: %v\n", err)
+	}
 }
@@ -359,7 +359,7 @@ func (s *CommentService) Update(id, userID uint, role string, req *UpdateComment
 	}
 
 	// 权限检查：只有作者和具备管理员权限的用户可以修改
-	if comment.UserID != userID && !constant.IsAdminRole(role) {
+	if comment.UserID != userID && !repository.NewRoleRepository().HasPermission(role, constant.PermCommentModerate) {
 		return nil, errors.New("无权限修改此评论")
 	}
 
@@ -382,7 +382,7 @@ func (s *CommentService) Delete(id, userID uint, role string) error {
 	}
 
 	// 权限检查：只有作者和具备管理员权限的用户可以删除
-	if comment.UserID != userID && !constant.IsAdminRole(role) {
+	if comment.UserID != userID && !repository.NewRoleRepository().HasPermission(role, constant.PermCommentModerate) {
 		return errors.New("无权限删除此评论")
 	}
 
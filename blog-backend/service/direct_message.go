@@ -0,0 +1,185 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：direct_message.go
+ * 创建时间：2026-10-18 06:21:08
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：私信业务逻辑层，提供登录用户之间的一对一私信、已读回执、未读计数和屏蔽功能，对方在线时通过WebSocket实时推送
+ */
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"blog-backend/model"
+	"blog-backend/repository"
+)
+
+// ErrDirectPeerNotFound 私信对象不存在或已被禁用
+var ErrDirectPeerNotFound = errors.New("用户不存在")
+
+// DirectMessageService 私信业务逻辑层结构体
+type DirectMessageService struct {
+	repo *repository.DirectMessageRepository
+	hub  *Hub
+}
+
+// NewDirectMessageService 创建私信业务逻辑层实例
+func NewDirectMessageService(hub *Hub) *DirectMessageService {
+	return &DirectMessageService{
+		repo: repository.NewDirectMessageRepository(),
+		hub:  hub,
+	}
+}
+
+// SendDirectMessageRequest 发送私信请求
+type SendDirectMessageRequest struct {
+	Content string `json:"content" binding:"required,max=1000"`
+}
+
+// DirectMessageEvent 通过WebSocket推送的私信，附带发送者资料便于客户端直接展示
+type DirectMessageEvent struct {
+	model.DirectMessage
+	Sender *repository.DirectPeer `json:"sender"`
+}
+
+// Contacts 获取可直接联系的站长
+func (s *DirectMessageService) Contacts() ([]repository.DirectPeer, error) {
+	return s.repo.ListContacts()
+}
+
+// Conversations 分页获取会话列表
+func (s *DirectMessageService) Conversations(userID uint, page, pageSize int) ([]repository.DirectConversation, int64, error) {
+	return s.repo.ListConversations(userID, page, pageSize)
+}
+
+// Messages 分页获取与某个用户的私信记录（按发送时间倒序），并返回对方资料
+func (s *DirectMessageService) Messages(userID, peerID uint, page, pageSize int) ([]model.DirectMessage, int64, *repository.DirectPeer, error) {
+	peer, err := s.repo.GetPeer(peerID)
+	if err != nil {
+		return nil, 0, nil, ErrDirectPeerNotFound
+	}
+
+	messages, total, err := s.repo.ListMessages(userID, peerID, page, pageSize)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return messages, total, peer, nil
+}
+
+// Send 发送私信，保存后推送给双方在线的连接（发送方的其他设备也会同步）
+func (s *DirectMessageService) Send(senderID, receiverID uint, content string) (*model.DirectMessage, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, errors.New("消息内容不能为空")
+	}
+	if senderID == receiverID {
+		return nil, errors.New("不能给自己发送私信")
+	}
+
+	if _, err := s.repo.GetPeer(receiverID); err != nil {
+		return nil, ErrDirectPeerNotFound
+	}
+	sender, err := s.repo.GetPeer(senderID)
+	if err != nil {
+		return nil, errors.New("当前账号不可用")
+	}
+
+	blocked, err := s.repo.IsBlocked(senderID, receiverID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, errors.New("你已屏蔽对方，请先取消屏蔽")
+	}
+	if blocked, err = s.repo.IsBlocked(receiverID, senderID); err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, errors.New("对方已屏蔽你，无法发送私信")
+	}
+
+	msg := &model.DirectMessage{
+		SenderID:   senderID,
+		ReceiverID: receiverID,
+		Content:    content,
+	}
+	if err := s.repo.Create(msg); err != nil {
+		return nil, err
+	}
+
+	s.push(WebSocketMessage{
+		Type:      "direct_message",
+		Data:      DirectMessageEvent{DirectMessage: *msg, Sender: sender},
+		Timestamp: time.Now().Unix(),
+	}, receiverID, senderID)
+	return msg, nil
+}
+
+// MarkRead 将与某个用户的会话标记为已读，并通知对方（已读回执）和自己的其他设备
+func (s *DirectMessageService) MarkRead(userID, peerID uint) (int64, error) {
+	count, err := s.repo.MarkRead(userID, peerID)
+	if err != nil || count == 0 {
+		return count, err
+	}
+
+	s.push(WebSocketMessage{
+		Type: "direct_read",
+		Data: map[string]interface{}{
+			"reader_id": userID,
+			"peer_id":   peerID,
+			"count":     count,
+		},
+		Timestamp: time.Now().Unix(),
+	}, userID, peerID)
+	return count, nil
+}
+
+// UnreadCount 获取未读私信总数
+func (s *DirectMessageService) UnreadCount(userID uint) (int64, error) {
+	return s.repo.CountUnread(userID)
+}
+
+// Block 屏蔽用户，屏蔽后对方不能再给自己发送私信
+func (s *DirectMessageService) Block(userID, blockedID uint) error {
+	if userID == blockedID {
+		return errors.New("不能屏蔽自己")
+	}
+	if _, err := s.repo.GetPeer(blockedID); err != nil {
+		return ErrDirectPeerNotFound
+	}
+	return s.repo.Block(userID, blockedID)
+}
+
+// Unblock 取消屏蔽
+func (s *DirectMessageService) Unblock(userID, blockedID uint) error {
+	found, err := s.repo.Unblock(userID, blockedID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New("未屏蔽该用户")
+	}
+	return nil
+}
+
+// BlockedUsers 获取屏蔽列表
+func (s *DirectMessageService) BlockedUsers(userID uint) ([]repository.BlockedUser, error) {
+	return s.repo.ListBlocked(userID)
+}
+
+// push 将事件推送给指定用户在所有实例上的连接
+func (s *DirectMessageService) push(wsMsg WebSocketMessage, userIDs ...uint) {
+	data, err := json.Marshal(wsMsg)
+	if err != nil {
+		return
+	}
+	for _, userID := range userIDs {
+		s.hub.PublishToUser(userID, data)
+	}
+}
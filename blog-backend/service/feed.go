@@ -0,0 +1,517 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：feed.go
+ * 创建时间：2026-10-17 16:28:14
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：订阅源业务逻辑层，基于公开的已发布文章生成 RSS 2.0、Atom 和 JSON Feed，支持按分类/标签输出，使用Redis缓存
+ */
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"blog-backend/db"
+	"blog-backend/model"
+	"blog-backend/repository"
+	"blog-backend/util"
+
+	"gorm.io/gorm"
+)
+
+// 订阅源格式
+const (
+	FeedFormatRSS  = "rss"
+	FeedFormatAtom = "atom"
+	FeedFormatJSON = "json"
+)
+
+const (
+	// feedItemLimit 订阅源输出的文章数量
+	feedItemLimit = 20
+	// feedCacheTTL 订阅源缓存时间（文章变更时会主动清理）
+	feedCacheTTL = time.Hour
+	// feedSummaryLength 文章无摘要时自动截取的长度
+	feedSummaryLength = 200
+)
+
+// 订阅范围不存在时返回的错误
+var (
+	ErrFeedCategoryNotFound = errors.New("分类不存在")
+	ErrFeedTagNotFound      = errors.New("标签不存在")
+)
+
+// FeedScope 订阅源范围，均为0时输出全站文章
+type FeedScope struct {
+	CategoryID uint
+	TagID      uint
+}
+
+// FeedResult 生成的订阅源
+type FeedResult struct {
+	Body         []byte    `json:"body"`
+	ContentType  string    `json:"content_type"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// FeedService 订阅源业务逻辑层结构体
+type FeedService struct {
+	postRepo     *repository.PostRepository
+	categoryRepo *repository.CategoryRepository
+	tagRepo      *repository.TagRepository
+	settingRepo  *repository.SettingRepository
+}
+
+// NewFeedService 创建订阅源业务逻辑层实例
+func NewFeedService() *FeedService {
+	return &FeedService{
+		postRepo:     repository.NewPostRepository(),
+		categoryRepo: repository.NewCategoryRepository(),
+		tagRepo:      repository.NewTagRepository(),
+		settingRepo:  repository.NewSettingRepository(),
+	}
+}
+
+// feedMeta 订阅源元信息
+type feedMeta struct {
+	Title       string
+	Description string
+	Link        string // 对应的网页地址
+	SelfURL     string // 订阅源自身地址
+	Updated     time.Time
+}
+
+// feedItem 订阅源条目（与具体格式无关）
+type feedItem struct {
+	Title      string
+	Link       string
+	Summary    string // 纯文本摘要
+	Content    string // 渲染后的 HTML 全文（仅全文模式）
+	Image      string
+	Author     string
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+}
+
+// Build 生成订阅源，优先读取缓存
+// 参数:
+//   - format: 订阅源格式（rss/atom/json）
+//   - scope: 订阅范围（分类/标签）
+//   - selfPath: 订阅源的请求路径，用于生成自引用链接
+//
+// 返回:
+//   - *FeedResult: 订阅源内容及缓存校验信息
+//   - error: 分类/标签不存在或生成失败时返回错误
+func (s *FeedService) Build(format string, scope FeedScope, selfPath string) (*FeedResult, error) {
+	ctx := context.Background()
+	cacheKey := fmt.Sprintf("feed:%s:c%d:t%d", format, scope.CategoryID, scope.TagID)
+	if cached, err := db.RDB.Get(ctx, cacheKey).Bytes(); err == nil {
+		var result FeedResult
+		if err := json.Unmarshal(cached, &result); err == nil {
+			return &result, nil
+		}
+	}
+
+	site := loadSiteInfo(s.settingRepo)
+	meta := &feedMeta{
+		Title:       site["site_name"],
+		Description: site["site_description"],
+		Link:        site["site_url"],
+		SelfURL:     site["site_url"] + selfPath,
+	}
+	if meta.Description == "" {
+		meta.Description = meta.Title
+	}
+
+	if scope.CategoryID > 0 {
+		category, err := s.categoryRepo.GetByID(scope.CategoryID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrFeedCategoryNotFound
+			}
+			return nil, err
+		}
+		meta.Title = fmt.Sprintf("%s - 分类：%s", meta.Title, category.Name)
+		meta.Link = fmt.Sprintf("%s/category/%d", site["site_url"], category.ID)
+	}
+	if scope.TagID > 0 {
+		tag, err := s.tagRepo.GetByID(scope.TagID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrFeedTagNotFound
+			}
+			return nil, err
+		}
+		meta.Title = fmt.Sprintf("%s - 标签：%s", meta.Title, tag.Name)
+		meta.Link = fmt.Sprintf("%s/tag/%d", site["site_url"], tag.ID)
+	}
+
+	// 只输出公开的已发布文章
+	visibility := 1
+	posts, _, err := s.postRepo.List(1, feedItemLimit, scope.CategoryID, scope.TagID, "", 1, &visibility)
+	if err != nil {
+		return nil, err
+	}
+
+	fullContent := site["feed_content"] == "full"
+	items := make([]feedItem, 0, len(posts))
+	for i := range posts {
+		items = append(items, s.buildItem(&posts[i], site["site_url"], fullContent))
+	}
+	// List 会把置顶文章排在前面，订阅源按发布时间倒序输出
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Published.After(items[j].Published)
+	})
+
+	for _, item := range items {
+		if item.Updated.After(meta.Updated) {
+			meta.Updated = item.Updated
+		}
+	}
+	if meta.Updated.IsZero() {
+		meta.Updated = time.Now()
+	}
+	meta.Updated = meta.Updated.UTC().Truncate(time.Second)
+
+	var body []byte
+	var contentType string
+	switch format {
+	case FeedFormatRSS:
+		body, err = renderRSS(meta, items)
+		contentType = "application/rss+xml; charset=utf-8"
+	case FeedFormatAtom:
+		body, err = renderAtom(meta, items)
+		contentType = "application/atom+xml; charset=utf-8"
+	case FeedFormatJSON:
+		body, err = renderJSONFeed(meta, items)
+		contentType = "application/feed+json; charset=utf-8"
+	default:
+		return nil, errors.New("不支持的订阅格式")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(body)
+	result := &FeedResult{
+		Body:         body,
+		ContentType:  contentType,
+		ETag:         `"` + hex.EncodeToString(sum[:16]) + `"`,
+		LastModified: meta.Updated,
+	}
+
+	if data, err := json.Marshal(result); err == nil {
+		db.RDB.Set(ctx, cacheKey, data, feedCacheTTL)
+	}
+
+	return result, nil
+}
+
+// buildItem 将文章转换为订阅源条目
+func (s *FeedService) buildItem(post *model.Post, siteURL string, fullContent bool) feedItem {
+	item := feedItem{
+		Title:     post.Title,
+		Link:      siteURL + "/post/" + postPath(post),
+		Summary:   post.Summary,
+		Image:     post.Cover,
+		Author:    post.User.Nickname,
+		Published: post.CreatedAt,
+		Updated:   post.UpdatedAt,
+	}
+	if post.PublishedAt != nil {
+		item.Published = *post.PublishedAt
+	}
+	if item.Updated.Before(item.Published) {
+		item.Updated = item.Published
+	}
+	if item.Author == "" {
+		item.Author = post.User.Username
+	}
+	if item.Summary == "" {
+		// 先渲染再去除标签，避免摘要中出现 Markdown 语法
+		runes := []rune(util.StripHTML(util.RenderMarkdown(post.Content)))
+		if len(runes) > feedSummaryLength {
+			runes = append(runes[:feedSummaryLength], []rune("...")...)
+		}
+		item.Summary = string(runes)
+	}
+	if fullContent {
+		item.Content = util.RenderMarkdown(post.Content)
+	}
+
+	if post.Category.Name != "" {
+		item.Categories = append(item.Categories, post.Category.Name)
+	}
+	for _, tag := range post.Tags {
+		item.Categories = append(item.Categories, tag.Name)
+	}
+	return item
+}
+
+// ==================== RSS 2.0 ====================
+
+type rssFeed struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	DCNS      string     `xml:"xmlns:dc,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string      `xml:"title"`
+	Link          string      `xml:"link"`
+	Description   string      `xml:"description"`
+	Language      string      `xml:"language"`
+	LastBuildDate string      `xml:"lastBuildDate"`
+	Generator     string      `xml:"generator"`
+	AtomLink      rssAtomLink `xml:"atom:link"`
+	Items         []rssItem   `xml:"item"`
+}
+
+type rssAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	GUID        rssGUID   `xml:"guid"`
+	Description string    `xml:"description"`
+	Content     *rssCDATA `xml:"content:encoded,omitempty"`
+	Creator     string    `xml:"dc:creator,omitempty"`
+	Categories  []string  `xml:"category"`
+	PubDate     string    `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type rssCDATA struct {
+	Value string `xml:",cdata"`
+}
+
+// renderRSS 生成 RSS 2.0
+// 全文模式下 description 输出摘要，content:encoded 输出完整 HTML
+func renderRSS(meta *feedMeta, items []feedItem) ([]byte, error) {
+	feed := rssFeed{
+		Version:   "2.0",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		DCNS:      "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:         meta.Title,
+			Link:          meta.Link,
+			Description:   meta.Description,
+			Language:      "zh-CN",
+			LastBuildDate: meta.Updated.Format(time.RFC1123Z),
+			Generator:     "blog-backend",
+			AtomLink:      rssAtomLink{Href: meta.SelfURL, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	for _, item := range items {
+		ri := rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{Value: item.Link, IsPermaLink: true},
+			Description: item.Summary,
+			Creator:     item.Author,
+			Categories:  item.Categories,
+			PubDate:     item.Published.Format(time.RFC1123Z),
+		}
+		if item.Content != "" {
+			ri.Content = &rssCDATA{Value: item.Content}
+		}
+		feed.Channel.Items = append(feed.Channel.Items, ri)
+	}
+	return marshalXML(feed)
+}
+
+// ==================== Atom ====================
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Links      []atomLink     `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     *atomAuthor    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// renderAtom 生成 Atom 1.0
+func renderAtom(meta *feedMeta, items []feedItem) ([]byte, error) {
+	feed := atomFeed{
+		Title:    meta.Title,
+		Subtitle: meta.Description,
+		ID:       meta.SelfURL,
+		Updated:  meta.Updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: meta.Link, Rel: "alternate", Type: "text/html"},
+			{Href: meta.SelfURL, Rel: "self", Type: "application/atom+xml"},
+		},
+	}
+	for _, item := range items {
+		entry := atomEntry{
+			Title:     item.Title,
+			ID:        item.Link,
+			Links:     []atomLink{{Href: item.Link, Rel: "alternate", Type: "text/html"}},
+			Published: item.Published.Format(time.RFC3339),
+			Updated:   item.Updated.Format(time.RFC3339),
+			Summary:   &atomText{Type: "text", Value: item.Summary},
+		}
+		if item.Author != "" {
+			entry.Author = &atomAuthor{Name: item.Author}
+		}
+		for _, name := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: name})
+		}
+		if item.Content != "" {
+			entry.Content = &atomText{Type: "html", Value: item.Content}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return marshalXML(feed)
+}
+
+// marshalXML 序列化 XML 并加上声明头
+func marshalXML(v interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// ==================== JSON Feed 1.1 ====================
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description,omitempty"`
+	Language    string         `json:"language"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	Title         string           `json:"title"`
+	ContentHTML   string           `json:"content_html,omitempty"`
+	ContentText   string           `json:"content_text,omitempty"`
+	Summary       string           `json:"summary,omitempty"`
+	Image         string           `json:"image,omitempty"`
+	DatePublished string           `json:"date_published"`
+	DateModified  string           `json:"date_modified"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+// renderJSONFeed 生成 JSON Feed 1.1
+// 规范要求 content_html 和 content_text 至少有一个，摘要模式下使用 content_text
+func renderJSONFeed(meta *feedMeta, items []feedItem) ([]byte, error) {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       meta.Title,
+		HomePageURL: meta.Link,
+		FeedURL:     meta.SelfURL,
+		Description: meta.Description,
+		Language:    "zh-CN",
+		Items:       make([]jsonFeedItem, 0, len(items)),
+	}
+	for _, item := range items {
+		fi := jsonFeedItem{
+			ID:            item.Link,
+			URL:           item.Link,
+			Title:         item.Title,
+			Summary:       item.Summary,
+			Image:         item.Image,
+			DatePublished: item.Published.Format(time.RFC3339),
+			DateModified:  item.Updated.Format(time.RFC3339),
+			Tags:          item.Categories,
+		}
+		if item.Content != "" {
+			fi.ContentHTML = item.Content
+		} else {
+			fi.ContentText = item.Summary
+		}
+		if item.Author != "" {
+			fi.Authors = []jsonFeedAuthor{{Name: item.Author}}
+		}
+		feed.Items = append(feed.Items, fi)
+	}
+
+	var sb strings.Builder
+	enc := json.NewEncoder(&sb)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(feed); err != nil {
+		return nil, err
+	}
+	return []byte(sb.String()), nil
+}
+
+// clearFeedCaches 清理所有订阅源缓存
+// 文章变更（经由 clearPostCaches）以及网站配置变更时调用
+func clearFeedCaches() {
+	ctx := context.Background()
+	iter := db.RDB.Scan(ctx, 0, "feed:*", 100).Iterator()
+	for iter.Next(ctx) {
+		db.RDB.Del(ctx, iter.Val())
+	}
+}
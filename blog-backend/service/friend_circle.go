@@ -0,0 +1,405 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：friend_circle.go
+ * 创建时间：2026-10-17 18:58:45
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：朋友圈业务逻辑层，定期抓取已启用友链的 RSS/Atom 订阅并去重入库，记录每个订阅的错误次数并做指数退避，
+ *          对外提供朋友圈时间线查询
+ */
+package service
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"blog-backend/config"
+	"blog-backend/db"
+	"blog-backend/logger"
+	"blog-backend/model"
+	"blog-backend/repository"
+	"blog-backend/util"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// friendCircleLockKey 朋友圈抓取分布式锁键，避免多个实例同时抓取
+	friendCircleLockKey = "friend_circle:fetch:lock"
+	// friendCircleMaxFeedSize 单个订阅响应的最大字节数
+	friendCircleMaxFeedSize = 5 << 20
+	// friendCircleMaxBackoff 失败退避的最长间隔
+	friendCircleMaxBackoff = 24 * time.Hour
+	// friendCircleConcurrency 并发抓取的订阅数量
+	friendCircleConcurrency = 4
+	// friendCircleSummaryLength 摘要最大长度
+	friendCircleSummaryLength = 200
+	// friendCircleUserAgent 抓取时使用的 User-Agent
+	friendCircleUserAgent = "Mozilla/5.0 (compatible; blog-backend friend-circle)"
+)
+
+// FriendCircleService 朋友圈业务逻辑层结构体
+type FriendCircleService struct {
+	friendLinkRepo *repository.FriendLinkRepository
+	feedRepo       *repository.FriendFeedRepository
+	client         *http.Client
+	interval       time.Duration // 正常抓取间隔
+	maxItems       int           // 每个友链保留的文章数
+}
+
+// NewFriendCircleService 创建朋友圈业务逻辑层实例
+func NewFriendCircleService() *FriendCircleService {
+	interval, timeout, maxItems := 30, 15, 20
+	if config.Cfg != nil {
+		if config.Cfg.FriendCircle.IntervalMinutes > 0 {
+			interval = config.Cfg.FriendCircle.IntervalMinutes
+		}
+		if config.Cfg.FriendCircle.TimeoutSeconds > 0 {
+			timeout = config.Cfg.FriendCircle.TimeoutSeconds
+		}
+		if config.Cfg.FriendCircle.MaxItemsPerFeed > 0 {
+			maxItems = config.Cfg.FriendCircle.MaxItemsPerFeed
+		}
+	}
+
+	return &FriendCircleService{
+		friendLinkRepo: repository.NewFriendLinkRepository(),
+		feedRepo:       repository.NewFriendFeedRepository(),
+		client:         util.NewSafeHTTPClient(time.Duration(timeout) * time.Second),
+		interval:       time.Duration(interval) * time.Minute,
+		maxItems:       maxItems,
+	}
+}
+
+// SetHTTPClient 替换抓取订阅使用的 HTTP 客户端（用于自定义代理、超时或对接本地测试服务器）
+// 默认客户端禁止访问内网地址，替换后由调用方自行负责
+func (s *FriendCircleService) SetHTTPClient(client *http.Client) {
+	s.client = client
+}
+
+// FetchResult 单个订阅的抓取结果
+type FetchResult struct {
+	Entries      []util.FeedEntry // 解析出的条目（未修改时为空）
+	NotModified  bool             // 服务器返回 304
+	ETag         string           // 响应的 ETag
+	LastModified string           // 响应的 Last-Modified
+}
+
+// FetchSummary 一轮抓取的汇总
+type FetchSummary struct {
+	Feeds   int `json:"feeds"`   // 本轮抓取的订阅数
+	Skipped int `json:"skipped"` // 处于退避期而跳过的订阅数
+	Failed  int `json:"failed"`  // 抓取失败的订阅数
+	Added   int `json:"added"`   // 新增的文章数
+}
+
+// StartFetcher 启动朋友圈定时抓取任务
+func (s *FriendCircleService) StartFetcher() {
+	go s.fetchPeriodically()
+}
+
+// fetchPeriodically 定期抓取订阅
+// 调度周期取抓取间隔和 5 分钟中的较小值，每个订阅是否到期由 next_fetch_at 决定
+func (s *FriendCircleService) fetchPeriodically() {
+	tick := s.interval
+	if tick > 5*time.Minute {
+		tick = 5 * time.Minute
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	s.runOnce(tick)
+	for range ticker.C {
+		s.runOnce(tick)
+	}
+}
+
+// runOnce 获取分布式锁后执行一轮抓取
+func (s *FriendCircleService) runOnce(tick time.Duration) {
+	ctx := context.Background()
+	token := uuid.NewString()
+
+	// 锁的过期时间略短于执行间隔，实例异常退出时下一轮可以被其他实例接管
+	ok, err := db.RDB.SetNX(ctx, friendCircleLockKey, token, tick-5*time.Second).Result()
+	if err != nil || !ok {
+		return
+	}
+	defer db.RDB.Eval(ctx, releaseLockScript, []string{friendCircleLockKey}, token)
+
+	summary, err := s.FetchAll(false)
+	if err != nil {
+		logger.Error(fmt.Sprintf("朋友圈抓取失败: %v", err))
+		return
+	}
+	if summary.Feeds > 0 {
+		logger.Info(fmt.Sprintf("朋友圈抓取完成：订阅 %d 个，失败 %d 个，新增文章 %d 篇", summary.Feeds, summary.Failed, summary.Added))
+	}
+}
+
+// FetchAll 抓取所有到期的订阅
+// 参数:
+//   - force: 为 true 时忽略退避时间，立即抓取全部订阅
+//
+// 返回:
+//   - *FetchSummary: 抓取汇总
+//   - error: 查询友链或状态失败时返回错误
+func (s *FriendCircleService) FetchAll(force bool) (*FetchSummary, error) {
+	links, err := s.friendLinkRepo.ListWithFeed()
+	if err != nil {
+		return nil, err
+	}
+	states, err := s.feedRepo.ListStates()
+	if err != nil {
+		return nil, err
+	}
+	stateMap := make(map[uint]*model.FriendFeedState, len(states))
+	for i := range states {
+		stateMap[states[i].FriendLinkID] = &states[i]
+	}
+
+	summary := &FetchSummary{}
+	now := time.Now()
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, friendCircleConcurrency)
+
+	for i := range links {
+		link := &links[i]
+		state := stateMap[link.ID]
+		// 订阅地址变更后重置状态（ETag、错误次数等都属于旧地址）
+		if state == nil || state.FeedURL != link.AtomURL {
+			state = &model.FriendFeedState{FriendLinkID: link.ID, FeedURL: link.AtomURL}
+		}
+		if !force && state.NextFetchAt.After(now) {
+			summary.Skipped++
+			continue
+		}
+
+		summary.Feeds++
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			added, err := s.FetchLink(link, state)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				summary.Failed++
+				return
+			}
+			summary.Added += added
+		}()
+	}
+	wg.Wait()
+
+	return summary, nil
+}
+
+// FetchLink 抓取单个友链的订阅，写入新文章并更新抓取状态
+// 返回:
+//   - int: 新增的文章数
+//   - error: 抓取或解析失败时返回错误（错误已记录到抓取状态中）
+func (s *FriendCircleService) FetchLink(link *model.FriendLink, state *model.FriendFeedState) (int, error) {
+	now := time.Now()
+	state.FriendLinkID = link.ID
+	state.FeedURL = link.AtomURL
+	state.LastFetchedAt = &now
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.client.Timeout+5*time.Second)
+	defer cancel()
+
+	result, err := s.FetchFeed(ctx, link.AtomURL, state.ETag, state.LastModified)
+	if err != nil {
+		s.recordFailure(state, err)
+		return 0, err
+	}
+
+	added := 0
+	if !result.NotModified {
+		items := s.buildItems(link.ID, result.Entries, now)
+		count, err := s.feedRepo.InsertItems(items)
+		if err != nil {
+			s.recordFailure(state, err)
+			return 0, err
+		}
+		added = int(count)
+		if added > 0 {
+			if err := s.feedRepo.PruneItems(link.ID, s.maxItems); err != nil {
+				logger.Error(fmt.Sprintf("清理友链 %d 的旧文章失败: %v", link.ID, err))
+			}
+		}
+		state.ETag = result.ETag
+		state.LastModified = result.LastModified
+	}
+
+	state.LastSuccessAt = &now
+	state.ErrorCount = 0
+	state.LastError = ""
+	state.NextFetchAt = now.Add(s.interval)
+	if err := s.feedRepo.SaveState(state); err != nil {
+		logger.Error(fmt.Sprintf("保存友链 %d 的抓取状态失败: %v", link.ID, err))
+	}
+
+	return added, nil
+}
+
+// FetchFeed 请求并解析订阅（不涉及数据库）
+// 参数:
+//   - ctx: 请求上下文
+//   - feedURL: 订阅地址（仅支持 http/https）
+//   - etag: 上次响应的 ETag（可为空）
+//   - lastModified: 上次响应的 Last-Modified（可为空）
+//
+// 返回:
+//   - *FetchResult: 抓取结果，服务器返回 304 时 NotModified 为 true
+//   - error: 请求失败、状态码异常或解析失败时返回错误
+func (s *FriendCircleService) FetchFeed(ctx context.Context, feedURL, etag, lastModified string) (*FetchResult, error) {
+	u, err := url.Parse(feedURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("订阅地址无效，仅支持 http/https")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", friendCircleUserAgent)
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, text/xml;q=0.8, */*;q=0.5")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return &FetchResult{NotModified: true, ETag: etag, LastModified: lastModified}, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("订阅返回状态码 %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, friendCircleMaxFeedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > friendCircleMaxFeedSize {
+		return nil, errors.New("订阅内容过大")
+	}
+
+	entries, err := util.ParseFeed(data)
+	if err != nil {
+		return nil, fmt.Errorf("解析订阅失败: %v", err)
+	}
+
+	return &FetchResult{
+		Entries:      entries,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// buildItems 将订阅条目转换为待入库的文章（只取最新的 maxItems 条）
+func (s *FriendCircleService) buildItems(friendLinkID uint, entries []util.FeedEntry, now time.Time) []model.FriendFeedItem {
+	if len(entries) > s.maxItems {
+		entries = entries[:s.maxItems]
+	}
+
+	seen := make(map[string]bool, len(entries))
+	items := make([]model.FriendFeedItem, 0, len(entries))
+	for _, entry := range entries {
+		guid := entry.GUID
+		// 超长的标识使用哈希，保证能写入且仍可去重
+		if len(guid) > 500 {
+			sum := sha1.Sum([]byte(guid))
+			guid = "sha1:" + hex.EncodeToString(sum[:])
+		}
+		if seen[guid] || len(entry.Link) > 500 {
+			continue
+		}
+		seen[guid] = true
+
+		// 缺失或未来的发布时间按抓取时间处理，避免长期置顶
+		published := entry.Published
+		if published.IsZero() || published.After(now) {
+			published = now
+		}
+
+		items = append(items, model.FriendFeedItem{
+			FriendLinkID: friendLinkID,
+			GUID:         guid,
+			Title:        truncateRunes(entry.Title, 300),
+			Link:         entry.Link,
+			Summary:      truncateRunes(entry.Summary, friendCircleSummaryLength),
+			Author:       truncateRunes(entry.Author, 100),
+			PublishedAt:  published,
+		})
+	}
+	return items
+}
+
+// recordFailure 记录抓取失败，并按连续失败次数指数退避
+func (s *FriendCircleService) recordFailure(state *model.FriendFeedState, err error) {
+	state.ErrorCount++
+	state.LastError = truncateRunes(err.Error(), 500)
+	state.NextFetchAt = time.Now().Add(s.backoff(state.ErrorCount))
+
+	if err := s.feedRepo.SaveState(state); err != nil {
+		logger.Error(fmt.Sprintf("保存友链 %d 的抓取状态失败: %v", state.FriendLinkID, err))
+	}
+}
+
+// backoff 计算连续失败 errorCount 次后的重试间隔：从抓取间隔开始每次翻倍，最长不超过 friendCircleMaxBackoff
+func (s *FriendCircleService) backoff(errorCount int) time.Duration {
+	backoff := s.interval
+	for i := 1; i < errorCount && backoff < friendCircleMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > friendCircleMaxBackoff {
+		backoff = friendCircleMaxBackoff
+	}
+	return backoff
+}
+
+// ListTimeline 获取朋友圈时间线
+func (s *FriendCircleService) ListTimeline(page, pageSize int) ([]model.FriendFeedItem, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 50 {
+		pageSize = 20
+	}
+	return s.feedRepo.ListTimeline(page, pageSize)
+}
+
+// ListStates 获取所有订阅的抓取状态（管理员用）
+func (s *FriendCircleService) ListStates() ([]model.FriendFeedState, error) {
+	return s.feedRepo.ListStates()
+}
+
+// truncateRunes 按字符截断字符串
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
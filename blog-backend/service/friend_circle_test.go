@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"blog-backend/util"
+)
+
+const testRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Example</title>
+    <item>
+      <title>第二篇</title>
+      <link>https://example.com/2</link>
+      <guid>https://example.com/2</guid>
+      <description><![CDATA[<p>Hello <b>RSS</b></p>]]></description>
+      <author>alice</author>
+      <pubDate>Tue, 02 Jan 2024 15:04:05 +0000</pubDate>
+    </item>
+    <item>
+      <title>第一篇</title>
+      <link>https://example.com/1</link>
+      <pubDate>Mon, 01 Jan 2024 15:04:05 +0000</pubDate>
+    </item>
+  </channel>
+</rss>`
+
+const testAtom = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Example</title>
+  <entry>
+    <title>Atom 文章</title>
+    <id>tag:example.com,2024:1</id>
+    <link rel="alternate" href="https://example.com/atom/1"/>
+    <summary>Hello Atom</summary>
+    <author><name>bob</name></author>
+    <updated>2024-01-03T10:00:00Z</updated>
+  </entry>
+</feed>`
+
+// newTestFetcher 创建使用 httptest 客户端的抓取服务
+func newTestFetcher(t *testing.T, handler http.HandlerFunc) (*FriendCircleService, string) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	s := NewFriendCircleService()
+	s.SetHTTPClient(server.Client())
+	return s, server.URL
+}
+
+func TestFetchFeedRSS(t *testing.T) {
+	s, url := newTestFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != friendCircleUserAgent {
+			t.Errorf("User-Agent = %q", r.Header.Get("User-Agent"))
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Tue, 02 Jan 2024 15:04:05 GMT")
+		w.Write([]byte(testRSS))
+	})
+
+	result, err := s.FetchFeed(context.Background(), url+"/rss.xml", "", "")
+	if err != nil {
+		t.Fatalf("FetchFeed: %v", err)
+	}
+	if result.NotModified {
+		t.Fatal("NotModified = true")
+	}
+	if result.ETag != `"v1"` || result.LastModified != "Tue, 02 Jan 2024 15:04:05 GMT" {
+		t.Errorf("ETag/LastModified = %q/%q", result.ETag, result.LastModified)
+	}
+	if len(result.Entries) != 2 {
+		t.Fatalf("len(Entries) = %d, want 2", len(result.Entries))
+	}
+
+	first := result.Entries[0]
+	if first.Title != "第二篇" || first.Link != "https://example.com/2" || first.Author != "alice" {
+		t.Errorf("first entry = %+v", first)
+	}
+	if first.Summary != "Hello RSS" {
+		t.Errorf("Summary = %q, want HTML stripped", first.Summary)
+	}
+	if want := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC); !first.Published.Equal(want) {
+		t.Errorf("Published = %v, want %v", first.Published, want)
+	}
+	// 缺少 guid 时使用链接作为标识
+	if result.Entries[1].GUID != "https://example.com/1" {
+		t.Errorf("GUID = %q", result.Entries[1].GUID)
+	}
+}
+
+func TestFetchFeedAtom(t *testing.T) {
+	s, url := newTestFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/atom+xml")
+		w.Write([]byte(testAtom))
+	})
+
+	result, err := s.FetchFeed(context.Background(), url+"/atom.xml", "", "")
+	if err != nil {
+		t.Fatalf("FetchFeed: %v", err)
+	}
+	if len(result.Entries) != 1 {
+		t.Fatalf("len(Entries) = %d, want 1", len(result.Entries))
+	}
+
+	entry := result.Entries[0]
+	if entry.GUID != "tag:example.com,2024:1" || entry.Title != "Atom 文章" || entry.Link != "https://example.com/atom/1" {
+		t.Errorf("entry = %+v", entry)
+	}
+	if entry.Author != "bob" || entry.Summary != "Hello Atom" {
+		t.Errorf("Author/Summary = %q/%q", entry.Author, entry.Summary)
+	}
+	if want := time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC); !entry.Published.Equal(want) {
+		t.Errorf("Published = %v, want %v", entry.Published, want)
+	}
+}
+
+func TestFetchFeedNotModified(t *testing.T) {
+	const etag = `"v1"`
+	const lastModified = "Tue, 02 Jan 2024 15:04:05 GMT"
+
+	s, url := newTestFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag && r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Write([]byte(testRSS))
+	})
+
+	first, err := s.FetchFeed(context.Background(), url, "", "")
+	if err != nil {
+		t.Fatalf("first FetchFeed: %v", err)
+	}
+	if first.NotModified || len(first.Entries) == 0 {
+		t.Fatalf("first fetch = %+v, want entries", first)
+	}
+
+	second, err := s.FetchFeed(context.Background(), url, first.ETag, first.LastModified)
+	if err != nil {
+		t.Fatalf("second FetchFeed: %v", err)
+	}
+	if !second.NotModified {
+		t.Fatal("NotModified = false, want true")
+	}
+	if len(second.Entries) != 0 {
+		t.Errorf("len(Entries) = %d, want 0", len(second.Entries))
+	}
+	// 304 时保留原有的缓存校验值，供下次请求继续使用
+	if second.ETag != etag || second.LastModified != lastModified {
+		t.Errorf("ETag/LastModified = %q/%q", second.ETag, second.LastModified)
+	}
+}
+
+func TestFetchFeedErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{name: "malformed xml", status: http.StatusOK, body: `<rss><channel><item><title>broken`, wantErr: "解析订阅失败"},
+		{name: "not a feed", status: http.StatusOK, body: `<html><body>hello</body></html>`, wantErr: "解析订阅失败"},
+		{name: "server error", status: http.StatusInternalServerError, body: "oops", wantErr: "状态码 500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, url := newTestFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := s.FetchFeed(context.Background(), url, "", "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFetchFeedInvalidURL(t *testing.T) {
+	s := NewFriendCircleService()
+	for _, feedURL := range []string{"", "ftp://example.com/feed", "file:///etc/passwd", "http://"} {
+		if _, err := s.FetchFeed(context.Background(), feedURL, "", ""); err == nil {
+			t.Errorf("FetchFeed(%q) err = nil", feedURL)
+		}
+	}
+}
+
+func TestDefaultClientRejectsInternalAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testRSS))
+	}))
+	defer server.Close()
+
+	// 默认客户端不允许访问回环地址
+	s := NewFriendCircleService()
+	_, err := s.FetchFeed(context.Background(), server.URL, "", "")
+	if !errors.Is(err, util.ErrForbiddenAddress) {
+		t.Fatalf("err = %v, want ErrForbiddenAddress", err)
+	}
+}
+
+func TestBackoffAfterRepeatedErrors(t *testing.T) {
+	s := NewFriendCircleService()
+	s.interval = 30 * time.Minute
+
+	tests := []struct {
+		errorCount int
+		want       time.Duration
+	}{
+		{errorCount: 1, want: 30 * time.Minute},
+		{errorCount: 2, want: time.Hour},
+		{errorCount: 3, want: 2 * time.Hour},
+		{errorCount: 5, want: 8 * time.Hour},
+		{errorCount: 6, want: 16 * time.Hour},
+		{errorCount: 7, want: friendCircleMaxBackoff},
+		{errorCount: 100, want: friendCircleMaxBackoff},
+	}
+	for _, tt := range tests {
+		if got := s.backoff(tt.errorCount); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.errorCount, got, tt.want)
+		}
+	}
+}
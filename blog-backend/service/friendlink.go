@@ -22,13 +22,15 @@ import (
 
 // FriendLinkService 友链业务逻辑层结构体
 type FriendLinkService struct {
-	repo *repository.FriendLinkRepository
+	repo       *repository.FriendLinkRepository
+	healthRepo *repository.FriendLinkHealthRepository
 }
 
 // NewFriendLinkService 创建友链业务逻辑层实例
 func NewFriendLinkService() *FriendLinkService {
 	return &FriendLinkService{
-		repo: repository.NewFriendLinkRepository(),
+		repo:       repository.NewFriendLinkRepository(),
+		healthRepo: repository.NewFriendLinkHealthRepository(),
 	}
 }
 
@@ -184,13 +186,19 @@ func (s *FriendLinkService) Update(id uint, req *UpdateFriendLinkRequest) (*mode
 	if req.SortOrder != nil {
 		friendLink.SortOrder = *req.SortOrder
 	}
+	// 管理员重新启用友链时清零健康检查的连续失败次数，避免下次检查失败后立即再次被禁用
+	reenabled := false
 	if req.Status != nil {
+		reenabled = friendLink.Status != 1 && *req.Status == 1
 		friendLink.Status = *req.Status
 	}
 
 	if err := s.repo.Update(friendLink); err != nil {
 		return nil, err
 	}
+	if reenabled {
+		_ = s.healthRepo.ResetFailures(friendLink.ID)
+	}
 
 	// 更新成功后，清理前台友链列表缓存
 	go func() {
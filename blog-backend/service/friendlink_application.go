@@ -0,0 +1,249 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：friendlink_application.go
+ * 创建时间：2026-10-17 20:31:09
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：友链申请业务逻辑层，处理访客提交的友链申请（验证码校验、去重、限流），
+ *          以及超级管理员的审核通过（创建友链）和拒绝，并邮件通知申请人
+ */
+package service
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"blog-backend/model"
+	"blog-backend/repository"
+	"blog-backend/util"
+
+	"gorm.io/gorm"
+)
+
+// friendLinkApplyDailyLimit 每个IP每天最多提交的申请数
+const friendLinkApplyDailyLimit = 3
+
+var (
+	// ErrApplicationNotFound 友链申请不存在
+	ErrApplicationNotFound = errors.New("友链申请不存在")
+	// ErrApplicationReviewed 友链申请已被审核
+	ErrApplicationReviewed = errors.New("该申请已被审核")
+)
+
+// FriendLinkApplicationService 友链申请业务逻辑层结构体
+type FriendLinkApplicationService struct {
+	repo           *repository.FriendLinkApplicationRepository
+	friendLinkRepo *repository.FriendLinkRepository
+	categoryRepo   *repository.FriendLinkCategoryRepository
+	settingRepo    *repository.SettingRepository
+}
+
+// NewFriendLinkApplicationService 创建友链申请业务逻辑层实例
+func NewFriendLinkApplicationService() *FriendLinkApplicationService {
+	return &FriendLinkApplicationService{
+		repo:           repository.NewFriendLinkApplicationRepository(),
+		friendLinkRepo: repository.NewFriendLinkRepository(),
+		categoryRepo:   repository.NewFriendLinkCategoryRepository(),
+		settingRepo:    repository.NewSettingRepository(),
+	}
+}
+
+// ApplyFriendLinkRequest 提交友链申请请求
+type ApplyFriendLinkRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	URL         string `json:"url" binding:"required,max=255"`
+	Icon        string `json:"icon" binding:"max=255"`
+	Description string `json:"description" binding:"max=500"`
+	AtomURL     string `json:"atom_url" binding:"max=255"`
+	Email       string `json:"email" binding:"required,email,max=100"`
+	CaptchaID   string `json:"captcha_id" binding:"required"`
+	Captcha     string `json:"captcha" binding:"required"`
+}
+
+// ApproveFriendLinkRequest 审核通过请求
+type ApproveFriendLinkRequest struct {
+	CategoryID uint `json:"category_id" binding:"required"` // 友链分类ID
+	SortOrder  int  `json:"sort_order"`
+}
+
+// RejectFriendLinkRequest 审核拒绝请求
+type RejectFriendLinkRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// Apply 提交友链申请
+// 参数:
+//   - req: 申请内容
+//   - ip: 申请人IP（用于验证码校验和限流）
+//
+// 返回:
+//   - *model.FriendLinkApplication: 创建的申请
+//   - error: 验证码错误、参数无效、重复申请或超出次数限制时返回错误
+func (s *FriendLinkApplicationService) Apply(req *ApplyFriendLinkRequest, ip string) (*model.FriendLinkApplication, error) {
+	if err := util.VerifyCaptcha(req.CaptchaID, req.Captcha, ip); err != nil {
+		return nil, err
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	req.URL = strings.TrimSpace(req.URL)
+	req.Icon = strings.TrimSpace(req.Icon)
+	req.AtomURL = strings.TrimSpace(req.AtomURL)
+	if req.Name == "" {
+		return nil, errors.New("网站名称不能为空")
+	}
+	if !isHTTPURL(req.URL) {
+		return nil, errors.New("网站地址无效，仅支持 http/https")
+	}
+	if req.Icon != "" && !isHTTPURL(req.Icon) {
+		return nil, errors.New("网站图标地址无效，仅支持 http/https")
+	}
+	if req.AtomURL != "" && !isHTTPURL(req.AtomURL) {
+		return nil, errors.New("订阅地址无效，仅支持 http/https")
+	}
+
+	count, err := s.repo.CountRecentByIP(ip, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return nil, errors.New("提交申请失败")
+	}
+	if count >= friendLinkApplyDailyLimit {
+		return nil, errors.New("提交过于频繁，请明天再试")
+	}
+
+	if exists, err := s.friendLinkRepo.ExistsByURL(req.URL); err != nil {
+		return nil, errors.New("提交申请失败")
+	} else if exists {
+		return nil, errors.New("该网站已在友链中")
+	}
+	if exists, err := s.repo.ExistsPendingByURL(req.URL); err != nil {
+		return nil, errors.New("提交申请失败")
+	} else if exists {
+		return nil, errors.New("该网站已有待审核的申请，请耐心等待")
+	}
+
+	app := &model.FriendLinkApplication{
+		Name:        req.Name,
+		URL:         req.URL,
+		Icon:        req.Icon,
+		Description: strings.TrimSpace(req.Description),
+		AtomURL:     req.AtomURL,
+		Email:       strings.TrimSpace(req.Email),
+		Status:      0,
+		IP:          ip,
+	}
+	if err := s.repo.Create(app); err != nil {
+		return nil, errors.New("提交申请失败")
+	}
+
+	return app, nil
+}
+
+// List 获取友链申请列表（管理员用）
+func (s *FriendLinkApplicationService) List(page, pageSize int, status *int) ([]model.FriendLinkApplication, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+	return s.repo.List(page, pageSize, status)
+}
+
+// Approve 审核通过友链申请，在指定分类下创建友链并邮件通知申请人
+func (s *FriendLinkApplicationService) Approve(id uint, req *ApproveFriendLinkRequest, reviewerID uint) (*model.FriendLink, error) {
+	app, err := s.getPending(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.categoryRepo.GetByID(req.CategoryID); err != nil {
+		return nil, errors.New("友链分类不存在")
+	}
+
+	friendLink := &model.FriendLink{
+		Name:        app.Name,
+		URL:         app.URL,
+		Icon:        app.Icon,
+		Description: app.Description,
+		AtomURL:     app.AtomURL,
+		CategoryID:  req.CategoryID,
+		SortOrder:   req.SortOrder,
+		Status:      1,
+	}
+	ok, err := s.repo.Approve(app, friendLink, reviewerID)
+	if err != nil {
+		return nil, errors.New("审核失败")
+	}
+	if !ok {
+		return nil, ErrApplicationReviewed
+	}
+
+	clearFriendLinkCaches()
+
+	site := loadSiteInfo(s.settingRepo)
+	sendEmailAsync(s.settingRepo, "友链审核结果邮件", app.Email, func(cfg util.EmailConfig) error {
+		return util.SendFriendLinkApprovedEmail(cfg, app.Email, app.Name, site["site_url"]+"/friend-links")
+	})
+
+	return s.friendLinkRepo.GetByID(friendLink.ID)
+}
+
+// Reject 拒绝友链申请并邮件通知申请人
+func (s *FriendLinkApplicationService) Reject(id uint, req *RejectFriendLinkRequest, reviewerID uint) error {
+	app, err := s.getPending(id)
+	if err != nil {
+		return err
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return errors.New("请填写拒绝原因")
+	}
+	ok, err := s.repo.Reject(app.ID, reason, reviewerID)
+	if err != nil {
+		return errors.New("审核失败")
+	}
+	if !ok {
+		return ErrApplicationReviewed
+	}
+
+	sendEmailAsync(s.settingRepo, "友链审核结果邮件", app.Email, func(cfg util.EmailConfig) error {
+		return util.SendFriendLinkRejectedEmail(cfg, app.Email, app.Name, reason)
+	})
+
+	return nil
+}
+
+// Delete 删除友链申请
+func (s *FriendLinkApplicationService) Delete(id uint) error {
+	if _, err := s.repo.GetByID(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrApplicationNotFound
+		}
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// getPending 获取待审核的申请
+func (s *FriendLinkApplicationService) getPending(id uint) (*model.FriendLinkApplication, error) {
+	app, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrApplicationNotFound
+		}
+		return nil, err
+	}
+	if app.Status != 0 {
+		return nil, ErrApplicationReviewed
+	}
+	return app, nil
+}
+
+// isHTTPURL 检查是否为 http/https 地址
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
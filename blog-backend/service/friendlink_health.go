@@ -0,0 +1,353 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：friendlink_health.go
+ * 创建时间：2026-10-17 19:46:53
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：友链健康检查业务逻辑层，定期请求已启用友链的地址，记录状态码、耗时和最后成功时间，
+ *          可选检查对方页面是否仍包含本站链接，连续失败达到阈值后自动禁用友链
+ */
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"blog-backend/config"
+	"blog-backend/db"
+	"blog-backend/logger"
+	"blog-backend/model"
+	"blog-backend/repository"
+	"blog-backend/util"
+
+	"github.com/google/uuid"
+	"golang.org/x/net/html"
+)
+
+const (
+	// friendLinkCheckLockKey 友链健康检查分布式锁键，避免多个实例同时检查
+	friendLinkCheckLockKey = "friend_link:check:lock"
+	// friendLinkCheckMaxBody 回链检查时读取的最大页面字节数
+	friendLinkCheckMaxBody = 2 << 20
+	// friendLinkCheckConcurrency 并发检查的友链数量
+	friendLinkCheckConcurrency = 4
+)
+
+// FriendLinkHealthService 友链健康检查业务逻辑层结构体
+type FriendLinkHealthService struct {
+	friendLinkRepo *repository.FriendLinkRepository
+	healthRepo     *repository.FriendLinkHealthRepository
+	settingRepo    *repository.SettingRepository
+	client         *http.Client
+	interval       time.Duration // 检查间隔
+	maxFailures    int           // 连续失败多少次后自动禁用
+	checkBacklink  bool          // 是否检查回链
+}
+
+// NewFriendLinkHealthService 创建友链健康检查业务逻辑层实例
+func NewFriendLinkHealthService() *FriendLinkHealthService {
+	interval, timeout, maxFailures, checkBacklink := 360, 10, 5, false
+	if config.Cfg != nil {
+		if config.Cfg.FriendLinkCheck.IntervalMinutes > 0 {
+			interval = config.Cfg.FriendLinkCheck.IntervalMinutes
+		}
+		if config.Cfg.FriendLinkCheck.TimeoutSeconds > 0 {
+			timeout = config.Cfg.FriendLinkCheck.TimeoutSeconds
+		}
+		if config.Cfg.FriendLinkCheck.MaxFailures > 0 {
+			maxFailures = config.Cfg.FriendLinkCheck.MaxFailures
+		}
+		checkBacklink = config.Cfg.FriendLinkCheck.CheckBacklink
+	}
+
+	return &FriendLinkHealthService{
+		friendLinkRepo: repository.NewFriendLinkRepository(),
+		healthRepo:     repository.NewFriendLinkHealthRepository(),
+		settingRepo:    repository.NewSettingRepository(),
+		client:         util.NewSafeHTTPClient(time.Duration(timeout) * time.Second),
+		interval:       time.Duration(interval) * time.Minute,
+		maxFailures:    maxFailures,
+		checkBacklink:  checkBacklink,
+	}
+}
+
+// SetHTTPClient 替换检查使用的 HTTP 客户端（用于自定义代理、超时或对接本地测试服务器）
+// 默认客户端禁止访问内网地址，替换后由调用方自行负责
+func (s *FriendLinkHealthService) SetHTTPClient(client *http.Client) {
+	s.client = client
+}
+
+// HealthCheckResult 单次检查结果
+type HealthCheckResult struct {
+	StatusCode  int           // 最终响应的状态码（请求失败时为0）
+	Latency     time.Duration // 从发出请求到收到响应头的耗时
+	HasBacklink *bool         // 页面是否有指向本站的链接（未检查时为nil）
+	Err         error         // 请求失败或状态码异常时的错误
+}
+
+// HealthCheckSummary 一轮检查的汇总
+type HealthCheckSummary struct {
+	Checked  int `json:"checked"`  // 检查的友链数
+	Failed   int `json:"failed"`   // 检查失败的友链数
+	Disabled int `json:"disabled"` // 本轮被自动禁用的友链数
+}
+
+// StartChecker 启动友链健康检查定时任务
+func (s *FriendLinkHealthService) StartChecker() {
+	go s.checkPeriodically()
+}
+
+// checkPeriodically 定期检查友链
+func (s *FriendLinkHealthService) checkPeriodically() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.runOnce()
+	}
+}
+
+// runOnce 获取分布式锁后执行一轮检查
+func (s *FriendLinkHealthService) runOnce() {
+	ctx := context.Background()
+	token := uuid.NewString()
+
+	ok, err := db.RDB.SetNX(ctx, friendLinkCheckLockKey, token, s.interval).Result()
+	if err != nil || !ok {
+		return
+	}
+	defer db.RDB.Eval(ctx, releaseLockScript, []string{friendLinkCheckLockKey}, token)
+
+	summary, err := s.CheckAll()
+	if err != nil {
+		logger.Error(fmt.Sprintf("友链健康检查失败: %v", err))
+		return
+	}
+	logger.Info(fmt.Sprintf("友链健康检查完成：检查 %d 个，失败 %d 个，自动禁用 %d 个", summary.Checked, summary.Failed, summary.Disabled))
+}
+
+// CheckAll 检查所有启用的友链
+// 返回:
+//   - *HealthCheckSummary: 检查汇总
+//   - error: 查询友链或检查结果失败时返回错误
+func (s *FriendLinkHealthService) CheckAll() (*HealthCheckSummary, error) {
+	links, err := s.friendLinkRepo.ListEnabled()
+	if err != nil {
+		return nil, err
+	}
+	healthList, err := s.healthRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	healthMap := make(map[uint]*model.FriendLinkHealth, len(healthList))
+	for i := range healthList {
+		healthMap[healthList[i].FriendLinkID] = &healthList[i]
+	}
+
+	backlinkHost := ""
+	if s.checkBacklink {
+		backlinkHost = s.siteHost()
+	}
+
+	summary := &HealthCheckSummary{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, friendLinkCheckConcurrency)
+
+	for i := range links {
+		link := &links[i]
+		health := healthMap[link.ID]
+		if health == nil {
+			health = &model.FriendLinkHealth{FriendLinkID: link.ID}
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			failed, disabled := s.CheckLink(link, health, backlinkHost)
+			mu.Lock()
+			defer mu.Unlock()
+			summary.Checked++
+			if failed {
+				summary.Failed++
+			}
+			if disabled {
+				summary.Disabled++
+			}
+		}()
+	}
+	wg.Wait()
+
+	if summary.Disabled > 0 {
+		clearFriendLinkCaches()
+	}
+
+	return summary, nil
+}
+
+// CheckLink 检查单个友链并保存结果，连续失败达到阈值时自动禁用
+// 参数:
+//   - link: 友链
+//   - health: 该友链之前的检查结果（首次检查时传入空结构）
+//   - backlinkHost: 回链检查使用的本站域名，为空时不检查回链
+//
+// 返回:
+//   - bool: 本次检查是否失败
+//   - bool: 是否因此禁用了友链
+func (s *FriendLinkHealthService) CheckLink(link *model.FriendLink, health *model.FriendLinkHealth, backlinkHost string) (bool, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.client.Timeout+5*time.Second)
+	defer cancel()
+
+	result := s.Check(ctx, link.URL, backlinkHost)
+	now := time.Now()
+
+	health.FriendLinkID = link.ID
+	health.StatusCode = result.StatusCode
+	health.LatencyMs = int(result.Latency / time.Millisecond)
+	health.HasBacklink = result.HasBacklink
+	health.LastCheckedAt = &now
+
+	failed := result.Err != nil
+	disabled := false
+	if failed {
+		health.ConsecutiveFailures++
+		health.LastError = truncateRunes(result.Err.Error(), 500)
+		if health.ConsecutiveFailures >= s.maxFailures {
+			ok, err := s.friendLinkRepo.Disable(link.ID)
+			if err != nil {
+				logger.Error(fmt.Sprintf("自动禁用友链 %d 失败: %v", link.ID, err))
+			} else if ok {
+				disabled = true
+				health.AutoDisabledAt = &now
+				logger.Info(fmt.Sprintf("友链 %d（%s）连续 %d 次检查失败，已自动禁用", link.ID, link.URL, health.ConsecutiveFailures))
+			}
+		}
+	} else {
+		health.ConsecutiveFailures = 0
+		health.LastError = ""
+		health.LastSuccessAt = &now
+	}
+
+	if err := s.healthRepo.Save(health); err != nil {
+		logger.Error(fmt.Sprintf("保存友链 %d 的检查结果失败: %v", link.ID, err))
+	}
+
+	return failed, disabled
+}
+
+// Check 请求友链地址（不涉及数据库）
+// 参数:
+//   - ctx: 请求上下文
+//   - linkURL: 友链地址（仅支持 http/https）
+//   - backlinkHost: 本站域名，不为空时检查页面中是否有指向该域名的链接
+//
+// 返回:
+//   - *HealthCheckResult: 检查结果，跟随重定向后状态码不是 2xx 时 Err 不为空
+func (s *FriendLinkHealthService) Check(ctx context.Context, linkURL, backlinkHost string) *HealthCheckResult {
+	result := &HealthCheckResult{}
+
+	u, err := url.Parse(linkURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		result.Err = errors.New("友链地址无效，仅支持 http/https")
+		return result
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, linkURL, nil)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	req.Header.Set("User-Agent", friendCircleUserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.8")
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	result.Latency = time.Since(start)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		result.Err = fmt.Errorf("友链返回状态码 %d", resp.StatusCode)
+		return result
+	}
+
+	if backlinkHost != "" {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, friendLinkCheckMaxBody))
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		found := hasBacklink(body, resp.Request.URL, backlinkHost)
+		result.HasBacklink = &found
+	}
+
+	return result
+}
+
+// hasBacklink 页面中是否有指向本站的链接
+// 逐个解析 <a href>，按页面地址补全相对地址后比较域名（忽略 www. 前缀），
+// 避免页面正文或其他域名（如 example.com.evil.net）中碰巧包含本站域名时被误判
+func hasBacklink(body []byte, pageURL *url.URL, host string) bool {
+	tokenizer := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return false
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			if string(name) != "a" {
+				continue
+			}
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = tokenizer.TagAttr()
+				if string(key) != "href" {
+					continue
+				}
+				u, err := pageURL.Parse(strings.TrimSpace(string(val)))
+				if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+					continue
+				}
+				if strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.") == host {
+					return true
+				}
+			}
+		}
+	}
+}
+
+// siteHost 获取网站设置中 site_url 的域名（去掉 www. 前缀），未配置站点地址时返回空
+func (s *FriendLinkHealthService) siteHost() string {
+	u, err := url.Parse(loadSiteInfo(s.settingRepo)["site_url"])
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" || host == "localhost" || host == "127.0.0.1" {
+		return ""
+	}
+	return strings.TrimPrefix(host, "www.")
+}
+
+// clearFriendLinkCaches 清理友链相关缓存（前台友链列表和站点地图）
+func clearFriendLinkCaches() {
+	db.RDB.Del(context.Background(), "friend_links:public:list")
+	clearSitemapCaches()
+}
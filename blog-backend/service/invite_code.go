@@ -0,0 +1,95 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：invite_code.go
+ * 创建时间：2026-10-18 05:36:44
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：注册邀请码业务逻辑层，提供邀请码的生成、列表和删除（管理员用）
+ */
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"blog-backend/logger"
+	"blog-backend/model"
+	"blog-backend/repository"
+	"blog-backend/util"
+)
+
+// ErrInviteCodeNotFound 邀请码不存在
+var ErrInviteCodeNotFound = errors.New("邀请码不存在")
+
+// InviteCodeService 注册邀请码业务逻辑层结构体
+type InviteCodeService struct {
+	repo *repository.InviteCodeRepository
+}
+
+// NewInviteCodeService 创建注册邀请码业务逻辑层实例
+func NewInviteCodeService() *InviteCodeService {
+	return &InviteCodeService{
+		repo: repository.NewInviteCodeRepository(),
+	}
+}
+
+// CreateInviteCodeRequest 生成邀请码请求
+type CreateInviteCodeRequest struct {
+	MaxUses    int    `json:"max_uses" binding:"omitempty,min=1,max=1000"`   // 最多可使用次数，默认1
+	ExpireDays int    `json:"expire_days" binding:"omitempty,min=1,max=365"` // 有效天数，为空表示长期有效
+	Note       string `json:"note" binding:"max=100"`
+}
+
+// List 获取邀请码列表
+func (s *InviteCodeService) List(page, pageSize int) ([]model.InviteCode, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	return s.repo.List(page, pageSize)
+}
+
+// Create 生成邀请码
+func (s *InviteCodeService) Create(creatorID uint, req *CreateInviteCodeRequest) (*model.InviteCode, error) {
+	maxUses := req.MaxUses
+	if maxUses == 0 {
+		maxUses = 1
+	}
+
+	inviteCode := &model.InviteCode{
+		Code:      strings.ToUpper(util.GenerateRandomString(10)),
+		MaxUses:   maxUses,
+		Note:      strings.TrimSpace(req.Note),
+		CreatedBy: &creatorID,
+	}
+	if req.ExpireDays > 0 {
+		expireAt := time.Now().AddDate(0, 0, req.ExpireDays)
+		inviteCode.ExpireAt = &expireAt
+	}
+
+	if err := s.repo.Create(inviteCode); err != nil {
+		return nil, errors.New("生成邀请码失败")
+	}
+
+	logger.Info(fmt.Sprintf("用户 %d 生成了邀请码 %d（可用 %d 次）", creatorID, inviteCode.ID, maxUses))
+	return inviteCode, nil
+}
+
+// Delete 删除邀请码（已使用该邀请码注册的账号不受影响）
+func (s *InviteCodeService) Delete(id uint) error {
+	deleted, err := s.repo.Delete(id)
+	if err != nil {
+		return errors.New("删除邀请码失败")
+	}
+	if !deleted {
+		return ErrInviteCodeNotFound
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：login_history.go
+ * 创建时间：2026-10-18 00:44:10
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：登录历史业务逻辑层，记录每次登录尝试，提供用户查看本人登录记录和超级管理员查看全部记录的功能
+ */
+package service
+
+import (
+	"fmt"
+
+	"blog-backend/logger"
+	"blog-backend/model"
+	"blog-backend/repository"
+)
+
+// loginHistoryRetentionDays 登录记录保留天数
+const loginHistoryRetentionDays = 180
+
+// LoginHistoryService 登录历史业务逻辑层结构体
+type LoginHistoryService struct {
+	repo *repository.LoginHistoryRepository
+}
+
+// NewLoginHistoryService 创建登录历史业务逻辑层实例
+func NewLoginHistoryService() *LoginHistoryService {
+	return &LoginHistoryService{
+		repo: repository.NewLoginHistoryRepository(),
+	}
+}
+
+// Record 写入一条登录记录（写入失败只记录日志，不影响登录流程）
+// 参数:
+//   - user: 登录账号，用户名不存在时为 nil
+//   - username: 登录时提交的用户名
+//   - reason: 失败原因（model.LoginFail*），为空表示登录成功
+func (s *LoginHistoryService) Record(user *model.User, username, reason, ip, userAgent string) {
+	history := &model.LoginHistory{
+		Username:  truncateRunes(username, 50),
+		Success:   reason == "",
+		Reason:    reason,
+		IP:        ip,
+		UserAgent: userAgent,
+	}
+	if user != nil {
+		history.UserID = &user.ID
+		history.Username = user.Username
+	}
+
+	if err := s.repo.Create(history); err != nil {
+		logger.Error(fmt.Sprintf("写入登录记录失败 (%s): %v", username, err))
+	}
+}
+
+// ListByUser 获取用户本人的登录记录
+func (s *LoginHistoryService) ListByUser(userID uint, page, pageSize int) ([]model.LoginHistory, int64, error) {
+	page, pageSize = normalizeLoginHistoryPage(page, pageSize)
+	return s.repo.List(page, pageSize, repository.LoginHistoryFilter{UserID: userID})
+}
+
+// List 获取全部登录记录（超级管理员用）
+func (s *LoginHistoryService) List(page, pageSize int, filter repository.LoginHistoryFilter) ([]model.LoginHistory, int64, error) {
+	page, pageSize = normalizeLoginHistoryPage(page, pageSize)
+	return s.repo.List(page, pageSize, filter)
+}
+
+// normalizeLoginHistoryPage 规范化分页参数
+func normalizeLoginHistoryPage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+	return page, pageSize
+}
@@ -0,0 +1,142 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：login_lock.go
+ * 创建时间：2026-10-18 00:36:45
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：账号登录锁定，按账号在Redis中统计登录失败次数（与IP无关），
+ *          达到阈值后临时锁定账号，24小时内再次锁定时锁定时长翻倍
+ */
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"blog-backend/config"
+	"blog-backend/db"
+	"blog-backend/logger"
+)
+
+const (
+	// loginFailKeyPrefix 窗口期内的登录失败次数
+	loginFailKeyPrefix = "login:fail:"
+	// loginLockKeyPrefix 账号锁定标记，键的剩余有效期即剩余锁定时长
+	loginLockKeyPrefix = "login:lock:"
+	// loginLockLevelKeyPrefix 近期锁定次数（决定下一次锁定时长）
+	loginLockLevelKeyPrefix = "login:lock_level:"
+	// loginLockLevelTTL 锁定次数的保留时长，超过后锁定时长恢复为初始值
+	loginLockLevelTTL = 24 * time.Hour
+)
+
+// loginLockError 账号被锁定时返回给客户端的错误
+func loginLockError(remaining time.Duration) error {
+	minutes := int((remaining + time.Minute - 1) / time.Minute)
+	return fmt.Errorf("登录失败次数过多，账号已被临时锁定，请 %d 分钟后再试", minutes)
+}
+
+// checkLoginLock 获取账号的剩余锁定时长，未锁定时返回 0
+// Redis 不可用时不阻止登录（IP频率限制仍然生效）
+func checkLoginLock(userID uint) time.Duration {
+	ttl, err := db.RDB.PTTL(context.Background(), fmt.Sprintf("%s%d", loginLockKeyPrefix, userID)).Result()
+	if err != nil {
+		logger.Error(fmt.Sprintf("查询账号 %d 的锁定状态失败: %v", userID, err))
+		return 0
+	}
+	if ttl <= 0 {
+		return 0
+	}
+	return ttl
+}
+
+// recordLoginFailure 记录一次登录失败，达到阈值时锁定账号
+// 返回:
+//   - time.Duration: 本次触发的锁定时长，未触发锁定时返回 0
+func recordLoginFailure(userID uint) time.Duration {
+	ctx := context.Background()
+	failKey := fmt.Sprintf("%s%d", loginFailKeyPrefix, userID)
+	maxFailures, window, baseLock, maxLock := loginLockSettings()
+
+	failures, err := db.RDB.Incr(ctx, failKey).Result()
+	if err != nil {
+		logger.Error(fmt.Sprintf("记录账号 %d 的登录失败次数失败: %v", userID, err))
+		return 0
+	}
+	if failures == 1 {
+		db.RDB.Expire(ctx, failKey, window)
+	}
+	if failures < int64(maxFailures) {
+		return 0
+	}
+
+	levelKey := fmt.Sprintf("%s%d", loginLockLevelKeyPrefix, userID)
+	pipe := db.RDB.TxPipeline()
+	level := pipe.Incr(ctx, levelKey)
+	pipe.Expire(ctx, levelKey, loginLockLevelTTL)
+	pipe.Del(ctx, failKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Error(fmt.Sprintf("锁定账号 %d 失败: %v", userID, err))
+		return 0
+	}
+
+	duration := baseLock
+	for i := int64(1); i < level.Val() && duration < maxLock; i++ {
+		duration *= 2
+	}
+	if duration > maxLock {
+		duration = maxLock
+	}
+	if err := db.RDB.Set(ctx, fmt.Sprintf("%s%d", loginLockKeyPrefix, userID), level.Val(), duration).Err(); err != nil {
+		logger.Error(fmt.Sprintf("锁定账号 %d 失败: %v", userID, err))
+		return 0
+	}
+
+	logger.Info(fmt.Sprintf("账号 %d 连续登录失败 %d 次，锁定 %s", userID, maxFailures, duration))
+	return duration
+}
+
+// clearLoginFailures 登录成功后清除账号的失败次数和锁定记录
+func clearLoginFailures(userID uint) {
+	if err := deleteLoginLockKeys(userID); err != nil {
+		logger.Error(fmt.Sprintf("清除账号 %d 的登录失败记录失败: %v", userID, err))
+	}
+}
+
+// deleteLoginLockKeys 删除账号的失败次数、锁定标记和锁定次数
+func deleteLoginLockKeys(userID uint) error {
+	return db.RDB.Del(context.Background(),
+		fmt.Sprintf("%s%d", loginFailKeyPrefix, userID),
+		fmt.Sprintf("%s%d", loginLockKeyPrefix, userID),
+		fmt.Sprintf("%s%d", loginLockLevelKeyPrefix, userID),
+	).Err()
+}
+
+// loginLockSettings 读取登录锁定配置（未配置时使用默认值）
+// 返回:
+//   - int: 触发锁定的失败次数（默认5）
+//   - time.Duration: 失败次数统计窗口（默认15分钟）
+//   - time.Duration: 首次锁定时长（默认5分钟）
+//   - time.Duration: 最长锁定时长（默认24小时）
+func loginLockSettings() (int, time.Duration, time.Duration, time.Duration) {
+	maxFailures, window, lock, maxLock := 5, 15, 5, 1440
+	cfg := config.Cfg.LoginLock
+	if cfg.MaxFailures > 0 {
+		maxFailures = cfg.MaxFailures
+	}
+	if cfg.WindowMinutes > 0 {
+		window = cfg.WindowMinutes
+	}
+	if cfg.LockMinutes > 0 {
+		lock = cfg.LockMinutes
+	}
+	if cfg.MaxLockMinutes > 0 {
+		maxLock = cfg.MaxLockMinutes
+	}
+	if maxLock < lock {
+		maxLock = lock
+	}
+	return maxFailures, time.Duration(window) * time.Minute, time.Duration(lock) * time.Minute, time.Duration(maxLock) * time.Minute
+}
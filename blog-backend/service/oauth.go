@@ -0,0 +1,456 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：oauth.go
+ * 创建时间：2026-10-18 03:21:44
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：第三方登录业务逻辑层，实现授权码模式（state + 浏览器 nonce + PKCE）的登录和账号绑定：
+ *          已绑定的第三方账号直接登录，未绑定时按注册设置自动创建账号；已登录用户可在个人中心绑定和解绑
+ */
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog-backend/config"
+	"blog-backend/constant"
+	"blog-backend/db"
+	"blog-backend/logger"
+	"blog-backend/model"
+	"blog-backend/repository"
+	"blog-backend/util"
+
+	"gorm.io/gorm"
+)
+
+// 第三方登录流程中的临时数据
+const (
+	oauthStateKeyPrefix  = "oauth:state:"  // state -> 授权请求信息
+	oauthTicketKeyPrefix = "oauth:ticket:" // 一次性登录票据 -> 用户ID
+	oauthStateTTL        = 10 * time.Minute
+	oauthTicketTTL       = time.Minute
+)
+
+// OAuthNonceMaxAge 浏览器 nonce Cookie 的有效期（秒），与 state 一致
+const OAuthNonceMaxAge = int(oauthStateTTL / time.Second)
+
+// 第三方登录回调的处理结果类型
+const (
+	OAuthModeLogin = "login" // 登录（或自动注册）
+	OAuthModeLink  = "link"  // 已登录用户绑定第三方账号
+)
+
+var (
+	// ErrOAuthProviderUnavailable 提供方不存在或未启用
+	ErrOAuthProviderUnavailable = errors.New("不支持该登录方式")
+	// ErrOAuthBindingNotFound 绑定不存在
+	ErrOAuthBindingNotFound = errors.New("未绑定该第三方账号")
+)
+
+// OAuthService 第三方登录业务逻辑层结构体
+type OAuthService struct {
+	userRepo    *repository.UserRepository
+	bindingRepo *repository.OAuthBindingRepository
+	authService *AuthService
+}
+
+// NewOAuthService 创建第三方登录业务逻辑层实例
+func NewOAuthService() *OAuthService {
+	return &OAuthService{
+		userRepo:    repository.NewUserRepository(),
+		bindingRepo: repository.NewOAuthBindingRepository(),
+		authService: NewAuthService(),
+	}
+}
+
+// OAuthProviderInfo 可用的第三方登录方式
+type OAuthProviderInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// OAuthBindingStatus 第三方账号绑定状态
+type OAuthBindingStatus struct {
+	Provider         string     `json:"provider"`
+	DisplayName      string     `json:"display_name"`
+	Bound            bool       `json:"bound"`
+	ProviderUsername string     `json:"provider_username,omitempty"`
+	Avatar           string     `json:"avatar,omitempty"`
+	BoundAt          *time.Time `json:"bound_at,omitempty"`
+}
+
+// OAuthCallbackResult 授权回调的处理结果
+type OAuthCallbackResult struct {
+	Mode     string // OAuthModeLogin 或 OAuthModeLink
+	Provider string
+	Ticket   string // 登录模式下的一次性票据，前端凭此换取令牌
+}
+
+// OAuthExchangeRequest 使用一次性票据换取登录令牌请求
+type OAuthExchangeRequest struct {
+	Ticket string `json:"ticket" binding:"required"`
+}
+
+// oauthState 授权请求信息（以 state 为键保存在 Redis 中）
+type oauthState struct {
+	Provider  string `json:"provider"`
+	Verifier  string `json:"verifier"`          // PKCE 校验码
+	UserID    uint   `json:"user_id,omitempty"` // 绑定模式下为发起绑定的用户ID
+	NonceHash string `json:"nonce_hash"`        // 浏览器 nonce 的 SHA-256 哈希，回调时与 Cookie 比对
+}
+
+// Providers 获取已启用的第三方登录方式
+func (s *OAuthService) Providers() []OAuthProviderInfo {
+	providers := util.EnabledOAuthProviders()
+	list := make([]OAuthProviderInfo, 0, len(providers))
+	for _, p := range providers {
+		list = append(list, OAuthProviderInfo{Name: p.Name(), DisplayName: p.DisplayName()})
+	}
+	return list
+}
+
+// AuthorizeURL 生成第三方授权页地址
+// 同时生成一个浏览器 nonce，由调用方写入 Cookie，state 只保存其哈希，回调时两者必须匹配，
+// 保证授权回调只能在发起授权的浏览器中完成
+// 参数:
+//   - providerName: 提供方标识
+//   - userID: 绑定模式下为当前用户ID，登录模式传 0
+//
+// 返回:
+//   - string: 授权页地址
+//   - string: 浏览器 nonce
+//   - error: 提供方不可用或保存 state 失败时返回错误
+func (s *OAuthService) AuthorizeURL(providerName string, userID uint) (string, string, error) {
+	provider, ok := util.GetOAuthProvider(providerName)
+	if !ok {
+		return "", "", ErrOAuthProviderUnavailable
+	}
+
+	state := util.GenerateRandomString(32)
+	nonce := util.GenerateRandomString(32)
+	verifier := util.GeneratePKCEVerifier()
+	data, _ := json.Marshal(oauthState{Provider: providerName, Verifier: verifier, UserID: userID, NonceHash: hashOAuthNonce(nonce)})
+	if err := db.RDB.Set(context.Background(), oauthStateKeyPrefix+state, data, oauthStateTTL).Err(); err != nil {
+		return "", "", errors.New("发起授权失败")
+	}
+
+	return provider.AuthCodeURL(state, util.PKCEChallenge(verifier)), nonce, nil
+}
+
+// OAuthCallbackPath 获取提供方授权回调的路径，用作 nonce Cookie 的 Path，使其只随回调请求发送
+// 优先取配置的回调地址中的路径，未配置时使用默认路由
+func OAuthCallbackPath(providerName string) string {
+	if config.Cfg != nil {
+		if cfg, ok := config.Cfg.OAuth.Providers[providerName]; ok {
+			if u, err := url.Parse(cfg.RedirectURL); err == nil && u.Path != "" {
+				return u.Path
+			}
+		}
+	}
+	return "/api/auth/oauth/" + providerName + "/callback"
+}
+
+// hashOAuthNonce 计算浏览器 nonce 的 SHA-256 哈希
+func hashOAuthNonce(nonce string) string {
+	sum := sha256.Sum256([]byte(nonce))
+	return hex.EncodeToString(sum[:])
+}
+
+// Callback 处理提供方的授权回调
+// 登录模式下返回一次性票据；绑定模式下直接完成绑定
+// 参数:
+//   - nonce: 浏览器 Cookie 中的 nonce，与发起授权时不一致（如回调链接在其他浏览器中打开）时拒绝
+func (s *OAuthService) Callback(providerName, code, state, nonce, ip, userAgent string) (*OAuthCallbackResult, error) {
+	ctx := context.Background()
+	result := &OAuthCallbackResult{Mode: OAuthModeLogin, Provider: providerName}
+
+	// state 只能使用一次
+	key := oauthStateKeyPrefix + state
+	raw, err := db.RDB.Get(ctx, key).Result()
+	if err != nil || state == "" {
+		return result, errors.New("授权请求已过期，请重新发起")
+	}
+	if deleted, err := db.RDB.Del(ctx, key).Result(); err != nil || deleted == 0 {
+		return result, errors.New("授权请求已过期，请重新发起")
+	}
+
+	var st oauthState
+	if err := json.Unmarshal([]byte(raw), &st); err != nil || st.Provider != providerName {
+		return result, errors.New("授权请求无效，请重新发起")
+	}
+	if nonce == "" || st.NonceHash == "" ||
+		subtle.ConstantTimeCompare([]byte(hashOAuthNonce(nonce)), []byte(st.NonceHash)) != 1 {
+		return result, errors.New("授权请求与当前浏览器不匹配，请重新发起")
+	}
+	if st.UserID != 0 {
+		result.Mode = OAuthModeLink
+	}
+	if code == "" {
+		return result, errors.New("已取消授权")
+	}
+
+	provider, ok := util.GetOAuthProvider(providerName)
+	if !ok {
+		return result, ErrOAuthProviderUnavailable
+	}
+
+	accessToken, err := provider.Exchange(ctx, code, st.Verifier)
+	if err != nil {
+		logger.Error(fmt.Sprintf("%s 授权码换取令牌失败: %v", providerName, err))
+		return result, errors.New("第三方授权失败，请重试")
+	}
+	info, err := provider.FetchUser(ctx, accessToken)
+	if err != nil {
+		logger.Error(fmt.Sprintf("获取 %s 用户信息失败: %v", providerName, err))
+		return result, errors.New("获取第三方账号信息失败，请重试")
+	}
+
+	if result.Mode == OAuthModeLink {
+		return result, s.link(st.UserID, providerName, info)
+	}
+
+	user, err := s.loginUser(providerName, info)
+	if err != nil {
+		return result, err
+	}
+	if err := s.authService.checkUserStatus(user, user.Username, ip, userAgent); err != nil {
+		return result, err
+	}
+
+	ticket := util.GenerateRandomString(32)
+	if err := db.RDB.Set(ctx, oauthTicketKeyPrefix+ticket, user.ID, oauthTicketTTL).Err(); err != nil {
+		return result, errors.New("登录失败")
+	}
+	result.Ticket = ticket
+	return result, nil
+}
+
+// Exchange 使用一次性票据完成登录（已启用两步验证时返回挑战令牌）
+func (s *OAuthService) Exchange(req *OAuthExchangeRequest, ip, userAgent string) (*LoginResponse, error) {
+	ctx := context.Background()
+	key := oauthTicketKeyPrefix + req.Ticket
+	raw, err := db.RDB.Get(ctx, key).Result()
+	if err != nil {
+		return nil, errors.New("登录票据无效或已过期，请重新登录")
+	}
+	if deleted, err := db.RDB.Del(ctx, key).Result(); err != nil || deleted == 0 {
+		return nil, errors.New("登录票据无效或已过期，请重新登录")
+	}
+
+	userID, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		return nil, errors.New("登录票据无效或已过期，请重新登录")
+	}
+	user, err := s.userRepo.GetByID(uint(userID))
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	if remaining := checkLoginLock(user.ID); remaining > 0 {
+		s.authService.loginHistoryService.Record(user, user.Username, model.LoginFailLocked, ip, userAgent)
+		return nil, loginLockError(remaining)
+	}
+	if err := s.authService.checkUserStatus(user, user.Username, ip, userAgent); err != nil {
+		return nil, err
+	}
+
+	return s.authService.completeLogin(user, user.Username, ip, userAgent)
+}
+
+// ListBindings 获取用户的第三方账号绑定状态（包含所有已启用的提供方和已绑定的提供方）
+func (s *OAuthService) ListBindings(userID uint) ([]OAuthBindingStatus, error) {
+	bindings, err := s.bindingRepo.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]OAuthBindingStatus, 0)
+	seen := make(map[string]bool)
+	for _, p := range util.EnabledOAuthProviders() {
+		seen[p.Name()] = true
+		list = append(list, OAuthBindingStatus{Provider: p.Name(), DisplayName: p.DisplayName()})
+	}
+	// 提供方停用后仍展示已有绑定，便于用户解绑
+	for _, b := range bindings {
+		if !seen[b.Provider] {
+			seen[b.Provider] = true
+			list = append(list, OAuthBindingStatus{Provider: b.Provider, DisplayName: b.Provider})
+		}
+	}
+
+	for i := range list {
+		for _, b := range bindings {
+			if b.Provider == list[i].Provider {
+				createdAt := b.CreatedAt
+				list[i].Bound = true
+				list[i].ProviderUsername = b.ProviderUsername
+				list[i].Avatar = b.Avatar
+				list[i].BoundAt = &createdAt
+			}
+		}
+	}
+	return list, nil
+}
+
+// Unlink 解除第三方账号绑定
+// 通过第三方登录自动创建的账号没有设置过密码，解绑前应先通过"忘记密码"设置密码
+func (s *OAuthService) Unlink(userID uint, providerName string) error {
+	deleted, err := s.bindingRepo.Delete(userID, providerName)
+	if err != nil {
+		return errors.New("解除绑定失败")
+	}
+	if !deleted {
+		return ErrOAuthBindingNotFound
+	}
+	logger.Info(fmt.Sprintf("用户 %d 解除了 %s 账号绑定", userID, providerName))
+	return nil
+}
+
+// link 将第三方账号绑定到已登录用户
+func (s *OAuthService) link(userID uint, providerName string, info *util.OAuthUserInfo) error {
+	existing, err := s.bindingRepo.GetByProviderUser(providerName, info.ID)
+	if err == nil {
+		if existing.UserID == userID {
+			return nil
+		}
+		return errors.New("该第三方账号已绑定其他用户")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("绑定失败")
+	}
+
+	binding := newOAuthBinding(providerName, info)
+	binding.UserID = userID
+	if err := s.bindingRepo.Create(binding); err != nil {
+		if isUniqueViolation(err) {
+			return errors.New("已绑定过该平台的其他账号，请先解除绑定")
+		}
+		return errors.New("绑定失败")
+	}
+
+	logger.Info(fmt.Sprintf("用户 %d 绑定了 %s 账号 %s", userID, providerName, info.Username))
+	return nil
+}
+
+// loginUser 获取第三方账号绑定的用户，未绑定时按注册设置创建新用户
+// 不按邮箱自动关联已有账号，避免第三方平台邮箱未验证时被冒用；已有账号请登录后在个人中心绑定
+func (s *OAuthService) loginUser(providerName string, info *util.OAuthUserInfo) (*model.User, error) {
+	binding, err := s.bindingRepo.GetByProviderUser(providerName, info.ID)
+	if err == nil {
+		user, err := s.userRepo.GetByID(binding.UserID)
+		if err != nil {
+			return nil, errors.New("用户不存在")
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("登录失败")
+	}
+
+	if disabled, err := s.authService.isRegisterDisabled(); err == nil && disabled {
+		return nil, errors.New("用户注册功能已关闭，已有账号请登录后在个人中心绑定")
+	}
+	mode := loadRegisterMode(s.authService.settingRepo)
+	if mode == RegisterModeInvite {
+		return nil, errors.New("本站仅限邀请注册，请使用邀请码注册账号后在个人中心绑定")
+	}
+	if info.Email == "" || !util.ValidateEmail(info.Email) {
+		return nil, errors.New("未能获取第三方账号已验证的邮箱，请注册账号后在个人中心绑定")
+	}
+	if _, err := s.userRepo.GetByEmail(info.Email); err == nil {
+		return nil, errors.New("该邮箱已注册，请使用原账号登录后在个人中心绑定")
+	}
+
+	// 第三方登录创建的账号使用随机密码，需要密码登录时可通过"忘记密码"重新设置
+	hashedPassword, err := util.HashPassword(util.GenerateRandomString(32))
+	if err != nil {
+		return nil, errors.New("登录失败")
+	}
+
+	nickname := strings.TrimSpace(info.Nickname)
+	if nickname == "" {
+		nickname = info.Username
+	}
+	user := &model.User{
+		Username: s.availableUsername(providerName, info.Username),
+		Email:    info.Email,
+		Password: hashedPassword,
+		Nickname: truncateRunes(nickname, 50),
+		Avatar:   truncateRunes(info.Avatar, 255),
+		Role:     constant.RoleUser,
+		Status:   model.UserStatusActive,
+	}
+	// 需要审核时同样创建为待审核账号，随后的状态检查会提示等待审核
+	if mode == RegisterModeApproval {
+		user.Status = model.UserStatusPending
+	}
+	if err := s.bindingRepo.CreateUserWithBinding(user, newOAuthBinding(providerName, info)); err != nil {
+		if isUniqueViolation(err) {
+			return nil, errors.New("账号创建失败，请重试")
+		}
+		return nil, errors.New("账号创建失败")
+	}
+
+	logger.Info(fmt.Sprintf("通过 %s 登录创建了用户 %s（ID: %d）", providerName, user.Username, user.ID))
+	return user, nil
+}
+
+// availableUsername 根据第三方登录名生成未被占用的用户名
+func (s *OAuthService) availableUsername(providerName, login string) string {
+	candidate := login
+	if len(candidate) > 20 {
+		candidate = candidate[:20]
+	}
+	if util.ValidateUsername(candidate) {
+		if _, err := s.userRepo.GetByUsername(candidate); errors.Is(err, gorm.ErrRecordNotFound) {
+			return candidate
+		}
+	}
+
+	base := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return -1
+	}, login)
+	if len(base) < 3 {
+		base = providerName
+	}
+	if len(base) > 14 {
+		base = base[:14]
+	}
+	for i := 0; i < 5; i++ {
+		candidate = base + "_" + strings.ToLower(util.GenerateRandomString(5))
+		if _, err := s.userRepo.GetByUsername(candidate); errors.Is(err, gorm.ErrRecordNotFound) {
+			return candidate
+		}
+	}
+	return base + "_" + strings.ToLower(util.GenerateRandomString(5))
+}
+
+// newOAuthBinding 根据第三方账号信息创建绑定记录
+func newOAuthBinding(providerName string, info *util.OAuthUserInfo) *model.UserOAuthBinding {
+	return &model.UserOAuthBinding{
+		Provider:         providerName,
+		ProviderUserID:   info.ID,
+		ProviderUsername: truncateRunes(info.Username, 100),
+		Avatar:           truncateRunes(info.Avatar, 255),
+	}
+}
+
+// isUniqueViolation 判断是否为唯一约束冲突
+func isUniqueViolation(err error) bool {
+	errStr := err.Error()
+	return strings.Contains(errStr, "duplicate key") || strings.Contains(errStr, "unique constraint")
+}
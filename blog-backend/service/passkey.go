@@ -0,0 +1,369 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：passkey.go
+ * 创建时间：2026-10-18 04:13:52
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：通行密钥业务逻辑层，实现 WebAuthn 通行密钥的注册、免密登录和管理；
+ *          登录使用可发现凭据，无需输入用户名，成功后与密码登录签发相同的令牌
+ */
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog-backend/config"
+	"blog-backend/db"
+	"blog-backend/logger"
+	"blog-backend/model"
+	"blog-backend/repository"
+	"blog-backend/util"
+)
+
+// 通行密钥流程中的临时数据
+const (
+	passkeyRegisterKeyPrefix = "webauthn:register:" // 用户ID -> 注册挑战值
+	passkeyLoginKeyPrefix    = "webauthn:login:"    // 登录挑战值（只能使用一次）
+	passkeyChallengeTTL      = 5 * time.Minute
+	passkeyMaxPerUser        = 10 // 每个用户最多注册的通行密钥数量
+)
+
+// ErrPasskeyNotFound 通行密钥不存在
+var ErrPasskeyNotFound = errors.New("通行密钥不存在")
+
+// PasskeyService 通行密钥业务逻辑层结构体
+type PasskeyService struct {
+	credentialRepo *repository.WebAuthnCredentialRepository
+	userRepo       *repository.UserRepository
+	authService    *AuthService
+}
+
+// NewPasskeyService 创建通行密钥业务逻辑层实例
+func NewPasskeyService() *PasskeyService {
+	return &PasskeyService{
+		credentialRepo: repository.NewWebAuthnCredentialRepository(),
+		userRepo:       repository.NewUserRepository(),
+		authService:    NewAuthService(),
+	}
+}
+
+// PasskeyCredentialDescriptor 凭据描述（用于排除已注册的凭据）
+type PasskeyCredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+// PasskeyCredentialParam 可接受的公钥算法
+type PasskeyCredentialParam struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+// PasskeyRegistrationOptions 注册选项，对应 PublicKeyCredentialCreationOptions（二进制字段为 base64url）
+type PasskeyRegistrationOptions struct {
+	Challenge string `json:"challenge"`
+	RP        struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"rp"`
+	User struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		DisplayName string `json:"displayName"`
+	} `json:"user"`
+	PubKeyCredParams       []PasskeyCredentialParam      `json:"pubKeyCredParams"`
+	Timeout                int                           `json:"timeout"`
+	Attestation            string                        `json:"attestation"`
+	ExcludeCredentials     []PasskeyCredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection struct {
+		ResidentKey      string `json:"residentKey"`
+		UserVerification string `json:"userVerification"`
+	} `json:"authenticatorSelection"`
+}
+
+// PasskeyLoginOptions 登录选项，对应 PublicKeyCredentialRequestOptions
+type PasskeyLoginOptions struct {
+	Challenge        string `json:"challenge"`
+	RPID             string `json:"rpId"`
+	Timeout          int    `json:"timeout"`
+	UserVerification string `json:"userVerification"`
+}
+
+// PasskeyRegisterRequest 完成注册请求，credential 为 PublicKeyCredential 的 JSON 形式（二进制字段为 base64url）
+type PasskeyRegisterRequest struct {
+	Name       string `json:"name" binding:"max=50"`
+	Credential struct {
+		ID       string `json:"id" binding:"required"`
+		Type     string `json:"type"`
+		Response struct {
+			ClientDataJSON    string   `json:"clientDataJSON" binding:"required"`
+			AttestationObject string   `json:"attestationObject" binding:"required"`
+			Transports        []string `json:"transports"`
+		} `json:"response"`
+	} `json:"credential"`
+}
+
+// PasskeyLoginRequest 完成登录请求
+type PasskeyLoginRequest struct {
+	Credential struct {
+		ID       string `json:"id" binding:"required"`
+		Type     string `json:"type"`
+		Response struct {
+			ClientDataJSON    string `json:"clientDataJSON" binding:"required"`
+			AuthenticatorData string `json:"authenticatorData" binding:"required"`
+			Signature         string `json:"signature" binding:"required"`
+			UserHandle        string `json:"userHandle"`
+		} `json:"response"`
+	} `json:"credential"`
+}
+
+// BeginRegistration 发起注册，返回浏览器 navigator.credentials.create 所需的选项
+func (s *PasskeyService) BeginRegistration(userID uint) (*PasskeyRegistrationOptions, error) {
+	if err := checkPasskeyConfig(); err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	credentials, err := s.credentialRepo.ListByUser(userID)
+	if err != nil {
+		return nil, errors.New("发起注册失败")
+	}
+	if len(credentials) >= passkeyMaxPerUser {
+		return nil, fmt.Errorf("每个账号最多注册%d个通行密钥", passkeyMaxPerUser)
+	}
+
+	challenge := util.GenerateWebAuthnChallenge()
+	key := passkeyRegisterKeyPrefix + strconv.FormatUint(uint64(userID), 10)
+	if err := db.RDB.Set(context.Background(), key, challenge, passkeyChallengeTTL).Err(); err != nil {
+		return nil, errors.New("发起注册失败")
+	}
+
+	options := &PasskeyRegistrationOptions{
+		Challenge:          challenge,
+		Timeout:            int(passkeyChallengeTTL / time.Millisecond),
+		Attestation:        "none",
+		ExcludeCredentials: make([]PasskeyCredentialDescriptor, 0, len(credentials)),
+	}
+	options.RP.ID = config.Cfg.WebAuthn.RPID
+	options.RP.Name = config.Cfg.WebAuthn.RPName
+	options.User.ID = passkeyUserHandle(user.ID)
+	options.User.Name = user.Username
+	options.User.DisplayName = user.Nickname
+	if options.User.DisplayName == "" {
+		options.User.DisplayName = user.Username
+	}
+	for _, alg := range util.WebAuthnSupportedAlgorithms {
+		options.PubKeyCredParams = append(options.PubKeyCredParams, PasskeyCredentialParam{Type: "public-key", Alg: alg})
+	}
+	// 可发现凭据：登录时无需输入用户名
+	options.AuthenticatorSelection.ResidentKey = "required"
+	options.AuthenticatorSelection.UserVerification = "required"
+	// 避免在同一个认证器上重复注册
+	for _, c := range credentials {
+		options.ExcludeCredentials = append(options.ExcludeCredentials, PasskeyCredentialDescriptor{
+			Type:       "public-key",
+			ID:         c.CredentialID,
+			Transports: splitTransports(c.Transports),
+		})
+	}
+	return options, nil
+}
+
+// FinishRegistration 校验浏览器返回的注册结果并保存通行密钥
+func (s *PasskeyService) FinishRegistration(userID uint, req *PasskeyRegisterRequest) (*model.WebAuthnCredential, error) {
+	if err := checkPasskeyConfig(); err != nil {
+		return nil, err
+	}
+
+	// 挑战值只能使用一次
+	ctx := context.Background()
+	key := passkeyRegisterKeyPrefix + strconv.FormatUint(uint64(userID), 10)
+	challenge, err := db.RDB.Get(ctx, key).Result()
+	if err != nil {
+		return nil, errors.New("注册请求已过期，请重试")
+	}
+	db.RDB.Del(ctx, key)
+
+	clientDataJSON, err := util.DecodeBase64URL(req.Credential.Response.ClientDataJSON)
+	if err != nil {
+		return nil, errors.New("请求参数错误")
+	}
+	attestationObject, err := util.DecodeBase64URL(req.Credential.Response.AttestationObject)
+	if err != nil {
+		return nil, errors.New("请求参数错误")
+	}
+
+	data, err := util.VerifyWebAuthnRegistration(clientDataJSON, attestationObject, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.credentialRepo.CountByUser(userID)
+	if err != nil {
+		return nil, errors.New("注册失败")
+	}
+	if count >= passkeyMaxPerUser {
+		return nil, fmt.Errorf("每个账号最多注册%d个通行密钥", passkeyMaxPerUser)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = fmt.Sprintf("通行密钥 %d", count+1)
+	}
+	credential := &model.WebAuthnCredential{
+		UserID:       userID,
+		Name:         truncateRunes(name, 50),
+		CredentialID: base64.RawURLEncoding.EncodeToString(data.CredentialID),
+		PublicKey:    base64.RawURLEncoding.EncodeToString(data.PublicKey),
+		Algorithm:    data.Algorithm,
+		SignCount:    int64(data.SignCount),
+		Transports:   truncateRunes(strings.Join(req.Credential.Response.Transports, ","), 100),
+	}
+	if err := s.credentialRepo.Create(credential); err != nil {
+		if isUniqueViolation(err) {
+			return nil, errors.New("该通行密钥已注册")
+		}
+		return nil, errors.New("注册失败")
+	}
+
+	logger.Info(fmt.Sprintf("用户 %d 注册了通行密钥 %d", userID, credential.ID))
+	return credential, nil
+}
+
+// BeginLogin 发起登录，返回浏览器 navigator.credentials.get 所需的选项
+// 不指定 allowCredentials，由浏览器列出该站点的可发现凭据供用户选择
+func (s *PasskeyService) BeginLogin() (*PasskeyLoginOptions, error) {
+	if err := checkPasskeyConfig(); err != nil {
+		return nil, err
+	}
+
+	challenge := util.GenerateWebAuthnChallenge()
+	if err := db.RDB.Set(context.Background(), passkeyLoginKeyPrefix+challenge, 1, passkeyChallengeTTL).Err(); err != nil {
+		return nil, errors.New("发起登录失败")
+	}
+
+	return &PasskeyLoginOptions{
+		Challenge:        challenge,
+		RPID:             config.Cfg.WebAuthn.RPID,
+		Timeout:          int(passkeyChallengeTTL / time.Millisecond),
+		UserVerification: "required",
+	}, nil
+}
+
+// FinishLogin 校验浏览器返回的登录断言，通过后与密码登录一样创建会话（已启用两步验证时返回挑战令牌）
+func (s *PasskeyService) FinishLogin(req *PasskeyLoginRequest, ip, userAgent string) (*LoginResponse, error) {
+	if err := checkPasskeyConfig(); err != nil {
+		return nil, err
+	}
+
+	clientDataJSON, err := util.DecodeBase64URL(req.Credential.Response.ClientDataJSON)
+	if err != nil {
+		return nil, errors.New("请求参数错误")
+	}
+	authenticatorData, err := util.DecodeBase64URL(req.Credential.Response.AuthenticatorData)
+	if err != nil {
+		return nil, errors.New("请求参数错误")
+	}
+	signature, err := util.DecodeBase64URL(req.Credential.Response.Signature)
+	if err != nil {
+		return nil, errors.New("请求参数错误")
+	}
+	rawID, err := util.DecodeBase64URL(req.Credential.ID)
+	if err != nil {
+		return nil, errors.New("请求参数错误")
+	}
+
+	// 挑战值必须由本站下发且只能使用一次
+	challenge, err := util.ParseWebAuthnChallenge(clientDataJSON)
+	if err != nil || challenge == "" {
+		return nil, errors.New("登录请求已过期，请重试")
+	}
+	if deleted, err := db.RDB.Del(context.Background(), passkeyLoginKeyPrefix+challenge).Result(); err != nil || deleted == 0 {
+		return nil, errors.New("登录请求已过期，请重试")
+	}
+
+	credential, err := s.credentialRepo.GetByCredentialID(base64.RawURLEncoding.EncodeToString(rawID))
+	if err != nil {
+		return nil, errors.New("通行密钥未注册或已被删除")
+	}
+	if handle := req.Credential.Response.UserHandle; handle != "" && handle != passkeyUserHandle(credential.UserID) {
+		return nil, errors.New("通行密钥与账号不匹配")
+	}
+
+	publicKey, err := util.DecodeBase64URL(credential.PublicKey)
+	if err != nil {
+		return nil, errors.New("登录失败")
+	}
+	signCount, err := util.VerifyWebAuthnAssertion(clientDataJSON, authenticatorData, signature, publicKey, challenge, uint32(credential.SignCount))
+	if err != nil {
+		return nil, err
+	}
+	if updated, err := s.credentialRepo.UpdateUsage(credential.ID, credential.SignCount, int64(signCount), ip); err != nil || !updated {
+		return nil, errors.New("登录失败，请重试")
+	}
+
+	user, err := s.userRepo.GetByID(credential.UserID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	if remaining := checkLoginLock(user.ID); remaining > 0 {
+		s.authService.loginHistoryService.Record(user, user.Username, model.LoginFailLocked, ip, userAgent)
+		return nil, loginLockError(remaining)
+	}
+	if err := s.authService.checkUserStatus(user, user.Username, ip, userAgent); err != nil {
+		return nil, err
+	}
+
+	return s.authService.completeLogin(user, user.Username, ip, userAgent)
+}
+
+// List 获取用户的通行密钥列表
+func (s *PasskeyService) List(userID uint) ([]model.WebAuthnCredential, error) {
+	return s.credentialRepo.ListByUser(userID)
+}
+
+// Delete 删除通行密钥
+func (s *PasskeyService) Delete(userID, id uint) error {
+	deleted, err := s.credentialRepo.Delete(userID, id)
+	if err != nil {
+		return errors.New("删除通行密钥失败")
+	}
+	if !deleted {
+		return ErrPasskeyNotFound
+	}
+	logger.Info(fmt.Sprintf("用户 %d 删除了通行密钥 %d", userID, id))
+	return nil
+}
+
+// checkPasskeyConfig 检查是否已配置通行密钥（依赖方ID和允许的来源）
+func checkPasskeyConfig() error {
+	if config.Cfg.WebAuthn.RPID == "" || len(config.Cfg.WebAuthn.Origins) == 0 {
+		return errors.New("站点未启用通行密钥登录")
+	}
+	return nil
+}
+
+// passkeyUserHandle 生成用户句柄（写入通行密钥的用户标识，不包含用户名和邮箱）
+func passkeyUserHandle(userID uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(uint64(userID), 10)))
+}
+
+// splitTransports 拆分以逗号分隔的传输方式
+func splitTransports(transports string) []string {
+	if transports == "" {
+		return nil
+	}
+	return strings.Split(transports, ",")
+}
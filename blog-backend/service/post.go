@@ -27,12 +27,16 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrPostStatusChanged 编辑期间文章状态已被其他操作（如定时发布）修改
+var ErrPostStatusChanged = errors.New("文章状态已变更，请刷新后重试")
+
 // PostService 文章业务逻辑层结构体
 type PostService struct {
 	postRepo     *repository.PostRepository
 	categoryRepo *repository.CategoryRepository
 	tagRepo      *repository.TagRepository
 	postViewRepo *repository.PostViewRepository
+	revisionRepo *repository.PostRevisionRepository
 }
 
 // NewPostService 创建文章业务逻辑层实例
@@ -42,33 +46,36 @@ func NewPostService() *PostService {
 		categoryRepo: repository.NewCategoryRepository(),
 		tagRepo:      repository.NewTagRepository(),
 		postViewRepo: repository.NewPostViewRepository(),
+		revisionRepo: repository.NewPostRevisionRepository(),
 	}
 }
 
 // CreatePostRequest 创建文章请求
 type CreatePostRequest struct {
-	Title      string `json:"title" binding:"required"`
-	Content    string `json:"content" binding:"required"`
-	Summary    string `json:"summary"`
-	Cover      string `json:"cover"`
-	CategoryID uint   `json:"category_id" binding:"required"`
-	TagIDs     []uint `json:"tag_ids"`
-	Status     int    `json:"status"`     // 0:草稿 1:发布
-	Visibility int    `json:"visibility"` // 1:公开 0:私密（默认值 1 在前端设置）
-	IsTop      bool   `json:"is_top"`
+	Title       string     `json:"title" binding:"required"`
+	Content     string     `json:"content" binding:"required"`
+	Summary     string     `json:"summary"`
+	Cover       string     `json:"cover"`
+	CategoryID  uint       `json:"category_id" binding:"required"`
+	TagIDs      []uint     `json:"tag_ids"`
+	Status      int        `json:"status"`     // 0:草稿 1:发布 2:定时发布
+	Visibility  int        `json:"visibility"` // 1:公开 0:私密（默认值 1 在前端设置）
+	IsTop       bool       `json:"is_top"`
+	PublishedAt *time.Time `json:"published_at"` // 定时发布时间（仅 status=2 时使用，必须晚于当前时间）
 }
 
 // UpdatePostRequest 更新文章请求
 type UpdatePostRequest struct {
-	Title      string `json:"title"`
-	Content    string `json:"content"`
-	Summary    string `json:"summary"`
-	Cover      string `json:"cover"`
-	CategoryID *uint  `json:"category_id"` // 使用指针类型，nil 表示不修改
-	TagIDs     []uint `json:"tag_ids"`
-	Status     int    `json:"status"`
-	Visibility *int   `json:"visibility"` // 1:公开 0:私密（nil 表示不修改）
-	IsTop      bool   `json:"is_top"`
+	Title       string     `json:"title"`
+	Content     string     `json:"content"`
+	Summary     string     `json:"summary"`
+	Cover       string     `json:"cover"`
+	CategoryID  *uint      `json:"category_id"` // 使用指针类型，nil 表示不修改
+	TagIDs      []uint     `json:"tag_ids"`
+	Status      int        `json:"status"`     // 0:草稿 1:发布 2:定时发布
+	Visibility  *int       `json:"visibility"` // 1:公开 0:私密（nil 表示不修改）
+	IsTop       bool       `json:"is_top"`
+	PublishedAt *time.Time `json:"published_at"` // 定时发布时间（仅 status=2 时使用，nil 表示沿用原定时间）
 }
 
 // Create 创建文章
@@ -81,14 +88,19 @@ func (s *PostService) Create(userID uint, req *CreatePostRequest) (*model.Post,
 	// 处理状态：确保草稿状态（0）能正确保存
 	// 如果 status 为 0（草稿），需要明确设置，避免被默认值覆盖
 	postStatus := req.Status
-	if postStatus != 0 && postStatus != 1 {
+	if postStatus != 0 && postStatus != 1 && postStatus != 2 {
 		// 如果状态值无效，默认为发布（1）
 		postStatus = 1
 	}
 
+	// 定时发布：必须指定一个未来的发布时间
+	if postStatus == 2 && (req.PublishedAt == nil || !req.PublishedAt.After(time.Now())) {
+		return nil, errors.New("定时发布时间必须晚于当前时间")
+	}
+
 	// 处理可见性：
 	// - 如果状态为草稿（0），自动设置为私密（0）
-	// - 如果状态为发布（1），使用用户选择的可见性，默认公开（1）
+	// - 如果状态为发布（1）或定时发布（2），使用用户选择的可见性，默认公开（1）
 	var visibility int
 	if postStatus == 0 {
 		// 草稿状态，强制设置为私密
@@ -130,10 +142,13 @@ func (s *PostService) Create(userID uint, req *CreatePostRequest) (*model.Post,
 		UserID:     userID,
 	}
 
-	// 如果是发布状态，设置发布时间
-	if req.Status == 1 {
+	// 如果是发布状态，设置发布时间；定时发布使用指定时间，由调度器到点后发布
+	if postStatus == 1 {
 		now := time.Now()
 		post.PublishedAt = &now
+	} else if postStatus == 2 {
+		publishAt := *req.PublishedAt
+		post.PublishedAt = &publishAt
 	}
 
 	// 使用事务确保数据一致性
@@ -157,8 +172,8 @@ func (s *PostService) Create(userID uint, req *CreatePostRequest) (*model.Post,
 				return err
 			}
 
-			// 增加标签文章数（仅发布状态）
-			if req.Status == 1 {
+			// 增加标签文章数（仅发布状态，定时发布在调度器发布时再计数）
+			if postStatus == 1 {
 				for _, tagID := range req.TagIDs {
 					if err := s.tagRepo.IncrementPostCountTx(tx, tagID); err != nil {
 						return err
@@ -168,7 +183,7 @@ func (s *PostService) Create(userID uint, req *CreatePostRequest) (*model.Post,
 		}
 
 		// 增加分类文章数
-		if req.Status == 1 {
+		if postStatus == 1 {
 			if err := s.categoryRepo.IncrementPostCountTx(tx, req.CategoryID); err != nil {
 				return err
 			}
@@ -182,17 +197,7 @@ func (s *PostService) Create(userID uint, req *CreatePostRequest) (*model.Post,
 	}
 
 	// 写操作成功后，删除与文章列表相关的缓存（最新文章等）
-	go func() {
-		ctx := context.Background()
-		// 删除常用的最新文章缓存（不同 limit 可以按需扩展）
-		for _, limit := range []int{5, 10, 20} {
-			key := fmt.Sprintf("post:recent:%d", limit)
-			db.RDB.Del(ctx, key)
-		}
-		// 文章数、标签统计等也会受影响，清理相关缓存
-		db.RDB.Del(ctx, "blog:author_profile")
-		db.RDB.Del(ctx, "tag:stats:top10")
-	}()
+	go clearPostCaches()
 
 	return s.postRepo.GetByID(post.ID)
 }
@@ -226,8 +231,8 @@ func (s *PostService) GetBySlug(slug string, userID *uint, role string, ip strin
 // checkPostPermission 检查文章权限并记录浏览
 func (s *PostService) checkPostPermission(post *model.Post, userID *uint, role string, ip string) (*model.Post, error) {
 
-	// 私密/草稿仅作者或管理员可见
-	if (post.Visibility == 0 || post.Status == 0) && !constant.IsAdminRole(role) {
+	// 私密/草稿/定时发布（未到发布时间）仅作者或管理员可见
+	if (post.Visibility == 0 || post.Status != 1) && !repository.NewRoleRepository().HasPermission(role, constant.PermPostManage) {
 		if userID == nil || *userID != post.UserID {
 			return nil, errors.New("无权限查看")
 		}
@@ -260,14 +265,24 @@ func (s *PostService) Update(id, userID uint, role string, req *UpdatePostReques
 		return nil, errors.New("文章不存在")
 	}
 
-	// 权限检查：只有作者和管理员可以修改
-	if post.UserID != userID && !constant.IsAdminRole(role) {
+	// 权限检查：只有作者和具备 post.manage 权限的用户可以修改
+	if post.UserID != userID && !repository.NewRoleRepository().HasPermission(role, constant.PermPostManage) {
 		return nil, errors.New("无权限修改此文章")
 	}
 
 	oldCategoryID := post.CategoryID
 	oldStatus := post.Status
 
+	// 保存更新前的内容快照，用于写入修订历史
+	revision := &model.PostRevision{
+		PostID:  post.ID,
+		Title:   post.Title,
+		Content: post.Content,
+		Summary: post.Summary,
+		UserID:  &userID,
+		Remark:  "更新文章",
+	}
+
 	// 获取旧的标签列表（在更新之前）
 	oldTagIDs := make([]uint, 0)
 	if len(post.Tags) > 0 {
@@ -306,9 +321,25 @@ func (s *PostService) Update(id, userID uint, role string, req *UpdatePostReques
 	post.Status = req.Status
 	post.IsTop = req.IsTop
 
+	// 定时发布：使用新传入的时间，未传入时沿用原定时间，且必须晚于当前时间
+	if req.Status == 2 {
+		if req.PublishedAt != nil {
+			publishAt := *req.PublishedAt
+			post.PublishedAt = &publishAt
+		} else if oldStatus != 2 {
+			post.PublishedAt = nil
+		}
+		if post.PublishedAt == nil || !post.PublishedAt.After(time.Now()) {
+			return nil, errors.New("定时发布时间必须晚于当前时间")
+		}
+	} else if oldStatus == 2 && req.Status == 0 {
+		// 取消定时发布转为草稿，清除预定的发布时间
+		post.PublishedAt = nil
+	}
+
 	// 更新可见性：
 	// - 如果状态为草稿（0），自动设置为私密（0）
-	// - 如果状态为发布（1），使用用户选择的可见性（如果传入）
+	// - 如果状态为发布（1）或定时发布（2），使用用户选择的可见性（如果传入）
 	if req.Status == 0 {
 		// 草稿状态，强制设置为私密
 		post.Visibility = 0
@@ -321,18 +352,30 @@ func (s *PostService) Update(id, userID uint, role string, req *UpdatePostReques
 	}
 	// 如果状态为发布且未传入可见性，保持原有可见性不变
 
-	// 如果从草稿变为发布，设置发布时间
-	if oldStatus == 0 && req.Status == 1 {
+	// 如果从草稿或定时发布变为发布，设置发布时间
+	if oldStatus != 1 && req.Status == 1 {
 		now := time.Now()
 		post.PublishedAt = &now
 	}
 
 	// 使用事务确保数据一致性
 	err = s.postRepo.Transaction(func(tx *gorm.DB) error {
-		// 更新文章
-		if err := s.postRepo.UpdateTx(tx, post); err != nil {
+		// 标题、正文或摘要发生变化时，先写入修订历史（与文章更新处于同一事务）
+		if revision.Title != post.Title || revision.Content != post.Content || revision.Summary != post.Summary {
+			if err := s.revisionRepo.CreateTx(tx, revision); err != nil {
+				return err
+			}
+		}
+
+		// 更新文章：以读取时的状态为条件，期间被定时任务发布等状态变化会使本次更新失败，
+		// 避免覆盖新状态，同时保证下面按 oldStatus 调整的分类和标签文章数准确
+		updated, err := s.postRepo.UpdateIfStatusTx(tx, post, oldStatus)
+		if err != nil {
 			return err
 		}
+		if !updated {
+			return ErrPostStatusChanged
+		}
 
 		// 更新标签关联
 		if len(req.TagIDs) > 0 {
@@ -374,8 +417,8 @@ func (s *PostService) Update(id, userID uint, role string, req *UpdatePostReques
 					}
 				}
 
-				// 如果状态从草稿变为发布，所有新标签都要增加计数
-				if oldStatus == 0 && post.Status == 1 {
+				// 如果状态从未发布（草稿/定时）变为发布，所有新标签都要增加计数
+				if oldStatus != 1 && post.Status == 1 {
 					for _, tagID := range req.TagIDs {
 						alreadyCounted := false
 						for _, oldTagID := range oldTagIDs {
@@ -392,8 +435,8 @@ func (s *PostService) Update(id, userID uint, role string, req *UpdatePostReques
 					}
 				}
 
-				// 如果状态从发布变为草稿，所有旧标签都要减少计数
-				if oldStatus == 1 && post.Status == 0 {
+				// 如果状态从发布变为未发布（草稿/定时），所有旧标签都要减少计数
+				if oldStatus == 1 && post.Status != 1 {
 					for _, oldTagID := range oldTagIDs {
 						if err := s.tagRepo.DecrementPostCountTx(tx, oldTagID); err != nil {
 							return err
@@ -415,7 +458,8 @@ func (s *PostService) Update(id, userID uint, role string, req *UpdatePostReques
 					return err
 				}
 			}
-		} else if oldStatus != post.Status {
+		} else if (oldStatus == 1) != (post.Status == 1) {
+			// 只有“是否已发布”发生变化时才调整计数（草稿与定时发布之间切换不影响）
 			if post.Status == 1 {
 				if err := s.categoryRepo.IncrementPostCountTx(tx, post.CategoryID); err != nil {
 					return err
@@ -435,15 +479,141 @@ func (s *PostService) Update(id, userID uint, role string, req *UpdatePostReques
 	}
 
 	// 写操作成功后，删除与文章列表相关的缓存（最新文章等）
-	go func() {
-		ctx := context.Background()
-		for _, limit := range []int{5, 10, 20} {
-			key := fmt.Sprintf("post:recent:%d", limit)
-			db.RDB.Del(ctx, key)
+	go clearPostCaches()
+
+	return s.postRepo.GetByID(post.ID)
+}
+
+// RevisionDiff 两个修订版本之间的差异
+type RevisionDiff struct {
+	From    *model.PostRevision `json:"from"`
+	To      *model.PostRevision `json:"to"` // 为当前版本时 ID 为 0
+	Added   int                 `json:"added"`
+	Removed int                 `json:"removed"`
+	Lines   []util.DiffLine     `json:"lines"`
+}
+
+// ListRevisions 获取文章的修订历史列表
+func (s *PostService) ListRevisions(postID uint, page, pageSize int) ([]model.PostRevision, int64, error) {
+	if _, err := s.postRepo.GetByID(postID); err != nil {
+		return nil, 0, errors.New("文章不存在")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return s.revisionRepo.ListByPostID(postID, page, pageSize)
+}
+
+// GetRevision 获取单个修订版本（含正文）
+func (s *PostService) GetRevision(postID, revisionID uint) (*model.PostRevision, error) {
+	revision, err := s.revisionRepo.GetByID(postID, revisionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("修订版本不存在")
+		}
+		return nil, errors.New("获取修订版本失败")
+	}
+	return revision, nil
+}
+
+// DiffRevisions 对比两个修订版本的正文
+// toID 为 0 时与文章当前内容对比
+func (s *PostService) DiffRevisions(postID, fromID, toID uint) (*RevisionDiff, error) {
+	from, err := s.GetRevision(postID, fromID)
+	if err != nil {
+		return nil, err
+	}
+
+	var to *model.PostRevision
+	if toID == 0 {
+		post, err := s.postRepo.GetByID(postID)
+		if err != nil {
+			return nil, errors.New("文章不存在")
+		}
+		to = &model.PostRevision{
+			PostID:    post.ID,
+			Title:     post.Title,
+			Content:   post.Content,
+			Summary:   post.Summary,
+			CreatedAt: post.UpdatedAt,
 		}
-		db.RDB.Del(ctx, "blog:author_profile")
-		db.RDB.Del(ctx, "tag:stats:top10")
-	}()
+	} else {
+		to, err = s.GetRevision(postID, toID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	diff := &RevisionDiff{
+		From:  from,
+		To:    to,
+		Lines: util.DiffLines(from.Content, to.Content),
+	}
+	for _, line := range diff.Lines {
+		switch line.Type {
+		case util.DiffInsert:
+			diff.Added++
+		case util.DiffDelete:
+			diff.Removed++
+		}
+	}
+
+	return diff, nil
+}
+
+// RestoreRevision 将指定修订版本恢复为文章当前内容
+// 恢复前会把当前内容也保存为一条修订，保证恢复操作本身可撤销
+func (s *PostService) RestoreRevision(postID, revisionID, userID uint, role string) (*model.Post, error) {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		return nil, errors.New("文章不存在")
+	}
+
+	// 权限检查：与更新文章保持一致
+	if post.UserID != userID && !repository.NewRoleRepository().HasPermission(role, constant.PermPostManage) {
+		return nil, errors.New("无权限修改此文章")
+	}
+
+	revision, err := s.GetRevision(postID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	backup := &model.PostRevision{
+		PostID:  post.ID,
+		Title:   post.Title,
+		Content: post.Content,
+		Summary: post.Summary,
+		UserID:  &userID,
+		Remark:  fmt.Sprintf("恢复修订 #%d 前的备份", revision.ID),
+	}
+
+	// 标题变化时重新生成slug，与更新文章的逻辑一致
+	if revision.Title != "" && revision.Title != post.Title {
+		post.Title = revision.Title
+		baseSlug := util.GenerateSlug(revision.Title)
+		postRepo := s.postRepo
+		post.Slug = util.GenerateUniqueSlug(baseSlug, func(slug string) bool {
+			return postRepo.CheckSlugExists(slug, postID)
+		})
+	}
+	post.Content = revision.Content
+	post.Summary = revision.Summary
+
+	err = s.postRepo.Transaction(func(tx *gorm.DB) error {
+		if err := s.revisionRepo.CreateTx(tx, backup); err != nil {
+			return err
+		}
+		return s.postRepo.UpdateTx(tx, post)
+	})
+	if err != nil {
+		return nil, errors.New("恢复修订版本失败")
+	}
+
+	go clearPostCaches()
 
 	return s.postRepo.GetByID(post.ID)
 }
@@ -459,10 +629,10 @@ func (s *PostService) Delete(id, userID uint, role string) error {
 	// 1. 作者可以删除自己的文章
 	if post.UserID == userID {
 		// 作者可以删除自己的文章，继续执行
-	} else if constant.IsAdminRole(role) {
-		// 2. 管理员可以删除文章，但需要检查权限
-		// 如果当前用户是普通管理员（admin），且文章作者是超级管理员（super_admin），则禁止删除
-		if role == constant.RoleAdmin && post.User.Role == constant.RoleSuperAdmin {
+	} else if repository.NewRoleRepository().HasPermission(role, constant.PermPostManage) {
+		// 2. 具备 post.manage 权限的用户可以删除文章，但需要检查权限
+		// 如果当前用户不是超级管理员，且文章作者是超级管理员（super_admin），则禁止删除
+		if role != constant.RoleSuperAdmin && post.User.Role == constant.RoleSuperAdmin {
 			return errors.New("普通管理员无权删除超级管理员创建的文章")
 		}
 		// 超级管理员（super_admin）可以删除任何文章，包括普通管理员创建的文章
@@ -498,15 +668,7 @@ func (s *PostService) Delete(id, userID uint, role string) error {
 	}
 
 	// 删除成功后，清理与文章列表相关的缓存（异步执行，不阻塞主流程）
-	go func() {
-		ctx := context.Background()
-		for _, limit := range []int{5, 10, 20} {
-			key := fmt.Sprintf("post:recent:%d", limit)
-			db.RDB.Del(ctx, key)
-		}
-		db.RDB.Del(ctx, "blog:author_profile")
-		db.RDB.Del(ctx, "tag:stats:top10")
-	}()
+	go clearPostCaches()
 
 	return nil
 }
@@ -520,7 +682,7 @@ func (s *PostService) List(page, pageSize int, categoryID uint, keyword string,
 		pageSize = 10
 	}
 
-	return s.postRepo.List(page, pageSize, categoryID, keyword, status, visibility)
+	return s.postRepo.List(page, pageSize, categoryID, 0, keyword, status, visibility)
 }
 
 // GetByTag 根据标签获取文章
@@ -606,12 +768,13 @@ func (s *PostService) GetHotPosts(limit int) ([]model.Post, error) {
 }
 
 // GetRecentPosts 获取最新文章
-func (s *PostService) GetRecentPosts(limit int, userID *uint, role string) ([]model.Post, error) {
+// canManage 为 true（具备 post.manage 权限）时额外包含当前用户自己的私密文章
+func (s *PostService) GetRecentPosts(limit int, userID *uint, canManage bool) ([]model.Post, error) {
 	if limit < 1 || limit > 50 {
 		limit = 10
 	}
-	// 对公开接口的最新文章列表做缓存（管理员等角色视图通常不缓存）
-	if role == "" || role == constant.RoleUser {
+	// 对公开接口的最新文章列表做缓存（能看到自己私密文章的角色视图不缓存）
+	if !canManage || userID == nil {
 		ctx := context.Background()
 		cacheKey := fmt.Sprintf("post:recent:%d", limit)
 
@@ -625,7 +788,7 @@ func (s *PostService) GetRecentPosts(limit int, userID *uint, role string) ([]mo
 		}
 
 		// 2. 缓存未命中，从数据库获取
-		posts, err := s.postRepo.GetRecentPosts(limit, userID, role)
+		posts, err := s.postRepo.GetRecentPosts(limit, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -639,7 +802,7 @@ func (s *PostService) GetRecentPosts(limit int, userID *uint, role string) ([]mo
 	}
 
 	// 管理员等角色直接走数据库，避免缓存带来的视图差异
-	return s.postRepo.GetRecentPosts(limit, userID, role)
+	return s.postRepo.GetRecentPosts(limit, userID)
 }
 
 // GetByIDForAdmin 管理端获取文章（不计浏览、无权限限制）
@@ -653,3 +816,23 @@ func (s *PostService) GetByIDForAdmin(id uint) (*model.Post, error) {
 	}
 	return post, nil
 }
+
+// clearPostCaches 清理与文章列表相关的缓存
+// 文章的新增、更新、删除以及定时发布都会影响最新文章、博主资料、标签统计、订阅源和站点地图
+func clearPostCaches() {
+	ctx := context.Background()
+
+	// 最新文章缓存按 limit 区分，使用 SCAN 清理所有 post:recent:* 键
+	iter := db.RDB.Scan(ctx, 0, "post:recent:*", 100).Iterator()
+	for iter.Next(ctx) {
+		db.RDB.Del(ctx, iter.Val())
+	}
+
+	// 文章数、标签统计等也会受影响，清理相关缓存
+	db.RDB.Del(ctx, "blog:author_profile")
+	db.RDB.Del(ctx, "tag:stats:top10")
+
+	// 订阅源和站点地图内容来自已发布文章
+	clearFeedCaches()
+	clearSitemapCaches()
+}
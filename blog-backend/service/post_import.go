@@ -0,0 +1,496 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：post_import.go
+ * 创建时间：2026-10-17 13:40:08
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：文章导入业务逻辑层，解析带 YAML Front Matter 的 Markdown 文件（单文件或 zip 压缩包）并批量创建文章，
+ *          与文章导出格式互通，兼容 Hexo/Hugo 常见字段
+ */
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"blog-backend/model"
+	"blog-backend/repository"
+	"blog-backend/util"
+
+	"go.yaml.in/yaml/v3"
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxImportFileSize 单个 Markdown 文件的最大大小（5MB）
+	MaxImportFileSize = 5 << 20
+	// MaxImportArchiveSize 上传压缩包的最大大小（20MB）
+	MaxImportArchiveSize = 20 << 20
+	// maxImportArchiveFiles 压缩包内最多处理的 Markdown 文件数量
+	maxImportArchiveFiles = 500
+	// maxImportArchiveTotal 压缩包解压后的总大小上限（100MB），防止压缩炸弹
+	maxImportArchiveTotal = 100 << 20
+)
+
+// 导入结果状态
+const (
+	ImportStatusCreated = "created" // 已创建
+	ImportStatusSkipped = "skipped" // 已跳过（slug 重复）
+	ImportStatusFailed  = "failed"  // 失败
+)
+
+// validImportSlug Front Matter 中自带 slug 的合法格式（与 slug 迁移工具一致）
+var validImportSlug = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// importDateLayouts 支持的日期格式（导出格式为 RFC3339，其余为 Hexo/Hugo 常见写法）
+var importDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ImportFileResult 单个文件的导入结果
+type ImportFileResult struct {
+	File   string `json:"file"`
+	Status string `json:"status"` // created / skipped / failed
+	Title  string `json:"title,omitempty"`
+	Slug   string `json:"slug,omitempty"`
+	PostID uint   `json:"post_id,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ImportReport 导入报告
+type ImportReport struct {
+	Total   int                `json:"total"`
+	Created int                `json:"created"`
+	Skipped int                `json:"skipped"`
+	Failed  int                `json:"failed"`
+	Files   []ImportFileResult `json:"files"`
+}
+
+// add 追加单个文件结果并更新统计
+func (r *ImportReport) add(result ImportFileResult) {
+	r.Total++
+	switch result.Status {
+	case ImportStatusCreated:
+		r.Created++
+	case ImportStatusSkipped:
+		r.Skipped++
+	default:
+		r.Failed++
+	}
+	r.Files = append(r.Files, result)
+}
+
+// importFrontMatter Front Matter 字段（兼容导出格式与 Hexo/Hugo）
+type importFrontMatter struct {
+	Title       string      `yaml:"title"`
+	Slug        string      `yaml:"slug"`
+	Date        string      `yaml:"date"`
+	Updated     string      `yaml:"updated"`
+	LastMod     string      `yaml:"lastmod"`
+	Status      *int        `yaml:"status"`
+	Draft       bool        `yaml:"draft"`
+	Category    interface{} `yaml:"category"`
+	Categories  interface{} `yaml:"categories"`
+	Tags        interface{} `yaml:"tags"`
+	Summary     string      `yaml:"summary"`
+	Description string      `yaml:"description"`
+	Cover       string      `yaml:"cover"`
+	Image       string      `yaml:"image"`
+}
+
+// PostImportService 文章导入业务逻辑层结构体
+type PostImportService struct {
+	postRepo     *repository.PostRepository
+	categoryRepo *repository.CategoryRepository
+	tagRepo      *repository.TagRepository
+}
+
+// NewPostImportService 创建文章导入业务逻辑层实例
+func NewPostImportService() *PostImportService {
+	return &PostImportService{
+		postRepo:     repository.NewPostRepository(),
+		categoryRepo: repository.NewCategoryRepository(),
+		tagRepo:      repository.NewTagRepository(),
+	}
+}
+
+// Import 导入上传的文件（.md 单文件或 .zip 压缩包）
+// 参数:
+//   - userID: 导入者ID（作为文章作者）
+//   - filename: 上传的文件名
+//   - data: 文件内容
+//   - defaultCategoryID: Front Matter 未指定分类时使用的分类ID（0 表示不设置默认分类）
+//
+// 返回:
+//   - *ImportReport: 每个文件的导入结果
+//   - error: 文件类型不支持或压缩包无法解析时返回错误
+func (s *PostImportService) Import(userID uint, filename string, data []byte, defaultCategoryID uint) (*ImportReport, error) {
+	report := &ImportReport{Files: make([]ImportFileResult, 0)}
+
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".md", ".markdown":
+		if len(data) > MaxImportFileSize {
+			return nil, errors.New("文件大小超过限制（最大 5MB）")
+		}
+		report.add(s.importMarkdown(userID, filename, data, defaultCategoryID))
+	case ".zip":
+		if err := s.importArchive(userID, data, defaultCategoryID, report); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("仅支持 .md 文件或 .zip 压缩包")
+	}
+
+	if report.Created > 0 {
+		go clearPostCaches()
+	}
+
+	return report, nil
+}
+
+// importArchive 逐个导入压缩包中的 Markdown 文件
+func (s *PostImportService) importArchive(userID uint, data []byte, defaultCategoryID uint, report *ImportReport) error {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return errors.New("无法解析压缩包")
+	}
+
+	var totalSize int64
+	for _, f := range reader.File {
+		name := f.Name
+		base := path.Base(name)
+		ext := strings.ToLower(path.Ext(name))
+
+		// 跳过目录、隐藏文件、macOS 元数据以及非 Markdown 文件
+		if f.FileInfo().IsDir() || strings.HasPrefix(base, ".") || strings.HasPrefix(name, "__MACOSX/") {
+			continue
+		}
+		if ext != ".md" && ext != ".markdown" {
+			continue
+		}
+
+		if report.Total >= maxImportArchiveFiles {
+			report.add(ImportFileResult{File: name, Status: ImportStatusFailed, Reason: fmt.Sprintf("超过单次导入上限（%d 个文件）", maxImportArchiveFiles)})
+			continue
+		}
+		if f.UncompressedSize64 > MaxImportFileSize {
+			report.add(ImportFileResult{File: name, Status: ImportStatusFailed, Reason: "文件大小超过限制（最大 5MB）"})
+			continue
+		}
+		totalSize += int64(f.UncompressedSize64)
+		if totalSize > maxImportArchiveTotal {
+			return errors.New("压缩包解压后的内容过大")
+		}
+
+		content, err := readZipFile(f)
+		if err != nil {
+			report.add(ImportFileResult{File: name, Status: ImportStatusFailed, Reason: "读取文件失败"})
+			continue
+		}
+
+		report.add(s.importMarkdown(userID, name, content, defaultCategoryID))
+	}
+
+	return nil
+}
+
+// readZipFile 读取压缩包中的单个文件，限制实际读取的字节数
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(io.LimitReader(rc, MaxImportFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > MaxImportFileSize {
+		return nil, errors.New("文件过大")
+	}
+	return content, nil
+}
+
+// importMarkdown 导入单个 Markdown 文件
+func (s *PostImportService) importMarkdown(userID uint, filename string, data []byte, defaultCategoryID uint) ImportFileResult {
+	result := ImportFileResult{File: filename, Status: ImportStatusFailed}
+
+	fm, body, err := parseFrontMatter(data)
+	if err != nil {
+		result.Reason = err.Error()
+		return result
+	}
+
+	// 标题缺失时使用文件名
+	title := strings.TrimSpace(fm.Title)
+	if title == "" {
+		title = strings.TrimSuffix(path.Base(filename), path.Ext(filename))
+	}
+	result.Title = title
+	if strings.TrimSpace(body) == "" {
+		result.Reason = "文章内容为空"
+		return result
+	}
+
+	// slug：优先使用 Front Matter 中的合法 slug，否则由标题生成
+	slug := strings.TrimSpace(fm.Slug)
+	if !validImportSlug.MatchString(slug) {
+		slug = util.GenerateSlug(title)
+	}
+	if slug == "" {
+		result.Reason = "无法生成文章slug，请检查标题"
+		return result
+	}
+	result.Slug = slug
+	if s.postRepo.CheckSlugExists(slug, 0) {
+		result.Status = ImportStatusSkipped
+		result.Reason = "slug已存在"
+		return result
+	}
+
+	// 日期：保留原始发布时间和更新时间
+	createdAt := time.Now()
+	if fm.Date != "" {
+		if createdAt, err = parseImportDate(fm.Date); err != nil {
+			result.Reason = "无法解析日期：" + fm.Date
+			return result
+		}
+	}
+	updatedAt := createdAt
+	for _, v := range []string{fm.Updated, fm.LastMod} {
+		if v == "" {
+			continue
+		}
+		if t, err := parseImportDate(v); err == nil {
+			updatedAt = t
+			break
+		}
+	}
+
+	// 状态：优先使用导出的 status 字段，其次 Hugo 的 draft 字段
+	status := 1
+	if fm.Status != nil {
+		status = *fm.Status
+	} else if fm.Draft {
+		status = 0
+	}
+	if status == 2 && !createdAt.After(time.Now()) {
+		// 定时发布时间已过，直接按已发布导入
+		status = 1
+	}
+	if status != 0 && status != 1 && status != 2 {
+		status = 1
+	}
+
+	// 分类：category 优先，其次 categories 的第一项
+	categoryNames := toStringList(fm.Category)
+	if len(categoryNames) == 0 {
+		categoryNames = toStringList(fm.Categories)
+	}
+	categoryID := defaultCategoryID
+	if len(categoryNames) > 0 {
+		category, err := s.getOrCreateCategory(categoryNames[0])
+		if err != nil {
+			result.Reason = "创建分类失败"
+			return result
+		}
+		categoryID = category.ID
+	}
+	if categoryID == 0 {
+		result.Reason = "缺少分类"
+		return result
+	}
+
+	// 标签：不存在时自动创建
+	tagIDs := make([]uint, 0)
+	for _, name := range toStringList(fm.Tags) {
+		tag, err := s.tagRepo.GetOrCreate(name)
+		if err != nil {
+			result.Reason = "创建标签失败：" + name
+			return result
+		}
+		tagIDs = append(tagIDs, tag.ID)
+	}
+
+	summary := fm.Summary
+	if summary == "" {
+		summary = fm.Description
+	}
+	if len([]rune(summary)) > 500 {
+		summary = string([]rune(summary)[:500])
+	}
+	cover := fm.Cover
+	if cover == "" {
+		cover = fm.Image
+	}
+
+	visibility := 1
+	if status == 0 {
+		// 与创建文章一致：草稿强制私密
+		visibility = 0
+	}
+
+	post := &model.Post{
+		Title:      title,
+		Slug:       slug,
+		Content:    body,
+		Summary:    summary,
+		Cover:      cover,
+		CategoryID: categoryID,
+		Status:     status,
+		Visibility: visibility,
+		UserID:     userID,
+	}
+	if status != 0 {
+		publishedAt := createdAt
+		post.PublishedAt = &publishedAt
+	}
+
+	err = s.postRepo.Transaction(func(tx *gorm.DB) error {
+		if err := s.postRepo.CreateTx(tx, post); err != nil {
+			return err
+		}
+		if err := s.postRepo.SetTimestampsTx(tx, post.ID, createdAt, updatedAt); err != nil {
+			return err
+		}
+
+		if len(tagIDs) > 0 {
+			if err := s.postRepo.UpdateTagsTx(tx, post.ID, tagIDs); err != nil {
+				return err
+			}
+		}
+
+		// 与创建文章一致：仅已发布文章计入分类和标签文章数
+		if status == 1 {
+			if err := s.categoryRepo.IncrementPostCountTx(tx, categoryID); err != nil {
+				return err
+			}
+			for _, tagID := range tagIDs {
+				if err := s.tagRepo.IncrementPostCountTx(tx, tagID); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		errStr := err.Error()
+		if strings.Contains(errStr, "duplicate key") || strings.Contains(errStr, "unique constraint") {
+			result.Status = ImportStatusSkipped
+			result.Reason = "slug已存在"
+			return result
+		}
+		result.Reason = "创建文章失败"
+		return result
+	}
+
+	result.Status = ImportStatusCreated
+	result.PostID = post.ID
+	return result
+}
+
+// getOrCreateCategory 按名称获取分类，不存在时创建
+func (s *PostImportService) getOrCreateCategory(name string) (*model.Category, error) {
+	if category, err := s.categoryRepo.GetByName(name); err == nil {
+		return category, nil
+	}
+	category := &model.Category{Name: name}
+	if err := s.categoryRepo.Create(category); err != nil {
+		// 并发导入时可能已被创建，重新查询一次
+		return s.categoryRepo.GetByName(name)
+	}
+	return category, nil
+}
+
+// parseFrontMatter 拆分并解析 YAML Front Matter
+// 返回:
+//   - *importFrontMatter: 解析出的字段（没有 Front Matter 时为空结构）
+//   - string: 正文内容
+//   - error: Front Matter 格式错误时返回错误
+func parseFrontMatter(data []byte) (*importFrontMatter, string, error) {
+	text := strings.TrimPrefix(string(data), "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	fm := &importFrontMatter{}
+	if strings.HasPrefix(text, "+++\n") {
+		return nil, "", errors.New("暂不支持 TOML 格式的 Front Matter")
+	}
+	if !strings.HasPrefix(text, "---\n") {
+		// 没有 Front Matter，整篇作为正文
+		return fm, text, nil
+	}
+
+	// 查找结束标记（单独一行的 --- 或 ...）
+	lines := strings.Split(text, "\n")
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if line := strings.TrimRight(lines[i], " \t"); line == "---" || line == "..." {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return nil, "", errors.New("Front Matter 缺少结束标记")
+	}
+	header := strings.Join(lines[1:end], "\n")
+	body := strings.Join(lines[end+1:], "\n")
+
+	if err := yaml.Unmarshal([]byte(header), fm); err != nil {
+		return nil, "", errors.New("Front Matter 格式错误")
+	}
+
+	return fm, strings.TrimLeft(body, "\n"), nil
+}
+
+// parseImportDate 按支持的格式解析日期（不带时区的按服务器本地时区处理）
+func parseImportDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range importDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported date: %s", value)
+}
+
+// toStringList 将 Front Matter 中的字符串或（嵌套）列表统一转换为去重后的字符串列表
+func toStringList(v interface{}) []string {
+	result := make([]string, 0)
+	seen := make(map[string]bool)
+
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch val := v.(type) {
+		case nil:
+		case []interface{}:
+			for _, item := range val {
+				walk(item)
+			}
+		case string:
+			name := strings.TrimSpace(val)
+			if name != "" && !seen[name] && len([]rune(name)) <= 50 {
+				seen[name] = true
+				result = append(result, name)
+			}
+		default:
+			walk(fmt.Sprint(val))
+		}
+	}
+	walk(v)
+
+	return result
+}
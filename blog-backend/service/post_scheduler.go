@@ -0,0 +1,144 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：post_scheduler.go
+ * 创建时间：2026-10-17 11:05:32
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：文章定时发布调度器，定期将到达发布时间的定时文章切换为已发布，支持多实例部署
+ */
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"blog-backend/db"
+	"blog-backend/logger"
+	"blog-backend/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// postSchedulerLockKey 定时发布分布式锁键，避免多个实例同时扫描
+	postSchedulerLockKey = "post:scheduler:lock"
+	// postSchedulerBatchSize 每轮最多处理的文章数量
+	postSchedulerBatchSize = 100
+)
+
+// releaseLockScript 仅当锁仍归自己持有时才删除，防止误删其他实例的锁
+const releaseLockScript = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`
+
+// PostSchedulerService 文章定时发布业务逻辑层结构体
+type PostSchedulerService struct {
+	postRepo     *repository.PostRepository
+	categoryRepo *repository.CategoryRepository
+	tagRepo      *repository.TagRepository
+}
+
+// NewPostSchedulerService 创建文章定时发布业务逻辑层实例
+func NewPostSchedulerService() *PostSchedulerService {
+	return &PostSchedulerService{
+		postRepo:     repository.NewPostRepository(),
+		categoryRepo: repository.NewCategoryRepository(),
+		tagRepo:      repository.NewTagRepository(),
+	}
+}
+
+// StartScheduler 启动定时发布任务（每分钟检查一次）
+func (s *PostSchedulerService) StartScheduler() {
+	go s.publishPeriodically(1 * time.Minute)
+}
+
+// publishPeriodically 定期发布到期文章
+func (s *PostSchedulerService) publishPeriodically(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// 立即执行一次，处理服务停机期间到期的文章
+	s.runOnce(interval)
+
+	for range ticker.C {
+		s.runOnce(interval)
+	}
+}
+
+// runOnce 获取分布式锁后执行一轮发布
+func (s *PostSchedulerService) runOnce(interval time.Duration) {
+	ctx := context.Background()
+	token := uuid.NewString()
+
+	// 锁的过期时间略短于执行间隔，实例异常退出时下一轮可以被其他实例接管
+	ok, err := db.RDB.SetNX(ctx, postSchedulerLockKey, token, interval-5*time.Second).Result()
+	if err != nil || !ok {
+		return
+	}
+	defer db.RDB.Eval(ctx, releaseLockScript, []string{postSchedulerLockKey}, token)
+
+	count, err := s.PublishDuePosts()
+	if err != nil {
+		logger.Error(fmt.Sprintf("定时发布文章失败: %v", err))
+		return
+	}
+	if count > 0 {
+		logger.Info(fmt.Sprintf("定时发布文章完成，共发布 %d 篇", count))
+	}
+}
+
+// PublishDuePosts 发布所有已到期的定时文章
+// 每篇文章单独使用事务：先按 status=2 条件切换状态，成功后再增加分类和标签的文章数，
+// 即使锁失效导致多个实例同时执行，同一篇文章的计数也只会增加一次
+// 返回:
+//   - int: 本次发布的文章数量
+//   - error: 查询失败时返回错误
+func (s *PostSchedulerService) PublishDuePosts() (int, error) {
+	posts, err := s.postRepo.ListDueScheduled(time.Now(), postSchedulerBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, post := range posts {
+		done := false
+		err := s.postRepo.Transaction(func(tx *gorm.DB) error {
+			ok, err := s.postRepo.PublishScheduledTx(tx, post.ID)
+			if err != nil || !ok {
+				return err
+			}
+
+			// 与创建文章时一致：发布后增加分类和标签文章数
+			if err := s.categoryRepo.IncrementPostCountTx(tx, post.CategoryID); err != nil {
+				return err
+			}
+			for _, tag := range post.Tags {
+				if err := s.tagRepo.IncrementPostCountTx(tx, tag.ID); err != nil {
+					return err
+				}
+			}
+
+			done = true
+			return nil
+		})
+		if err != nil {
+			logger.Error(fmt.Sprintf("定时发布文章 ID %d 失败: %v", post.ID, err))
+			continue
+		}
+		if done {
+			published++
+		}
+	}
+
+	if published > 0 {
+		clearPostCaches()
+	}
+
+	return published, nil
+}
@@ -0,0 +1,261 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：role.go
+ * 创建时间：2026-10-18 02:31:05
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：角色业务逻辑层，提供自定义角色的增删改查和内置角色初始化；
+ *          非超级管理员只能授予自己拥有的权限，也只能管理权限不超过自己的角色和用户
+ */
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"blog-backend/constant"
+	"blog-backend/logger"
+	"blog-backend/model"
+	"blog-backend/repository"
+
+	"gorm.io/gorm"
+)
+
+// roleNamePattern 角色标识格式：小写字母开头，由小写字母、数字和下划线组成
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,19}$`)
+
+// ErrRoleNotFound 角色不存在
+var ErrRoleNotFound = errors.New("角色不存在")
+
+// systemRoles 内置角色的名称和说明（与 sql/init.sql 中的初始化数据保持一致）
+var systemRoles = []model.Role{
+	{Name: constant.RoleSuperAdmin, DisplayName: "超级管理员", Description: "系统拥有者，始终拥有全部权限", IsSystem: true},
+	{Name: constant.RoleAdmin, DisplayName: "管理员", Description: "后台内容管理", IsSystem: true},
+	{Name: constant.RoleUser, DisplayName: "普通用户", Description: "注册用户的默认角色", IsSystem: true},
+}
+
+// RoleService 角色业务逻辑层结构体
+type RoleService struct {
+	repo *repository.RoleRepository
+}
+
+// NewRoleService 创建角色业务逻辑层实例
+func NewRoleService() *RoleService {
+	return &RoleService{
+		repo: repository.NewRoleRepository(),
+	}
+}
+
+// RoleRequest 创建/更新角色请求（更新时忽略 Name）
+type RoleRequest struct {
+	Name        string   `json:"name" binding:"max=20"`
+	DisplayName string   `json:"display_name" binding:"required,max=50"`
+	Description string   `json:"description" binding:"max=255"`
+	Permissions []string `json:"permissions"`
+}
+
+// RoleInfo 角色列表项
+type RoleInfo struct {
+	model.Role
+	Permissions []string `json:"permissions"`
+	UserCount   int64    `json:"user_count"`
+}
+
+// EnsureSystemRoles 补齐缺失的内置角色（服务启动时调用，已有角色的权限不会被覆盖）
+func (s *RoleService) EnsureSystemRoles() error {
+	roles := make([]model.Role, 0, len(systemRoles))
+	for _, role := range systemRoles {
+		for _, p := range constant.DefaultRolePermissions[role.Name] {
+			role.Permissions = append(role.Permissions, model.RolePermission{Permission: p})
+		}
+		roles = append(roles, role)
+	}
+	return s.repo.EnsureSystemRoles(roles)
+}
+
+// ListPermissions 获取所有可分配的权限
+func (s *RoleService) ListPermissions() []constant.PermissionInfo {
+	return constant.Permissions
+}
+
+// List 获取所有角色及其权限和用户数
+func (s *RoleService) List() ([]RoleInfo, error) {
+	roles, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.repo.CountUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]RoleInfo, 0, len(roles))
+	for _, role := range roles {
+		perms, _ := s.repo.GetPermissions(role.Name)
+		list = append(list, RoleInfo{
+			Role:        role,
+			Permissions: sortedPermissions(perms),
+			UserCount:   counts[role.Name],
+		})
+	}
+	return list, nil
+}
+
+// Create 创建自定义角色
+func (s *RoleService) Create(operatorRole string, req *RoleRequest) (*model.Role, error) {
+	name := strings.TrimSpace(req.Name)
+	if !roleNamePattern.MatchString(name) {
+		return nil, errors.New("角色标识须为2-20位小写字母、数字或下划线，且以字母开头")
+	}
+	if _, err := s.repo.GetByName(name); err == nil {
+		return nil, errors.New("角色标识已存在")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("创建角色失败")
+	}
+
+	permissions, err := s.checkGrantablePermissions(operatorRole, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &model.Role{
+		Name:        name,
+		DisplayName: strings.TrimSpace(req.DisplayName),
+		Description: strings.TrimSpace(req.Description),
+	}
+	if err := s.repo.Create(role, permissions); err != nil {
+		return nil, errors.New("创建角色失败")
+	}
+
+	logger.Info(fmt.Sprintf("创建角色 %s，权限：%s", role.Name, strings.Join(permissions, ",")))
+	return role, nil
+}
+
+// Update 更新角色名称、说明和权限（超级管理员角色不可修改，角色标识不可修改）
+func (s *RoleService) Update(operatorRole string, id uint, req *RoleRequest) (*model.Role, error) {
+	role, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, errors.New("获取角色失败")
+	}
+	if role.Name == constant.RoleSuperAdmin {
+		return nil, errors.New("超级管理员角色始终拥有全部权限，不能修改")
+	}
+	if !s.CanManageRole(operatorRole, role.Name) {
+		return nil, errors.New("不能修改权限超出自身的角色")
+	}
+
+	permissions, err := s.checkGrantablePermissions(operatorRole, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role.DisplayName = strings.TrimSpace(req.DisplayName)
+	role.Description = strings.TrimSpace(req.Description)
+	if err := s.repo.Update(role, permissions); err != nil {
+		return nil, errors.New("更新角色失败")
+	}
+
+	logger.Info(fmt.Sprintf("更新角色 %s，权限：%s", role.Name, strings.Join(permissions, ",")))
+	return role, nil
+}
+
+// Delete 删除自定义角色（内置角色和仍有用户使用的角色不能删除）
+func (s *RoleService) Delete(operatorRole string, id uint) (*model.Role, error) {
+	role, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, errors.New("获取角色失败")
+	}
+	if role.IsSystem {
+		return nil, errors.New("内置角色不能删除")
+	}
+	if !s.CanManageRole(operatorRole, role.Name) {
+		return nil, errors.New("不能删除权限超出自身的角色")
+	}
+
+	deleted, err := s.repo.Delete(role)
+	if err != nil {
+		return nil, errors.New("删除角色失败")
+	}
+	if !deleted {
+		return nil, errors.New("仍有用户使用该角色，请先为这些用户更换角色")
+	}
+	return role, nil
+}
+
+// CanManageRole 判断操作者能否管理目标角色（及属于该角色的用户）
+// 超级管理员可以管理除自身角色外的任意角色；其他角色只能管理权限是自己子集的角色
+func (s *RoleService) CanManageRole(operatorRole, targetRole string) bool {
+	if targetRole == constant.RoleSuperAdmin {
+		return false
+	}
+	if operatorRole == constant.RoleSuperAdmin {
+		return true
+	}
+
+	operatorPerms, err := s.repo.GetPermissions(operatorRole)
+	if err != nil {
+		return false
+	}
+	targetPerms, err := s.repo.GetPermissions(targetRole)
+	if err != nil {
+		return false
+	}
+	for p := range targetPerms {
+		if !operatorPerms[p] {
+			return false
+		}
+	}
+	return true
+}
+
+// GetPermissions 获取角色拥有的权限列表（按定义顺序排列）
+func (s *RoleService) GetPermissions(roleName string) []string {
+	perms, err := s.repo.GetPermissions(roleName)
+	if err != nil {
+		return []string{}
+	}
+	return sortedPermissions(perms)
+}
+
+// checkGrantablePermissions 校验并去重要授予的权限，非超级管理员只能授予自己拥有的权限
+func (s *RoleService) checkGrantablePermissions(operatorRole string, permissions []string) ([]string, error) {
+	seen := make(map[string]bool, len(permissions))
+	result := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		p = strings.TrimSpace(p)
+		if !constant.IsValidPermission(p) {
+			return nil, fmt.Errorf("无效的权限: %s", p)
+		}
+		if !s.repo.HasPermission(operatorRole, p) {
+			return nil, fmt.Errorf("不能授予自己没有的权限: %s", p)
+		}
+		if !seen[p] {
+			seen[p] = true
+			result = append(result, p)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// sortedPermissions 将权限集合按 constant.Permissions 的定义顺序转换为列表
+func sortedPermissions(perms map[string]bool) []string {
+	list := make([]string, 0, len(perms))
+	for _, p := range constant.Permissions {
+		if perms[p.Name] {
+			list = append(list, p.Name)
+		}
+	}
+	return list
+}
@@ -0,0 +1,268 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：session.go
+ * 创建时间：2026-10-17 21:58:02
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：登录会话业务逻辑层，签发短期访问令牌和轮换式刷新令牌，检测刷新令牌重放，
+ *          并提供会话列表和吊销功能
+ */
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"blog-backend/config"
+	"blog-backend/logger"
+	"blog-backend/model"
+	"blog-backend/repository"
+	"blog-backend/util"
+
+	"gorm.io/gorm"
+)
+
+// 会话吊销原因
+const (
+	SessionRevokeLogout   = "logout"         // 用户登出
+	SessionRevokeUser     = "user"           // 用户在会话列表中手动吊销
+	SessionRevokePassword = "password"       // 修改或重置密码
+	SessionRevokeAccount  = "account"        // 账号被禁用、删除或角色变更
+	SessionRevokeReuse    = "reuse_detected" // 检测到刷新令牌重放
+)
+
+var (
+	// ErrInvalidRefreshToken 刷新令牌无效或已过期
+	ErrInvalidRefreshToken = errors.New("刷新令牌无效或已过期，请重新登录")
+	// ErrRefreshTokenReused 刷新令牌被重复使用
+	ErrRefreshTokenReused = errors.New("检测到刷新令牌被重复使用，该会话已失效，请重新登录")
+	// ErrSessionNotFound 会话不存在
+	ErrSessionNotFound = errors.New("会话不存在")
+)
+
+// SessionService 登录会话业务逻辑层结构体
+type SessionService struct {
+	repo     *repository.SessionRepository
+	userRepo *repository.UserRepository
+}
+
+// NewSessionService 创建登录会话业务逻辑层实例
+func NewSessionService() *SessionService {
+	return &SessionService{
+		repo:     repository.NewSessionRepository(),
+		userRepo: repository.NewUserRepository(),
+	}
+}
+
+// TokenPair 访问令牌和刷新令牌
+type TokenPair struct {
+	Token            string    `json:"token"`              // 访问令牌（JWT）
+	ExpiresIn        int64     `json:"expires_in"`         // 访问令牌有效期（秒）
+	RefreshToken     string    `json:"refresh_token"`      // 刷新令牌（不透明字符串，只能使用一次）
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"` // 刷新令牌过期时间
+}
+
+// SessionInfo 会话列表项
+type SessionInfo struct {
+	model.UserSession
+	Current bool `json:"current"` // 是否为当前请求所在的会话
+}
+
+// CreateSession 为登录成功的用户创建会话并签发令牌
+// 参数:
+//   - user: 登录用户
+//   - ip: 客户端IP
+//   - userAgent: 客户端User-Agent
+func (s *SessionService) CreateSession(user *model.User, ip, userAgent string) (*TokenPair, error) {
+	now := time.Now()
+	expiresAt := now.Add(refreshTokenTTL())
+
+	session := &model.UserSession{
+		UserID:     user.ID,
+		Device:     util.DescribeUserAgent(userAgent),
+		IP:         ip,
+		UserAgent:  userAgent,
+		LastUsedAt: now,
+		ExpiresAt:  expiresAt,
+	}
+	rawToken, token := newRefreshToken(expiresAt)
+	if err := s.repo.Create(session, token); err != nil {
+		return nil, err
+	}
+
+	return s.issue(user, session.ID, rawToken, expiresAt)
+}
+
+// Refresh 使用刷新令牌换取新的访问令牌和刷新令牌（旧刷新令牌随即失效）
+// 已使用过的刷新令牌再次出现时视为泄露，吊销整个会话
+func (s *SessionService) Refresh(rawToken, ip, userAgent string) (*TokenPair, error) {
+	token, err := s.repo.GetRefreshToken(hashRefreshToken(rawToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	session := token.Session
+	now := time.Now()
+	if session == nil || session.RevokedAt != nil || now.After(session.ExpiresAt) || now.After(token.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if token.UsedAt != nil {
+		s.revokeReused(session)
+		return nil, ErrRefreshTokenReused
+	}
+
+	user, err := s.userRepo.GetByID(session.UserID)
+	if err != nil || user.Status != 1 {
+		s.revoke(session.ID, SessionRevokeAccount)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	expiresAt := now.Add(refreshTokenTTL())
+	newRaw, newToken := newRefreshToken(expiresAt)
+	newToken.SessionID = session.ID
+	session.IP = ip
+	session.UserAgent = userAgent
+	session.LastUsedAt = now
+	session.ExpiresAt = expiresAt
+
+	rotated, err := s.repo.Rotate(token.ID, newToken, session)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// 同一令牌被并发使用，另一个请求已完成轮换
+		s.revokeReused(session)
+		return nil, ErrRefreshTokenReused
+	}
+
+	return s.issue(user, session.ID, newRaw, expiresAt)
+}
+
+// ListSessions 获取用户的有效会话
+// 参数:
+//   - currentSessionID: 当前请求所在的会话ID（用于标记 current）
+func (s *SessionService) ListSessions(userID, currentSessionID uint) ([]SessionInfo, error) {
+	sessions, err := s.repo.ListActiveByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		list = append(list, SessionInfo{UserSession: session, Current: session.ID == currentSessionID})
+	}
+	return list, nil
+}
+
+// RevokeSession 吊销用户的指定会话
+func (s *SessionService) RevokeSession(userID, sessionID uint) error {
+	session, err := s.repo.GetByID(sessionID)
+	if err != nil || session.UserID != userID || session.RevokedAt != nil {
+		return ErrSessionNotFound
+	}
+	return s.revoke(sessionID, SessionRevokeUser)
+}
+
+// RevokeAllSessions 吊销用户的所有会话
+// 参数:
+//   - exceptSessionID: 保留的会话ID（0 表示全部吊销，包括当前会话）
+//
+// 返回:
+//   - int: 被吊销的会话数
+func (s *SessionService) RevokeAllSessions(userID, exceptSessionID uint) (int, error) {
+	ids, err := s.repo.RevokeAllByUser(userID, exceptSessionID, SessionRevokeUser)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		_ = util.RevokeSessionTokens(id)
+	}
+	return len(ids), nil
+}
+
+// Logout 登出：吊销访问令牌所在的会话以及该访问令牌本身
+func (s *SessionService) Logout(claims *util.Claims) error {
+	if claims.SessionID != 0 {
+		if err := s.revoke(claims.SessionID, SessionRevokeLogout); err != nil {
+			return err
+		}
+	}
+	return util.RevokeToken(claims)
+}
+
+// issue 签发访问令牌并组装令牌对
+func (s *SessionService) issue(user *model.User, sessionID uint, refreshToken string, refreshExpiresAt time.Time) (*TokenPair, error) {
+	accessToken, err := util.GenerateToken(user.ID, user.Username, user.Role, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		Token:            accessToken,
+		ExpiresIn:        int64(util.AccessTokenTTL() / time.Second),
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+	}, nil
+}
+
+// revoke 吊销会话及其已签发的访问令牌
+func (s *SessionService) revoke(sessionID uint, reason string) error {
+	if _, err := s.repo.Revoke(sessionID, reason); err != nil {
+		return err
+	}
+	return util.RevokeSessionTokens(sessionID)
+}
+
+// revokeReused 检测到刷新令牌重放时吊销会话并记录日志
+func (s *SessionService) revokeReused(session *model.UserSession) {
+	logger.Info(fmt.Sprintf("检测到刷新令牌重放，吊销会话 %d（用户 %d）", session.ID, session.UserID))
+	if err := s.revoke(session.ID, SessionRevokeReuse); err != nil {
+		logger.Error(fmt.Sprintf("吊销会话 %d 失败: %v", session.ID, err))
+	}
+}
+
+// revokeAllUserSessions 吊销用户的所有会话、此前签发的所有访问令牌以及个人访问令牌
+// 用于修改/重置密码、禁用或删除账号、变更角色等场景
+func revokeAllUserSessions(userID uint, reason string) {
+	if _, err := repository.NewSessionRepository().RevokeAllByUser(userID, 0, reason); err != nil {
+		logger.Error(fmt.Sprintf("吊销用户 %d 的会话失败: %v", userID, err))
+	}
+	if err := util.RevokeUserTokens(userID); err != nil {
+		logger.Error(fmt.Sprintf("吊销用户 %d 的访问令牌失败: %v", userID, err))
+	}
+	if err := repository.NewAccessTokenRepository().RevokeAllByUser(userID); err != nil {
+		logger.Error(fmt.Sprintf("吊销用户 %d 的个人访问令牌失败: %v", userID, err))
+	}
+}
+
+// newRefreshToken 生成刷新令牌，返回原始令牌（只下发给客户端）和待入库的哈希记录
+func newRefreshToken(expiresAt time.Time) (string, *model.RefreshToken) {
+	raw := util.GenerateRandomString(48)
+	return raw, &model.RefreshToken{
+		TokenHash: hashRefreshToken(raw),
+		ExpiresAt: expiresAt,
+	}
+}
+
+// hashRefreshToken 计算刷新令牌的 SHA-256 哈希
+func hashRefreshToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// refreshTokenTTL 刷新令牌有效期（默认30天）
+func refreshTokenTTL() time.Duration {
+	days := 30
+	if config.Cfg.JWT.RefreshExpireDays > 0 {
+		days = config.Cfg.JWT.RefreshExpireDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
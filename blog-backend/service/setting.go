@@ -12,9 +12,14 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
+	"blog-backend/config"
+	"blog-backend/logger"
 	"blog-backend/model"
 	"blog-backend/repository"
+	"blog-backend/util"
 	"time"
 )
 
@@ -58,7 +63,62 @@ func (s *SettingService) UpdateSiteSettings(data map[string]string) error {
 		})
 	}
 
-	return s.repo.BatchUpsert(settings)
+	if err := s.repo.BatchUpsert(settings); err != nil {
+		return err
+	}
+
+	// 网站名称、地址和订阅输出模式都会影响订阅源和站点地图
+	clearFeedCaches()
+	clearSitemapCaches()
+	return nil
+}
+
+// loadSiteInfo 读取网站配置分组，并补全网站名称和地址（去掉末尾的 /）的默认值
+// 供订阅源、站点地图等需要生成站点绝对地址的功能使用
+func loadSiteInfo(repo *repository.SettingRepository) map[string]string {
+	site := map[string]string{}
+	if settings, err := repo.GetByGroup("site"); err == nil {
+		for _, setting := range settings {
+			site[setting.Key] = setting.Value
+		}
+	}
+	if site["site_name"] == "" {
+		site["site_name"] = "博客"
+	}
+	site["site_url"] = strings.TrimRight(site["site_url"], "/")
+	if site["site_url"] == "" {
+		site["site_url"] = "http://localhost:3000"
+	}
+	return site
+}
+
+// newEmailConfig 获取发送邮件使用的配置（发件人信息来自配置文件，网站名称来自站点设置）
+func newEmailConfig(repo *repository.SettingRepository) util.EmailConfig {
+	return util.EmailConfig{
+		Host:     config.Cfg.Email.Host,
+		Port:     config.Cfg.Email.Port,
+		Username: config.Cfg.Email.Username,
+		Password: config.Cfg.Email.Password,
+		FromName: config.Cfg.Email.FromName,
+		SiteName: loadSiteInfo(repo)["site_name"],
+	}
+}
+
+// sendEmailAsync 异步发送邮件，避免阻塞请求；邮件未配置时跳过，发送失败时记录日志
+// 参数:
+//   - purpose: 邮件用途（用于日志，如"免密登录邮件"）
+//   - to: 收件人
+//   - send: 使用邮件配置发送邮件
+func sendEmailAsync(repo *repository.SettingRepository, purpose, to string, send func(cfg util.EmailConfig) error) {
+	if config.Cfg.Email.Host == "" || config.Cfg.Email.Username == "" {
+		logger.Info(fmt.Sprintf("邮件未配置，跳过发送%s (%s)", purpose, to))
+		return
+	}
+	go func() {
+		if err := send(newEmailConfig(repo)); err != nil {
+			logger.Error(fmt.Sprintf("发送%s失败 (%s): %v", purpose, to, err))
+		}
+	}()
 }
 
 // GetPublicSettings 获取公开的网站配置（前端用）
@@ -217,16 +277,15 @@ func (s *SettingService) UpdateNotificationSettings(data map[string]string) erro
 
 // GetRegisterSettings 获取注册配置
 func (s *SettingService) GetRegisterSettings() (map[string]string, error) {
-	setting, err := s.repo.GetByKey("disable_register")
-	if err != nil {
-		// 如果配置不存在，返回默认值（允许注册）
-		return map[string]string{
-			"disable_register": "0",
-		}, nil
+	// 配置不存在时返回默认值（允许注册、开放注册）
+	disableRegister := "0"
+	if setting, err := s.repo.GetByKey("disable_register"); err == nil {
+		disableRegister = setting.Value
 	}
 
 	return map[string]string{
-		"disable_register": setting.Value,
+		"disable_register":     disableRegister,
+		registerModeSettingKey: loadRegisterMode(s.repo),
 	}, nil
 }
 
@@ -234,7 +293,7 @@ func (s *SettingService) GetRegisterSettings() (map[string]string, error) {
 func (s *SettingService) UpdateRegisterSettings(data map[string]string) error {
 	var settings []model.Setting
 
-	// 只允许修改 disable_register
+	// 只允许修改 disable_register 和 register_mode
 	if disableRegister, ok := data["disable_register"]; ok {
 		// 验证值只能是 "0" 或 "1"
 		if disableRegister != "0" && disableRegister != "1" {
@@ -251,6 +310,57 @@ func (s *SettingService) UpdateRegisterSettings(data map[string]string) error {
 		})
 	}
 
+	// 注册方式：open-开放注册，invite-仅限邀请码注册，approval-注册后需管理员审核
+	if mode, ok := data[registerModeSettingKey]; ok {
+		if mode != RegisterModeOpen && mode != RegisterModeInvite && mode != RegisterModeApproval {
+			return errors.New("register_mode 值只能是 open、invite 或 approval")
+		}
+
+		settings = append(settings, model.Setting{
+			Group:     "site",
+			Key:       registerModeSettingKey,
+			Value:     mode,
+			Type:      "text",
+			Label:     "注册方式",
+			UpdatedAt: time.Now(),
+		})
+	}
+
+	return s.repo.BatchUpsert(settings)
+}
+
+// GetSecuritySettings 获取安全配置
+func (s *SettingService) GetSecuritySettings() (map[string]string, error) {
+	value := "0"
+	if setting, err := s.repo.GetByKey(forceAdmin2FASettingKey); err == nil && setting.Value != "" {
+		value = setting.Value
+	}
+
+	return map[string]string{
+		forceAdmin2FASettingKey: value,
+	}, nil
+}
+
+// UpdateSecuritySettings 更新安全配置
+func (s *SettingService) UpdateSecuritySettings(data map[string]string) error {
+	var settings []model.Setting
+
+	// 只允许修改 force_admin_2fa（开启后所有管理员角色必须启用两步验证）
+	if forceAdmin2FA, ok := data[forceAdmin2FASettingKey]; ok {
+		if forceAdmin2FA != "0" && forceAdmin2FA != "1" {
+			return errors.New("force_admin_2fa 值只能是 0 或 1")
+		}
+
+		settings = append(settings, model.Setting{
+			Group:     "security",
+			Key:       forceAdmin2FASettingKey,
+			Value:     forceAdmin2FA,
+			Type:      "text",
+			Label:     "强制管理员启用两步验证",
+			UpdatedAt: time.Now(),
+		})
+	}
+
 	return s.repo.BatchUpsert(settings)
 }
 
@@ -0,0 +1,289 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：sitemap.go
+ * 创建时间：2026-10-17 17:24:50
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：站点地图业务逻辑层，生成 sitemap.xml（文章较多时输出站点地图索引）和 robots.txt，使用Redis缓存
+ */
+package service
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog-backend/db"
+	"blog-backend/model"
+	"blog-backend/repository"
+)
+
+const (
+	// sitemapPostsPerFile 单个站点地图文件包含的文章数量，超过后 /sitemap.xml 输出站点地图索引
+	sitemapPostsPerFile = 5000
+	// sitemapCacheTTL 站点地图缓存时间（文章变更时会主动清理）
+	sitemapCacheTTL = time.Hour
+	// sitemapNS 站点地图命名空间
+	sitemapNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+)
+
+// ErrSitemapNotFound 请求的站点地图分片不存在
+var ErrSitemapNotFound = errors.New("站点地图不存在")
+
+// defaultRobotsRules 未配置 robots_txt 时的默认规则（屏蔽后台、个人中心和接口）
+const defaultRobotsRules = `User-agent: *
+Disallow: /admin
+Disallow: /profile
+Disallow: /auth
+Disallow: /api/`
+
+// SitemapService 站点地图业务逻辑层结构体
+type SitemapService struct {
+	postRepo       *repository.PostRepository
+	categoryRepo   *repository.CategoryRepository
+	tagRepo        *repository.TagRepository
+	momentRepo     *repository.MomentRepository
+	friendLinkRepo *repository.FriendLinkRepository
+	settingRepo    *repository.SettingRepository
+}
+
+// NewSitemapService 创建站点地图业务逻辑层实例
+func NewSitemapService() *SitemapService {
+	return &SitemapService{
+		postRepo:       repository.NewPostRepository(),
+		categoryRepo:   repository.NewCategoryRepository(),
+		tagRepo:        repository.NewTagRepository(),
+		momentRepo:     repository.NewMomentRepository(),
+		friendLinkRepo: repository.NewFriendLinkRepository(),
+		settingRepo:    repository.NewSettingRepository(),
+	}
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	XMLNS    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapRef `xml:"sitemap"`
+}
+
+type sitemapRef struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// Sitemap 生成 /sitemap.xml
+// 文章数量不超过 sitemapPostsPerFile 时直接输出包含全部地址的 urlset，
+// 否则输出站点地图索引，指向 /sitemaps/pages.xml 和 /sitemaps/posts-N.xml
+func (s *SitemapService) Sitemap() ([]byte, error) {
+	return s.cached("sitemap:index", func() ([]byte, error) {
+		site := loadSiteInfo(s.settingRepo)
+
+		total, err := s.postRepo.CountPublic()
+		if err != nil {
+			return nil, err
+		}
+
+		if total <= sitemapPostsPerFile {
+			urls, err := s.pageURLs(site["site_url"])
+			if err != nil {
+				return nil, err
+			}
+			postURLs, err := s.postURLs(site["site_url"], 0, sitemapPostsPerFile)
+			if err != nil {
+				return nil, err
+			}
+			return marshalXML(sitemapURLSet{XMLNS: sitemapNS, URLs: append(urls, postURLs...)})
+		}
+
+		index := sitemapIndex{XMLNS: sitemapNS}
+		index.Sitemaps = append(index.Sitemaps, sitemapRef{Loc: site["site_url"] + "/sitemaps/pages.xml"})
+		files := int((total + sitemapPostsPerFile - 1) / sitemapPostsPerFile)
+		for i := 1; i <= files; i++ {
+			index.Sitemaps = append(index.Sitemaps, sitemapRef{Loc: fmt.Sprintf("%s/sitemaps/posts-%d.xml", site["site_url"], i)})
+		}
+		return marshalXML(index)
+	})
+}
+
+// Part 生成站点地图索引中的分片
+// 参数:
+//   - name: 分片文件名（pages.xml 或 posts-N.xml）
+func (s *SitemapService) Part(name string) ([]byte, error) {
+	if name == "pages.xml" {
+		return s.cached("sitemap:pages", func() ([]byte, error) {
+			urls, err := s.pageURLs(loadSiteInfo(s.settingRepo)["site_url"])
+			if err != nil {
+				return nil, err
+			}
+			return marshalXML(sitemapURLSet{XMLNS: sitemapNS, URLs: urls})
+		})
+	}
+
+	if !strings.HasPrefix(name, "posts-") || !strings.HasSuffix(name, ".xml") {
+		return nil, ErrSitemapNotFound
+	}
+	page, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, "posts-"), ".xml"))
+	if err != nil || page < 1 {
+		return nil, ErrSitemapNotFound
+	}
+
+	return s.cached(fmt.Sprintf("sitemap:posts:%d", page), func() ([]byte, error) {
+		urls, err := s.postURLs(loadSiteInfo(s.settingRepo)["site_url"], (page-1)*sitemapPostsPerFile, sitemapPostsPerFile)
+		if err != nil {
+			return nil, err
+		}
+		if len(urls) == 0 {
+			return nil, ErrSitemapNotFound
+		}
+		return marshalXML(sitemapURLSet{XMLNS: sitemapNS, URLs: urls})
+	})
+}
+
+// Robots 生成 robots.txt
+// 规则取自网站设置中的 robots_txt，未配置时使用默认规则；规则中未声明 Sitemap 时自动追加站点地图地址
+func (s *SitemapService) Robots() string {
+	site := loadSiteInfo(s.settingRepo)
+
+	rules := strings.TrimSpace(strings.ReplaceAll(site["robots_txt"], "\r\n", "\n"))
+	if rules == "" {
+		rules = defaultRobotsRules
+	}
+	if !strings.Contains(strings.ToLower(rules), "sitemap:") {
+		rules += "\n\nSitemap: " + site["site_url"] + "/sitemap.xml"
+	}
+	return rules + "\n"
+}
+
+// pageURLs 生成首页、说说、友链以及分类、标签页面的地址
+func (s *SitemapService) pageURLs(siteURL string) ([]sitemapURL, error) {
+	postsUpdated, err := s.postRepo.GetLatestPublicUpdatedAt()
+	if err != nil {
+		return nil, err
+	}
+	momentsUpdated, err := s.momentRepo.GetLatestUpdatedAt()
+	if err != nil {
+		return nil, err
+	}
+	friendLinksUpdated, err := s.friendLinkRepo.GetLatestUpdatedAt()
+	if err != nil {
+		return nil, err
+	}
+
+	urls := []sitemapURL{
+		{Loc: siteURL + "/", LastMod: formatLastMod(postsUpdated), ChangeFreq: "daily", Priority: "1.0"},
+		{Loc: siteURL + "/moments", LastMod: formatLastMod(momentsUpdated), ChangeFreq: "daily", Priority: "0.6"},
+		{Loc: siteURL + "/friend-links", LastMod: formatLastMod(friendLinksUpdated), ChangeFreq: "weekly", Priority: "0.5"},
+	}
+
+	categories, err := s.categoryRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, category := range categories {
+		// 没有文章的分类不收录
+		if category.PostCount <= 0 {
+			continue
+		}
+		urls = append(urls, sitemapURL{
+			Loc:        fmt.Sprintf("%s/category/%d", siteURL, category.ID),
+			LastMod:    formatLastMod(&category.UpdatedAt),
+			ChangeFreq: "weekly",
+			Priority:   "0.6",
+		})
+	}
+
+	tags, err := s.tagRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		if tag.PostCount <= 0 {
+			continue
+		}
+		urls = append(urls, sitemapURL{
+			Loc:        fmt.Sprintf("%s/tag/%d", siteURL, tag.ID),
+			LastMod:    formatLastMod(&tag.UpdatedAt),
+			ChangeFreq: "weekly",
+			Priority:   "0.5",
+		})
+	}
+
+	return urls, nil
+}
+
+// postURLs 生成公开文章的地址（优先使用 slug）
+func (s *SitemapService) postURLs(siteURL string, offset, limit int) ([]sitemapURL, error) {
+	posts, err := s.postRepo.ListPublicForSitemap(offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]sitemapURL, 0, len(posts))
+	for i := range posts {
+		urls = append(urls, sitemapURL{
+			Loc:        siteURL + "/post/" + postPath(&posts[i]),
+			LastMod:    formatLastMod(&posts[i].UpdatedAt),
+			ChangeFreq: "monthly",
+			Priority:   "0.8",
+		})
+	}
+	return urls, nil
+}
+
+// cached 读取站点地图缓存，未命中时生成并写入缓存
+func (s *SitemapService) cached(key string, build func() ([]byte, error)) ([]byte, error) {
+	ctx := context.Background()
+	if data, err := db.RDB.Get(ctx, key).Bytes(); err == nil {
+		return data, nil
+	}
+
+	data, err := build()
+	if err != nil {
+		return nil, err
+	}
+	db.RDB.Set(ctx, key, data, sitemapCacheTTL)
+	return data, nil
+}
+
+// postPath 文章在前台的路径标识，优先使用 slug，没有时回退为ID
+func postPath(post *model.Post) string {
+	if post.Slug != "" {
+		return post.Slug
+	}
+	return strconv.FormatUint(uint64(post.ID), 10)
+}
+
+// formatLastMod 格式化 lastmod（W3C Datetime），时间为空时返回空字符串
+func formatLastMod(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// clearSitemapCaches 清理所有站点地图缓存
+func clearSitemapCaches() {
+	ctx := context.Background()
+	iter := db.RDB.Scan(ctx, 0, "sitemap:*", 100).Iterator()
+	for iter.Next(ctx) {
+		db.RDB.Del(ctx, iter.Val())
+	}
+}
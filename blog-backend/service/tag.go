@@ -125,6 +125,10 @@ func (s *TagService) Update(id uint, req *UpdateTagRequest) (*model.Tag, error)
 		db.RDB.Del(ctx, "tag:list")
 		db.RDB.Del(ctx, "tag:stats:top10")
 		db.RDB.Del(ctx, "blog:author_profile")
+
+		// 订阅源和站点地图中包含标签名称和地址
+		clearFeedCaches()
+		clearSitemapCaches()
 	}()
 
 	return tag, nil
@@ -152,6 +156,10 @@ func (s *TagService) Delete(id uint) error {
 		db.RDB.Del(ctx, "tag:list")
 		db.RDB.Del(ctx, "tag:stats:top10")
 		db.RDB.Del(ctx, "blog:author_profile")
+
+		// 订阅源和站点地图中包含标签名称和地址
+		clearFeedCaches()
+		clearSitemapCaches()
 	}()
 
 	return nil
@@ -0,0 +1,366 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：two_factor.go
+ * 创建时间：2026-10-17 23:04:51
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：两步验证业务逻辑层，提供TOTP绑定（生成密钥、首个验证码确认）、停用、恢复码管理，
+ *          以及登录时的两步验证挑战（密码验证通过后签发短期挑战令牌，验证码通过后再创建会话）
+ */
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"blog-backend/constant"
+	"blog-backend/db"
+	"blog-backend/logger"
+	"blog-backend/model"
+	"blog-backend/repository"
+	"blog-backend/util"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// twoFactorChallengeKeyPrefix 登录挑战令牌的Redis键前缀
+	twoFactorChallengeKeyPrefix = "2fa:challenge:"
+	// twoFactorChallengeTTL 登录挑战令牌有效期
+	twoFactorChallengeTTL = 5 * time.Minute
+	// twoFactorChallengeMaxAttempts 每个挑战令牌允许的验证码尝试次数
+	twoFactorChallengeMaxAttempts = 5
+	// twoFactorRecoveryCodeCount 每次生成的恢复码数量
+	twoFactorRecoveryCodeCount = 10
+	// forceAdmin2FASettingKey 强制管理员启用两步验证的设置项
+	forceAdmin2FASettingKey = "force_admin_2fa"
+)
+
+var (
+	// ErrTwoFactorChallengeInvalid 登录挑战令牌无效、已过期或尝试次数过多
+	ErrTwoFactorChallengeInvalid = errors.New("验证已过期，请重新登录")
+	// ErrTwoFactorCodeInvalid 验证码或恢复码错误
+	ErrTwoFactorCodeInvalid = errors.New("验证码错误")
+)
+
+// TwoFactorService 两步验证业务逻辑层结构体
+type TwoFactorService struct {
+	repo                *repository.TwoFactorRepository
+	userRepo            *repository.UserRepository
+	settingRepo         *repository.SettingRepository
+	sessionService      *SessionService
+	loginHistoryService *LoginHistoryService
+}
+
+// NewTwoFactorService 创建两步验证业务逻辑层实例
+func NewTwoFactorService() *TwoFactorService {
+	return &TwoFactorService{
+		repo:                repository.NewTwoFactorRepository(),
+		userRepo:            repository.NewUserRepository(),
+		settingRepo:         repository.NewSettingRepository(),
+		sessionService:      NewSessionService(),
+		loginHistoryService: NewLoginHistoryService(),
+	}
+}
+
+// TwoFactorStatus 两步验证状态
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`                  // 是否已启用
+	EnabledAt              *time.Time `json:"enabled_at"`               // 启用时间
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"` // 剩余可用恢复码数量
+	Required               bool       `json:"required"`                 // 站点是否强制当前用户启用
+}
+
+// TwoFactorSetup 绑定信息
+type TwoFactorSetup struct {
+	Secret     string `json:"secret"`      // Base32密钥（无法扫码时手动输入）
+	OTPAuthURL string `json:"otpauth_url"` // otpauth:// 配置链接（用于生成二维码）
+}
+
+// TwoFactorCodeRequest 验证码请求（TOTP验证码或恢复码）
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// DisableTwoFactorRequest 停用两步验证请求
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// TwoFactorLoginRequest 登录第二步请求
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// GetStatus 获取用户的两步验证状态
+func (s *TwoFactorService) GetStatus(userID uint) (*TwoFactorStatus, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	status := &TwoFactorStatus{Required: s.IsRequired(user.Role)}
+	tf, err := s.repo.Get(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return status, nil
+		}
+		return nil, errors.New("获取两步验证状态失败")
+	}
+	if tf.Enabled {
+		status.Enabled = true
+		status.EnabledAt = tf.EnabledAt
+		status.RecoveryCodesRemaining, _ = s.repo.CountUnusedRecoveryCodes(userID)
+	}
+	return status, nil
+}
+
+// Setup 生成新的TOTP密钥（需调用 Enable 提交首个验证码后才会生效）
+func (s *TwoFactorService) Setup(userID uint) (*TwoFactorSetup, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	if enabled, err := s.repo.IsEnabled(userID); err != nil {
+		return nil, errors.New("生成密钥失败")
+	} else if enabled {
+		return nil, errors.New("两步验证已启用，如需更换请先停用")
+	}
+
+	secret, err := util.GenerateTOTPSecret()
+	if err != nil {
+		return nil, errors.New("生成密钥失败")
+	}
+	if err := s.repo.SavePending(userID, secret); err != nil {
+		return nil, errors.New("生成密钥失败")
+	}
+
+	issuer := loadSiteInfo(s.settingRepo)["site_name"]
+	return &TwoFactorSetup{
+		Secret:     secret,
+		OTPAuthURL: util.TOTPProvisioningURI(issuer, user.Username, secret),
+	}, nil
+}
+
+// Enable 使用认证器App生成的首个验证码确认并启用两步验证
+// 返回:
+//   - []string: 恢复码明文（只返回这一次，需提示用户妥善保存）
+func (s *TwoFactorService) Enable(userID uint, req *TwoFactorCodeRequest) ([]string, error) {
+	tf, err := s.repo.Get(userID)
+	if err != nil {
+		return nil, errors.New("请先生成两步验证密钥")
+	}
+	if tf.Enabled {
+		return nil, errors.New("两步验证已启用")
+	}
+
+	step, ok := util.ValidateTOTPCode(tf.Secret, req.Code, time.Now(), tf.LastUsedStep)
+	if !ok {
+		return nil, ErrTwoFactorCodeInvalid
+	}
+
+	codes, records, err := newRecoveryCodes(userID)
+	if err != nil {
+		return nil, errors.New("生成恢复码失败")
+	}
+	enabled, err := s.repo.Enable(userID, step, records)
+	if err != nil {
+		return nil, errors.New("启用两步验证失败")
+	}
+	if !enabled {
+		return nil, errors.New("两步验证已启用")
+	}
+
+	logger.Info(fmt.Sprintf("用户 %d 启用了两步验证", userID))
+	return codes, nil
+}
+
+// Disable 停用两步验证（需验证密码和验证码，站点强制启用时管理员不能停用）
+func (s *TwoFactorService) Disable(userID uint, req *DisableTwoFactorRequest) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+	if s.IsRequired(user.Role) {
+		return errors.New("站点要求管理员必须启用两步验证，无法停用")
+	}
+	if !util.CheckPassword(req.Password, user.Password) {
+		return errors.New("密码错误")
+	}
+
+	tf, err := s.repo.Get(userID)
+	if err != nil || !tf.Enabled {
+		return errors.New("两步验证未启用")
+	}
+	if !s.verifyCode(tf, req.Code) {
+		return ErrTwoFactorCodeInvalid
+	}
+
+	if err := s.repo.Disable(userID); err != nil {
+		return errors.New("停用两步验证失败")
+	}
+
+	logger.Info(fmt.Sprintf("用户 %d 停用了两步验证", userID))
+	return nil
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码（原有恢复码全部作废）
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID uint, req *TwoFactorCodeRequest) ([]string, error) {
+	tf, err := s.repo.Get(userID)
+	if err != nil || !tf.Enabled {
+		return nil, errors.New("两步验证未启用")
+	}
+	if !s.verifyCode(tf, req.Code) {
+		return nil, ErrTwoFactorCodeInvalid
+	}
+
+	codes, records, err := newRecoveryCodes(userID)
+	if err != nil {
+		return nil, errors.New("生成恢复码失败")
+	}
+	if err := s.repo.ReplaceRecoveryCodes(userID, records); err != nil {
+		return nil, errors.New("生成恢复码失败")
+	}
+	return codes, nil
+}
+
+// IsEnabled 检查用户是否已启用两步验证
+func (s *TwoFactorService) IsEnabled(userID uint) (bool, error) {
+	return s.repo.IsEnabled(userID)
+}
+
+// IsRequired 站点是否强制该角色启用两步验证（开启后对所有可进入管理后台的角色生效）
+func (s *TwoFactorService) IsRequired(role string) bool {
+	return repository.NewRoleRepository().HasPermission(role, constant.PermAdminAccess) && isForceAdmin2FA(s.settingRepo)
+}
+
+// CreateChallenge 密码验证通过后为已启用两步验证的用户签发登录挑战令牌
+func (s *TwoFactorService) CreateChallenge(userID uint) (string, error) {
+	token := util.GenerateRandomString(32)
+	key := twoFactorChallengeKeyPrefix + token
+
+	ctx := context.Background()
+	pipe := db.RDB.TxPipeline()
+	pipe.HSet(ctx, key, "user_id", userID, "attempts", 0)
+	pipe.Expire(ctx, key, twoFactorChallengeTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// CompleteLogin 校验登录挑战令牌和验证码（或恢复码），通过后创建登录会话
+func (s *TwoFactorService) CompleteLogin(req *TwoFactorLoginRequest, ip, userAgent string) (*LoginResponse, error) {
+	ctx := context.Background()
+	key := twoFactorChallengeKeyPrefix + req.ChallengeToken
+
+	attempts, err := db.RDB.HIncrBy(ctx, key, "attempts", 1).Result()
+	if err != nil {
+		return nil, ErrTwoFactorChallengeInvalid
+	}
+	rawUserID, err := db.RDB.HGet(ctx, key, "user_id").Result()
+	if err != nil {
+		// HIncrBy 会在键不存在时创建键，此时清理掉
+		db.RDB.Del(ctx, key)
+		return nil, ErrTwoFactorChallengeInvalid
+	}
+	if attempts > twoFactorChallengeMaxAttempts {
+		db.RDB.Del(ctx, key)
+		return nil, ErrTwoFactorChallengeInvalid
+	}
+	userID, err := strconv.ParseUint(rawUserID, 10, 64)
+	if err != nil {
+		return nil, ErrTwoFactorChallengeInvalid
+	}
+
+	user, err := s.userRepo.GetByID(uint(userID))
+	if err != nil || user.Status != 1 {
+		db.RDB.Del(ctx, key)
+		return nil, ErrTwoFactorChallengeInvalid
+	}
+	tf, err := s.repo.Get(user.ID)
+	if err != nil || !tf.Enabled {
+		db.RDB.Del(ctx, key)
+		return nil, ErrTwoFactorChallengeInvalid
+	}
+	if remaining := checkLoginLock(user.ID); remaining > 0 {
+		db.RDB.Del(ctx, key)
+		s.loginHistoryService.Record(user, user.Username, model.LoginFailLocked, ip, userAgent)
+		return nil, loginLockError(remaining)
+	}
+	if !s.verifyCode(tf, req.Code) {
+		// 验证码错误同样计入账号的登录失败次数，触发锁定时作废挑战令牌
+		s.loginHistoryService.Record(user, user.Username, model.LoginFailTwoFactor, ip, userAgent)
+		if lockFor := recordLoginFailure(user.ID); lockFor > 0 {
+			db.RDB.Del(ctx, key)
+			return nil, loginLockError(lockFor)
+		}
+		return nil, ErrTwoFactorCodeInvalid
+	}
+
+	// 挑战令牌只能使用一次
+	if deleted, err := db.RDB.Del(ctx, key).Result(); err != nil || deleted == 0 {
+		return nil, ErrTwoFactorChallengeInvalid
+	}
+
+	tokens, err := s.sessionService.CreateSession(user, ip, userAgent)
+	if err != nil {
+		return nil, errors.New("Token 生成失败")
+	}
+	clearLoginFailures(user.ID)
+	s.loginHistoryService.Record(user, user.Username, "", ip, userAgent)
+	user.Permissions = NewRoleService().GetPermissions(user.Role)
+	return &LoginResponse{TokenPair: tokens, User: user}, nil
+}
+
+// verifyCode 校验TOTP验证码或恢复码（验证码使用后同一时间步内不能再次使用，恢复码只能使用一次）
+func (s *TwoFactorService) verifyCode(tf *model.UserTwoFactor, code string) bool {
+	if step, ok := util.ValidateTOTPCode(tf.Secret, code, time.Now(), tf.LastUsedStep); ok {
+		marked, err := s.repo.MarkStepUsed(tf.UserID, step)
+		return err == nil && marked
+	}
+
+	used, err := s.repo.UseRecoveryCode(tf.UserID, hashRecoveryCode(util.NormalizeRecoveryCode(code)))
+	if err != nil || !used {
+		return false
+	}
+	logger.Info(fmt.Sprintf("用户 %d 使用了两步验证恢复码", tf.UserID))
+	return true
+}
+
+// isForceAdmin2FA 读取"强制管理员启用两步验证"设置
+func isForceAdmin2FA(settingRepo *repository.SettingRepository) bool {
+	setting, err := settingRepo.GetByKey(forceAdmin2FASettingKey)
+	return err == nil && setting.Value == "1"
+}
+
+// newRecoveryCodes 生成恢复码，返回明文（下发给用户）和待入库的哈希记录
+func newRecoveryCodes(userID uint) ([]string, []model.TwoFactorRecoveryCode, error) {
+	codes, err := util.GenerateRecoveryCodes(twoFactorRecoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	records := make([]model.TwoFactorRecoveryCode, 0, len(codes))
+	for _, code := range codes {
+		records = append(records, model.TwoFactorRecoveryCode{
+			UserID:   userID,
+			CodeHash: hashRecoveryCode(code),
+		})
+	}
+	return codes, records, nil
+}
+
+// hashRecoveryCode 计算恢复码的 SHA-256 哈希
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -6,29 +6,41 @@
  * 系统用户：Administrator
  * 作　　者：無以菱
  * 联系邮箱：huangjing510@126.com
- * 功能描述：用户业务逻辑层，提供用户信息查询、状态更新、删除等业务处理
+ * 功能描述：用户业务逻辑层，提供用户信息查询、状态更新、注册审核、删除等业务处理
  */
 package service
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"blog-backend/constant"
+	"blog-backend/logger"
 	"blog-backend/model"
 	"blog-backend/repository"
+	"blog-backend/util"
 
 	"gorm.io/gorm"
 )
 
 // UserService 用户业务逻辑层结构体
 type UserService struct {
-	repo *repository.UserRepository
+	repo        *repository.UserRepository
+	roleRepo    *repository.RoleRepository
+	roleService *RoleService
+	dataRepo    *repository.UserDataRepository
+	settingRepo *repository.SettingRepository
 }
 
 // NewUserService 创建用户业务逻辑层实例
 func NewUserService() *UserService {
 	return &UserService{
-		repo: repository.NewUserRepository(),
+		repo:        repository.NewUserRepository(),
+		roleRepo:    repository.NewRoleRepository(),
+		roleService: NewRoleService(),
+		dataRepo:    repository.NewUserDataRepository(),
+		settingRepo: repository.NewSettingRepository(),
 	}
 }
 
@@ -45,7 +57,9 @@ func (s *UserService) GetByID(id uint) (*model.User, error) {
 }
 
 // List 获取用户列表
-func (s *UserService) List(page, pageSize int) ([]model.User, int64, error) {
+// 参数:
+//   - status: 按状态筛选（如只看待审核用户），为 nil 时不筛选
+func (s *UserService) List(page, pageSize int, status *int) ([]model.User, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -53,23 +67,46 @@ func (s *UserService) List(page, pageSize int) ([]model.User, int64, error) {
 		pageSize = 10
 	}
 
-	return s.repo.List(page, pageSize)
+	return s.repo.List(page, pageSize, status)
 }
 
 // UpdateStatus 更新用户状态
-func (s *UserService) UpdateStatus(id uint, status int) error {
-	if _, err := s.repo.GetByID(id); err != nil {
+// 参数:
+//   - operatorRole: 操作者角色，只能管理权限不超过自己的用户
+func (s *UserService) UpdateStatus(operatorRole string, id uint, status int) error {
+	user, err := s.repo.GetByID(id)
+	if err != nil {
 		return errors.New("用户不存在")
 	}
+	if !s.roleService.CanManageRole(operatorRole, user.Role) {
+		return errors.New("不能修改权限超出自身的用户")
+	}
+	if user.Status == model.UserStatusPending {
+		return errors.New("该用户正在等待审核，请通过审核操作处理")
+	}
+
+	if err := s.repo.UpdateStatus(id, status); err != nil {
+		return err
+	}
 
-	return s.repo.UpdateStatus(id, status)
+	// 禁用账号后立即吊销该用户的登录会话和已签发的Token
+	if status != 1 {
+		revokeAllUserSessions(id, SessionRevokeAccount)
+	}
+	return nil
 }
 
 // UpdateRole 更新用户角色
-func (s *UserService) UpdateRole(id uint, role string) error {
+// 参数:
+//   - operatorRole: 操作者角色，只能在权限不超过自己的角色之间调整
+//   - role: 目标角色标识（须为 roles 表中已存在的角色）
+func (s *UserService) UpdateRole(operatorRole string, id uint, role string) error {
 	// 验证角色值
-	if role != constant.RoleSuperAdmin && role != constant.RoleAdmin && role != constant.RoleUser {
-		return errors.New("无效的角色值")
+	if _, err := s.roleRepo.GetByName(role); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("无效的角色值")
+		}
+		return errors.New("获取角色失败")
 	}
 
 	// 检查用户是否存在
@@ -91,11 +128,90 @@ func (s *UserService) UpdateRole(id uint, role string) error {
 		return errors.New("禁止将用户升级为超级管理员，请通过数据库手动设置")
 	}
 
-	return s.repo.UpdateRole(id, role)
+	if !s.roleService.CanManageRole(operatorRole, user.Role) || !s.roleService.CanManageRole(operatorRole, role) {
+		return errors.New("不能分配或修改权限超出自身的角色")
+	}
+
+	if err := s.repo.UpdateRole(id, role); err != nil {
+		return err
+	}
+
+	// 角色变更后吊销登录会话和旧Token，新角色在重新登录后生效
+	if user.Role != role {
+		revokeAllUserSessions(id, SessionRevokeAccount)
+	}
+	return nil
+}
+
+// Approve 审核通过待审核用户，并邮件通知用户可以登录
+func (s *UserService) Approve(id uint) error {
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+
+	approved, err := s.repo.Approve(id)
+	if err != nil {
+		return errors.New("审核失败")
+	}
+	if !approved {
+		return errors.New("该用户不在待审核状态")
+	}
+
+	loginURL := loadSiteInfo(s.settingRepo)["site_url"] + "/auth/login"
+	sendEmailAsync(s.settingRepo, "注册审核结果邮件", user.Email, func(cfg util.EmailConfig) error {
+		return util.SendRegistrationApprovedEmail(cfg, user.Email, user.Username, loginURL)
+	})
+
+	logger.Info(fmt.Sprintf("用户 %d（%s）注册审核通过", user.ID, user.Username))
+	return nil
+}
+
+// Reject 拒绝待审核用户：删除账号并邮件通知原因，用户名和邮箱随之释放，可重新注册
+func (s *UserService) Reject(id uint, reason string) error {
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+
+	// 在删除事务中确认仍为待审核状态，避免删除刚被并发审核通过的账号
+	deleted, err := repository.NewUserDataRepository().PurgeUserInStatus(id, model.UserStatusPending)
+	if err != nil {
+		return errors.New("审核失败")
+	}
+	if !deleted {
+		return errors.New("该用户不在待审核状态")
+	}
+	_ = util.RevokeUserTokens(id)
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		reason = "不符合本站的注册要求"
+	}
+	sendEmailAsync(s.settingRepo, "注册审核结果邮件", user.Email, func(cfg util.EmailConfig) error {
+		return util.SendRegistrationRejectedEmail(cfg, user.Email, user.Username, reason)
+	})
+
+	logger.Info(fmt.Sprintf("用户 %d（%s）注册审核未通过，账号已删除", user.ID, user.Username))
+	return nil
+}
+
+// Unlock 解除账号因多次登录失败导致的锁定，同时清空失败次数
+func (s *UserService) Unlock(id uint) error {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return errors.New("用户不存在")
+	}
+
+	if err := deleteLoginLockKeys(id); err != nil {
+		return errors.New("解除锁定失败")
+	}
+	return nil
 }
 
 // Delete 删除用户
-func (s *UserService) Delete(id uint) error {
+// 参数:
+//   - operatorRole: 操作者角色，只能删除权限不超过自己的用户
+func (s *UserService) Delete(operatorRole string, id uint) error {
 	// 检查用户是否存在
 	user, err := s.repo.GetByID(id)
 	if err != nil {
@@ -109,6 +225,16 @@ func (s *UserService) Delete(id uint) error {
 	if user.Role == constant.RoleSuperAdmin {
 		return errors.New("禁止删除超级管理员账号")
 	}
+	if !s.roleService.CanManageRole(operatorRole, user.Role) {
+		return errors.New("不能删除权限超出自身的用户")
+	}
 
-	return s.repo.Delete(id)
+	// 与用户自助注销一致：先确认没有文章和说说，再在同一事务中清理评论、点赞、聊天消息等关联数据
+	if err := checkNoAuthoredContent(s.dataRepo, id); err != nil {
+		return err
+	}
+	if err := PurgeUser(id); err != nil {
+		return errors.New("删除用户失败")
+	}
+	return nil
 }
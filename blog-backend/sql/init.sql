@@ -36,8 +36,39 @@ COMMENT ON COLUMN users.password IS '密码（bcrypt加密）';
 COMMENT ON COLUMN users.nickname IS '昵称';
 COMMENT ON COLUMN users.avatar IS '头像URL';
 COMMENT ON COLUMN users.bio IS '个人简介';
-COMMENT ON COLUMN users.role IS '角色：super_admin-超级管理员，admin-管理员，user-普通用户';
-COMMENT ON COLUMN users.status IS '状态：1-正常，0-禁用';
+COMMENT ON COLUMN users.role IS '角色名称（对应 roles.name），内置角色：super_admin-超级管理员，admin-管理员，user-普通用户';
+COMMENT ON COLUMN users.status IS '状态：1-正常，0-禁用，2-待审核';
+
+-- 创建角色表
+CREATE TABLE IF NOT EXISTS roles (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(20) UNIQUE NOT NULL,
+    display_name VARCHAR(50) NOT NULL,
+    description VARCHAR(255),
+    is_system BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 角色表注释
+COMMENT ON TABLE roles IS '角色表（角色即权限集合）';
+COMMENT ON COLUMN roles.name IS '角色标识（写入 users.role）';
+COMMENT ON COLUMN roles.display_name IS '显示名称';
+COMMENT ON COLUMN roles.description IS '角色说明';
+COMMENT ON COLUMN roles.is_system IS '是否为内置角色（内置角色不能删除，super_admin 始终拥有全部权限）';
+
+-- 创建角色权限表
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id INTEGER NOT NULL,
+    permission VARCHAR(50) NOT NULL,
+    PRIMARY KEY (role_id, permission),
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE
+);
+
+-- 角色权限表注释
+COMMENT ON TABLE role_permissions IS '角色权限表';
+COMMENT ON COLUMN role_permissions.role_id IS '角色ID';
+COMMENT ON COLUMN role_permissions.permission IS '权限名称，如 comment.moderate、friendlink.manage、settings.upload';
 
 -- =============================================================================
 -- 2. 分类和标签系统
@@ -118,6 +149,7 @@ CREATE INDEX IF NOT EXISTS idx_posts_visibility ON posts(visibility);
 CREATE INDEX IF NOT EXISTS idx_posts_category_id ON posts(category_id);
 CREATE INDEX IF NOT EXISTS idx_posts_user_id ON posts(user_id);
 CREATE INDEX IF NOT EXISTS idx_posts_created_at ON posts(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_posts_scheduled ON posts(published_at) WHERE status = 2;
 
 -- 全文搜索索引（使用 GIN 索引用于全文搜索，组合标题和内容）
 CREATE INDEX IF NOT EXISTS idx_posts_search_gin ON posts USING gin(search_tsv);
@@ -129,14 +161,14 @@ COMMENT ON COLUMN posts.slug IS 'URL友好的标识符（拼音）';
 COMMENT ON COLUMN posts.content IS '文章内容（Markdown格式）';
 COMMENT ON COLUMN posts.summary IS '文章摘要';
 COMMENT ON COLUMN posts.cover IS '封面图URL';
-COMMENT ON COLUMN posts.status IS '状态：1-已发布，0-草稿，-1-删除';
+COMMENT ON COLUMN posts.status IS '状态：1-已发布，0-草稿，2-定时发布，-1-删除';
 COMMENT ON COLUMN posts.visibility IS '可见性：1-公开，0-私密';
 COMMENT ON COLUMN posts.is_top IS '是否置顶';
 COMMENT ON COLUMN posts.view_count IS '浏览量';
 COMMENT ON COLUMN posts.like_count IS '点赞数';
 COMMENT ON COLUMN posts.user_id IS '作者ID';
 COMMENT ON COLUMN posts.category_id IS '分类ID';
-COMMENT ON COLUMN posts.published_at IS '发布时间';
+COMMENT ON COLUMN posts.published_at IS '发布时间（定时发布时为预定发布时间）';
 COMMENT ON COLUMN posts.search_tsv IS '全文搜索向量（标题+内容）';
 
 -- 创建文章标签关联表
@@ -151,6 +183,34 @@ CREATE TABLE IF NOT EXISTS post_tags (
 -- 文章标签关联表注释
 COMMENT ON TABLE post_tags IS '文章标签关联表';
 
+-- 创建文章修订历史表
+CREATE TABLE IF NOT EXISTS post_revisions (
+    id SERIAL PRIMARY KEY,
+    post_id INT NOT NULL,
+    title VARCHAR(200),
+    content TEXT,
+    summary VARCHAR(500),
+    user_id INT,
+    remark VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- 文章修订历史表索引
+CREATE INDEX IF NOT EXISTS idx_post_revisions_post_id ON post_revisions(post_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_post_revisions_user_id ON post_revisions(user_id);
+
+-- 文章修订历史表注释
+COMMENT ON TABLE post_revisions IS '文章修订历史表（每次更新前保存旧版本快照）';
+COMMENT ON COLUMN post_revisions.post_id IS '文章ID';
+COMMENT ON COLUMN post_revisions.title IS '修订时的文章标题';
+COMMENT ON COLUMN post_revisions.content IS '修订时的文章内容（Markdown格式）';
+COMMENT ON COLUMN post_revisions.summary IS '修订时的文章摘要';
+COMMENT ON COLUMN post_revisions.user_id IS '产生该修订的编辑者ID';
+COMMENT ON COLUMN post_revisions.remark IS '修订说明';
+COMMENT ON COLUMN post_revisions.created_at IS '修订时间';
+
 -- =============================================================================
 -- 4. 评论系统
 -- =============================================================================
@@ -344,11 +404,15 @@ CREATE TABLE IF NOT EXISTS password_reset_tokens (
     email VARCHAR(100) NOT NULL,
     token VARCHAR(100) NOT NULL UNIQUE,
     code VARCHAR(6) NOT NULL,
+    purpose VARCHAR(20) NOT NULL DEFAULT '',
     expire_at TIMESTAMP NOT NULL,
     is_used BOOLEAN DEFAULT FALSE,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- 已有数据库补充验证码用途字段
+ALTER TABLE password_reset_tokens ADD COLUMN IF NOT EXISTS purpose VARCHAR(20) NOT NULL DEFAULT '';
+
 -- 密码重置令牌表索引
 CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_email ON password_reset_tokens(email);
 CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_token ON password_reset_tokens(token);
@@ -357,11 +421,12 @@ CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_t
 CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_email_code ON password_reset_tokens(email, code);
 
 -- 密码重置令牌表注释
-COMMENT ON TABLE password_reset_tokens IS '密码重置和注册验证码令牌表';
-COMMENT ON COLUMN password_reset_tokens.user_id IS '用户ID（注册时为NULL，密码重置时为实际用户ID）';
+COMMENT ON TABLE password_reset_tokens IS '密码重置、注册和修改邮箱验证码令牌表';
+COMMENT ON COLUMN password_reset_tokens.user_id IS '用户ID（注册时为NULL，密码重置和修改邮箱时为实际用户ID）';
 COMMENT ON COLUMN password_reset_tokens.email IS '用户邮箱';
 COMMENT ON COLUMN password_reset_tokens.token IS '令牌（唯一标识）';
 COMMENT ON COLUMN password_reset_tokens.code IS '6位数字验证码';
+COMMENT ON COLUMN password_reset_tokens.purpose IS '用途：register-注册，reset_password-重置密码，change_email-修改邮箱，magic_login-免密登录链接';
 COMMENT ON COLUMN password_reset_tokens.expire_at IS '过期时间（15分钟有效期）';
 COMMENT ON COLUMN password_reset_tokens.is_used IS '是否已使用';
 COMMENT ON COLUMN password_reset_tokens.created_at IS '创建时间';
@@ -372,12 +437,19 @@ CREATE TABLE IF NOT EXISTS email_change_records (
     user_id INTEGER NOT NULL,
     old_email VARCHAR(100) NOT NULL,
     new_email VARCHAR(100) NOT NULL,
+    revert_token VARCHAR(64),
+    reverted_at TIMESTAMP,
     changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 );
 
+-- 已有数据库补充撤销相关字段
+ALTER TABLE email_change_records ADD COLUMN IF NOT EXISTS revert_token VARCHAR(64);
+ALTER TABLE email_change_records ADD COLUMN IF NOT EXISTS reverted_at TIMESTAMP;
+
 -- 邮箱修改记录表索引
 CREATE INDEX IF NOT EXISTS idx_email_change_records_user_id ON email_change_records(user_id);
+CREATE INDEX IF NOT EXISTS idx_email_change_records_revert_token ON email_change_records(revert_token);
 CREATE INDEX IF NOT EXISTS idx_email_change_records_changed_at ON email_change_records(changed_at);
 
 -- 邮箱修改记录表注释
@@ -385,8 +457,258 @@ COMMENT ON TABLE email_change_records IS '邮箱修改记录表（用于限制
 COMMENT ON COLUMN email_change_records.user_id IS '用户ID';
 COMMENT ON COLUMN email_change_records.old_email IS '原邮箱地址';
 COMMENT ON COLUMN email_change_records.new_email IS '新邮箱地址';
+COMMENT ON COLUMN email_change_records.revert_token IS '撤销链接令牌的SHA-256哈希（发送到原邮箱）';
+COMMENT ON COLUMN email_change_records.reverted_at IS '撤销时间（不为空表示已恢复原邮箱）';
 COMMENT ON COLUMN email_change_records.changed_at IS '修改时间';
 
+-- 创建用户登录会话表
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    device VARCHAR(100),
+    ip VARCHAR(50),
+    user_agent TEXT,
+    last_used_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    revoke_reason VARCHAR(50),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- 用户登录会话表索引
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_id ON user_sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_expires_at ON user_sessions(expires_at);
+
+-- 用户登录会话表注释
+COMMENT ON TABLE user_sessions IS '用户登录会话表（每次登录一个会话，通过刷新令牌续期）';
+COMMENT ON COLUMN user_sessions.user_id IS '用户ID';
+COMMENT ON COLUMN user_sessions.device IS '设备描述（由User-Agent解析）';
+COMMENT ON COLUMN user_sessions.ip IS '最近一次使用的IP';
+COMMENT ON COLUMN user_sessions.user_agent IS '最近一次使用的User-Agent';
+COMMENT ON COLUMN user_sessions.last_used_at IS '最近一次登录或刷新时间';
+COMMENT ON COLUMN user_sessions.expires_at IS '会话过期时间（随刷新顺延）';
+COMMENT ON COLUMN user_sessions.revoked_at IS '吊销时间';
+COMMENT ON COLUMN user_sessions.revoke_reason IS '吊销原因';
+
+-- 创建刷新令牌表
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    session_id INTEGER NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (session_id) REFERENCES user_sessions(id) ON DELETE CASCADE
+);
+
+-- 刷新令牌表索引
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON refresh_tokens(session_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_expires_at ON refresh_tokens(expires_at);
+
+-- 刷新令牌表注释
+COMMENT ON TABLE refresh_tokens IS '刷新令牌表（只保存哈希，使用后轮换）';
+COMMENT ON COLUMN refresh_tokens.session_id IS '所属会话ID';
+COMMENT ON COLUMN refresh_tokens.token_hash IS '令牌的SHA-256哈希';
+COMMENT ON COLUMN refresh_tokens.expires_at IS '过期时间';
+COMMENT ON COLUMN refresh_tokens.used_at IS '轮换时间（不为空表示已使用，再次使用视为重放）';
+
+-- 创建用户两步验证表
+CREATE TABLE IF NOT EXISTS user_two_factors (
+    user_id INTEGER PRIMARY KEY,
+    secret VARCHAR(64) NOT NULL,
+    enabled BOOLEAN DEFAULT FALSE,
+    last_used_step BIGINT DEFAULT 0,
+    enabled_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- 用户两步验证表注释
+COMMENT ON TABLE user_two_factors IS '用户两步验证（TOTP）表';
+COMMENT ON COLUMN user_two_factors.user_id IS '用户ID';
+COMMENT ON COLUMN user_two_factors.secret IS 'Base32编码的TOTP密钥';
+COMMENT ON COLUMN user_two_factors.enabled IS '是否已启用（首个验证码确认后启用）';
+COMMENT ON COLUMN user_two_factors.last_used_step IS '最近一次验证成功的时间步（防止验证码重放）';
+COMMENT ON COLUMN user_two_factors.enabled_at IS '启用时间';
+
+-- 创建两步验证恢复码表
+CREATE TABLE IF NOT EXISTS two_factor_recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- 两步验证恢复码表索引
+CREATE INDEX IF NOT EXISTS idx_two_factor_recovery_codes_user_id ON two_factor_recovery_codes(user_id);
+
+-- 两步验证恢复码表注释
+COMMENT ON TABLE two_factor_recovery_codes IS '两步验证恢复码表（只保存哈希，每个恢复码只能使用一次）';
+COMMENT ON COLUMN two_factor_recovery_codes.user_id IS '用户ID';
+COMMENT ON COLUMN two_factor_recovery_codes.code_hash IS '恢复码的SHA-256哈希';
+COMMENT ON COLUMN two_factor_recovery_codes.used_at IS '使用时间（不为空表示已使用）';
+
+-- 创建个人访问令牌表
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    token_hint VARCHAR(20),
+    scopes VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP,
+    last_used_ip VARCHAR(45),
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- 个人访问令牌表索引
+CREATE INDEX IF NOT EXISTS idx_personal_access_tokens_user_id ON personal_access_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_personal_access_tokens_expires_at ON personal_access_tokens(expires_at);
+
+-- 个人访问令牌表注释
+COMMENT ON TABLE personal_access_tokens IS '个人访问令牌表（供脚本和CI调用接口，只保存哈希）';
+COMMENT ON COLUMN personal_access_tokens.user_id IS '所属用户ID';
+COMMENT ON COLUMN personal_access_tokens.name IS '令牌名称';
+COMMENT ON COLUMN personal_access_tokens.token_hash IS '令牌的SHA-256哈希';
+COMMENT ON COLUMN personal_access_tokens.token_hint IS '令牌末尾几位（便于辨认）';
+COMMENT ON COLUMN personal_access_tokens.scopes IS '权限范围，逗号分隔：posts:write、moments:write、upload';
+COMMENT ON COLUMN personal_access_tokens.expires_at IS '过期时间';
+COMMENT ON COLUMN personal_access_tokens.last_used_at IS '最近使用时间';
+COMMENT ON COLUMN personal_access_tokens.last_used_ip IS '最近使用的IP';
+COMMENT ON COLUMN personal_access_tokens.revoked_at IS '吊销时间（不为空表示已吊销）';
+
+-- 创建第三方账号绑定表
+CREATE TABLE IF NOT EXISTS user_oauth_bindings (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    provider VARCHAR(20) NOT NULL,
+    provider_user_id VARCHAR(100) NOT NULL,
+    provider_username VARCHAR(100),
+    avatar VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- 第三方账号绑定表索引
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_oauth_bindings_user_provider ON user_oauth_bindings(user_id, provider);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_oauth_bindings_provider_uid ON user_oauth_bindings(provider, provider_user_id);
+
+-- 第三方账号绑定表注释
+COMMENT ON TABLE user_oauth_bindings IS '第三方账号绑定表（GitHub、Gitee 等 OAuth 登录）';
+COMMENT ON COLUMN user_oauth_bindings.user_id IS '用户ID';
+COMMENT ON COLUMN user_oauth_bindings.provider IS '提供方：github、gitee';
+COMMENT ON COLUMN user_oauth_bindings.provider_user_id IS '提供方内的用户ID';
+COMMENT ON COLUMN user_oauth_bindings.provider_username IS '提供方内的登录名';
+COMMENT ON COLUMN user_oauth_bindings.avatar IS '第三方账号头像';
+COMMENT ON COLUMN user_oauth_bindings.created_at IS '绑定时间';
+
+-- 创建通行密钥表
+CREATE TABLE IF NOT EXISTS webauthn_credentials (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    credential_id VARCHAR(255) UNIQUE NOT NULL,
+    public_key TEXT NOT NULL,
+    algorithm INTEGER NOT NULL,
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    transports VARCHAR(100),
+    last_used_at TIMESTAMP,
+    last_used_ip VARCHAR(45),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- 通行密钥表索引
+CREATE INDEX IF NOT EXISTS idx_webauthn_credentials_user_id ON webauthn_credentials(user_id);
+
+-- 通行密钥表注释
+COMMENT ON TABLE webauthn_credentials IS '通行密钥表（WebAuthn 凭据，用于免密登录）';
+COMMENT ON COLUMN webauthn_credentials.user_id IS '所属用户ID';
+COMMENT ON COLUMN webauthn_credentials.name IS '通行密钥名称（便于用户辨认设备）';
+COMMENT ON COLUMN webauthn_credentials.credential_id IS '凭据ID（base64url）';
+COMMENT ON COLUMN webauthn_credentials.public_key IS 'COSE 格式公钥（base64url）';
+COMMENT ON COLUMN webauthn_credentials.algorithm IS 'COSE 签名算法：-7 ES256，-8 EdDSA，-257 RS256';
+COMMENT ON COLUMN webauthn_credentials.sign_count IS '签名计数器（用于发现被克隆的认证器）';
+COMMENT ON COLUMN webauthn_credentials.transports IS '认证器传输方式，逗号分隔';
+COMMENT ON COLUMN webauthn_credentials.last_used_at IS '最近使用时间';
+COMMENT ON COLUMN webauthn_credentials.last_used_ip IS '最近使用的IP';
+COMMENT ON COLUMN webauthn_credentials.created_at IS '注册时间';
+
+-- 创建注册邀请码表
+CREATE TABLE IF NOT EXISTS invite_codes (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(32) UNIQUE NOT NULL,
+    max_uses INTEGER NOT NULL DEFAULT 1,
+    used_count INTEGER NOT NULL DEFAULT 0,
+    expire_at TIMESTAMP,
+    note VARCHAR(100),
+    created_by INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- 注册邀请码表注释
+COMMENT ON TABLE invite_codes IS '注册邀请码表（站点设置为仅限邀请注册时使用）';
+COMMENT ON COLUMN invite_codes.code IS '邀请码';
+COMMENT ON COLUMN invite_codes.max_uses IS '最多可使用次数';
+COMMENT ON COLUMN invite_codes.used_count IS '已使用次数';
+COMMENT ON COLUMN invite_codes.expire_at IS '过期时间，NULL表示长期有效';
+COMMENT ON COLUMN invite_codes.note IS '备注';
+COMMENT ON COLUMN invite_codes.created_by IS '创建人ID';
+COMMENT ON COLUMN invite_codes.created_at IS '创建时间';
+
+-- 创建账号注销申请表
+CREATE TABLE IF NOT EXISTS account_deletion_requests (
+    user_id INTEGER PRIMARY KEY,
+    reason VARCHAR(255),
+    requested_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    scheduled_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- 账号注销申请表索引
+CREATE INDEX IF NOT EXISTS idx_account_deletion_requests_scheduled_at ON account_deletion_requests(scheduled_at);
+
+-- 账号注销申请表注释
+COMMENT ON TABLE account_deletion_requests IS '账号注销申请表（冷静期内可撤销，期满删除账号并清理个人数据）';
+COMMENT ON COLUMN account_deletion_requests.user_id IS '申请注销的用户ID';
+COMMENT ON COLUMN account_deletion_requests.reason IS '注销原因';
+COMMENT ON COLUMN account_deletion_requests.requested_at IS '申请时间';
+COMMENT ON COLUMN account_deletion_requests.scheduled_at IS '计划删除时间';
+
+-- 创建登录历史表
+CREATE TABLE IF NOT EXISTS login_history (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER,
+    username VARCHAR(50) NOT NULL,
+    success BOOLEAN NOT NULL,
+    reason VARCHAR(20),
+    ip VARCHAR(45),
+    user_agent TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- 登录历史表索引
+CREATE INDEX IF NOT EXISTS idx_login_history_user_id ON login_history(user_id);
+CREATE INDEX IF NOT EXISTS idx_login_history_created_at ON login_history(created_at DESC);
+
+-- 登录历史表注释
+COMMENT ON TABLE login_history IS '登录历史表（记录每次登录尝试，保留180天）';
+COMMENT ON COLUMN login_history.user_id IS '用户ID（用户名不存在时为空）';
+COMMENT ON COLUMN login_history.username IS '登录时提交的用户名';
+COMMENT ON COLUMN login_history.success IS '是否登录成功';
+COMMENT ON COLUMN login_history.reason IS '失败原因：password-密码错误，locked-账号锁定，disabled-账号禁用，two_factor-两步验证失败，pending-账号待审核';
+COMMENT ON COLUMN login_history.ip IS '客户端IP';
+COMMENT ON COLUMN login_history.user_agent IS '客户端User-Agent';
+
 -- =============================================================================
 -- 10. IP 黑名单系统
 -- =============================================================================
@@ -441,24 +763,73 @@ COMMENT ON COLUMN ip_whitelist.expire_at IS '过期时间，NULL表示永久有
 -- 11. 聊天室系统
 -- =============================================================================
 
+-- 创建聊天室表
+CREATE TABLE IF NOT EXISTS chat_rooms (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    type VARCHAR(20) NOT NULL, -- lobby / public / post / admin
+    post_id INTEGER UNIQUE,
+    description VARCHAR(200),
+    is_locked BOOLEAN NOT NULL DEFAULT FALSE,
+    is_archived BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- 聊天室表索引
+CREATE INDEX IF NOT EXISTS idx_chat_rooms_type ON chat_rooms(type);
+
+-- 聊天室表注释
+COMMENT ON TABLE chat_rooms IS '聊天室表';
+COMMENT ON COLUMN chat_rooms.id IS '主键ID';
+COMMENT ON COLUMN chat_rooms.name IS '聊天室名称';
+COMMENT ON COLUMN chat_rooms.type IS '类型：lobby-公共大厅，public-公开聊天室，post-文章讨论室，admin-管理员专用';
+COMMENT ON COLUMN chat_rooms.post_id IS '文章讨论室对应的文章ID';
+COMMENT ON COLUMN chat_rooms.description IS '聊天室简介';
+COMMENT ON COLUMN chat_rooms.is_locked IS '是否锁定（锁定后仅管理员可发言）';
+COMMENT ON COLUMN chat_rooms.is_archived IS '是否归档（归档后不可进入和发言）';
+COMMENT ON COLUMN chat_rooms.created_by IS '创建者ID';
+COMMENT ON COLUMN chat_rooms.created_at IS '创建时间';
+COMMENT ON COLUMN chat_rooms.updated_at IS '更新时间';
+
 -- 创建聊天消息表
 CREATE TABLE IF NOT EXISTS chat_messages (
     id SERIAL PRIMARY KEY,
     content TEXT NOT NULL,
+    room_id INTEGER,
     user_id INTEGER,
     username VARCHAR(50) NOT NULL,
     avatar VARCHAR(255),
     ip VARCHAR(45),
+    client_id VARCHAR(36),
     priority INTEGER NOT NULL DEFAULT 0, -- 0:普通 1:置顶
     target VARCHAR(20) NOT NULL DEFAULT 'announcement', -- 投递目标：announcement / chat / both
     is_broadcast BOOLEAN NOT NULL DEFAULT FALSE,
     status INTEGER NOT NULL DEFAULT 1,
+    is_recalled BOOLEAN NOT NULL DEFAULT FALSE,
+    recalled_by VARCHAR(10),
+    recalled_at TIMESTAMP,
+    edited_at TIMESTAMP,
+    original_content TEXT,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (room_id) REFERENCES chat_rooms(id) ON DELETE CASCADE,
     FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
 );
 
+ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS room_id INTEGER REFERENCES chat_rooms(id) ON DELETE CASCADE;
+ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS is_recalled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS recalled_by VARCHAR(10);
+ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS recalled_at TIMESTAMP;
+ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP;
+ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS original_content TEXT;
+ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS client_id VARCHAR(36);
+
 -- 聊天消息表索引
+CREATE INDEX IF NOT EXISTS idx_chat_messages_room_id ON chat_messages(room_id);
 CREATE INDEX IF NOT EXISTS idx_chat_messages_user_id ON chat_messages(user_id);
 CREATE INDEX IF NOT EXISTS idx_chat_messages_status ON chat_messages(status);
 CREATE INDEX IF NOT EXISTS idx_chat_messages_is_broadcast ON chat_messages(is_broadcast);
@@ -468,16 +839,73 @@ CREATE INDEX IF NOT EXISTS idx_chat_messages_created_at ON chat_messages(created
 COMMENT ON TABLE chat_messages IS '聊天消息表';
 COMMENT ON COLUMN chat_messages.id IS '主键ID';
 COMMENT ON COLUMN chat_messages.content IS '消息内容';
+COMMENT ON COLUMN chat_messages.room_id IS '所属聊天室ID（系统广播为NULL，投递到所有聊天室）';
 COMMENT ON COLUMN chat_messages.user_id IS '用户ID（NULL表示匿名用户）';
 COMMENT ON COLUMN chat_messages.username IS '用户名（登录用户为真实用户名，匿名用户为临时昵称）';
 COMMENT ON COLUMN chat_messages.avatar IS '头像URL';
 COMMENT ON COLUMN chat_messages.ip IS 'IP地址';
+COMMENT ON COLUMN chat_messages.client_id IS '发送连接的标识（匿名用户只能撤回和编辑本连接发送的消息）';
 COMMENT ON COLUMN chat_messages.priority IS '优先级：0-普通，1-置顶';
 COMMENT ON COLUMN chat_messages.is_broadcast IS '是否为系统广播';
 COMMENT ON COLUMN chat_messages.status IS '状态：1-正常，0-删除';
+COMMENT ON COLUMN chat_messages.is_recalled IS '是否已撤回';
+COMMENT ON COLUMN chat_messages.recalled_by IS '撤回者：sender-发送者本人，admin-管理员';
+COMMENT ON COLUMN chat_messages.recalled_at IS '撤回时间';
+COMMENT ON COLUMN chat_messages.edited_at IS '最后编辑时间（NULL表示未编辑）';
+COMMENT ON COLUMN chat_messages.original_content IS '首次编辑前的原始内容（仅管理端可见）';
 COMMENT ON COLUMN chat_messages.created_at IS '创建时间';
 COMMENT ON COLUMN chat_messages.updated_at IS '更新时间';
 
+-- 创建私信消息表
+CREATE TABLE IF NOT EXISTS direct_messages (
+    id SERIAL PRIMARY KEY,
+    sender_id INTEGER NOT NULL,
+    receiver_id INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    is_read BOOLEAN NOT NULL DEFAULT FALSE,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (sender_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (receiver_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- 私信消息表索引
+CREATE INDEX IF NOT EXISTS idx_direct_messages_sender_id ON direct_messages(sender_id);
+CREATE INDEX IF NOT EXISTS idx_direct_messages_receiver_id ON direct_messages(receiver_id);
+CREATE INDEX IF NOT EXISTS idx_direct_messages_pair ON direct_messages(LEAST(sender_id, receiver_id), GREATEST(sender_id, receiver_id), id DESC);
+CREATE INDEX IF NOT EXISTS idx_direct_messages_unread ON direct_messages(receiver_id, sender_id) WHERE is_read = FALSE;
+
+-- 私信消息表注释
+COMMENT ON TABLE direct_messages IS '私信消息表';
+COMMENT ON COLUMN direct_messages.id IS '主键ID';
+COMMENT ON COLUMN direct_messages.sender_id IS '发送者ID';
+COMMENT ON COLUMN direct_messages.receiver_id IS '接收者ID';
+COMMENT ON COLUMN direct_messages.content IS '消息内容';
+COMMENT ON COLUMN direct_messages.is_read IS '接收者是否已读';
+COMMENT ON COLUMN direct_messages.read_at IS '阅读时间';
+COMMENT ON COLUMN direct_messages.created_at IS '发送时间';
+
+-- 创建用户屏蔽关系表
+CREATE TABLE IF NOT EXISTS user_blocks (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    blocked_id INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (blocked_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- 用户屏蔽关系表索引
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_blocks_pair ON user_blocks(user_id, blocked_id);
+CREATE INDEX IF NOT EXISTS idx_user_blocks_blocked_id ON user_blocks(blocked_id);
+
+-- 用户屏蔽关系表注释
+COMMENT ON TABLE user_blocks IS '用户屏蔽关系表（被屏蔽的用户不能向屏蔽者发送私信）';
+COMMENT ON COLUMN user_blocks.id IS '主键ID';
+COMMENT ON COLUMN user_blocks.user_id IS '发起屏蔽的用户ID';
+COMMENT ON COLUMN user_blocks.blocked_id IS '被屏蔽的用户ID';
+COMMENT ON COLUMN user_blocks.created_at IS '屏蔽时间';
+
 -- =============================================================================
 -- 12. 初始化默认数据
 -- =============================================================================
@@ -491,6 +919,33 @@ VALUES
 ('admin', 'admin@example.com', '$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi', '管理员', '', '博客超级管理员', 'super_admin', 1, NOW(), NOW())
 ON CONFLICT (username) DO NOTHING;
 
+-- 插入聊天室公共大厅，并将升级前的聊天消息归入大厅
+INSERT INTO chat_rooms (name, type, description, created_at, updated_at)
+SELECT '大厅', 'lobby', '所有访客默认进入的公共聊天室', NOW(), NOW()
+WHERE NOT EXISTS (SELECT 1 FROM chat_rooms WHERE type = 'lobby');
+
+UPDATE chat_messages SET room_id = (SELECT id FROM chat_rooms WHERE type = 'lobby' ORDER BY id LIMIT 1)
+WHERE room_id IS NULL AND is_broadcast = FALSE;
+
+-- 插入内置角色
+INSERT INTO roles (name, display_name, description, is_system, created_at, updated_at)
+VALUES
+('super_admin', '超级管理员', '系统拥有者，始终拥有全部权限', TRUE, NOW(), NOW()),
+('admin', '管理员', '后台内容管理', TRUE, NOW(), NOW()),
+('user', '普通用户', '注册用户的默认角色', TRUE, NOW(), NOW())
+ON CONFLICT (name) DO NOTHING;
+
+-- 插入管理员角色的默认权限
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM roles r
+CROSS JOIN (VALUES
+    ('admin.access'), ('post.manage'), ('comment.moderate'), ('moment.manage'),
+    ('category.manage'), ('ip.manage'), ('chat.manage')
+) AS p(permission)
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
 -- 插入默认分类
 INSERT INTO categories (name, description, color, sort, post_count, created_at, updated_at)
 VALUES 
@@ -571,6 +1026,131 @@ COMMENT ON COLUMN friend_links.category_id IS '分类ID（必选）';
 COMMENT ON COLUMN friend_links.sort_order IS '排序顺序（数字越大越靠前）';
 COMMENT ON COLUMN friend_links.status IS '状态：1-启用，0-禁用';
 
+-- 创建友链健康检查表
+CREATE TABLE IF NOT EXISTS friend_link_health (
+    friend_link_id INT PRIMARY KEY,
+    status_code INT DEFAULT 0,
+    latency_ms INT DEFAULT 0,
+    has_backlink BOOLEAN,
+    consecutive_failures INT DEFAULT 0,
+    last_error VARCHAR(500),
+    last_checked_at TIMESTAMP,
+    last_success_at TIMESTAMP,
+    auto_disabled_at TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (friend_link_id) REFERENCES friend_links(id) ON DELETE CASCADE
+);
+
+-- 友链健康检查表注释
+COMMENT ON TABLE friend_link_health IS '友链健康检查表';
+COMMENT ON COLUMN friend_link_health.friend_link_id IS '友链ID';
+COMMENT ON COLUMN friend_link_health.status_code IS '最后一次检查的HTTP状态码（请求失败时为0）';
+COMMENT ON COLUMN friend_link_health.latency_ms IS '最后一次检查的响应耗时（毫秒）';
+COMMENT ON COLUMN friend_link_health.has_backlink IS '对方页面是否包含本站链接（未开启回链检查时为NULL）';
+COMMENT ON COLUMN friend_link_health.consecutive_failures IS '连续失败次数';
+COMMENT ON COLUMN friend_link_health.last_error IS '最后一次错误信息';
+COMMENT ON COLUMN friend_link_health.last_checked_at IS '最后检查时间';
+COMMENT ON COLUMN friend_link_health.last_success_at IS '最后成功时间';
+COMMENT ON COLUMN friend_link_health.auto_disabled_at IS '因连续失败被自动禁用的时间';
+
+-- 创建友链申请表
+CREATE TABLE IF NOT EXISTS friend_link_applications (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    url VARCHAR(255) NOT NULL,
+    icon VARCHAR(255),
+    description TEXT,
+    atom_url VARCHAR(255),
+    email VARCHAR(100) NOT NULL,
+    status INT DEFAULT 0,
+    reject_reason VARCHAR(500),
+    friend_link_id INT,
+    ip VARCHAR(50),
+    reviewer_id INT,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (friend_link_id) REFERENCES friend_links(id) ON DELETE SET NULL,
+    FOREIGN KEY (reviewer_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- 友链申请表索引
+CREATE INDEX IF NOT EXISTS idx_friend_link_applications_status ON friend_link_applications(status, created_at DESC);
+
+-- 友链申请表注释
+COMMENT ON TABLE friend_link_applications IS '友链申请表（访客提交，超级管理员审核）';
+COMMENT ON COLUMN friend_link_applications.name IS '网站名称';
+COMMENT ON COLUMN friend_link_applications.url IS '网站地址';
+COMMENT ON COLUMN friend_link_applications.icon IS '网站图标';
+COMMENT ON COLUMN friend_link_applications.description IS '网站描述';
+COMMENT ON COLUMN friend_link_applications.atom_url IS 'RSS/Atom订阅地址';
+COMMENT ON COLUMN friend_link_applications.email IS '申请人联系邮箱（接收审核结果）';
+COMMENT ON COLUMN friend_link_applications.status IS '状态：0-待审核，1-已通过，2-已拒绝';
+COMMENT ON COLUMN friend_link_applications.reject_reason IS '拒绝原因';
+COMMENT ON COLUMN friend_link_applications.friend_link_id IS '审核通过后创建的友链ID';
+COMMENT ON COLUMN friend_link_applications.ip IS '申请人IP';
+COMMENT ON COLUMN friend_link_applications.reviewer_id IS '审核人ID';
+COMMENT ON COLUMN friend_link_applications.reviewed_at IS '审核时间';
+
+-- 创建朋友圈文章表（友链订阅聚合）
+CREATE TABLE IF NOT EXISTS friend_feed_items (
+    id SERIAL PRIMARY KEY,
+    friend_link_id INT NOT NULL,
+    guid VARCHAR(500) NOT NULL,
+    title VARCHAR(300) NOT NULL,
+    link VARCHAR(500) NOT NULL,
+    summary TEXT,
+    author VARCHAR(100),
+    published_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (friend_link_id) REFERENCES friend_links(id) ON DELETE CASCADE
+);
+
+-- 朋友圈文章表索引
+CREATE UNIQUE INDEX IF NOT EXISTS idx_friend_feed_items_guid ON friend_feed_items(friend_link_id, guid);
+CREATE INDEX IF NOT EXISTS idx_friend_feed_items_published_at ON friend_feed_items(published_at DESC, id DESC);
+
+-- 朋友圈文章表注释
+COMMENT ON TABLE friend_feed_items IS '朋友圈文章表（从友链RSS/Atom订阅抓取）';
+COMMENT ON COLUMN friend_feed_items.friend_link_id IS '友链ID';
+COMMENT ON COLUMN friend_feed_items.guid IS '条目唯一标识（guid/id，缺失时使用链接），与友链ID联合去重';
+COMMENT ON COLUMN friend_feed_items.title IS '文章标题';
+COMMENT ON COLUMN friend_feed_items.link IS '文章链接';
+COMMENT ON COLUMN friend_feed_items.summary IS '文章摘要（纯文本）';
+COMMENT ON COLUMN friend_feed_items.author IS '作者';
+COMMENT ON COLUMN friend_feed_items.published_at IS '发布时间';
+COMMENT ON COLUMN friend_feed_items.created_at IS '抓取时间';
+
+-- 创建友链订阅抓取状态表
+CREATE TABLE IF NOT EXISTS friend_feed_states (
+    friend_link_id INT PRIMARY KEY,
+    feed_url VARCHAR(255),
+    etag VARCHAR(255),
+    last_modified VARCHAR(100),
+    last_fetched_at TIMESTAMP,
+    last_success_at TIMESTAMP,
+    error_count INT DEFAULT 0,
+    last_error VARCHAR(500),
+    next_fetch_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (friend_link_id) REFERENCES friend_links(id) ON DELETE CASCADE
+);
+
+-- 友链订阅抓取状态表索引
+CREATE INDEX IF NOT EXISTS idx_friend_feed_states_next_fetch_at ON friend_feed_states(next_fetch_at);
+
+-- 友链订阅抓取状态表注释
+COMMENT ON TABLE friend_feed_states IS '友链订阅抓取状态表';
+COMMENT ON COLUMN friend_feed_states.friend_link_id IS '友链ID';
+COMMENT ON COLUMN friend_feed_states.feed_url IS '抓取时使用的订阅地址（订阅地址变更后重置状态）';
+COMMENT ON COLUMN friend_feed_states.etag IS '上次响应的ETag（用于条件请求）';
+COMMENT ON COLUMN friend_feed_states.last_modified IS '上次响应的Last-Modified（用于条件请求）';
+COMMENT ON COLUMN friend_feed_states.last_fetched_at IS '最后抓取时间';
+COMMENT ON COLUMN friend_feed_states.last_success_at IS '最后成功时间';
+COMMENT ON COLUMN friend_feed_states.error_count IS '连续失败次数';
+COMMENT ON COLUMN friend_feed_states.last_error IS '最后一次错误信息';
+COMMENT ON COLUMN friend_feed_states.next_fetch_at IS '下次允许抓取的时间（失败后指数退避）';
+
 -- 插入网站配置
 INSERT INTO settings (key, value, type, "group", label, created_at, updated_at)
 VALUES 
@@ -578,6 +1158,8 @@ VALUES
 ('site_url', 'http://localhost:3000', 'text', 'site', '网站URL', NOW(), NOW()),
 ('site_icp', '', 'text', 'site', 'ICP备案号', NOW(), NOW()),
 ('site_police', '', 'text', 'site', '公安备案号', NOW(), NOW()),
+('feed_content', 'summary', 'text', 'site', '订阅源输出内容（summary-摘要，full-全文）', NOW(), NOW()),
+('robots_txt', '', 'text', 'site', 'robots.txt 规则（为空时使用默认规则）', NOW(), NOW()),
 ('storage_type', 'local', 'text', 'upload', '存储类型', NOW(), NOW()),
 ('notify_admin_on_comment', '0', 'text', 'notification', '评论时通知管理员', NOW(), NOW())
 ON CONFLICT (key) DO NOTHING;
@@ -0,0 +1,36 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：access_token.go
+ * 创建时间：2026-10-18 01:21:05
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：个人访问令牌工具函数，提供令牌生成、识别和哈希计算（数据库只保存哈希）
+ */
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// PersonalAccessTokenPrefix 个人访问令牌前缀，用于和JWT区分，也便于密钥扫描工具识别泄露的令牌
+const PersonalAccessTokenPrefix = "pat_"
+
+// GeneratePersonalAccessToken 生成新的个人访问令牌（明文只在创建时返回给用户一次）
+func GeneratePersonalAccessToken() string {
+	return PersonalAccessTokenPrefix + GenerateRandomString(40)
+}
+
+// IsPersonalAccessToken 判断 Bearer 令牌是否为个人访问令牌
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, PersonalAccessTokenPrefix)
+}
+
+// HashPersonalAccessToken 计算个人访问令牌的 SHA-256 哈希
+func HashPersonalAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,190 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：diff.go
+ * 创建时间：2026-10-17 10:20:15
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：文本差异对比工具函数，基于 Myers 算法提供按行的差异计算
+ */
+package util
+
+import "strings"
+
+// 差异行类型
+const (
+	DiffEqual  = "equal"  // 未变化
+	DiffInsert = "insert" // 新增行
+	DiffDelete = "delete" // 删除行
+)
+
+// maxDiffEdits 最大编辑距离，超过后直接按“全部删除+全部新增”处理，避免超大文本占用过多内存
+const maxDiffEdits = 2000
+
+// DiffLine 差异结果中的一行
+type DiffLine struct {
+	Type    string `json:"type"`               // equal / insert / delete
+	OldLine int    `json:"old_line,omitempty"` // 旧文本行号（从1开始，新增行为0）
+	NewLine int    `json:"new_line,omitempty"` // 新文本行号（从1开始，删除行为0）
+	Content string `json:"content"`
+}
+
+// diffEdit Myers 算法内部的编辑步骤（下标从0开始）
+type diffEdit struct {
+	op     string
+	oldIdx int
+	newIdx int
+}
+
+// DiffLines 按行对比两段文本
+// 参数:
+//   - oldText: 旧文本
+//   - newText: 新文本
+//
+// 返回:
+//   - []DiffLine: 按顺序排列的差异行
+func DiffLines(oldText, newText string) []DiffLine {
+	a := splitLines(oldText)
+	b := splitLines(newText)
+
+	// 先去掉公共前缀和后缀，大多数编辑只改动少量行，可以显著缩小计算规模
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	result := make([]DiffLine, 0, len(a)+len(b)-prefix-suffix)
+	for i := 0; i < prefix; i++ {
+		result = append(result, DiffLine{Type: DiffEqual, OldLine: i + 1, NewLine: i + 1, Content: a[i]})
+	}
+
+	for _, e := range myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		switch e.op {
+		case DiffEqual:
+			result = append(result, DiffLine{Type: DiffEqual, OldLine: prefix + e.oldIdx + 1, NewLine: prefix + e.newIdx + 1, Content: a[prefix+e.oldIdx]})
+		case DiffDelete:
+			result = append(result, DiffLine{Type: DiffDelete, OldLine: prefix + e.oldIdx + 1, Content: a[prefix+e.oldIdx]})
+		case DiffInsert:
+			result = append(result, DiffLine{Type: DiffInsert, NewLine: prefix + e.newIdx + 1, Content: b[prefix+e.newIdx]})
+		}
+	}
+
+	for i := suffix; i > 0; i-- {
+		oldIdx := len(a) - i
+		newIdx := len(b) - i
+		result = append(result, DiffLine{Type: DiffEqual, OldLine: oldIdx + 1, NewLine: newIdx + 1, Content: a[oldIdx]})
+	}
+
+	return result
+}
+
+// splitLines 将文本拆分为行，统一换行符
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Split(text, "\n")
+}
+
+// myersDiff Myers O(ND) 差异算法
+// 每一轮只保存 [-d, d] 范围内的 V 数组快照，回溯时据此还原编辑路径
+func myersDiff(a, b []string) []diffEdit {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	maxD := n + m
+	if maxD > maxDiffEdits {
+		maxD = maxDiffEdits
+	}
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	trace := make([][]int, 0)
+
+	found := false
+	for d := 0; d <= maxD && !found; d++ {
+		// 保存上一轮结束时的状态，下标 k+d 对应对角线 k
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // 向下移动（插入）
+			} else {
+				x = v[offset+k-1] + 1 // 向右移动（删除）
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	// 编辑距离过大，退化为全部删除再全部新增
+	if !found {
+		edits := make([]diffEdit, 0, n+m)
+		for i := 0; i < n; i++ {
+			edits = append(edits, diffEdit{op: DiffDelete, oldIdx: i})
+		}
+		for j := 0; j < m; j++ {
+			edits = append(edits, diffEdit{op: DiffInsert, newIdx: j})
+		}
+		return edits
+	}
+
+	// 回溯编辑路径（逆序生成，最后反转）
+	edits := make([]diffEdit, 0, n+m)
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		snapshot := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && snapshot[k-1+d] < snapshot[k+1+d]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := snapshot[prevK+d]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, diffEdit{op: DiffEqual, oldIdx: x, newIdx: y})
+		}
+		if x == prevX {
+			y--
+			edits = append(edits, diffEdit{op: DiffInsert, newIdx: y})
+		} else {
+			x--
+			edits = append(edits, diffEdit{op: DiffDelete, oldIdx: x})
+		}
+	}
+	// d == 0 时剩余部分必然是公共行
+	for x > 0 && y > 0 {
+		x--
+		y--
+		edits = append(edits, diffEdit{op: DiffEqual, oldIdx: x, newIdx: y})
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
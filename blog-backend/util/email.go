@@ -176,6 +176,306 @@ func SendAdminCommentNotificationEmail(config EmailConfig, to string, commenterN
 	return sendEmailHTML(config, to, subject, htmlBody, textBody)
 }
 
+// SendFriendLinkApprovedEmail 发送友链申请通过邮件（给申请人）
+func SendFriendLinkApprovedEmail(config EmailConfig, to string, linkName string, friendLinksURL string) error {
+	// 优先使用配置的网站名称，其次使用发件人名称，最后使用默认值
+	siteName := config.SiteName
+	if siteName == "" {
+		siteName = config.FromName
+	}
+	if siteName == "" {
+		siteName = "菱风叙"
+	}
+	subject := fmt.Sprintf("【%s】友链申请已通过", siteName)
+
+	data := map[string]interface{}{
+		"SiteName":   siteName,
+		"Title":      "友链申请已通过",
+		"LinkName":   linkName,
+		"Message":    "您提交的友链申请已通过审核，现已展示在本站友链页面，欢迎常来串门！",
+		"ButtonText": "查看友链页面",
+		"ButtonURL":  friendLinksURL,
+		"Year":       "2025",
+	}
+
+	htmlBody := getEmailTemplate("friend_link_result", data)
+	textBody := fmt.Sprintf(`您好！
+
+您为「%s」提交的友链申请已通过审核，现已展示在本站友链页面，欢迎常来串门！
+
+查看友链页面：%s
+
+---
+此邮件由系统自动发送，请勿直接回复
+© 2025 %s`, linkName, friendLinksURL, siteName)
+
+	return sendEmailHTML(config, to, subject, htmlBody, textBody)
+}
+
+// SendFriendLinkRejectedEmail 发送友链申请未通过邮件（给申请人）
+func SendFriendLinkRejectedEmail(config EmailConfig, to string, linkName string, reason string) error {
+	// 优先使用配置的网站名称，其次使用发件人名称，最后使用默认值
+	siteName := config.SiteName
+	if siteName == "" {
+		siteName = config.FromName
+	}
+	if siteName == "" {
+		siteName = "菱风叙"
+	}
+	subject := fmt.Sprintf("【%s】友链申请未通过", siteName)
+
+	data := map[string]interface{}{
+		"SiteName": siteName,
+		"Title":    "友链申请未通过",
+		"LinkName": linkName,
+		"Message":  "很遗憾，您提交的友链申请未能通过审核。",
+		"Reason":   reason,
+		"Year":     "2025",
+	}
+
+	htmlBody := getEmailTemplate("friend_link_result", data)
+	textBody := fmt.Sprintf(`您好！
+
+很遗憾，您为「%s」提交的友链申请未能通过审核。
+
+原因：%s
+
+---
+此邮件由系统自动发送，请勿直接回复
+© 2025 %s`, linkName, reason, siteName)
+
+	return sendEmailHTML(config, to, subject, htmlBody, textBody)
+}
+
+// SendRegistrationApprovedEmail 发送注册审核通过邮件（给注册用户）
+func SendRegistrationApprovedEmail(config EmailConfig, to string, username string, loginURL string) error {
+	// 优先使用配置的网站名称，其次使用发件人名称，最后使用默认值
+	siteName := config.SiteName
+	if siteName == "" {
+		siteName = config.FromName
+	}
+	if siteName == "" {
+		siteName = "菱风叙"
+	}
+	subject := fmt.Sprintf("【%s】注册申请已通过", siteName)
+
+	data := map[string]interface{}{
+		"SiteName":   siteName,
+		"Title":      "注册申请已通过",
+		"Username":   username,
+		"Message":    "您的注册申请已通过审核，现在可以登录了，欢迎加入！",
+		"ButtonText": "立即登录",
+		"ButtonURL":  loginURL,
+		"Year":       "2025",
+	}
+
+	htmlBody := getEmailTemplate("registration_result", data)
+	textBody := fmt.Sprintf(`您好！
+
+您的账号（%s）注册申请已通过审核，现在可以登录了，欢迎加入！
+
+立即登录：%s
+
+---
+此邮件由系统自动发送，请勿直接回复
+© 2025 %s`, username, loginURL, siteName)
+
+	return sendEmailHTML(config, to, subject, htmlBody, textBody)
+}
+
+// SendRegistrationRejectedEmail 发送注册审核未通过邮件（给注册用户）
+func SendRegistrationRejectedEmail(config EmailConfig, to string, username string, reason string) error {
+	// 优先使用配置的网站名称，其次使用发件人名称，最后使用默认值
+	siteName := config.SiteName
+	if siteName == "" {
+		siteName = config.FromName
+	}
+	if siteName == "" {
+		siteName = "菱风叙"
+	}
+	subject := fmt.Sprintf("【%s】注册申请未通过", siteName)
+
+	data := map[string]interface{}{
+		"SiteName": siteName,
+		"Title":    "注册申请未通过",
+		"Username": username,
+		"Message":  "很遗憾，您的注册申请未能通过审核，账号信息已删除。",
+		"Reason":   reason,
+		"Year":     "2025",
+	}
+
+	htmlBody := getEmailTemplate("registration_result", data)
+	textBody := fmt.Sprintf(`您好！
+
+很遗憾，您的账号（%s）注册申请未能通过审核，账号信息已删除。
+
+原因：%s
+
+---
+此邮件由系统自动发送，请勿直接回复
+© 2025 %s`, username, reason, siteName)
+
+	return sendEmailHTML(config, to, subject, htmlBody, textBody)
+}
+
+// SendEmailChangeCodeEmail 发送修改邮箱验证码邮件（发送到新邮箱）
+func SendEmailChangeCodeEmail(config EmailConfig, to string, username string, code string) error {
+	// 优先使用配置的网站名称，其次使用发件人名称，最后使用默认值
+	siteName := config.SiteName
+	if siteName == "" {
+		siteName = config.FromName
+	}
+	if siteName == "" {
+		siteName = "菱风叙"
+	}
+	subject := fmt.Sprintf("【%s】验证新邮箱", siteName)
+
+	data := map[string]interface{}{
+		"SiteName": siteName,
+		"Username": username,
+		"Code":     code,
+		"Year":     "2025",
+	}
+
+	htmlBody := getEmailTemplate("email_change_code", data)
+	textBody := fmt.Sprintf(`您好！
+
+您正在将%s账号（%s）的邮箱修改为此邮箱，请使用以下验证码完成验证：
+
+验证码：%s
+
+重要提示：
+• 验证码有效期为 15 分钟，请尽快使用
+• 请勿将验证码告诉他人，以保护账号安全
+
+如果这不是您本人的操作，请忽略此邮件。
+
+---
+此邮件由系统自动发送，请勿直接回复
+© 2025 %s`, siteName, username, code, siteName)
+
+	return sendEmailHTML(config, to, subject, htmlBody, textBody)
+}
+
+// SendEmailChangedNoticeEmail 发送邮箱已修改通知邮件（发送到原邮箱，附带撤销链接）
+func SendEmailChangedNoticeEmail(config EmailConfig, to string, username string, newEmail string, changedAt string, revertURL string, revertDays int) error {
+	// 优先使用配置的网站名称，其次使用发件人名称，最后使用默认值
+	siteName := config.SiteName
+	if siteName == "" {
+		siteName = config.FromName
+	}
+	if siteName == "" {
+		siteName = "菱风叙"
+	}
+	subject := fmt.Sprintf("【%s】账号邮箱已修改", siteName)
+
+	data := map[string]interface{}{
+		"SiteName":   siteName,
+		"Username":   username,
+		"OldEmail":   to,
+		"NewEmail":   newEmail,
+		"ChangedAt":  changedAt,
+		"RevertURL":  revertURL,
+		"RevertDays": revertDays,
+		"Year":       "2025",
+	}
+
+	htmlBody := getEmailTemplate("email_change_notice", data)
+	textBody := fmt.Sprintf(`您好！
+
+您在%s的账号（%s）绑定的邮箱已修改：
+
+原邮箱：%s
+新邮箱：%s
+修改时间：%s
+
+如果这是您本人的操作，请忽略此邮件。
+
+如果这不是您本人的操作，请在 %d 天内打开以下链接撤销修改。撤销后账号的所有登录设备都将退出，请尽快修改密码：
+%s
+
+---
+此邮件由系统自动发送，请勿直接回复
+© 2025 %s`, siteName, username, to, newEmail, changedAt, revertDays, revertURL, siteName)
+
+	return sendEmailHTML(config, to, subject, htmlBody, textBody)
+}
+
+// SendMagicLinkEmail 发送免密登录链接邮件
+func SendMagicLinkEmail(config EmailConfig, to string, username string, loginURL string, expireMinutes int) error {
+	// 优先使用配置的网站名称，其次使用发件人名称，最后使用默认值
+	siteName := config.SiteName
+	if siteName == "" {
+		siteName = config.FromName
+	}
+	if siteName == "" {
+		siteName = "菱风叙"
+	}
+	subject := fmt.Sprintf("【%s】登录链接", siteName)
+
+	data := map[string]interface{}{
+		"SiteName":      siteName,
+		"Username":      username,
+		"LoginURL":      loginURL,
+		"ExpireMinutes": expireMinutes,
+		"Year":          "2025",
+	}
+
+	htmlBody := getEmailTemplate("magic_link", data)
+	textBody := fmt.Sprintf(`您好！
+
+您正在登录%s的账号（%s），打开以下链接即可直接登录，无需输入密码：
+%s
+
+链接 %d 分钟内有效，只能使用一次。
+如果不是您本人的操作，请忽略此邮件，您的账号不会受到影响。
+
+---
+此邮件由系统自动发送，请勿直接回复
+© 2025 %s`, siteName, username, loginURL, expireMinutes, siteName)
+
+	return sendEmailHTML(config, to, subject, htmlBody, textBody)
+}
+
+// SendAccountDeletionEmail 发送账号注销申请通知邮件（附带撤销入口）
+func SendAccountDeletionEmail(config EmailConfig, to string, username string, scheduledAt string, cancelURL string, graceDays int) error {
+	// 优先使用配置的网站名称，其次使用发件人名称，最后使用默认值
+	siteName := config.SiteName
+	if siteName == "" {
+		siteName = config.FromName
+	}
+	if siteName == "" {
+		siteName = "菱风叙"
+	}
+	subject := fmt.Sprintf("【%s】账号注销申请", siteName)
+
+	data := map[string]interface{}{
+		"SiteName":    siteName,
+		"Username":    username,
+		"ScheduledAt": scheduledAt,
+		"CancelURL":   cancelURL,
+		"GraceDays":   graceDays,
+		"Year":        "2025",
+	}
+
+	htmlBody := getEmailTemplate("account_deletion", data)
+	textBody := fmt.Sprintf(`您好！
+
+我们收到了您注销%s账号（%s）的申请，账号将于 %s 被永久删除。
+删除后评论、点赞、聊天消息等个人数据将被清理且无法恢复。
+
+冷静期 %d 天内登录后可在个人中心撤销注销：
+%s
+
+如果不是您本人的操作，请立即登录撤销并修改密码。
+
+---
+此邮件由系统自动发送，请勿直接回复
+© 2025 %s`, siteName, username, scheduledAt, graceDays, cancelURL, siteName)
+
+	return sendEmailHTML(config, to, subject, htmlBody, textBody)
+}
+
 // sendEmailHTML 发送HTML格式邮件（支持纯文本回退）
 // 注意：为了兼容性，直接发送HTML格式，不使用multipart/alternative
 // 大多数现代邮件客户端都支持HTML，这样可以避免multipart格式导致的"short response"错误
@@ -242,6 +542,18 @@ func getEmailTemplate(templateName string, data map[string]interface{}) string {
 		templateStr = getRegisterVerificationTemplate()
 	case "admin_comment_notification":
 		templateStr = getAdminCommentNotificationTemplate()
+	case "friend_link_result":
+		templateStr = getFriendLinkResultTemplate()
+	case "email_change_code":
+		templateStr = getEmailChangeCodeTemplate()
+	case "email_change_notice":
+		templateStr = getEmailChangedNoticeTemplate()
+	case "magic_link":
+		templateStr = getMagicLinkTemplate()
+	case "account_deletion":
+		templateStr = getAccountDeletionTemplate()
+	case "registration_result":
+		templateStr = getRegistrationResultTemplate()
 	default:
 		return ""
 	}
@@ -565,3 +877,615 @@ func getAdminCommentNotificationTemplate() string {
 </body>
 </html>`
 }
+
+// getFriendLinkResultTemplate 获取友链审核结果邮件模板
+func getFriendLinkResultTemplate() string {
+	return `<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.Title}}</title>
+</head>
+<body style="margin: 0; padding: 0; font-family: 'helvetica neue', PingFangSC-Light, arial, 'hiragino sans gb', 'microsoft yahei ui', 'microsoft yahei', simsun, sans-serif; background-color: #f7f8fa;">
+    <div style="word-break: break-all; box-sizing: border-box; text-align: center; min-width: 320px; max-width: 660px; border: 1px solid #f6f6f6; background-color: #f7f8fa; margin: auto; padding: 20px 0 30px;">
+        <table style="width: 100%; font-weight: 300; margin-bottom: 10px; border-collapse: collapse;">
+            <tbody>
+                <tr style="font-weight: 300;">
+                    <td style="width: 3%; max-width: 30px;"></td>
+                    <td style="max-width: 600px;">
+                        <!-- 网站名称 -->
+                        <div style="width: 100%; text-align: left; margin-bottom: 20px;">
+                            <h1 style="margin: 0; color: #0891b2; font-size: 24px; font-weight: 600;">{{.SiteName}}</h1>
+                        </div>
+                        <!-- 蓝色分割线 -->
+                        <p style="height: 2px; background-color: #0891b2; border: 0; font-size: 0; padding: 0; width: 100%; margin-top: 20px; margin-bottom: 0;"></p>
+                        
+                        <!-- 内容区域 -->
+                        <div style="background-color: #fff; padding: 23px 0 20px; box-shadow: 0px 1px 1px 0px rgba(122, 55, 55, 0.2); text-align: left;">
+                            <table style="width: 100%; font-weight: 300; margin-bottom: 10px; border-collapse: collapse; text-align: left;">
+                                <tbody>
+                                    <tr style="font-weight: 300;">
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                        <td style="max-width: 480px; text-align: left;">
+                                            <!-- 标题 -->
+                                            <h1 style="font-size: 20px; line-height: 36px; margin: 0px 0px 22px; color: #333;">{{.Title}}</h1>
+                                            
+                                            <!-- 问候语 -->
+                                            <p style="font-size: 14px; color: #333; line-height: 24px; margin: 0;">您好！</p>
+                                            
+                                            <p style="line-height: 24px; margin: 6px 0px 0px; overflow-wrap: break-word; word-break: break-all;">
+                                                <span style="color: rgb(51, 51, 51); font-size: 14px;">{{.Message}}</span>
+                                            </p>
+                                            
+                                            <!-- 申请信息框 -->
+                                            <div style="background-color: #f0fdfa; border-left: 4px solid #0891b2; padding: 20px; margin: 30px 0; border-radius: 4px;">
+                                                <p style="margin: 0; color: #333; font-size: 14px; line-height: 24px;">
+                                                    <strong style="color: #0891b2;">网站：</strong>{{.LinkName}}
+                                                </p>
+                                                {{if .Reason}}
+                                                <p style="margin: 10px 0 0 0; color: #333; font-size: 14px; line-height: 24px;">
+                                                    <strong style="color: #0891b2;">原因：</strong>{{.Reason}}
+                                                </p>
+                                                {{end}}
+                                            </div>
+                                            {{if .ButtonURL}}
+                                            <!-- 按钮 -->
+                                            <p style="font-size: 14px; color: rgb(51, 51, 51); line-height: 24px; margin: 6px 0px 0px; word-wrap: break-word; word-break: break-all;">
+                                                <a href="{{.ButtonURL}}" title="{{.ButtonText}}" style="font-size: 16px; line-height: 45px; display: block; background-color: #0891b2; color: rgb(255, 255, 255); text-align: center; text-decoration: none; margin-top: 20px; border-radius: 3px;">
+                                                    {{.ButtonText}}
+                                                </a>
+                                            </p>
+                                            {{end}}
+                                            
+                                            <!-- 署名 -->
+                                            <p style="font-size: 14px; line-height: 26px; word-wrap: break-word; word-break: break-all; margin-top: 32px; color: #333;">
+                                                此致<br>
+                                                <strong>{{.SiteName}}团队</strong>
+                                            </p>
+                                        </td>
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                    </tr>
+                                </tbody>
+                            </table>
+                        </div>
+                        
+                        <!-- 底部 -->
+                        <div style="text-align: center; font-size: 12px; line-height: 18px; color: #999; margin-top: 20px;">
+                            <table style="width: 100%; font-weight: 300; margin-bottom: 10px; border-collapse: collapse;">
+                                <tbody>
+                                    <tr style="font-weight: 300;">
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                        <td style="max-width: 540px;">
+                                            <p style="text-align: center; margin: 20px auto 14px auto; font-size: 12px; color: #999;">
+                                                此为系统邮件，请勿回复。
+                                            </p>
+                                            <p style="max-width: 100%; margin: auto; font-size: 12px; color: #999; text-align: center; line-height: 22px;">
+                                                © {{.Year}} {{.SiteName}}
+                                            </p>
+                                        </td>
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                    </tr>
+                                </tbody>
+                            </table>
+                        </div>
+                    </td>
+                    <td style="width: 3%; max-width: 30px;"></td>
+                </tr>
+            </tbody>
+        </table>
+    </div>
+</body>
+</html>`
+}
+
+// getEmailChangeCodeTemplate 获取修改邮箱验证码邮件模板
+func getEmailChangeCodeTemplate() string {
+	return `<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>验证新邮箱</title>
+</head>
+<body style="margin: 0; padding: 0; font-family: 'helvetica neue', PingFangSC-Light, arial, 'hiragino sans gb', 'microsoft yahei ui', 'microsoft yahei', simsun, sans-serif; background-color: #f7f8fa;">
+    <div style="word-break: break-all; box-sizing: border-box; text-align: center; min-width: 320px; max-width: 660px; border: 1px solid #f6f6f6; background-color: #f7f8fa; margin: auto; padding: 20px 0 30px;">
+        <table style="width: 100%; font-weight: 300; margin-bottom: 10px; border-collapse: collapse;">
+            <tbody>
+                <tr style="font-weight: 300;">
+                    <td style="width: 3%; max-width: 30px;"></td>
+                    <td style="max-width: 600px;">
+                        <!-- 网站名称 -->
+                        <div style="width: 100%; text-align: left; margin-bottom: 20px;">
+                            <h1 style="margin: 0; color: #0891b2; font-size: 24px; font-weight: 600;">{{.SiteName}}</h1>
+                        </div>
+                        <!-- 蓝色分割线 -->
+                        <p style="height: 2px; background-color: #0891b2; border: 0; font-size: 0; padding: 0; width: 100%; margin-top: 20px; margin-bottom: 0;"></p>
+                        
+                        <!-- 内容区域 -->
+                        <div style="background-color: #fff; padding: 23px 0 20px; box-shadow: 0px 1px 1px 0px rgba(122, 55, 55, 0.2); text-align: left;">
+                            <table style="width: 100%; font-weight: 300; margin-bottom: 10px; border-collapse: collapse; text-align: left;">
+                                <tbody>
+                                    <tr style="font-weight: 300;">
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                        <td style="max-width: 480px; text-align: left;">
+                                            <!-- 标题 -->
+                                            <h1 style="font-size: 20px; line-height: 36px; margin: 0px 0px 22px; color: #333;">验证新邮箱</h1>
+                                            
+                                            <!-- 问候语 -->
+                                            <p style="font-size: 14px; color: #333; line-height: 24px; margin: 0;">您好！</p>
+                                            
+                                            <p style="line-height: 24px; margin: 6px 0px 0px; overflow-wrap: break-word; word-break: break-all;">
+                                                <span style="color: rgb(51, 51, 51); font-size: 14px;">您正在将{{.SiteName}}账号（{{.Username}}）的邮箱修改为此邮箱，请使用以下验证码完成验证：</span>
+                                            </p>
+                                            
+                                            <!-- 验证码框 -->
+                                            <div style="background-color: #f0fdfa; border: 2px dashed #0891b2; border-radius: 8px; padding: 20px; text-align: center; margin: 30px 0;">
+                                                <p style="margin: 0 0 10px 0; color: #64748b; font-size: 12px; text-transform: uppercase; letter-spacing: 1px;">验证码</p>
+                                                <p style="margin: 0; color: #0891b2; font-size: 32px; font-weight: bold; letter-spacing: 8px; font-family: 'Courier New', monospace;">{{.Code}}</p>
+                                            </div>
+                                            
+                                            <!-- 重要提示 -->
+                                            <p style="line-height: 24px; margin: 6px 0px 0px; overflow-wrap: break-word; word-break: break-all;">
+                                                <span style="color: rgb(51, 51, 51); font-size: 14px; font-weight: bold;">重要提示：</span>
+                                            </p>
+                                            <p style="line-height: 24px; margin: 6px 0px 0px; overflow-wrap: break-word; word-break: break-all;">
+                                                <span style="color: rgb(51, 51, 51); font-size: 14px;">• 验证码有效期为 15 分钟，请尽快使用</span>
+                                            </p>
+                                            <p style="line-height: 24px; margin: 6px 0px 0px; overflow-wrap: break-word; word-break: break-all;">
+                                                <span style="color: rgb(51, 51, 51); font-size: 14px;">• 请勿将验证码告诉他人，以保护账号安全</span>
+                                            </p>
+                                            
+                                            <p style="line-height: 24px; margin: 20px 0px 0px; overflow-wrap: break-word; word-break: break-all;">
+                                                <span style="color: rgb(51, 51, 51); font-size: 14px;">如果这不是您本人的操作，请忽略此邮件。</span>
+                                            </p>
+                                            
+                                            <!-- 署名 -->
+                                            <p style="font-size: 14px; line-height: 26px; word-wrap: break-word; word-break: break-all; margin-top: 32px; color: #333;">
+                                                此致<br>
+                                                <strong>{{.SiteName}}团队</strong>
+                                            </p>
+                                        </td>
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                    </tr>
+                                </tbody>
+                            </table>
+                        </div>
+                        
+                        <!-- 底部 -->
+                        <div style="text-align: center; font-size: 12px; line-height: 18px; color: #999; margin-top: 20px;">
+                            <table style="width: 100%; font-weight: 300; margin-bottom: 10px; border-collapse: collapse;">
+                                <tbody>
+                                    <tr style="font-weight: 300;">
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                        <td style="max-width: 540px;">
+                                            <p style="text-align: center; margin: 20px auto 14px auto; font-size: 12px; color: #999;">
+                                                此为系统邮件，请勿回复。
+                                            </p>
+                                            <p style="max-width: 100%; margin: auto; font-size: 12px; color: #999; text-align: center; line-height: 22px;">
+                                                © {{.Year}} {{.SiteName}}
+                                            </p>
+                                        </td>
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                    </tr>
+                                </tbody>
+                            </table>
+                        </div>
+                    </td>
+                    <td style="width: 3%; max-width: 30px;"></td>
+                </tr>
+            </tbody>
+        </table>
+    </div>
+</body>
+</html>`
+}
+
+// getEmailChangedNoticeTemplate 获取邮箱已修改通知邮件模板（发送到原邮箱）
+func getEmailChangedNoticeTemplate() string {
+	return `<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>账号邮箱已修改</title>
+</head>
+<body style="margin: 0; padding: 0; font-family: 'helvetica neue', PingFangSC-Light, arial, 'hiragino sans gb', 'microsoft yahei ui', 'microsoft yahei', simsun, sans-serif; background-color: #f7f8fa;">
+    <div style="word-break: break-all; box-sizing: border-box; text-align: center; min-width: 320px; max-width: 660px; border: 1px solid #f6f6f6; background-color: #f7f8fa; margin: auto; padding: 20px 0 30px;">
+        <table style="width: 100%; font-weight: 300; margin-bottom: 10px; border-collapse: collapse;">
+            <tbody>
+                <tr style="font-weight: 300;">
+                    <td style="width: 3%; max-width: 30px;"></td>
+                    <td style="max-width: 600px;">
+                        <!-- 网站名称 -->
+                        <div style="width: 100%; text-align: left; margin-bottom: 20px;">
+                            <h1 style="margin: 0; color: #0891b2; font-size: 24px; font-weight: 600;">{{.SiteName}}</h1>
+                        </div>
+                        <!-- 蓝色分割线 -->
+                        <p style="height: 2px; background-color: #0891b2; border: 0; font-size: 0; padding: 0; width: 100%; margin-top: 20px; margin-bottom: 0;"></p>
+                        
+                        <!-- 内容区域 -->
+                        <div style="background-color: #fff; padding: 23px 0 20px; box-shadow: 0px 1px 1px 0px rgba(122, 55, 55, 0.2); text-align: left;">
+                            <table style="width: 100%; font-weight: 300; margin-bottom: 10px; border-collapse: collapse; text-align: left;">
+                                <tbody>
+                                    <tr style="font-weight: 300;">
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                        <td style="max-width: 480px; text-align: left;">
+                                            <!-- 标题 -->
+                                            <h1 style="font-size: 20px; line-height: 36px; margin: 0px 0px 22px; color: #333;">账号邮箱已修改</h1>
+                                            
+                                            <!-- 问候语 -->
+                                            <p style="font-size: 14px; color: #333; line-height: 24px; margin: 0;">您好！</p>
+                                            
+                                            <p style="line-height: 24px; margin: 6px 0px 0px; overflow-wrap: break-word; word-break: break-all;">
+                                                <span style="color: rgb(51, 51, 51); font-size: 14px;">您在{{.SiteName}}的账号（{{.Username}}）绑定的邮箱已修改：</span>
+                                            </p>
+                                            
+                                            <!-- 修改信息框 -->
+                                            <div style="background-color: #f0fdfa; border-left: 4px solid #0891b2; padding: 20px; margin: 30px 0; border-radius: 4px;">
+                                                <p style="margin: 0; color: #333; font-size: 14px; line-height: 24px;">
+                                                    <strong style="color: #0891b2;">原邮箱：</strong>{{.OldEmail}}
+                                                </p>
+                                                <p style="margin: 10px 0 0 0; color: #333; font-size: 14px; line-height: 24px;">
+                                                    <strong style="color: #0891b2;">新邮箱：</strong>{{.NewEmail}}
+                                                </p>
+                                                <p style="margin: 10px 0 0 0; color: #333; font-size: 14px; line-height: 24px;">
+                                                    <strong style="color: #0891b2;">修改时间：</strong>{{.ChangedAt}}
+                                                </p>
+                                            </div>
+                                            
+                                            <p style="line-height: 24px; margin: 6px 0px 0px; overflow-wrap: break-word; word-break: break-all;">
+                                                <span style="color: rgb(51, 51, 51); font-size: 14px;">如果这是您本人的操作，请忽略此邮件。</span>
+                                            </p>
+                                            <p style="line-height: 24px; margin: 6px 0px 0px; overflow-wrap: break-word; word-break: break-all;">
+                                                <span style="color: rgb(51, 51, 51); font-size: 14px;">如果这不是您本人的操作，请在 {{.RevertDays}} 天内点击下方按钮撤销修改。撤销后账号的所有登录设备都将退出，请尽快修改密码。</span>
+                                            </p>
+                                            
+                                            <!-- 撤销按钮 -->
+                                            <p style="font-size: 14px; color: rgb(51, 51, 51); line-height: 24px; margin: 6px 0px 0px; word-wrap: break-word; word-break: break-all;">
+                                                <a href="{{.RevertURL}}" title="这不是我，撤销修改" style="font-size: 16px; line-height: 45px; display: block; background-color: #dc2626; color: rgb(255, 255, 255); text-align: center; text-decoration: none; margin-top: 20px; border-radius: 3px;">
+                                                    这不是我，撤销修改
+                                                </a>
+                                            </p>
+                                            
+                                            <!-- 署名 -->
+                                            <p style="font-size: 14px; line-height: 26px; word-wrap: break-word; word-break: break-all; margin-top: 32px; color: #333;">
+                                                此致<br>
+                                                <strong>{{.SiteName}}团队</strong>
+                                            </p>
+                                        </td>
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                    </tr>
+                                </tbody>
+                            </table>
+                        </div>
+                        
+                        <!-- 底部 -->
+                        <div style="text-align: center; font-size: 12px; line-height: 18px; color: #999; margin-top: 20px;">
+                            <table style="width: 100%; font-weight: 300; margin-bottom: 10px; border-collapse: collapse;">
+                                <tbody>
+                                    <tr style="font-weight: 300;">
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                        <td style="max-width: 540px;">
+                                            <p style="text-align: center; margin: 20px auto 14px auto; font-size: 12px; color: #999;">
+                                                此为系统邮件，请勿回复。
+                                            </p>
+                                            <p style="max-width: 100%; margin: auto; font-size: 12px; color: #999; text-align: center; line-height: 22px;">
+                                                © {{.Year}} {{.SiteName}}
+                                            </p>
+                                        </td>
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                    </tr>
+                                </tbody>
+                            </table>
+                        </div>
+                    </td>
+                    <td style="width: 3%; max-width: 30px;"></td>
+                </tr>
+            </tbody>
+        </table>
+    </div>
+</body>
+</html>`
+}
+
+// getMagicLinkTemplate 获取免密登录链接邮件模板
+func getMagicLinkTemplate() string {
+	return `<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>登录链接</title>
+</head>
+<body style="margin: 0; padding: 0; font-family: 'helvetica neue', PingFangSC-Light, arial, 'hiragino sans gb', 'microsoft yahei ui', 'microsoft yahei', simsun, sans-serif; background-color: #f7f8fa;">
+    <div style="word-break: break-all; box-sizing: border-box; text-align: center; min-width: 320px; max-width: 660px; border: 1px solid #f6f6f6; background-color: #f7f8fa; margin: auto; padding: 20px 0 30px;">
+        <table style="width: 100%; font-weight: 300; margin-bottom: 10px; border-collapse: collapse;">
+            <tbody>
+                <tr style="font-weight: 300;">
+                    <td style="width: 3%; max-width: 30px;"></td>
+                    <td style="max-width: 600px;">
+                        <!-- 网站名称 -->
+                        <div style="width: 100%; text-align: left; margin-bottom: 20px;">
+                            <h1 style="margin: 0; color: #0891b2; font-size: 24px; font-weight: 600;">{{.SiteName}}</h1>
+                        </div>
+                        <!-- 蓝色分割线 -->
+                        <p style="height: 2px; background-color: #0891b2; border: 0; font-size: 0; padding: 0; width: 100%; margin-top: 20px; margin-bottom: 0;"></p>
+                        
+                        <!-- 内容区域 -->
+                        <div style="background-color: #fff; padding: 23px 0 20px; box-shadow: 0px 1px 1px 0px rgba(122, 55, 55, 0.2); text-align: left;">
+                            <table style="width: 100%; font-weight: 300; margin-bottom: 10px; border-collapse: collapse; text-align: left;">
+                                <tbody>
+                                    <tr style="font-weight: 300;">
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                        <td style="max-width: 480px; text-align: left;">
+                                            <!-- 标题 -->
+                                            <h1 style="font-size: 20px; line-height: 36px; margin: 0px 0px 22px; color: #333;">登录链接</h1>
+                                            
+                                            <!-- 问候语 -->
+                                            <p style="font-size: 14px; color: #333; line-height: 24px; margin: 0;">您好！</p>
+                                            
+                                            <p style="line-height: 24px; margin: 6px 0px 0px; overflow-wrap: break-word; word-break: break-all;">
+                                                <span style="color: rgb(51, 51, 51); font-size: 14px;">您正在登录{{.SiteName}}的账号（{{.Username}}），点击下方按钮即可直接登录，无需输入密码。</span>
+                                            </p>
+                                            
+                                            <!-- 登录按钮 -->
+                                            <p style="font-size: 14px; color: rgb(51, 51, 51); line-height: 24px; margin: 6px 0px 0px; word-wrap: break-word; word-break: break-all;">
+                                                <a href="{{.LoginURL}}" title="登录" style="font-size: 16px; line-height: 45px; display: block; background-color: #0891b2; color: rgb(255, 255, 255); text-align: center; text-decoration: none; margin-top: 20px; border-radius: 3px;">
+                                                    登录
+                                                </a>
+                                            </p>
+                                            
+                                            <!-- 提示信息框 -->
+                                            <div style="background-color: #f0fdfa; border-left: 4px solid #0891b2; padding: 20px; margin: 30px 0; border-radius: 4px;">
+                                                <p style="margin: 0; color: #333; font-size: 14px; line-height: 24px;">
+                                                    <strong style="color: #0891b2;">温馨提示：</strong>
+                                                </p>
+                                                <p style="margin: 10px 0 0 0; color: #666; font-size: 14px; line-height: 24px;">
+                                                    • 链接 {{.ExpireMinutes}} 分钟内有效，只能使用一次<br>
+                                                    • 如果不是您本人的操作，请忽略此邮件，您的账号不会受到影响<br>
+                                                    • 请勿将此邮件转发给他人
+                                                </p>
+                                            </div>
+                                            
+                                            <!-- 署名 -->
+                                            <p style="font-size: 14px; line-height: 26px; word-wrap: break-word; word-break: break-all; margin-top: 32px; color: #333;">
+                                                此致<br>
+                                                <strong>{{.SiteName}}团队</strong>
+                                            </p>
+                                        </td>
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                    </tr>
+                                </tbody>
+                            </table>
+                        </div>
+                        
+                        <!-- 底部 -->
+                        <div style="text-align: center; font-size: 12px; line-height: 18px; color: #999; margin-top: 20px;">
+                            <table style="width: 100%; font-weight: 300; margin-bottom: 10px; border-collapse: collapse;">
+                                <tbody>
+                                    <tr style="font-weight: 300;">
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                        <td style="max-width: 540px;">
+                                            <p style="text-align: center; margin: 20px auto 14px auto; font-size: 12px; color: #999;">
+                                                此为系统邮件，请勿回复。
+                                            </p>
+                                            <p style="max-width: 100%; margin: auto; font-size: 12px; color: #999; text-align: center; line-height: 22px;">
+                                                © {{.Year}} {{.SiteName}}
+                                            </p>
+                                        </td>
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                    </tr>
+                                </tbody>
+                            </table>
+                        </div>
+                    </td>
+                    <td style="width: 3%; max-width: 30px;"></td>
+                </tr>
+            </tbody>
+        </table>
+    </div>
+</body>
+</html>`
+}
+
+// getAccountDeletionTemplate 获取账号注销申请通知邮件模板
+func getAccountDeletionTemplate() string {
+	return `<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>账号注销申请</title>
+</head>
+<body style="margin: 0; padding: 0; font-family: 'helvetica neue', PingFangSC-Light, arial, 'hiragino sans gb', 'microsoft yahei ui', 'microsoft yahei', simsun, sans-serif; background-color: #f7f8fa;">
+    <div style="word-break: break-all; box-sizing: border-box; text-align: center; min-width: 320px; max-width: 660px; border: 1px solid #f6f6f6; background-color: #f7f8fa; margin: auto; padding: 20px 0 30px;">
+        <table style="width: 100%; font-weight: 300; margin-bottom: 10px; border-collapse: collapse;">
+            <tbody>
+                <tr style="font-weight: 300;">
+                    <td style="width: 3%; max-width: 30px;"></td>
+                    <td style="max-width: 600px;">
+                        <!-- 网站名称 -->
+                        <div style="width: 100%; text-align: left; margin-bottom: 20px;">
+                            <h1 style="margin: 0; color: #0891b2; font-size: 24px; font-weight: 600;">{{.SiteName}}</h1>
+                        </div>
+                        <!-- 蓝色分割线 -->
+                        <p style="height: 2px; background-color: #0891b2; border: 0; font-size: 0; padding: 0; width: 100%; margin-top: 20px; margin-bottom: 0;"></p>
+                        
+                        <!-- 内容区域 -->
+                        <div style="background-color: #fff; padding: 23px 0 20px; box-shadow: 0px 1px 1px 0px rgba(122, 55, 55, 0.2); text-align: left;">
+                            <table style="width: 100%; font-weight: 300; margin-bottom: 10px; border-collapse: collapse; text-align: left;">
+                                <tbody>
+                                    <tr style="font-weight: 300;">
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                        <td style="max-width: 480px; text-align: left;">
+                                            <!-- 标题 -->
+                                            <h1 style="font-size: 20px; line-height: 36px; margin: 0px 0px 22px; color: #333;">账号注销申请</h1>
+                                            
+                                            <!-- 问候语 -->
+                                            <p style="font-size: 14px; color: #333; line-height: 24px; margin: 0;">您好！</p>
+                                            
+                                            <p style="line-height: 24px; margin: 6px 0px 0px; overflow-wrap: break-word; word-break: break-all;">
+                                                <span style="color: rgb(51, 51, 51); font-size: 14px;">我们收到了您注销{{.SiteName}}账号（{{.Username}}）的申请，账号将于 {{.ScheduledAt}} 被永久删除。</span>
+                                            </p>
+                                            
+                                            <!-- 撤销按钮 -->
+                                            <p style="font-size: 14px; color: rgb(51, 51, 51); line-height: 24px; margin: 6px 0px 0px; word-wrap: break-word; word-break: break-all;">
+                                                <a href="{{.CancelURL}}" title="撤销注销" style="font-size: 16px; line-height: 45px; display: block; background-color: #0891b2; color: rgb(255, 255, 255); text-align: center; text-decoration: none; margin-top: 20px; border-radius: 3px;">
+                                                    撤销注销
+                                                </a>
+                                            </p>
+                                            
+                                            <!-- 提示信息框 -->
+                                            <div style="background-color: #f0fdfa; border-left: 4px solid #0891b2; padding: 20px; margin: 30px 0; border-radius: 4px;">
+                                                <p style="margin: 0; color: #333; font-size: 14px; line-height: 24px;">
+                                                    <strong style="color: #0891b2;">温馨提示：</strong>
+                                                </p>
+                                                <p style="margin: 10px 0 0 0; color: #666; font-size: 14px; line-height: 24px;">
+                                                    • 冷静期 {{.GraceDays}} 天内登录后可在个人中心撤销注销<br>
+                                                    • 删除后评论、点赞、聊天消息等个人数据将被清理且无法恢复<br>
+                                                    • 如果不是您本人的操作，请立即登录撤销并修改密码
+                                                </p>
+                                            </div>
+                                            
+                                            <!-- 署名 -->
+                                            <p style="font-size: 14px; line-height: 26px; word-wrap: break-word; word-break: break-all; margin-top: 32px; color: #333;">
+                                                此致<br>
+                                                <strong>{{.SiteName}}团队</strong>
+                                            </p>
+                                        </td>
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                    </tr>
+                                </tbody>
+                            </table>
+                        </div>
+                        
+                        <!-- 底部 -->
+                        <div style="text-align: center; font-size: 12px; line-height: 18px; color: #999; margin-top: 20px;">
+                            <table style="width: 100%; font-weight: 300; margin-bottom: 10px; border-collapse: collapse;">
+                                <tbody>
+                                    <tr style="font-weight: 300;">
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                        <td style="max-width: 540px;">
+                                            <p style="text-align: center; margin: 20px auto 14px auto; font-size: 12px; color: #999;">
+                                                此为系统邮件，请勿回复。
+                                            </p>
+                                            <p style="max-width: 100%; margin: auto; font-size: 12px; color: #999; text-align: center; line-height: 22px;">
+                                                © {{.Year}} {{.SiteName}}
+                                            </p>
+                                        </td>
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                    </tr>
+                                </tbody>
+                            </table>
+                        </div>
+                    </td>
+                    <td style="width: 3%; max-width: 30px;"></td>
+                </tr>
+            </tbody>
+        </table>
+    </div>
+</body>
+</html>`
+}
+
+// getRegistrationResultTemplate 获取注册审核结果邮件模板（通过和未通过共用）
+func getRegistrationResultTemplate() string {
+	return `<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.Title}}</title>
+</head>
+<body style="margin: 0; padding: 0; font-family: 'helvetica neue', PingFangSC-Light, arial, 'hiragino sans gb', 'microsoft yahei ui', 'microsoft yahei', simsun, sans-serif; background-color: #f7f8fa;">
+    <div style="word-break: break-all; box-sizing: border-box; text-align: center; min-width: 320px; max-width: 660px; border: 1px solid #f6f6f6; background-color: #f7f8fa; margin: auto; padding: 20px 0 30px;">
+        <table style="width: 100%; font-weight: 300; margin-bottom: 10px; border-collapse: collapse;">
+            <tbody>
+                <tr style="font-weight: 300;">
+                    <td style="width: 3%; max-width: 30px;"></td>
+                    <td style="max-width: 600px;">
+                        <!-- 网站名称 -->
+                        <div style="width: 100%; text-align: left; margin-bottom: 20px;">
+                            <h1 style="margin: 0; color: #0891b2; font-size: 24px; font-weight: 600;">{{.SiteName}}</h1>
+                        </div>
+                        <!-- 蓝色分割线 -->
+                        <p style="height: 2px; background-color: #0891b2; border: 0; font-size: 0; padding: 0; width: 100%; margin-top: 20px; margin-bottom: 0;"></p>
+                        
+                        <!-- 内容区域 -->
+                        <div style="background-color: #fff; padding: 23px 0 20px; box-shadow: 0px 1px 1px 0px rgba(122, 55, 55, 0.2); text-align: left;">
+                            <table style="width: 100%; font-weight: 300; margin-bottom: 10px; border-collapse: collapse; text-align: left;">
+                                <tbody>
+                                    <tr style="font-weight: 300;">
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                        <td style="max-width: 480px; text-align: left;">
+                                            <!-- 标题 -->
+                                            <h1 style="font-size: 20px; line-height: 36px; margin: 0px 0px 22px; color: #333;">{{.Title}}</h1>
+                                            
+                                            <!-- 问候语 -->
+                                            <p style="font-size: 14px; color: #333; line-height: 24px; margin: 0;">您好！</p>
+                                            
+                                            <p style="line-height: 24px; margin: 6px 0px 0px; overflow-wrap: break-word; word-break: break-all;">
+                                                <span style="color: rgb(51, 51, 51); font-size: 14px;">{{.Message}}</span>
+                                            </p>
+                                            
+                                            <!-- 账号信息框 -->
+                                            <div style="background-color: #f0fdfa; border-left: 4px solid #0891b2; padding: 20px; margin: 30px 0; border-radius: 4px;">
+                                                <p style="margin: 0; color: #333; font-size: 14px; line-height: 24px;">
+                                                    <strong style="color: #0891b2;">账号：</strong>{{.Username}}
+                                                </p>
+                                                {{if .Reason}}
+                                                <p style="margin: 10px 0 0 0; color: #333; font-size: 14px; line-height: 24px;">
+                                                    <strong style="color: #0891b2;">原因：</strong>{{.Reason}}
+                                                </p>
+                                                {{end}}
+                                            </div>
+                                            {{if .ButtonURL}}
+                                            <!-- 按钮 -->
+                                            <p style="font-size: 14px; color: rgb(51, 51, 51); line-height: 24px; margin: 6px 0px 0px; word-wrap: break-word; word-break: break-all;">
+                                                <a href="{{.ButtonURL}}" title="{{.ButtonText}}" style="font-size: 16px; line-height: 45px; display: block; background-color: #0891b2; color: rgb(255, 255, 255); text-align: center; text-decoration: none; margin-top: 20px; border-radius: 3px;">
+                                                    {{.ButtonText}}
+                                                </a>
+                                            </p>
+                                            {{end}}
+                                            
+                                            <!-- 署名 -->
+                                            <p style="font-size: 14px; line-height: 26px; word-wrap: break-word; word-break: break-all; margin-top: 32px; color: #333;">
+                                                此致<br>
+                                                <strong>{{.SiteName}}团队</strong>
+                                            </p>
+                                        </td>
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                    </tr>
+                                </tbody>
+                            </table>
+                        </div>
+                        
+                        <!-- 底部 -->
+                        <div style="text-align: center; font-size: 12px; line-height: 18px; color: #999; margin-top: 20px;">
+                            <table style="width: 100%; font-weight: 300; margin-bottom: 10px; border-collapse: collapse;">
+                                <tbody>
+                                    <tr style="font-weight: 300;">
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                        <td style="max-width: 540px;">
+                                            <p style="text-align: center; margin: 20px auto 14px auto; font-size: 12px; color: #999;">
+                                                此为系统邮件，请勿回复。
+                                            </p>
+                                            <p style="max-width: 100%; margin: auto; font-size: 12px; color: #999; text-align: center; line-height: 22px;">
+                                                © {{.Year}} {{.SiteName}}
+                                            </p>
+                                        </td>
+                                        <td style="width: 3.2%; max-width: 30px;"></td>
+                                    </tr>
+                                </tbody>
+                            </table>
+                        </div>
+                    </td>
+                    <td style="width: 3%; max-width: 30px;"></td>
+                </tr>
+            </tbody>
+        </table>
+    </div>
+</body>
+</html>`
+}
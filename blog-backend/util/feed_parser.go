@@ -0,0 +1,223 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：feed_parser.go
+ * 创建时间：2026-10-17 18:12:36
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：订阅源解析工具函数，将 RSS 2.0、RSS 1.0（RDF）和 Atom 订阅统一解析为条目列表
+ */
+package util
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// FeedEntry 订阅条目（与订阅格式无关）
+type FeedEntry struct {
+	GUID      string    // 条目唯一标识（guid/id，缺失时使用链接）
+	Title     string    // 标题
+	Link      string    // 文章链接
+	Summary   string    // 摘要（已去除HTML）
+	Author    string    // 作者
+	Published time.Time // 发布时间（无法解析时为零值）
+}
+
+// rawFeed 同时兼容 RSS 2.0（rss>channel>item）、RSS 1.0（rdf:RDF>item）和 Atom（feed>entry）
+type rawFeed struct {
+	XMLName      xml.Name
+	ChannelItems []rawItem  `xml:"channel>item"`
+	Items        []rawItem  `xml:"item"`
+	Entries      []rawEntry `xml:"entry"`
+}
+
+type rawItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	Description string `xml:"description"`
+	Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Author      string `xml:"author"`
+	Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	About       string `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+}
+
+type rawEntry struct {
+	Title     string         `xml:"title"`
+	ID        string         `xml:"id"`
+	Links     []rawAtomLink  `xml:"link"`
+	Summary   string         `xml:"summary"`
+	Content   string         `xml:"content"`
+	Published string         `xml:"published"`
+	Updated   string         `xml:"updated"`
+	Authors   []rawAtomActor `xml:"author"`
+}
+
+type rawAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+type rawAtomActor struct {
+	Name string `xml:"name"`
+}
+
+// feedDateLayouts 订阅中常见的时间格式（RFC 822/1123 及其变体、RFC 3339、以及部分站点的非标准格式）
+var feedDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 02 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04:05 -0700",
+	"02 Jan 2006 15:04:05 MST",
+	time.RFC822Z,
+	time.RFC822,
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// ParseFeed 解析 RSS/Atom 订阅内容
+// 参数:
+//   - data: 订阅原始内容（支持 XML 声明中的非 UTF-8 编码）
+//
+// 返回:
+//   - []FeedEntry: 订阅条目，保持订阅中的顺序
+//   - error: 不是有效的 RSS/Atom 时返回错误
+func ParseFeed(data []byte) ([]FeedEntry, error) {
+	var raw rawFeed
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.CharsetReader = charset.NewReaderLabel
+	dec.Strict = false
+	dec.Entity = xml.HTMLEntity
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	var entries []FeedEntry
+	switch strings.ToLower(raw.XMLName.Local) {
+	case "rss":
+		for _, item := range raw.ChannelItems {
+			entries = append(entries, item.toEntry())
+		}
+	case "rdf":
+		for _, item := range raw.Items {
+			entries = append(entries, item.toEntry())
+		}
+	case "feed":
+		for _, entry := range raw.Entries {
+			entries = append(entries, entry.toEntry())
+		}
+	default:
+		return nil, errors.New("不是有效的 RSS/Atom 订阅")
+	}
+
+	// 过滤没有链接的条目
+	result := entries[:0]
+	for _, entry := range entries {
+		if entry.Link == "" {
+			continue
+		}
+		if entry.GUID == "" {
+			entry.GUID = entry.Link
+		}
+		if entry.Title == "" {
+			entry.Title = entry.Link
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+// toEntry 转换 RSS 条目
+func (item rawItem) toEntry() FeedEntry {
+	summary := item.Description
+	if summary == "" {
+		summary = item.Content
+	}
+	author := item.Creator
+	if author == "" {
+		author = item.Author
+	}
+	date := item.PubDate
+	if date == "" {
+		date = item.Date
+	}
+	guid := item.GUID
+	if guid == "" {
+		guid = item.About
+	}
+
+	return FeedEntry{
+		GUID:      strings.TrimSpace(guid),
+		Title:     cleanText(item.Title),
+		Link:      strings.TrimSpace(item.Link),
+		Summary:   StripHTML(summary),
+		Author:    cleanText(author),
+		Published: ParseFeedDate(date),
+	}
+}
+
+// toEntry 转换 Atom 条目
+func (entry rawEntry) toEntry() FeedEntry {
+	// 优先使用 rel="alternate"（或未指定 rel）的链接
+	var link string
+	for _, l := range entry.Links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			link = l.Href
+			break
+		}
+	}
+	if link == "" && len(entry.Links) > 0 {
+		link = entry.Links[0].Href
+	}
+
+	summary := entry.Summary
+	if summary == "" {
+		summary = entry.Content
+	}
+	date := entry.Published
+	if date == "" {
+		date = entry.Updated
+	}
+	var author string
+	if len(entry.Authors) > 0 {
+		author = entry.Authors[0].Name
+	}
+
+	return FeedEntry{
+		GUID:      strings.TrimSpace(entry.ID),
+		Title:     cleanText(entry.Title),
+		Link:      strings.TrimSpace(link),
+		Summary:   StripHTML(summary),
+		Author:    cleanText(author),
+		Published: ParseFeedDate(date),
+	}
+}
+
+// ParseFeedDate 解析订阅中的时间，无法解析时返回零值
+func ParseFeedDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range feedDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,91 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：http_client.go
+ * 创建时间：2026-10-18 08:12:05
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：对外请求 HTTP 客户端，拦截指向内网、回环及云元数据地址的连接，防止 SSRF
+ */
+package util
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress 目标地址属于禁止访问的网段
+var ErrForbiddenAddress = errors.New("禁止访问内网地址")
+
+// forbiddenPrefixes 除回环、私有、链路本地等通用判断外额外禁止的网段
+var forbiddenPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),         // 本网络
+	netip.MustParsePrefix("100.64.0.0/10"),     // 运营商级 NAT（含阿里云元数据 100.100.100.200）
+	netip.MustParsePrefix("192.0.0.0/24"),      // IETF 协议分配
+	netip.MustParsePrefix("198.18.0.0/15"),     // 基准测试
+	netip.MustParsePrefix("240.0.0.0/4"),       // 保留地址
+	netip.MustParsePrefix("64:ff9b::/96"),      // NAT64
+	netip.MustParsePrefix("64:ff9b:1::/48"),    // 本地 NAT64
+	netip.MustParsePrefix("2001:db8::/32"),     // 文档地址
+	netip.MustParsePrefix("fd00:ec2::254/128"), // AWS IPv6 元数据
+}
+
+// IsPublicIP 判断 IP 是否为可对外访问的公网地址
+// 回环、私有、链路本地（含 169.254.169.254 元数据地址）、组播、未指定地址及保留网段均视为非公网
+func IsPublicIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, prefix := range forbiddenPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// safeDialControl 在建立连接前校验实际解析出的目标 IP
+// 作用于每一次拨号，因此重定向后的连接和 DNS 重绑定同样会被拦截
+func safeDialControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+	if !IsPublicIP(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// NewSafeHTTPClient 创建访问外部站点用的 HTTP 客户端
+// 拒绝连接回环、私有、链路本地及云元数据等地址；不读取环境变量中的代理，确保校验的是真实目标地址
+// 参数:
+//   - timeout: 整个请求的超时时间
+//
+// 返回:
+//   - *http.Client: HTTP 客户端
+func NewSafeHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   safeDialControl,
+	}
+	transport := &http.Transport{
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -6,7 +6,7 @@
  * 系统用户：Administrator
  * 作　　者：無以菱
  * 联系邮箱：huangjing510@126.com
- * 功能描述：JWT工具函数，提供Token生成、解析和刷新功能
+ * 功能描述：JWT工具函数，提供访问令牌的生成和解析（含吊销检查）功能
  */
 package util
 
@@ -17,29 +17,47 @@ import (
 	"blog-backend/config"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 // Claims JWT声明结构体
 // 包含用户ID、用户名、角色等自定义声明，以及JWT标准声明（过期时间、签发时间等）
 type Claims struct {
-	UserID               uint   `json:"user_id"`  // 用户ID
-	Username             string `json:"username"` // 用户名
-	Role                 string `json:"role"`     // 用户角色：admin或user
+	UserID               uint   `json:"user_id"`          // 用户ID
+	Username             string `json:"username"`         // 用户名
+	Role                 string `json:"role"`             // 用户角色：admin或user
+	SessionID            uint   `json:"sid,omitempty"`    // 登录会话ID（用于按会话吊销）
+	IssuedAtMs           int64  `json:"iat_ms,omitempty"` // 签发时间（Unix毫秒，标准 iat 只精确到秒，用于按时间点吊销）
 	jwt.RegisteredClaims        // JWT标准声明（过期时间、签发时间、签发者等）
 }
 
-// GenerateToken 生成 JWT Token
-func GenerateToken(userID uint, username, role string) (string, error) {
-	expirationTime := time.Now().Add(time.Duration(config.Cfg.JWT.ExpireHours) * time.Hour)
+// AccessTokenTTL 访问令牌有效期
+// 优先使用 access_expire_minutes，未配置时沿用 expire_hours
+func AccessTokenTTL() time.Duration {
+	if config.Cfg.JWT.AccessExpireMinutes > 0 {
+		return time.Duration(config.Cfg.JWT.AccessExpireMinutes) * time.Minute
+	}
+	return time.Duration(config.Cfg.JWT.ExpireHours) * time.Hour
+}
+
+// GenerateToken 生成 JWT 访问令牌
+// 参数:
+//   - sessionID: 所属登录会话ID
+func GenerateToken(userID uint, username, role string, sessionID uint) (string, error) {
+	now := time.Now()
+	expirationTime := now.Add(AccessTokenTTL())
 
 	claims := &Claims{
-		UserID:   userID,
-		Username: username,
-		Role:     role,
+		UserID:     userID,
+		Username:   username,
+		Role:       role,
+		SessionID:  sessionID,
+		IssuedAtMs: now.UnixMilli(),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(now),
 			Issuer:    "blog-backend",
+			ID:        uuid.NewString(), // Token ID（jti），用于单独吊销
 		},
 	}
 
@@ -64,20 +82,16 @@ func ParseToken(tokenString string) (*Claims, error) {
 	return nil, errors.New("invalid token")
 }
 
-// RefreshToken 刷新 Token
-func RefreshToken(tokenString string) (string, error) {
+// ParseValidToken 解析 JWT Token 并检查是否已被吊销
+func ParseValidToken(tokenString string) (*Claims, error) {
 	claims, err := ParseToken(tokenString)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-
-	// 如果 Token 还有超过 1 小时的有效期，不需要刷新
-	if time.Until(claims.ExpiresAt.Time) > time.Hour {
-		return tokenString, nil
+	if IsTokenRevoked(claims) {
+		return nil, errors.New("token revoked")
 	}
-
-	// 生成新的 Token
-	return GenerateToken(claims.UserID, claims.Username, claims.Role)
+	return claims, nil
 }
 
 // GetTimeAfterMinutes 获取指定分钟后的时间
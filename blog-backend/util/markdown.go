@@ -0,0 +1,58 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：markdown.go
+ * 创建时间：2026-10-17 16:05:32
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：Markdown 渲染工具函数，将文章 Markdown 转换为 HTML（用于订阅源全文输出等服务端渲染场景），
+ *          以及将 HTML 转换为纯文本（用于生成摘要）
+ */
+package util
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// markdownRenderer CommonMark + GFM 渲染器
+// 未启用 html.WithUnsafe，原文中的 HTML 会被忽略，javascript: 等危险链接也不会输出
+var markdownRenderer = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+)
+
+// RenderMarkdown 将 Markdown 渲染为 HTML
+// 参数:
+//   - src: Markdown 文本
+//
+// 返回:
+//   - string: 渲染后的 HTML，渲染失败时返回空字符串
+func RenderMarkdown(src string) string {
+	var buf bytes.Buffer
+	if err := markdownRenderer.Convert([]byte(src), &buf); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+var (
+	htmlTagRe    = regexp.MustCompile(`(?s)<(script|style)[^>]*>.*?</(script|style)>|<[^>]*>`)
+	whitespaceRe = regexp.MustCompile(`[\s\x{00a0}\x{3000}]+`)
+)
+
+// StripHTML 去除 HTML 标签并合并空白，返回纯文本
+func StripHTML(s string) string {
+	s = htmlTagRe.ReplaceAllString(s, " ")
+	return cleanText(html.UnescapeString(s))
+}
+
+// cleanText 合并连续空白并去除首尾空白
+func cleanText(s string) string {
+	return strings.TrimSpace(whitespaceRe.ReplaceAllString(s, " "))
+}
@@ -0,0 +1,351 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：oauth.go
+ * 创建时间：2026-10-18 03:05:37
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：第三方登录（OAuth2）提供方适配层，封装授权码模式的授权地址生成（state + PKCE）、
+ *          换取访问令牌和获取用户信息；新增提供方只需在 oauthProviderPresets 中登记
+ */
+package util
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog-backend/config"
+)
+
+// oauthHTTPTimeout 调用提供方接口的超时时间
+const oauthHTTPTimeout = 10 * time.Second
+
+// OAuthUserInfo 第三方账号信息
+type OAuthUserInfo struct {
+	ID       string // 提供方内的用户唯一ID
+	Username string // 提供方内的登录名
+	Nickname string
+	Email    string // 已验证的主邮箱，可能为空
+	Avatar   string
+}
+
+// OAuthProvider 第三方登录提供方
+type OAuthProvider interface {
+	// Name 提供方标识（github、gitee）
+	Name() string
+	// DisplayName 提供方展示名称
+	DisplayName() string
+	// AuthCodeURL 生成授权页地址
+	AuthCodeURL(state, codeChallenge string) string
+	// Exchange 使用授权码和 PKCE 校验码换取访问令牌
+	Exchange(ctx context.Context, code, codeVerifier string) (string, error)
+	// FetchUser 使用访问令牌获取第三方账号信息
+	FetchUser(ctx context.Context, accessToken string) (*OAuthUserInfo, error)
+}
+
+// oauthProviderPreset 提供方的默认端点和差异化处理
+type oauthProviderPreset struct {
+	displayName  string
+	authURL      string
+	tokenURL     string
+	userInfoURL  string
+	emailsURL    string
+	scopes       []string
+	tokenInQuery bool                              // 是否在查询参数中携带 access_token
+	parseEmails  func(body []byte) (string, error) // 从邮箱列表中选出已验证的主邮箱
+}
+
+// oauthProviderPresets 已支持的提供方
+var oauthProviderPresets = map[string]oauthProviderPreset{
+	"github": {
+		displayName: "GitHub",
+		authURL:     "https://github.com/login/oauth/authorize",
+		tokenURL:    "https://github.com/login/oauth/access_token",
+		userInfoURL: "https://api.github.com/user",
+		emailsURL:   "https://api.github.com/user/emails",
+		scopes:      []string{"read:user", "user:email"},
+		parseEmails: parseGitHubEmails,
+	},
+	"gitee": {
+		displayName:  "Gitee",
+		authURL:      "https://gitee.com/oauth/authorize",
+		tokenURL:     "https://gitee.com/oauth/token",
+		userInfoURL:  "https://gitee.com/api/v5/user",
+		emailsURL:    "https://gitee.com/api/v5/emails",
+		scopes:       []string{"user_info", "emails"},
+		tokenInQuery: true,
+		parseEmails:  parseGiteeEmails,
+	},
+}
+
+// oauth2Provider 基于配置的通用 OAuth2 提供方实现
+type oauth2Provider struct {
+	name   string
+	preset oauthProviderPreset
+	cfg    config.OAuthProviderConfig
+	client *http.Client
+}
+
+// GetOAuthProvider 获取已启用的提供方（未登记、未启用或缺少 Client ID 时返回 false）
+func GetOAuthProvider(name string) (OAuthProvider, bool) {
+	preset, ok := oauthProviderPresets[name]
+	if !ok || config.Cfg == nil {
+		return nil, false
+	}
+	cfg, ok := config.Cfg.OAuth.Providers[name]
+	if !ok || !cfg.Enabled || cfg.ClientID == "" {
+		return nil, false
+	}
+
+	// 端点留空时使用官方地址
+	if cfg.AuthURL == "" {
+		cfg.AuthURL = preset.authURL
+	}
+	if cfg.TokenURL == "" {
+		cfg.TokenURL = preset.tokenURL
+	}
+	if cfg.UserInfoURL == "" {
+		cfg.UserInfoURL = preset.userInfoURL
+	}
+	if cfg.EmailsURL == "" {
+		cfg.EmailsURL = preset.emailsURL
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = preset.scopes
+	}
+
+	return &oauth2Provider{
+		name:   name,
+		preset: preset,
+		cfg:    cfg,
+		client: &http.Client{Timeout: oauthHTTPTimeout},
+	}, true
+}
+
+// EnabledOAuthProviders 获取所有已启用的提供方（按名称排序）
+func EnabledOAuthProviders() []OAuthProvider {
+	names := make([]string, 0, len(oauthProviderPresets))
+	for name := range oauthProviderPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	providers := make([]OAuthProvider, 0, len(names))
+	for _, name := range names {
+		if p, ok := GetOAuthProvider(name); ok {
+			providers = append(providers, p)
+		}
+	}
+	return providers
+}
+
+// GeneratePKCEVerifier 生成 PKCE 校验码（43 位 base64url 字符）
+func GeneratePKCEVerifier() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// PKCEChallenge 按 S256 方式计算 PKCE 挑战码
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (p *oauth2Provider) Name() string {
+	return p.name
+}
+
+func (p *oauth2Provider) DisplayName() string {
+	return p.preset.displayName
+}
+
+func (p *oauth2Provider) AuthCodeURL(state, codeChallenge string) string {
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.cfg.ClientID)
+	params.Set("redirect_uri", p.cfg.RedirectURL)
+	params.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	params.Set("state", state)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(p.cfg.AuthURL, "?") {
+		sep = "&"
+	}
+	return p.cfg.AuthURL + sep + params.Encode()
+}
+
+func (p *oauth2Provider) Exchange(ctx context.Context, code, codeVerifier string) (string, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("client_secret", p.cfg.ClientSecret)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	body, err := p.do(req)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("解析令牌响应失败: %w", err)
+	}
+	if result.AccessToken == "" {
+		if result.Error != "" {
+			return "", fmt.Errorf("换取令牌失败: %s %s", result.Error, result.ErrorDescription)
+		}
+		return "", errors.New("换取令牌失败: 响应中没有 access_token")
+	}
+	return result.AccessToken, nil
+}
+
+func (p *oauth2Provider) FetchUser(ctx context.Context, accessToken string) (*OAuthUserInfo, error) {
+	body, err := p.get(ctx, p.cfg.UserInfoURL, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// GitHub 和 Gitee 的用户信息字段一致
+	var profile struct {
+		ID        json.Number `json:"id"`
+		Login     string      `json:"login"`
+		Name      string      `json:"name"`
+		AvatarURL string      `json:"avatar_url"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&profile); err != nil {
+		return nil, fmt.Errorf("解析用户信息失败: %w", err)
+	}
+	if profile.ID.String() == "" {
+		return nil, errors.New("用户信息中没有账号ID")
+	}
+
+	info := &OAuthUserInfo{
+		ID:       profile.ID.String(),
+		Username: profile.Login,
+		Nickname: profile.Name,
+		Avatar:   profile.AvatarURL,
+	}
+
+	// 只采用邮箱列表中已验证的主邮箱；资料中的公开邮箱未经验证，可被任意填写，不能用于注册账号
+	if p.cfg.EmailsURL != "" && p.preset.parseEmails != nil {
+		if emails, err := p.get(ctx, p.cfg.EmailsURL, accessToken); err == nil {
+			if email, err := p.preset.parseEmails(emails); err == nil && email != "" {
+				info.Email = email
+			}
+		}
+	}
+	return info, nil
+}
+
+// get 携带访问令牌发起 GET 请求
+func (p *oauth2Provider) get(ctx context.Context, rawURL, accessToken string) ([]byte, error) {
+	if p.preset.tokenInQuery {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		q := u.Query()
+		q.Set("access_token", accessToken)
+		u.RawQuery = q.Encode()
+		rawURL = u.String()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+	return p.do(req)
+}
+
+// do 发送请求并返回响应体（非 2xx 视为失败）
+func (p *oauth2Provider) do(req *http.Request) ([]byte, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, errors.New("请求 " + req.URL.Host + " 失败: HTTP " + strconv.Itoa(resp.StatusCode))
+	}
+	return body, nil
+}
+
+// parseGitHubEmails 解析 GitHub 邮箱列表：[{"email", "primary", "verified"}]
+func parseGitHubEmails(body []byte) (string, error) {
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := json.Unmarshal(body, &emails); err != nil {
+		return "", err
+	}
+	for _, e := range emails {
+		if e.Primary && e.Verified {
+			return e.Email, nil
+		}
+	}
+	return "", nil
+}
+
+// parseGiteeEmails 解析 Gitee 邮箱列表：[{"email", "state", "scope"}]，state 为 confirmed 表示已验证
+func parseGiteeEmails(body []byte) (string, error) {
+	var emails []struct {
+		Email string   `json:"email"`
+		State string   `json:"state"`
+		Scope []string `json:"scope"`
+	}
+	if err := json.Unmarshal(body, &emails); err != nil {
+		return "", err
+	}
+	for _, e := range emails {
+		if e.State != "confirmed" {
+			continue
+		}
+		for _, scope := range e.Scope {
+			if scope == "primary" {
+				return e.Email, nil
+			}
+		}
+	}
+	return "", nil
+}
@@ -35,16 +35,8 @@ func LogOperation(c *gin.Context, action, module string, targetID *uint, targetN
 
 	userID := userIDVal.(uint)
 
-	// 获取用户角色
-	roleVal, exists := c.Get("role")
-	if !exists {
-		return
-	}
-
-	role := roleVal.(string)
-
-	// 只记录管理员和超级管理员的操作
-	if !constant.IsAdminRole(role) {
+	// 只记录可以进入管理后台的角色的操作
+	if !HasPermission(c, constant.PermAdminAccess) {
 		return
 	}
 
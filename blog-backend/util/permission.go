@@ -0,0 +1,52 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：permission.go
+ * 创建时间：2026-10-18 02:24:37
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：请求级权限判断，按上下文中的角色加载一次权限集合并保存在上下文中，
+ *          供中间件和处理器在同一请求内复用
+ */
+package util
+
+import (
+	"blog-backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// permissionsKey 上下文中的当前用户权限集合键名
+	permissionsKey = "permissions"
+)
+
+// HasPermission 判断当前请求的用户是否拥有指定权限（未登录或加载失败时视为没有权限）
+func HasPermission(c *gin.Context, permission string) bool {
+	return CurrentPermissions(c)[permission]
+}
+
+// CurrentPermissions 获取当前请求的用户权限集合
+// 首次调用时按上下文中的角色（role）加载，之后直接使用上下文中保存的结果；角色变化时重新加载
+func CurrentPermissions(c *gin.Context) map[string]bool {
+	role := c.GetString("role")
+	if role == "" {
+		return nil
+	}
+	if val, exists := c.Get(permissionsKey); exists {
+		if cached, ok := val.(requestPermissions); ok && cached.role == role {
+			return cached.perms
+		}
+	}
+
+	perms, _ := repository.NewRoleRepository().GetPermissions(role)
+	c.Set(permissionsKey, requestPermissions{role: role, perms: perms})
+	return perms
+}
+
+// requestPermissions 保存在上下文中的权限集合及其对应的角色
+type requestPermissions struct {
+	role  string
+	perms map[string]bool
+}
@@ -0,0 +1,104 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：token_revoke.go
+ * 创建时间：2026-10-17 21:06:14
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：JWT吊销工具函数，基于Redis记录已吊销的Token ID（jti）、已吊销的登录会话以及用户级"此前签发的Token全部失效"时间点
+ */
+package util
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"blog-backend/db"
+)
+
+const (
+	// revokedTokenKeyPrefix 已吊销Token的键前缀，后接jti，过期时间与Token剩余有效期一致
+	revokedTokenKeyPrefix = "jwt:revoked:"
+	// revokedBeforeKeyPrefix 用户Token失效时间点的键前缀，后接用户ID，值为Unix毫秒
+	revokedBeforeKeyPrefix = "jwt:revoked_before:"
+	// revokedSessionKeyPrefix 已吊销会话的键前缀，后接会话ID
+	revokedSessionKeyPrefix = "jwt:revoked_session:"
+)
+
+// RevokeToken 吊销单个Token（用于登出）
+// 参数:
+//   - claims: 已解析的Token声明
+//
+// 返回:
+//   - error: 写入Redis失败时返回错误
+func RevokeToken(claims *Claims) error {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		// 旧版本签发的Token没有jti，只能按用户整体吊销
+		return RevokeUserTokens(claims.UserID)
+	}
+
+	ttl := time.Until(claims.ExpiresAt.Time)
+	if ttl <= 0 {
+		return nil
+	}
+	return db.RDB.Set(context.Background(), revokedTokenKeyPrefix+claims.ID, "1", ttl).Err()
+}
+
+// RevokeUserTokens 吊销用户此前签发的所有Token（用于修改/重置密码、禁用账号、变更角色等）
+// 签发时间早于记录时间点的Token都会被拒绝，记录在最长Token有效期后自动过期
+func RevokeUserTokens(userID uint) error {
+	// 按毫秒记录，吊销之后（即使在同一秒内）重新签发的Token不受影响
+	before := time.Now().UnixMilli()
+	ttl := AccessTokenTTL() + time.Minute
+	return db.RDB.Set(context.Background(), fmt.Sprintf("%s%d", revokedBeforeKeyPrefix, userID), before, ttl).Err()
+}
+
+// RevokeSessionTokens 吊销会话下已签发的所有访问令牌（会话被吊销时调用）
+func RevokeSessionTokens(sessionID uint) error {
+	ttl := AccessTokenTTL() + time.Minute
+	return db.RDB.Set(context.Background(), fmt.Sprintf("%s%d", revokedSessionKeyPrefix, sessionID), "1", ttl).Err()
+}
+
+// IsTokenRevoked 检查Token是否已被吊销
+// Redis 不可用时视为未吊销（Token 仍受签名和过期时间约束），避免缓存故障导致全站无法登录
+func IsTokenRevoked(claims *Claims) bool {
+	ctx := context.Background()
+
+	if claims.ID != "" {
+		if n, err := db.RDB.Exists(ctx, revokedTokenKeyPrefix+claims.ID).Result(); err == nil && n > 0 {
+			return true
+		}
+	}
+
+	if claims.SessionID != 0 {
+		if n, err := db.RDB.Exists(ctx, fmt.Sprintf("%s%d", revokedSessionKeyPrefix, claims.SessionID)).Result(); err == nil && n > 0 {
+			return true
+		}
+	}
+
+	value, err := db.RDB.Get(ctx, fmt.Sprintf("%s%d", revokedBeforeKeyPrefix, claims.UserID)).Result()
+	if err != nil {
+		return false
+	}
+	before, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false
+	}
+	issuedAt := tokenIssuedAtMs(claims)
+	return issuedAt == 0 || issuedAt < before
+}
+
+// tokenIssuedAtMs 获取Token的签发时间（Unix毫秒），无法确定时返回0
+// 旧版本签发的Token没有 iat_ms，只有精确到秒的 iat，按该秒的起点处理
+func tokenIssuedAtMs(claims *Claims) int64 {
+	if claims.IssuedAtMs > 0 {
+		return claims.IssuedAtMs
+	}
+	if claims.IssuedAt != nil {
+		return claims.IssuedAt.Unix() * 1000
+	}
+	return 0
+}
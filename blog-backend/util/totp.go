@@ -0,0 +1,168 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：totp.go
+ * 创建时间：2026-10-17 22:41:37
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：TOTP两步验证工具函数（RFC 6238，HMAC-SHA1、6位数字、30秒步长），
+ *          提供密钥生成、验证码计算与校验、otpauth 配置链接生成和恢复码生成
+ */
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// totpPeriod 验证码步长（秒）
+	totpPeriod = 30
+	// totpDigits 验证码位数
+	totpDigits = 6
+	// totpSkew 校验时允许的前后步数（容忍客户端时钟偏差）
+	totpSkew = 1
+)
+
+// totpEncoding 密钥使用不带填充的 Base32 编码（认证器App通用格式）
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret 生成随机的TOTP密钥（160位，Base32编码）
+func GenerateTOTPSecret() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(buf), nil
+}
+
+// GenerateTOTPCode 计算指定时间的TOTP验证码
+// 参数:
+//   - secret: Base32编码的密钥
+//   - t: 时间点
+//
+// 返回:
+//   - string: 6位数字验证码
+//   - error: 密钥格式错误时返回错误
+func GenerateTOTPCode(secret string, t time.Time) (string, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return totpCode(key, t.Unix()/totpPeriod), nil
+}
+
+// ValidateTOTPCode 校验TOTP验证码，允许前后各一个步长的时钟偏差
+// 参数:
+//   - secret: Base32编码的密钥
+//   - code: 用户输入的验证码（允许包含空格）
+//   - t: 当前时间
+//   - lastStep: 上次验证成功的步数，不大于该步数的验证码视为已使用（防止重放）
+//
+// 返回:
+//   - int64: 验证成功时对应的步数，调用方应保存以供下次校验
+//   - bool: 是否验证成功
+func ValidateTOTPCode(secret, code string, t time.Time, lastStep int64) (int64, bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return 0, false
+	}
+
+	current := t.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= lastStep {
+			continue
+		}
+		if hmac.Equal([]byte(totpCode(key, step)), []byte(code)) {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// TOTPProvisioningURI 生成认证器App使用的 otpauth:// 配置链接（前端可据此生成二维码）
+// 参数:
+//   - issuer: 签发方（一般为网站名称）
+//   - account: 账号名（用户名或邮箱）
+//   - secret: Base32编码的密钥
+func TOTPProvisioningURI(issuer, account, secret string) string {
+	label := url.PathEscape(account)
+	if issuer != "" {
+		label = url.PathEscape(issuer) + ":" + label
+	}
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	if issuer != "" {
+		query.Set("issuer", issuer)
+	}
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprintf("%d", totpDigits))
+	query.Set("period", fmt.Sprintf("%d", totpPeriod))
+
+	// 部分认证器App不识别查询参数中的 "+"，空格统一编码为 %20
+	return "otpauth://totp/" + label + "?" + strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+// GenerateRecoveryCodes 生成一次性恢复码，格式为 xxxxx-xxxxx（小写字母和数字，排除易混淆字符）
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	const charset = "abcdefghjkmnpqrstuvwxyz23456789"
+
+	codes := make([]string, 0, n)
+	buf := make([]byte, 10)
+	for i := 0; i < n; i++ {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		code := make([]byte, 0, 11)
+		for j, b := range buf {
+			if j == 5 {
+				code = append(code, '-')
+			}
+			code = append(code, charset[int(b)%len(charset)])
+		}
+		codes = append(codes, string(code))
+	}
+	return codes, nil
+}
+
+// NormalizeRecoveryCode 规范化用户输入的恢复码（去除空格、统一小写、补全连字符）
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+	if len(code) == 10 && !strings.Contains(code, "-") {
+		code = code[:5] + "-" + code[5:]
+	}
+	return code
+}
+
+// decodeTOTPSecret 解码Base32密钥（兼容小写和带填充的写法）
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(secret), " ", ""))
+	return totpEncoding.DecodeString(strings.TrimRight(secret, "="))
+}
+
+// totpCode 按 RFC 4226 计算指定计数器的HOTP验证码
+func totpCode(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
@@ -0,0 +1,71 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：useragent.go
+ * 创建时间：2026-10-17 21:38:40
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：User-Agent解析工具函数，将User-Agent粗略解析为"浏览器 on 操作系统"形式的设备描述
+ */
+package util
+
+import "strings"
+
+// uaRule User-Agent匹配规则（按顺序匹配，先匹配到的生效）
+type uaRule struct {
+	keyword string
+	name    string
+}
+
+// browserRules 浏览器规则（Edge/Opera 等基于 Chromium 的浏览器需排在 Chrome 之前）
+var browserRules = []uaRule{
+	{"micromessenger", "WeChat"},
+	{"edg/", "Edge"},
+	{"opr/", "Opera"},
+	{"firefox/", "Firefox"},
+	{"chrome/", "Chrome"},
+	{"safari/", "Safari"},
+	{"curl/", "curl"},
+	{"postman", "Postman"},
+}
+
+// osRules 操作系统规则（iOS/Android 需排在 macOS/Linux 之前）
+var osRules = []uaRule{
+	{"iphone", "iOS"},
+	{"ipad", "iPadOS"},
+	{"android", "Android"},
+	{"windows", "Windows"},
+	{"mac os x", "macOS"},
+	{"cros", "ChromeOS"},
+	{"linux", "Linux"},
+}
+
+// DescribeUserAgent 将User-Agent解析为设备描述，如 "Chrome on Windows"
+// 无法识别时返回 "Unknown"
+func DescribeUserAgent(ua string) string {
+	lower := strings.ToLower(ua)
+
+	browser := matchUARule(lower, browserRules)
+	os := matchUARule(lower, osRules)
+	switch {
+	case browser != "" && os != "":
+		return browser + " on " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	default:
+		return "Unknown"
+	}
+}
+
+// matchUARule 返回第一个匹配的规则名称
+func matchUARule(ua string, rules []uaRule) string {
+	for _, rule := range rules {
+		if strings.Contains(ua, rule.keyword) {
+			return rule.name
+		}
+	}
+	return ""
+}
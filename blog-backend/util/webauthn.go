@@ -0,0 +1,480 @@
+/*
+ * 项目名称：blog-backend
+ * 文件名称：webauthn.go
+ * 创建时间：2026-10-18 03:58:41
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：WebAuthn（通行密钥）校验工具，实现注册响应和登录断言的服务端校验（W3C WebAuthn Level 2），
+ *          支持 ES256、EdDSA、RS256 三种签名算法；不校验认证器的证明（attestation），按首次注册信任处理
+ */
+package util
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"blog-backend/config"
+)
+
+// COSE 签名算法
+const (
+	COSEAlgES256 = -7
+	COSEAlgEdDSA = -8
+	COSEAlgRS256 = -257
+)
+
+// 认证器数据标志位
+const (
+	webAuthnFlagUserPresent  = 0x01 // UP：用户在场
+	webAuthnFlagUserVerified = 0x04 // UV：已验证用户身份（指纹、面容、PIN 等）
+	webAuthnFlagAttestedData = 0x40 // AT：包含凭据数据（仅注册时）
+)
+
+// WebAuthnSupportedAlgorithms 支持的签名算法（按优先顺序，用于 pubKeyCredParams）
+var WebAuthnSupportedAlgorithms = []int{COSEAlgES256, COSEAlgEdDSA, COSEAlgRS256}
+
+// WebAuthnCredentialData 注册成功后得到的凭据信息
+type WebAuthnCredentialData struct {
+	CredentialID []byte
+	PublicKey    []byte // COSE 格式公钥
+	Algorithm    int
+	SignCount    uint32
+}
+
+// webAuthnClientData 客户端数据（clientDataJSON）
+type webAuthnClientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+// GenerateWebAuthnChallenge 生成 WebAuthn 挑战值（32 字节随机数，base64url 编码）
+func GenerateWebAuthnChallenge() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeBase64URL 解码 base64url 字符串（兼容带填充和标准 base64 编码）
+func DecodeBase64URL(s string) ([]byte, error) {
+	if b, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	if b, err := base64.URLEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return base64.StdEncoding.DecodeString(s)
+}
+
+// ParseWebAuthnChallenge 从 clientDataJSON 中取出挑战值（用于在校验前查找对应的登录请求）
+func ParseWebAuthnChallenge(clientDataJSON []byte) (string, error) {
+	var clientData webAuthnClientData
+	if err := json.Unmarshal(clientDataJSON, &clientData); err != nil {
+		return "", errors.New("无效的客户端数据")
+	}
+	return clientData.Challenge, nil
+}
+
+// VerifyWebAuthnRegistration 校验注册响应（navigator.credentials.create 的结果）
+// 参数:
+//   - clientDataJSON: 客户端数据
+//   - attestationObject: 证明对象（CBOR 编码）
+//   - challenge: 发起注册时下发的挑战值
+//
+// 返回:
+//   - *WebAuthnCredentialData: 凭据ID、公钥和初始签名计数
+func VerifyWebAuthnRegistration(clientDataJSON, attestationObject []byte, challenge string) (*WebAuthnCredentialData, error) {
+	if err := verifyWebAuthnClientData(clientDataJSON, "webauthn.create", challenge); err != nil {
+		return nil, err
+	}
+
+	decoded, _, err := decodeCBOR(attestationObject)
+	if err != nil {
+		return nil, errors.New("无效的证明对象")
+	}
+	attestation, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("无效的证明对象")
+	}
+	authData, ok := attestation["authData"].([]byte)
+	if !ok {
+		return nil, errors.New("无效的证明对象")
+	}
+
+	flags, signCount, err := verifyWebAuthnAuthData(authData)
+	if err != nil {
+		return nil, err
+	}
+	if flags&webAuthnFlagAttestedData == 0 {
+		return nil, errors.New("认证器未返回凭据数据")
+	}
+
+	// 凭据数据：AAGUID(16) + 凭据ID长度(2) + 凭据ID + COSE 公钥
+	data := authData[37:]
+	if len(data) < 18 {
+		return nil, errors.New("认证器数据不完整")
+	}
+	idLen := int(binary.BigEndian.Uint16(data[16:18]))
+	if len(data) < 18+idLen {
+		return nil, errors.New("认证器数据不完整")
+	}
+	credentialID := data[18 : 18+idLen]
+	keyData := data[18+idLen:]
+
+	_, keyLen, err := decodeCBOR(keyData)
+	if err != nil {
+		return nil, errors.New("无效的公钥")
+	}
+	publicKey := keyData[:keyLen]
+	alg, err := webAuthnKeyAlgorithm(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebAuthnCredentialData{
+		CredentialID: append([]byte(nil), credentialID...),
+		PublicKey:    append([]byte(nil), publicKey...),
+		Algorithm:    alg,
+		SignCount:    signCount,
+	}, nil
+}
+
+// VerifyWebAuthnAssertion 校验登录断言（navigator.credentials.get 的结果）
+// 参数:
+//   - clientDataJSON: 客户端数据
+//   - authenticatorData: 认证器数据
+//   - signature: 签名
+//   - publicKey: 注册时保存的 COSE 公钥
+//   - challenge: 发起登录时下发的挑战值
+//   - storedSignCount: 已保存的签名计数
+//
+// 返回:
+//   - uint32: 新的签名计数
+func VerifyWebAuthnAssertion(clientDataJSON, authenticatorData, signature, publicKey []byte, challenge string, storedSignCount uint32) (uint32, error) {
+	if err := verifyWebAuthnClientData(clientDataJSON, "webauthn.get", challenge); err != nil {
+		return 0, err
+	}
+
+	_, signCount, err := verifyWebAuthnAuthData(authenticatorData)
+	if err != nil {
+		return 0, err
+	}
+
+	// 签名内容：认证器数据 + SHA-256(clientDataJSON)
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte(nil), authenticatorData...), clientDataHash[:]...)
+	if err := verifyWebAuthnSignature(publicKey, signed, signature); err != nil {
+		return 0, err
+	}
+
+	// 计数器不支持时始终为 0；支持时必须递增，否则认证器可能被克隆
+	if (signCount != 0 || storedSignCount != 0) && signCount <= storedSignCount {
+		return 0, errors.New("通行密钥签名计数异常，可能已被复制，请删除后重新注册")
+	}
+	return signCount, nil
+}
+
+// verifyWebAuthnClientData 校验客户端数据的类型、挑战值和来源
+func verifyWebAuthnClientData(clientDataJSON []byte, expectedType, challenge string) error {
+	var clientData webAuthnClientData
+	if err := json.Unmarshal(clientDataJSON, &clientData); err != nil {
+		return errors.New("无效的客户端数据")
+	}
+	if clientData.Type != expectedType {
+		return errors.New("客户端数据类型不匹配")
+	}
+	if challenge == "" || clientData.Challenge != challenge {
+		return errors.New("挑战值不匹配，请重试")
+	}
+
+	for _, origin := range config.Cfg.WebAuthn.Origins {
+		if clientData.Origin == origin {
+			return nil
+		}
+	}
+	return errors.New("请求来源不被允许")
+}
+
+// verifyWebAuthnAuthData 校验认证器数据中的依赖方ID哈希和用户验证标志
+// 认证器数据：RP ID 哈希(32) + 标志(1) + 签名计数(4) + 可选的凭据数据和扩展
+func verifyWebAuthnAuthData(authData []byte) (byte, uint32, error) {
+	if len(authData) < 37 {
+		return 0, 0, errors.New("认证器数据不完整")
+	}
+
+	rpIDHash := sha256.Sum256([]byte(config.Cfg.WebAuthn.RPID))
+	if !bytes.Equal(authData[:32], rpIDHash[:]) {
+		return 0, 0, errors.New("依赖方ID不匹配")
+	}
+
+	// 通行密钥代替密码登录，要求认证器验证用户身份
+	flags := authData[32]
+	if flags&webAuthnFlagUserPresent == 0 || flags&webAuthnFlagUserVerified == 0 {
+		return 0, 0, errors.New("认证器未验证用户身份")
+	}
+	return flags, binary.BigEndian.Uint32(authData[33:37]), nil
+}
+
+// webAuthnKeyAlgorithm 解析 COSE 公钥的算法，并确认公钥可用
+func webAuthnKeyAlgorithm(coseKey []byte) (int, error) {
+	key, err := parseCOSEKey(coseKey)
+	if err != nil {
+		return 0, err
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey:
+		return COSEAlgES256, nil
+	case ed25519.PublicKey:
+		return COSEAlgEdDSA, nil
+	case *rsa.PublicKey:
+		return COSEAlgRS256, nil
+	}
+	return 0, errors.New("不支持的公钥算法")
+}
+
+// verifyWebAuthnSignature 使用 COSE 公钥校验签名
+func verifyWebAuthnSignature(coseKey, signed, signature []byte) error {
+	key, err := parseCOSEKey(coseKey)
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256(signed)
+	valid := false
+	switch pub := key.(type) {
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(pub, digest[:], signature)
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(pub, signed, signature)
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature) == nil
+	}
+	if !valid {
+		return errors.New("通行密钥签名校验失败")
+	}
+	return nil
+}
+
+// parseCOSEKey 解析 COSE 格式公钥（RFC 8152）
+func parseCOSEKey(coseKey []byte) (crypto.PublicKey, error) {
+	decoded, _, err := decodeCBOR(coseKey)
+	if err != nil {
+		return nil, errors.New("无效的公钥")
+	}
+	m, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("无效的公钥")
+	}
+
+	kty, _ := m[int64(1)].(int64)
+	alg, _ := m[int64(3)].(int64)
+	switch {
+	case kty == 2 && alg == COSEAlgES256:
+		// EC2：-1 曲线（1 为 P-256）、-2 x 坐标、-3 y 坐标
+		crv, _ := m[int64(-1)].(int64)
+		x, _ := m[int64(-2)].([]byte)
+		y, _ := m[int64(-3)].([]byte)
+		if crv != 1 || len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("无效的 ES256 公钥")
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, errors.New("无效的 ES256 公钥")
+		}
+		return pub, nil
+	case kty == 1 && alg == COSEAlgEdDSA:
+		// OKP：-1 曲线（6 为 Ed25519）、-2 公钥
+		crv, _ := m[int64(-1)].(int64)
+		x, _ := m[int64(-2)].([]byte)
+		if crv != 6 || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("无效的 EdDSA 公钥")
+		}
+		return ed25519.PublicKey(x), nil
+	case kty == 3 && alg == COSEAlgRS256:
+		// RSA：-1 模数 n、-2 指数 e
+		n, _ := m[int64(-1)].([]byte)
+		e, _ := m[int64(-2)].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("无效的 RS256 公钥")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	}
+	return nil, fmt.Errorf("不支持的公钥算法: %d", alg)
+}
+
+// decodeCBOR 解码一个 CBOR 数据项（RFC 8949），返回解码结果和消耗的字节数
+// 只支持 WebAuthn 用到的子集：整数、字节串、文本串、数组、映射、简单值和标签，不支持不定长编码
+// 整数统一解码为 int64，映射解码为 map[interface{}]interface{}
+func decodeCBOR(data []byte) (interface{}, int, error) {
+	d := &cborDecoder{data: data}
+	v, err := d.decode(0)
+	return v, d.pos, err
+}
+
+// cborMaxDepth CBOR 嵌套深度上限
+const cborMaxDepth = 16
+
+var errCBORTruncated = errors.New("cbor: 数据不完整")
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *cborDecoder) decode(depth int) (interface{}, error) {
+	if depth > cborMaxDepth {
+		return nil, errors.New("cbor: 嵌套过深")
+	}
+	if d.pos >= len(d.data) {
+		return nil, errCBORTruncated
+	}
+
+	initial := d.data[d.pos]
+	d.pos++
+	major, info := initial>>5, initial&0x1f
+
+	// 简单值和浮点数
+	if major == 7 {
+		switch info {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22, 23:
+			return nil, nil
+		case 25, 26, 27:
+			size := uint64(1) << (info - 24)
+			if _, err := d.read(size); err != nil {
+				return nil, err
+			}
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cbor: 不支持的简单值 %d", info)
+	}
+
+	arg, err := d.argument(info)
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case 0:
+		if arg > 1<<63-1 {
+			return nil, errors.New("cbor: 整数溢出")
+		}
+		return int64(arg), nil
+	case 1:
+		if arg > 1<<63-1 {
+			return nil, errors.New("cbor: 整数溢出")
+		}
+		return -1 - int64(arg), nil
+	case 2:
+		b, err := d.read(arg)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+	case 3:
+		b, err := d.read(arg)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case 4:
+		if arg > uint64(len(d.data)) {
+			return nil, errCBORTruncated
+		}
+		items := make([]interface{}, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			item, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case 5:
+		if arg > uint64(len(d.data)) {
+			return nil, errCBORTruncated
+		}
+		m := make(map[interface{}]interface{}, arg)
+		for i := uint64(0); i < arg; i++ {
+			key, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, errors.New("cbor: 不支持的映射键类型")
+			}
+			value, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = value
+		}
+		return m, nil
+	case 6:
+		// 标签：忽略标签号，返回被标记的数据项
+		return d.decode(depth + 1)
+	}
+	return nil, fmt.Errorf("cbor: 不支持的类型 %d", major)
+}
+
+// argument 读取数据项头部的参数（长度或整数值）
+func (d *cborDecoder) argument(info byte) (uint64, error) {
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info == 24:
+		b, err := d.read(1)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(b[0]), nil
+	case info == 25:
+		b, err := d.read(2)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case info == 26:
+		b, err := d.read(4)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	case info == 27:
+		b, err := d.read(8)
+		if err != nil {
+			return 0, err
+		}
+		return binary.BigEndian.Uint64(b), nil
+	}
+	return 0, errors.New("cbor: 不支持不定长编码")
+}
+
+// read 读取 n 个字节
+func (d *cborDecoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errCBORTruncated
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
@@ -0,0 +1,393 @@
+package util
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"blog-backend/config"
+)
+
+const (
+	testRPID   = "blog.example.com"
+	testOrigin = "https://blog.example.com"
+)
+
+// setTestWebAuthnConfig 设置测试用的依赖方配置，测试结束后恢复
+func setTestWebAuthnConfig(t *testing.T) {
+	t.Helper()
+	old := config.Cfg
+	cfg := &config.Config{}
+	cfg.WebAuthn.RPID = testRPID
+	cfg.WebAuthn.Origins = []string{testOrigin}
+	config.Cfg = cfg
+	t.Cleanup(func() { config.Cfg = old })
+}
+
+// cborPairs 按给定顺序编码的 CBOR 映射（键值交替）
+type cborPairs []interface{}
+
+// encodeCBOR 编码测试数据（只实现构造认证器数据所需的子集）
+func encodeCBOR(v interface{}) []byte {
+	switch x := v.(type) {
+	case int:
+		if x < 0 {
+			return cborHead(1, uint64(-1-x))
+		}
+		return cborHead(0, uint64(x))
+	case []byte:
+		return append(cborHead(2, uint64(len(x))), x...)
+	case string:
+		return append(cborHead(3, uint64(len(x))), x...)
+	case cborPairs:
+		out := cborHead(5, uint64(len(x)/2))
+		for _, item := range x {
+			out = append(out, encodeCBOR(item)...)
+		}
+		return out
+	}
+	panic("encodeCBOR: unsupported type")
+}
+
+// cborHead 编码数据项头部
+func cborHead(major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n <= 0xff:
+		return []byte{major<<5 | 24, byte(n)}
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(n))
+	case n <= 0xffffffff:
+		return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(n))
+	}
+	return binary.BigEndian.AppendUint64([]byte{major<<5 | 27}, n)
+}
+
+// testAuthenticator 模拟认证器，生成注册和登录响应
+type testAuthenticator struct {
+	alg          int
+	signer       crypto.Signer
+	coseKey      []byte
+	credentialID []byte
+}
+
+func newTestAuthenticator(t *testing.T, alg int) *testAuthenticator {
+	t.Helper()
+	a := &testAuthenticator{alg: alg, credentialID: []byte("credential-" + t.Name())}
+
+	switch alg {
+	case COSEAlgES256:
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, y := make([]byte, 32), make([]byte, 32)
+		key.X.FillBytes(x)
+		key.Y.FillBytes(y)
+		a.signer = key
+		a.coseKey = encodeCBOR(cborPairs{1, 2, 3, COSEAlgES256, -1, 1, -2, x, -3, y})
+	case COSEAlgEdDSA:
+		pub, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.signer = key
+		a.coseKey = encodeCBOR(cborPairs{1, 1, 3, COSEAlgEdDSA, -1, 6, -2, []byte(pub)})
+	case COSEAlgRS256:
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := big.NewInt(int64(key.E)).Bytes()
+		a.signer = key
+		a.coseKey = encodeCBOR(cborPairs{1, 3, 3, COSEAlgRS256, -1, key.N.Bytes(), -2, e})
+	default:
+		t.Fatalf("unsupported alg %d", alg)
+	}
+	return a
+}
+
+// testClientData 构造 clientDataJSON
+func testClientData(typ, challenge, origin string) []byte {
+	data, _ := json.Marshal(map[string]string{"type": typ, "challenge": challenge, "origin": origin})
+	return data
+}
+
+// authData 构造认证器数据，attested 为 true 时附带凭据数据
+func (a *testAuthenticator) authData(rpID string, flags byte, signCount uint32, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	data := append([]byte(nil), rpIDHash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, signCount)
+	if attested {
+		data = append(data, make([]byte, 16)...) // AAGUID
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		data = append(data, a.coseKey...)
+	}
+	return data
+}
+
+// register 生成注册响应（fmt 为 none 的证明对象）
+func (a *testAuthenticator) register(rpID, challenge string, signCount uint32) (clientDataJSON, attestationObject []byte) {
+	clientDataJSON = testClientData("webauthn.create", challenge, testOrigin)
+	flags := byte(webAuthnFlagUserPresent | webAuthnFlagUserVerified | webAuthnFlagAttestedData)
+	attestationObject = encodeCBOR(cborPairs{
+		"fmt", "none",
+		"attStmt", cborPairs{},
+		"authData", a.authData(rpID, flags, signCount, true),
+	})
+	return clientDataJSON, attestationObject
+}
+
+// assert 生成登录断言
+func (a *testAuthenticator) assert(t *testing.T, rpID, challenge string, flags byte, signCount uint32) (clientDataJSON, authData, signature []byte) {
+	t.Helper()
+	clientDataJSON = testClientData("webauthn.get", challenge, testOrigin)
+	authData = a.authData(rpID, flags, signCount, false)
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte(nil), authData...), clientDataHash[:]...)
+
+	var err error
+	if a.alg == COSEAlgEdDSA {
+		signature, err = a.signer.Sign(rand.Reader, signed, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(signed)
+		signature, err = a.signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return clientDataJSON, authData, signature
+}
+
+const testFlagsUPUV = byte(webAuthnFlagUserPresent | webAuthnFlagUserVerified)
+
+var testAlgorithms = []struct {
+	name string
+	alg  int
+}{
+	{"ES256", COSEAlgES256},
+	{"EdDSA", COSEAlgEdDSA},
+	{"RS256", COSEAlgRS256},
+}
+
+func TestWebAuthnRegisterAndAssert(t *testing.T) {
+	setTestWebAuthnConfig(t)
+
+	for _, tt := range testAlgorithms {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAuthenticator(t, tt.alg)
+
+			challenge := GenerateWebAuthnChallenge()
+			clientDataJSON, attestationObject := a.register(testRPID, challenge, 0)
+			cred, err := VerifyWebAuthnRegistration(clientDataJSON, attestationObject, challenge)
+			if err != nil {
+				t.Fatalf("VerifyWebAuthnRegistration: %v", err)
+			}
+			if cred.Algorithm != tt.alg {
+				t.Errorf("Algorithm = %d, want %d", cred.Algorithm, tt.alg)
+			}
+			if !bytes.Equal(cred.CredentialID, a.credentialID) {
+				t.Errorf("CredentialID = %q, want %q", cred.CredentialID, a.credentialID)
+			}
+			if !bytes.Equal(cred.PublicKey, a.coseKey) {
+				t.Error("PublicKey does not match the COSE key")
+			}
+
+			challenge = GenerateWebAuthnChallenge()
+			clientDataJSON, authData, signature := a.assert(t, testRPID, challenge, testFlagsUPUV, 1)
+			count, err := VerifyWebAuthnAssertion(clientDataJSON, authData, signature, cred.PublicKey, challenge, cred.SignCount)
+			if err != nil {
+				t.Fatalf("VerifyWebAuthnAssertion: %v", err)
+			}
+			if count != 1 {
+				t.Errorf("sign count = %d, want 1", count)
+			}
+
+			// 篡改签名
+			signature[len(signature)-1] ^= 0xff
+			if _, err := VerifyWebAuthnAssertion(clientDataJSON, authData, signature, cred.PublicKey, challenge, cred.SignCount); err == nil {
+				t.Error("tampered signature accepted")
+			}
+		})
+	}
+}
+
+func TestWebAuthnRejectsWrongRPID(t *testing.T) {
+	setTestWebAuthnConfig(t)
+
+	for _, tt := range testAlgorithms {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAuthenticator(t, tt.alg)
+
+			challenge := GenerateWebAuthnChallenge()
+			clientDataJSON, attestationObject := a.register("evil.example.com", challenge, 0)
+			if _, err := VerifyWebAuthnRegistration(clientDataJSON, attestationObject, challenge); err == nil || !strings.Contains(err.Error(), "依赖方ID不匹配") {
+				t.Errorf("registration err = %v, want rpId mismatch", err)
+			}
+
+			// 签名本身有效，但认证器数据属于其他依赖方
+			clientDataJSON, authData, signature := a.assert(t, "evil.example.com", challenge, testFlagsUPUV, 1)
+			if _, err := VerifyWebAuthnAssertion(clientDataJSON, authData, signature, a.coseKey, challenge, 0); err == nil || !strings.Contains(err.Error(), "依赖方ID不匹配") {
+				t.Errorf("assertion err = %v, want rpId mismatch", err)
+			}
+		})
+	}
+}
+
+func TestWebAuthnRejectsReplayedCounter(t *testing.T) {
+	setTestWebAuthnConfig(t)
+	a := newTestAuthenticator(t, COSEAlgES256)
+
+	tests := []struct {
+		name      string
+		stored    uint32
+		signCount uint32
+		wantErr   bool
+	}{
+		{name: "increased", stored: 5, signCount: 6},
+		{name: "replayed", stored: 5, signCount: 5, wantErr: true},
+		{name: "decreased", stored: 5, signCount: 3, wantErr: true},
+		{name: "reset to zero", stored: 5, signCount: 0, wantErr: true},
+		{name: "counter unsupported", stored: 0, signCount: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			challenge := GenerateWebAuthnChallenge()
+			clientDataJSON, authData, signature := a.assert(t, testRPID, challenge, testFlagsUPUV, tt.signCount)
+			count, err := VerifyWebAuthnAssertion(clientDataJSON, authData, signature, a.coseKey, challenge, tt.stored)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "签名计数异常") {
+					t.Errorf("err = %v, want sign count error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if count != tt.signCount {
+				t.Errorf("count = %d, want %d", count, tt.signCount)
+			}
+		})
+	}
+}
+
+func TestWebAuthnRejectsInvalidAssertion(t *testing.T) {
+	setTestWebAuthnConfig(t)
+	a := newTestAuthenticator(t, COSEAlgEdDSA)
+	challenge := GenerateWebAuthnChallenge()
+
+	t.Run("challenge mismatch", func(t *testing.T) {
+		clientDataJSON, authData, signature := a.assert(t, testRPID, challenge, testFlagsUPUV, 1)
+		if _, err := VerifyWebAuthnAssertion(clientDataJSON, authData, signature, a.coseKey, GenerateWebAuthnChallenge(), 0); err == nil {
+			t.Error("accepted assertion for another challenge")
+		}
+	})
+
+	t.Run("origin not allowed", func(t *testing.T) {
+		clientDataJSON := testClientData("webauthn.get", challenge, "https://evil.example.com")
+		authData := a.authData(testRPID, testFlagsUPUV, 1, false)
+		if _, err := VerifyWebAuthnAssertion(clientDataJSON, authData, nil, a.coseKey, challenge, 0); err == nil || !strings.Contains(err.Error(), "来源") {
+			t.Errorf("err = %v, want origin error", err)
+		}
+	})
+
+	t.Run("registration data used for login", func(t *testing.T) {
+		clientDataJSON := testClientData("webauthn.create", challenge, testOrigin)
+		authData := a.authData(testRPID, testFlagsUPUV, 1, false)
+		if _, err := VerifyWebAuthnAssertion(clientDataJSON, authData, nil, a.coseKey, challenge, 0); err == nil || !strings.Contains(err.Error(), "类型") {
+			t.Errorf("err = %v, want type error", err)
+		}
+	})
+
+	t.Run("user not verified", func(t *testing.T) {
+		clientDataJSON, authData, signature := a.assert(t, testRPID, challenge, webAuthnFlagUserPresent, 1)
+		if _, err := VerifyWebAuthnAssertion(clientDataJSON, authData, signature, a.coseKey, challenge, 0); err == nil || !strings.Contains(err.Error(), "未验证用户身份") {
+			t.Errorf("err = %v, want user verification error", err)
+		}
+	})
+
+	t.Run("signed by another key", func(t *testing.T) {
+		other := newTestAuthenticator(t, COSEAlgEdDSA)
+		clientDataJSON, authData, signature := other.assert(t, testRPID, challenge, testFlagsUPUV, 1)
+		if _, err := VerifyWebAuthnAssertion(clientDataJSON, authData, signature, a.coseKey, challenge, 0); err == nil {
+			t.Error("accepted signature from another key")
+		}
+	})
+}
+
+func TestParseCOSEKeyRejectsInvalidKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		key  []byte
+	}{
+		{"unsupported alg", encodeCBOR(cborPairs{1, 2, 3, -35, -1, 2, -2, make([]byte, 48), -3, make([]byte, 48)})},
+		{"ES256 point not on curve", encodeCBOR(cborPairs{1, 2, 3, COSEAlgES256, -1, 1, -2, make([]byte, 32), -3, make([]byte, 32)})},
+		{"ES256 wrong curve", encodeCBOR(cborPairs{1, 2, 3, COSEAlgES256, -1, 2, -2, make([]byte, 32), -3, make([]byte, 32)})},
+		{"EdDSA short key", encodeCBOR(cborPairs{1, 1, 3, COSEAlgEdDSA, -1, 6, -2, make([]byte, 31)})},
+		{"RS256 short modulus", encodeCBOR(cborPairs{1, 3, 3, COSEAlgRS256, -1, make([]byte, 128), -2, []byte{1, 0, 1}})},
+		{"not a map", encodeCBOR("key")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseCOSEKey(tt.key); err == nil {
+				t.Error("parseCOSEKey accepted invalid key")
+			}
+		})
+	}
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	data := encodeCBOR(cborPairs{"a", 1, -2, []byte{0xde, 0xad}, "nested", cborPairs{"n", -300}})
+	v, n, err := decodeCBOR(append(data, 0xff))
+	if err != nil {
+		t.Fatalf("decodeCBOR: %v", err)
+	}
+	if n != len(data) {
+		t.Errorf("consumed %d bytes, want %d", n, len(data))
+	}
+	m := v.(map[interface{}]interface{})
+	if m["a"] != int64(1) || !bytes.Equal(m[int64(-2)].([]byte), []byte{0xde, 0xad}) {
+		t.Errorf("decoded = %#v", m)
+	}
+	if nested := m["nested"].(map[interface{}]interface{}); nested["n"] != int64(-300) {
+		t.Errorf("nested = %#v", nested)
+	}
+}
+
+func TestDecodeCBORRejectsMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"truncated byte string", []byte{0x45, 1, 2}},
+		{"truncated map", []byte{0xa2, 0x01, 0x02}},
+		{"huge length", []byte{0x5b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"huge array", []byte{0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"indefinite length", []byte{0x9f, 0x01, 0xff}},
+		{"too deep", append(bytes.Repeat([]byte{0x81}, cborMaxDepth+2), 0x00)},
+		{"byte string map key", []byte{0xa1, 0x41, 0x00, 0x01}},
+		{"integer overflow", []byte{0x1b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := decodeCBOR(tt.data); err == nil {
+				t.Error("decodeCBOR accepted malformed input")
+			}
+		})
+	}
+}
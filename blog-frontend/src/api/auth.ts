@@ -9,8 +9,10 @@
  * 功能描述：用户认证相关 API 接口定义，包括登录、注册、登出、用户信息管理、密码重置、邮箱修改等功能。
  */
 
-import { request } from '@/utils/request'
-import type { LoginForm, RegisterForm, LoginResponse, User, ProfileForm, PasswordForm, CaptchaResponse } from '@/types/auth'
+import type { AxiosResponse } from 'axios'
+import service, { request } from '@/utils/request'
+import type { LoginForm, RegisterForm, LoginResponse, User, ProfileForm, PasswordForm, CaptchaResponse, LoginHistory, AccessToken, OAuthProvider, OAuthBinding, Passkey, PasskeyRegistrationOptions, PasskeyLoginOptions, AccountDeletion } from '@/types/auth'
+import type { PageData } from '@/types/common'
 
 /**
  * 获取图形验证码
@@ -48,6 +50,53 @@ export function login(data: LoginForm) {
   return request.post<LoginResponse>('/auth/login', data)
 }
 
+/**
+ * 登录第二步：提交两步验证码
+ * @param data 登录挑战数据
+ * @param data.challenge_token 登录接口返回的挑战令牌
+ * @param data.code 认证器App中的6位验证码或恢复码
+ * @returns 返回令牌和用户信息
+ */
+export function loginTwoFactor(data: { challenge_token: string; code: string }) {
+  return request.post<LoginResponse>('/auth/login/2fa', data)
+}
+
+/**
+ * 发送免密登录链接到邮箱
+ * @param data 邮箱数据
+ * @param data.email 用户邮箱地址
+ * @returns 返回发送结果（邮箱未注册时同样返回成功）
+ */
+export function sendMagicLink(data: { email: string }) {
+  return request.post('/auth/magic-link', data)
+}
+
+/**
+ * 使用邮件中的登录链接登录
+ * @param token 链接中的令牌
+ * @returns 返回登录响应（已启用两步验证时只返回挑战令牌）
+ */
+export function magicLinkLogin(token: string) {
+  return request.post<LoginResponse>('/auth/magic-link/login', { token })
+}
+
+/**
+ * 发起通行密钥登录
+ * @returns 返回 navigator.credentials.get 所需的选项
+ */
+export function beginPasskeyLogin() {
+  return request.post<PasskeyLoginOptions>('/auth/passkeys/login/begin')
+}
+
+/**
+ * 提交通行密钥登录断言
+ * @param credential 浏览器返回的登录断言
+ * @returns 返回登录响应（已启用两步验证时只返回挑战令牌）
+ */
+export function finishPasskeyLogin(credential: object) {
+  return request.post<LoginResponse>('/auth/passkeys/login/finish', { credential })
+}
+
 /**
  * 用户登出
  * @returns 返回登出结果
@@ -83,15 +132,35 @@ export function updatePassword(data: PasswordForm) {
 }
 
 /**
- * 修改用户邮箱
+ * 修改邮箱第一步：向新邮箱发送验证码
+ * @param data 邮箱数据
+ * @param data.new_email 新邮箱地址
+ * @returns 返回发送结果
+ */
+export function sendEmailChangeCode(data: { new_email: string }) {
+  return request.post('/auth/email/send-code', data)
+}
+
+/**
+ * 修改用户邮箱（提交新邮箱收到的验证码）
  * @param data 邮箱修改数据
  * @param data.new_email 新邮箱地址
+ * @param data.code 新邮箱收到的6位验证码
  * @returns 返回修改结果
  */
-export function updateEmail(data: { new_email: string }) {
+export function updateEmail(data: { new_email: string; code: string }) {
   return request.put('/auth/email', data)
 }
 
+/**
+ * 撤销邮箱修改（原邮箱收到的"这不是我"链接）
+ * @param token 撤销链接中的令牌
+ * @returns 返回撤销结果
+ */
+export function revertEmailChange(token: string) {
+  return request.post('/auth/email/revert', { token })
+}
+
 /**
  * 获取邮箱修改信息（包括修改次数、剩余次数等）
  * @returns 返回邮箱修改相关信息
@@ -105,11 +174,176 @@ export function getEmailChangeInfo() {
 }
 
 /**
- * 刷新访问令牌
- * @returns 返回新的访问令牌
+ * 获取本人的登录记录（包括失败的登录尝试）
+ * @param params 分页参数
+ * @returns 返回分页的登录记录
+ */
+export function getLoginHistory(params: { page?: number; page_size?: number }) {
+  return request.get<PageData<LoginHistory>>('/auth/login-history', { params })
+}
+
+/**
+ * 获取本人的个人访问令牌列表
+ * @returns 返回有效的令牌列表（不含明文令牌）
+ */
+export function getAccessTokens() {
+  return request.get<AccessToken[]>('/auth/tokens')
+}
+
+/**
+ * 获取可授予的权限范围
+ * @returns 返回权限范围及说明
+ */
+export function getAccessTokenScopes() {
+  return request.get<{ scope: string; description: string }[]>('/auth/tokens/scopes')
+}
+
+/**
+ * 创建个人访问令牌
+ * @param data 令牌名称、权限范围和有效期（天）
+ * @returns 返回令牌信息，明文令牌 token 只返回这一次
+ */
+export function createAccessToken(data: { name: string; scopes: string[]; expires_in_days: number }) {
+  return request.post<AccessToken>('/auth/tokens', data)
+}
+
+/**
+ * 吊销个人访问令牌
+ * @param id 令牌ID
+ * @returns 返回吊销结果
+ */
+export function revokeAccessToken(id: number) {
+  return request.delete(`/auth/tokens/${id}`)
+}
+
+/**
+ * 获取本人的通行密钥列表
+ * @returns 返回通行密钥列表
+ */
+export function getPasskeys() {
+  return request.get<Passkey[]>('/auth/passkeys')
+}
+
+/**
+ * 发起通行密钥注册
+ * @returns 返回 navigator.credentials.create 所需的选项
+ */
+export function beginPasskeyRegistration() {
+  return request.post<PasskeyRegistrationOptions>('/auth/passkeys/register/begin')
+}
+
+/**
+ * 提交通行密钥注册结果
+ * @param data 通行密钥名称和浏览器返回的凭据
+ * @returns 返回保存的通行密钥
+ */
+export function finishPasskeyRegistration(data: { name: string; credential: object }) {
+  return request.post<Passkey>('/auth/passkeys/register/finish', data)
+}
+
+/**
+ * 删除通行密钥
+ * @param id 通行密钥ID
+ * @returns 返回删除结果
+ */
+export function deletePasskey(id: number) {
+  return request.delete(`/auth/passkeys/${id}`)
+}
+
+/**
+ * 导出本人的个人数据（JSON文件）
+ * @returns 返回文件下载响应
+ */
+export function exportAccountData(): Promise<AxiosResponse<Blob>> {
+  return service.get<Blob>('/auth/account/export', { responseType: 'blob' })
+}
+
+/**
+ * 获取本人的注销申请
+ * @returns 返回注销申请，未申请时为 null
+ */
+export function getAccountDeletion() {
+  return request.get<AccountDeletion | null>('/auth/account/deletion')
+}
+
+/**
+ * 申请注销账号，冷静期满后删除
+ * @param data 注销数据
+ * @param data.password 当前密码
+ * @param data.reason 注销原因（可选）
+ * @returns 返回注销申请
+ */
+export function requestAccountDeletion(data: { password: string; reason?: string }) {
+  return request.post<AccountDeletion>('/auth/account/deletion', data)
+}
+
+/**
+ * 撤销注销申请
+ * @returns 返回撤销结果
+ */
+export function cancelAccountDeletion() {
+  return request.delete('/auth/account/deletion')
+}
+
+/**
+ * 获取已启用的第三方登录方式
+ * @returns 返回登录方式列表
+ */
+export function getOAuthProviders() {
+  return request.get<OAuthProvider[]>('/auth/oauth/providers')
+}
+
+/**
+ * 获取第三方登录的授权页地址
+ * @param provider 登录方式（github、gitee）
+ * @returns 返回授权页地址，前端跳转到该地址
+ */
+export function getOAuthAuthorizeUrl(provider: string) {
+  return request.get<{ url: string }>(`/auth/oauth/${provider}/authorize`)
+}
+
+/**
+ * 使用第三方登录回调页拿到的一次性票据换取登录令牌
+ * @param ticket 一次性票据
+ * @returns 返回登录响应（已启用两步验证时只返回挑战令牌）
+ */
+export function exchangeOAuthTicket(ticket: string) {
+  return request.post<LoginResponse>('/auth/oauth/exchange', { ticket })
+}
+
+/**
+ * 获取本人的第三方账号绑定状态
+ * @returns 返回各平台的绑定状态
+ */
+export function getOAuthBindings() {
+  return request.get<OAuthBinding[]>('/auth/oauth/bindings')
+}
+
+/**
+ * 获取绑定第三方账号用的授权页地址
+ * @param provider 第三方平台（github、gitee）
+ * @returns 返回授权页地址
+ */
+export function bindOAuth(provider: string) {
+  return request.post<{ url: string }>(`/auth/oauth/bindings/${provider}`)
+}
+
+/**
+ * 解除第三方账号绑定
+ * @param provider 第三方平台（github、gitee）
+ * @returns 返回解除结果
+ */
+export function unbindOAuth(provider: string) {
+  return request.delete(`/auth/oauth/bindings/${provider}`)
+}
+
+/**
+ * 刷新访问令牌（旧的刷新令牌随即失效）
+ * @param refresh_token 刷新令牌
+ * @returns 返回新的访问令牌和刷新令牌
  */
-export function refreshToken() {
-  return request.post<{ token: string }>('/auth/refresh')
+export function refreshToken(refresh_token: string) {
+  return request.post<Omit<LoginResponse, 'user'>>('/auth/refresh', { refresh_token })
 }
 
 /**
@@ -18,6 +18,7 @@ import type { PaginationParams, PaginationResult } from '@/types/common'
 export interface ChatMessage {
   id: number                                    // 消息ID
   content: string                               // 消息内容
+  room_id?: number | null                       // 所属聊天室ID（系统广播为空）
   user_id?: number                              // 用户ID（可选）
   username: string                              // 用户名
   avatar?: string                               // 用户头像（可选）
@@ -27,15 +28,36 @@ export interface ChatMessage {
   is_broadcast?: boolean                        // 是否为广播消息
   target?: 'announcement' | 'chat' | 'both'     // 目标位置：公告栏、聊天室或两者
   status: number                                // 消息状态
+  is_recalled?: boolean                         // 是否已撤回（撤回后内容为空）
+  recalled_by?: 'sender' | 'admin'              // 撤回者：发送者本人或管理员
+  recalled_at?: string                          // 撤回时间
+  edited_at?: string | null                     // 最后编辑时间，为空表示未编辑
+  original_content?: string                     // 首次编辑前的原始内容（仅管理端返回）
   created_at: string                            // 创建时间
   updated_at: string                            // 更新时间
 }
 
+/**
+ * 聊天室接口
+ */
+export interface ChatRoom {
+  id: number
+  name: string
+  type: 'lobby' | 'public' | 'post' | 'admin'   // 公共大厅、公开聊天室、文章讨论室、管理员专用
+  post_id?: number | null                       // 文章讨论室对应的文章ID
+  description?: string
+  is_locked: boolean                            // 锁定后仅管理员可发言
+  is_archived: boolean                          // 归档后不可进入和发言
+  created_at: string
+  updated_at: string
+}
+
 /**
  * 在线用户信息接口
  */
 export interface OnlineUser {
   id: string            // 用户ID（WebSocket客户端ID）
+  user_id?: number      // 登录用户ID（匿名用户为空），用于发起私信
   username: string      // 用户名
   avatar?: string       // 用户头像（可选）
 }
@@ -46,43 +68,107 @@ export interface OnlineUser {
 export interface OnlineInfo {
   online_count: number        // 在线用户数量
   online_users: OnlineUser[]  // 在线用户列表
+  room_id?: number            // 指定聊天室时返回
+  room_count?: number         // 聊天室在线人数
+  room_users?: OnlineUser[]   // 聊天室在线用户列表
 }
 
 /**
  * WebSocket消息接口
  */
 export interface WebSocketMessage {
-  type: 'message' | 'history' | 'user_join' | 'user_leave' | 'user_list' | 'system'  // 消息类型
+  type: 'message' | 'history' | 'user_join' | 'user_leave' | 'user_list' | 'system'
+    | 'room_joined' | 'room_left' | 'room_users' | 'room_updated' | 'kick'
+    | 'direct_message' | 'direct_read' | 'message_recalled' | 'message_edited'
+    | 'muted' | 'unmuted'                                                            // 消息类型
   data: any                                                                          // 消息数据
+  room_id?: number                                                                   // 所属聊天室，为空表示全站消息
   timestamp: number                                                                  // 时间戳
 }
 
 /**
  * 获取消息列表（公开接口）
- * @param params 分页参数
+ * @param params 分页参数，room_id 为空时返回公共大厅的消息
  * @returns 返回分页的消息列表
  */
-export function getChatMessages(params: PaginationParams) {
+export function getChatMessages(params: PaginationParams & { room_id?: number }) {
   return request.get<PaginationResult<ChatMessage>>('/chat/messages', { params })
 }
 
 /**
  * 获取在线信息
+ * @param roomId 聊天室ID（可选，传入时额外返回该聊天室的在线用户）
  * @returns 返回在线用户数量和列表
  */
-export function getOnlineInfo() {
-  return request.get<OnlineInfo>('/chat/online')
+export function getOnlineInfo(roomId?: number) {
+  return request.get<OnlineInfo>('/chat/online', { params: roomId ? { room_id: roomId } : undefined })
+}
+
+/**
+ * 获取可进入的聊天室列表
+ * @returns 返回聊天室列表（公共大厅在最前）
+ */
+export function getChatRooms() {
+  return request.get<ChatRoom[]>('/chat/rooms')
+}
+
+/**
+ * 获取文章讨论室（首次访问时自动创建）
+ * @param postId 文章ID
+ * @returns 返回讨论室信息
+ */
+export function getPostChatRoom(postId: number) {
+  return request.get<ChatRoom>(`/chat/rooms/post/${postId}`)
 }
 
 /**
  * 管理员：获取消息列表
- * @param params 分页参数
+ * @param params 分页参数，可按 room_id 筛选
  * @returns 返回分页的消息列表
  */
-export function adminGetMessages(params: PaginationParams) {
+export function adminGetMessages(params: PaginationParams & { room_id?: number }) {
   return request.get<PaginationResult<ChatMessage>>('/admin/chat/messages', { params })
 }
 
+/**
+ * 管理员：获取全部聊天室（包含已归档和文章讨论室）
+ * @returns 返回聊天室列表
+ */
+export function adminGetChatRooms() {
+  return request.get<ChatRoom[]>('/admin/chat/rooms')
+}
+
+/**
+ * 管理员：创建聊天室
+ * @param data.name 名称
+ * @param data.type 类型：public 公开，admin 管理员专用
+ * @param data.description 简介（可选）
+ * @returns 返回创建的聊天室
+ */
+export function adminCreateChatRoom(data: { name: string; type: 'public' | 'admin'; description?: string }) {
+  return request.post<ChatRoom>('/admin/chat/rooms', data)
+}
+
+/**
+ * 管理员：锁定或解锁聊天室
+ * @param id 聊天室ID
+ * @param locked 是否锁定
+ * @returns 返回更新后的聊天室
+ */
+export function adminLockChatRoom(id: number, locked: boolean) {
+  return request.put<ChatRoom>(`/admin/chat/rooms/${id}/lock`, { locked })
+}
+
+/**
+ * 管理员：归档或恢复聊天室
+ * @param id 聊天室ID
+ * @param archived 是否归档
+ * @returns 返回更新后的聊天室
+ */
+export function adminArchiveChatRoom(id: number, archived: boolean) {
+  return request.put<ChatRoom>(`/admin/chat/rooms/${id}/archive`, { archived })
+}
+
 /**
  * 管理员：删除消息
  * @param id 消息ID
@@ -124,11 +210,69 @@ export function adminBanIP(client_id: string, reason?: string, duration?: number
   return request.post('/admin/chat/ban', { client_id, reason, duration })
 }
 
+/**
+ * 管理员：撤回消息（不受撤回时限限制）
+ * @param id 消息ID
+ * @returns 返回撤回结果
+ */
+export function adminRecallMessage(id: number) {
+  return request.post(`/admin/chat/messages/${id}/recall`)
+}
+
+/**
+ * 禁言记录接口
+ */
+export interface ChatMute {
+  identity: string              // 发言者身份：user_<用户ID> 或 ip_<IP>
+  user_id?: number              // 登录用户ID（按IP禁言时为空）
+  username: string              // 用户名
+  reason: string                // 禁言原因
+  source: 'admin' | 'auto'      // 管理员禁言或违规自动禁言
+  muted_at: string              // 禁言时间
+  expires_at: string            // 到期时间
+}
+
+/**
+ * 管理员：获取禁言中的用户列表
+ * @returns 返回禁言列表（按到期时间排序）
+ */
+export function adminGetMutes() {
+  return request.get<ChatMute[]>('/admin/chat/mutes')
+}
+
+/**
+ * 管理员：禁言用户，client_id、user_id、ip 三选一（登录用户按用户禁言，匿名用户按IP禁言）
+ * @param data 禁言对象、时长（分钟）和原因
+ * @returns 返回禁言记录
+ */
+export function adminMuteUser(data: { client_id?: string; user_id?: number; ip?: string; duration: number; reason?: string }) {
+  return request.post<ChatMute>('/admin/chat/mutes', data)
+}
+
+/**
+ * 管理员：解除禁言
+ * @param identity 发言者身份
+ * @returns 返回解除结果
+ */
+export function adminUnmuteUser(identity: string) {
+  return request.delete(`/admin/chat/mutes/${encodeURIComponent(identity)}`)
+}
+
 /**
  * 聊天室配置接口
  */
 export interface ChatSettings {
-  chat_mute_all: string  // 是否全员禁言，'0'表示否，'1'表示是
+  chat_mute_all?: string             // 是否全员禁言，'0'表示否，'1'表示是
+  chat_max_length?: string           // 单条消息最大长度，'0'表示不限制
+  chat_rate_capacity?: string        // 令牌桶容量（允许的突发消息数），'0'表示不限流
+  chat_rate_interval?: string        // 令牌恢复间隔（秒）
+  chat_duplicate_window?: string     // 重复消息检测窗口（秒），'0'表示不检测
+  chat_auto_mute_threshold?: string  // 触发自动禁言的违规次数，'0'表示不自动禁言
+  chat_auto_mute_window?: string     // 违规次数统计窗口（秒）
+  chat_auto_mute_duration?: string   // 自动禁言时长（秒）
+  chat_recall_window?: string        // 发送后可撤回或编辑的时限（秒），'0'表示不允许
+  chat_sensitive_words?: string      // 敏感词列表，每行一个
+  chat_sensitive_action?: string     // 命中敏感词的处理方式：mask 替换为*，reject 拒绝发送
 }
 
 /**
@@ -140,10 +284,146 @@ export function getChatSettings() {
 }
 
 /**
- * 管理员更新聊天室配置
+ * 管理员获取聊天室完整配置（含防刷与内容过滤设置）
+ * @returns 返回聊天室配置
+ */
+export function adminGetChatSettings() {
+  return request.get<ChatSettings>('/admin/chat/settings')
+}
+
+/**
+ * 管理员更新聊天室配置（只需传入要修改的字段）
  * @param data 聊天室配置数据
  * @returns 返回更新结果
  */
 export function updateChatSettings(data: ChatSettings) {
   return request.put('/admin/chat/settings', data)
-}
\ No newline at end of file
+}
+
+/**
+ * 私信对象资料
+ */
+export interface DirectPeer {
+  id: number
+  username: string
+  nickname: string
+  avatar: string
+}
+
+/**
+ * 私信消息接口
+ */
+export interface DirectMessage {
+  id: number
+  sender_id: number
+  receiver_id: number
+  content: string
+  is_read: boolean              // 接收方是否已读
+  read_at?: string | null
+  created_at: string
+  sender?: DirectPeer           // WebSocket 推送时附带发送者资料
+}
+
+/**
+ * 私信会话接口
+ */
+export interface DirectConversation {
+  peer_id: number
+  peer_username: string
+  peer_nickname: string
+  peer_avatar: string
+  last_message_id: number
+  last_content: string
+  last_sender_id: number
+  last_created_at: string
+  unread_count: number          // 对方发来的未读消息数
+  is_blocked: boolean           // 当前用户是否已屏蔽对方
+}
+
+/**
+ * 屏蔽的用户
+ */
+export interface BlockedUser extends DirectPeer {
+  blocked_at: string
+}
+
+/**
+ * 获取可直接联系的站长
+ * @returns 返回站长列表
+ */
+export function getDirectContacts() {
+  return request.get<DirectPeer[]>('/chat/direct/contacts')
+}
+
+/**
+ * 获取私信会话列表
+ * @param params 分页参数
+ * @returns 返回按最新消息倒序的会话列表
+ */
+export function getDirectConversations(params: PaginationParams) {
+  return request.get<PaginationResult<DirectConversation>>('/chat/direct/conversations', { params })
+}
+
+/**
+ * 获取与某个用户的私信记录
+ * @param userId 对方用户ID
+ * @param params 分页参数
+ * @returns 返回按发送时间倒序的私信记录和对方资料
+ */
+export function getDirectMessages(userId: number, params: PaginationParams) {
+  return request.get<PaginationResult<DirectMessage> & { peer: DirectPeer }>(
+    `/chat/direct/conversations/${userId}/messages`,
+    { params }
+  )
+}
+
+/**
+ * 发送私信
+ * @param userId 对方用户ID
+ * @param content 消息内容
+ * @returns 返回保存后的私信
+ */
+export function sendDirectMessage(userId: number, content: string) {
+  return request.post<DirectMessage>(`/chat/direct/conversations/${userId}/messages`, { content })
+}
+
+/**
+ * 将与某个用户的会话标记为已读
+ * @param userId 对方用户ID
+ * @returns 返回标记的条数
+ */
+export function markDirectRead(userId: number) {
+  return request.put<{ count: number }>(`/chat/direct/conversations/${userId}/read`)
+}
+
+/**
+ * 获取未读私信总数
+ * @returns 返回未读数
+ */
+export function getDirectUnreadCount() {
+  return request.get<{ count: number }>('/chat/direct/unread')
+}
+
+/**
+ * 获取屏蔽列表
+ * @returns 返回屏蔽的用户
+ */
+export function getBlockedUsers() {
+  return request.get<BlockedUser[]>('/chat/direct/blocks')
+}
+
+/**
+ * 屏蔽用户（对方将不能再给你发送私信）
+ * @param userId 用户ID
+ */
+export function blockUser(userId: number) {
+  return request.post(`/chat/direct/blocks/${userId}`)
+}
+
+/**
+ * 取消屏蔽
+ * @param userId 用户ID
+ */
+export function unblockUser(userId: number) {
+  return request.delete(`/chat/direct/blocks/${userId}`)
+}
@@ -0,0 +1,89 @@
+/*
+ * 项目名称：blog-frontend
+ * 文件名称：role.ts
+ * 创建时间：2026-10-18 02:51:26
+ *
+ * 系统用户：Administrator
+ * 作　　者：無以菱
+ * 联系邮箱：huangjing510@126.com
+ * 功能描述：角色和权限相关 API 接口定义，包括权限列表查询以及自定义角色的增删改查（需要 role.manage 权限）。
+ */
+
+import { request } from '@/utils/request'
+
+/**
+ * 权限定义
+ */
+export interface Permission {
+  name: string        // 权限标识，如 comment.moderate
+  description: string
+}
+
+/**
+ * 角色
+ */
+export interface Role {
+  id: number
+  name: string          // 角色标识，写入用户的 role 字段
+  display_name: string
+  description: string
+  is_system: boolean    // 内置角色不能删除
+  permissions: string[]
+  user_count: number
+  created_at: string
+  updated_at: string
+}
+
+/**
+ * 创建/更新角色参数
+ */
+export interface RoleForm {
+  name?: string         // 仅创建时有效，2-20 位小写字母、数字或下划线
+  display_name: string
+  description?: string
+  permissions: string[]
+}
+
+/**
+ * 获取所有可分配的权限
+ * @returns 返回权限列表
+ */
+export function getPermissions() {
+  return request.get<Permission[]>('/admin/permissions')
+}
+
+/**
+ * 获取角色列表
+ * @returns 返回角色列表（含权限和用户数）
+ */
+export function getRoles() {
+  return request.get<Role[]>('/admin/roles')
+}
+
+/**
+ * 创建自定义角色
+ * @param data 角色信息
+ * @returns 返回创建的角色
+ */
+export function createRole(data: RoleForm) {
+  return request.post<Role>('/admin/roles', data)
+}
+
+/**
+ * 更新角色名称、说明和权限
+ * @param id 角色ID
+ * @param data 角色信息
+ * @returns 返回更新后的角色
+ */
+export function updateRole(id: number, data: RoleForm) {
+  return request.put<Role>(`/admin/roles/${id}`, data)
+}
+
+/**
+ * 删除自定义角色
+ * @param id 角色ID
+ * @returns 返回删除结果
+ */
+export function deleteRole(id: number) {
+  return request.delete(`/admin/roles/${id}`)
+}
@@ -27,6 +27,8 @@ export interface SiteSettings {
   social_wechat?: string       // 微信号
   social_csdn?: string         // CSDN链接
   social_link_order?: string   // 社交链接排序顺序，逗号分隔的类型列表，如 "github,gitee,email,rss,csdn,qq,wechat"
+  disable_register?: string    // 是否关闭注册：'0' | '1'
+  register_mode?: string       // 注册方式：'open' | 'invite' | 'approval'
 }
 
 /**
@@ -10,7 +10,7 @@
  */
 
 import { request } from '@/utils/request'
-import type { User } from '@/types/auth'
+import type { User, LoginHistory, InviteCode } from '@/types/auth'
 import type { PageData } from '@/types/common'
 
 /**
@@ -18,9 +18,10 @@ import type { PageData } from '@/types/common'
  * @param params 分页参数
  * @param params.page 页码（可选）
  * @param params.page_size 每页数量（可选）
+ * @param params.status 用户状态筛选（可选，0:禁用 1:正常 2:待审核）
  * @returns 返回分页的用户列表
  */
-export function getUsers(params: { page?: number; page_size?: number }) {
+export function getUsers(params: { page?: number; page_size?: number; status?: number }) {
   return request.get<PageData<User>>('/admin/users', { params })
 }
 
@@ -44,12 +45,12 @@ export function updateUserStatus(id: number, status: number) {
 }
 
 /**
- * 更新用户角色（仅超级管理员）
+ * 更新用户角色（需要 user.manage 权限）
  * @param id 用户ID
- * @param role 用户角色（super_admin/admin/user）
+ * @param role 角色标识（内置角色 admin/user 或自定义角色）
  * @returns 返回更新结果
  */
-export function updateUserRole(id: number, role: 'super_admin' | 'admin' | 'user') {
+export function updateUserRole(id: number, role: User['role']) {
   return request.put(`/admin/users/${id}/role`, { role })
 }
 
@@ -62,20 +63,93 @@ export function deleteUser(id: number) {
   return request.delete(`/admin/users/${id}`)
 }
 
+/**
+ * 解除账号因多次登录失败导致的锁定（仅超级管理员）
+ * @param id 用户ID
+ * @returns 返回解除结果
+ */
+export function unlockUser(id: number) {
+  return request.post(`/admin/users/${id}/unlock`)
+}
+
+/**
+ * 注册审核通过，并邮件通知用户
+ * @param id 用户ID
+ * @returns 返回审核结果
+ */
+export function approveUser(id: number) {
+  return request.post(`/admin/users/${id}/approve`)
+}
+
+/**
+ * 注册审核未通过，删除该账号并邮件通知原因
+ * @param id 用户ID
+ * @param reason 未通过原因（可选）
+ * @returns 返回审核结果
+ */
+export function rejectUser(id: number, reason?: string) {
+  return request.post(`/admin/users/${id}/reject`, { reason })
+}
+
+/**
+ * 获取注册邀请码列表
+ * @returns 返回邀请码列表
+ */
+export function getInviteCodes() {
+  return request.get<InviteCode[]>('/admin/invite-codes')
+}
+
+/**
+ * 生成注册邀请码
+ * @param data.max_uses 可使用次数（可选，默认1）
+ * @param data.expire_days 有效天数（可选，不填表示长期有效）
+ * @param data.note 备注（可选）
+ * @returns 返回生成的邀请码
+ */
+export function createInviteCode(data: { max_uses?: number; expire_days?: number; note?: string }) {
+  return request.post<InviteCode>('/admin/invite-codes', data)
+}
+
+/**
+ * 删除注册邀请码
+ * @param id 邀请码ID
+ * @returns 返回删除结果
+ */
+export function deleteInviteCode(id: number) {
+  return request.delete(`/admin/invite-codes/${id}`)
+}
+
+/**
+ * 获取全部用户的登录记录（仅超级管理员）
+ * @param params 分页和筛选参数
+ * @returns 返回分页的登录记录
+ */
+export function getAllLoginHistory(params: {
+  page?: number
+  page_size?: number
+  user_id?: number
+  username?: string
+  ip?: string
+  success?: boolean
+}) {
+  return request.get<PageData<LoginHistory>>('/admin/login-history', { params })
+}
+
 /**
  * 获取注册配置（管理员）
  * @returns 返回注册配置信息
  */
 export function getRegisterSettings() {
-  return request.get<{ disable_register: string }>('/admin/settings/register')
+  return request.get<{ disable_register: string; register_mode?: string }>('/admin/settings/register')
 }
 
 /**
  * 更新注册配置（管理员）
  * @param data 注册配置数据
  * @param data.disable_register 是否禁用注册：'0'表示否，'1'表示是
+ * @param data.register_mode 注册方式：'open' 开放注册，'invite' 仅限邀请码注册，'approval' 注册后需管理员审核
  * @returns 返回更新结果
  */
-export function updateRegisterSettings(data: { disable_register: string }) {
+export function updateRegisterSettings(data: { disable_register?: string; register_mode?: string }) {
   return request.put('/admin/settings/register', data)
 }
@@ -63,12 +63,29 @@ export function setupRouterGuards(router: Router) {
       return
     }
 
+    // 旧版本缓存的用户信息不含权限列表，进入管理后台前先刷新一次
+    if (to.meta.requiresAdmin && authStore.user && !authStore.user.permissions) {
+      authStore
+        .fetchUserInfo()
+        .catch(() => {})
+        .finally(() => {
+          next(authStore.user?.permissions ? to.fullPath : { name: 'Home' })
+        })
+      return
+    }
+
     // 检查是否需要管理员权限
     if (to.meta.requiresAdmin && !authStore.isAdmin) {
       next({ name: 'Home' })
       return
     }
 
+    // 页面所需权限
+    if (to.meta.permission && !authStore.hasPermission(to.meta.permission)) {
+      next({ name: 'Home' })
+      return
+    }
+
     // 角色白名单（更细粒度控制）
     if (to.meta.roles && to.meta.roles.length > 0) {
       if (!authStore.hasRole(to.meta.roles)) {
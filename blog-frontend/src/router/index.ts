@@ -34,7 +34,11 @@ const About = () => import('@/pages/blog/About.vue')
 const Login = () => import('@/pages/auth/Login.vue')
 const Register = () => import('@/pages/auth/Register.vue')
 const Profile = () => import('@/pages/auth/Profile.vue')
+const Messages = () => import('@/pages/auth/Messages.vue')
 const ForgotPassword = () => import('@/pages/auth/ForgotPassword.vue')
+const RevertEmail = () => import('@/pages/auth/RevertEmail.vue')
+const OAuthCallback = () => import('@/pages/auth/OAuthCallback.vue')
+const MagicLogin = () => import('@/pages/auth/MagicLogin.vue')
 
 // 管理后台页面
 const Dashboard = () => import('@/pages/admin/Dashboard.vue')
@@ -149,6 +153,24 @@ const routes: RouteRecordRaw[] = [
         name: 'ForgotPassword',
         component: ForgotPassword,
         meta: { title: '找回密码' }
+      },
+      {
+        path: 'revert-email',
+        name: 'RevertEmail',
+        component: RevertEmail,
+        meta: { title: '撤销邮箱修改' }
+      },
+      {
+        path: 'oauth-callback',
+        name: 'OAuthCallback',
+        component: OAuthCallback,
+        meta: { title: '第三方登录' }
+      },
+      {
+        path: 'magic-login',
+        name: 'MagicLogin',
+        component: MagicLogin,
+        meta: { title: '邮箱链接登录' }
       }
     ]
   },
@@ -168,6 +190,21 @@ const routes: RouteRecordRaw[] = [
     ]
   },
 
+  // 私信（需要认证）
+  {
+    path: '/messages',
+    component: DefaultLayout,
+    meta: { requiresAuth: true },
+    children: [
+      {
+        path: '',
+        name: 'Messages',
+        component: Messages,
+        meta: { title: '我的私信', requiresAuth: true }
+      }
+    ]
+  },
+
   // 管理后台路由
   {
     path: '/admin',
@@ -188,19 +225,19 @@ const routes: RouteRecordRaw[] = [
         path: 'posts',
         name: 'PostManage',
         component: PostManage,
-        meta: { title: '文章管理', requiresAuth: true, requiresAdmin: true }
+        meta: { title: '文章管理', requiresAuth: true, requiresAdmin: true, permission: 'post.manage' }
       },
       {
         path: 'posts/edit/:id',
         name: 'PostEdit',
         component: PostEdit,
-        meta: { title: '编辑文章', requiresAuth: true, requiresAdmin: true }
+        meta: { title: '编辑文章', requiresAuth: true, requiresAdmin: true, permission: 'post.manage' }
       },
       {
         path: 'categories',
         name: 'CategoryManage',
         component: CategoryManage,
-        meta: { title: '分类管理', requiresAuth: true, requiresAdmin: true }
+        meta: { title: '分类管理', requiresAuth: true, requiresAdmin: true, permission: 'category.manage' }
       },
       {
         path: 'tags',
@@ -212,61 +249,61 @@ const routes: RouteRecordRaw[] = [
         path: 'comments',
         name: 'CommentManage',
         component: CommentManage,
-        meta: { title: '评论管理', requiresAuth: true, requiresAdmin: true }
+        meta: { title: '评论管理', requiresAuth: true, requiresAdmin: true, permission: 'comment.moderate' }
       },
       {
         path: 'users',
         name: 'UserManage',
         component: UserManage,
-        meta: { title: '用户管理', requiresAuth: true, requiresAdmin: true, roles: ['super_admin'] }
+        meta: { title: '用户管理', requiresAuth: true, requiresAdmin: true, permission: 'user.manage' }
       },
       {
         path: 'site',
         name: 'SiteSettings',
         component: SiteSettings,
-        meta: { title: '网站设置', requiresAuth: true, requiresAdmin: true, roles: ['super_admin'] }
+        meta: { title: '网站设置', requiresAuth: true, requiresAdmin: true, permission: 'settings.manage' }
       },
       {
         path: 'moments',
         name: 'MomentManage',
         component: MomentManage,
-        meta: { title: '说说管理', requiresAuth: true, requiresAdmin: true }
+        meta: { title: '说说管理', requiresAuth: true, requiresAdmin: true, permission: 'moment.manage' }
       },
       {
         path: 'chat',
         name: 'ChatManage',
         component: ChatManage,
-        meta: { title: '聊天室管理', requiresAuth: true, requiresAdmin: true }
+        meta: { title: '聊天室管理', requiresAuth: true, requiresAdmin: true, permission: 'chat.manage' }
       },
       {
         path: 'ip-access-control',
         name: 'IPAccessControl',
         component: IPAccessControl,
-        meta: { title: 'IP访问控制', requiresAuth: true, requiresAdmin: true }
+        meta: { title: 'IP访问控制', requiresAuth: true, requiresAdmin: true, permission: 'ip.manage' }
       },
       {
         path: 'friend-links',
         name: 'FriendLinkManage',
         component: FriendLinkManage,
-        meta: { title: '友链管理', requiresAuth: true, requiresAdmin: true, roles: ['super_admin'] }
+        meta: { title: '友链管理', requiresAuth: true, requiresAdmin: true, permission: 'friendlink.manage' }
       },
       {
         path: 'about',
         name: 'AboutManage',
         component: AboutManage,
-        meta: { title: '关于我管理', requiresAuth: true, requiresAdmin: true, roles: ['super_admin'] }
+        meta: { title: '关于我管理', requiresAuth: true, requiresAdmin: true, permission: 'settings.manage' }
       },
       {
         path: 'operation-logs',
         name: 'OperationLogManage',
         component: OperationLogManage,
-        meta: { title: '操作日志', requiresAuth: true, requiresAdmin: true, roles: ['super_admin'] }
+        meta: { title: '操作日志', requiresAuth: true, requiresAdmin: true, permission: 'log.view' }
       },
       {
         path: 'album',
         name: 'AlbumManage',
         component: () => import('@/pages/admin/AlbumManage.vue'),
-        meta: { title: '我的相册', requiresAuth: true, requiresAdmin: true, roles: ['super_admin'] }
+        meta: { title: '我的相册', requiresAuth: true, requiresAdmin: true, permission: 'album.manage' }
       }
     ]
   },
@@ -12,20 +12,29 @@
 
 import { defineStore } from 'pinia'
 import { ref, computed } from 'vue'
-import type { User, LoginForm, RegisterForm } from '@/types/auth'
-import { login as loginApi, register as registerApi, getProfile, logout as logoutApi } from '@/api/auth'
+import type { User, LoginForm, RegisterForm, LoginResponse } from '@/types/auth'
+import { getPasskeyAssertion } from '@/utils/webauthn'
+import { login as loginApi, loginTwoFactor as loginTwoFactorApi, exchangeOAuthTicket, magicLinkLogin, beginPasskeyLogin, finishPasskeyLogin, register as registerApi, getProfile, logout as logoutApi } from '@/api/auth'
 
 export const useAuthStore = defineStore(
   'auth',
   () => {
     // 状态
     const token = ref<string | null>(null)
+    const refreshToken = ref<string | null>(null)
     const user = ref<User | null>(null)
 
     // 计算属性
     const isLoggedIn = computed(() => !!token.value)
     const isSuperAdmin = computed(() => user.value?.role === 'super_admin')
-    const isAdmin = computed(() => user.value?.role === 'admin' || user.value?.role === 'super_admin')
+    // 可以进入管理后台（具体功能再按权限判断）
+    const isAdmin = computed(() => hasPermission('admin.access'))
+
+    // 判断当前用户是否拥有指定权限（super_admin 始终拥有全部权限）
+    function hasPermission(permission: string): boolean {
+      if (user.value?.role === 'super_admin') return true
+      return user.value?.permissions?.includes(permission) ?? false
+    }
 
     function hasRole(roles: User['role'][]): boolean {
       const r = user.value?.role
@@ -33,17 +42,79 @@ export const useAuthStore = defineStore(
       return roles.includes(r)
     }
 
+    // 后端在角色没有任何权限时省略 permissions 字段，这里统一补成空数组
+    function withPermissions(u: User): User {
+      return { ...u, permissions: u.permissions ?? [] }
+    }
+
     // 登录
     async function login(form: LoginForm) {
       const res = await loginApi(form)
-      console.log(res)
+      // 已启用两步验证时只返回挑战令牌，由页面继续提交验证码
+      if (res.data && !res.data.two_factor_required) {
+        token.value = res.data.token
+        refreshToken.value = res.data.refresh_token
+        user.value = withPermissions(res.data.user)
+      }
+      return res
+    }
+
+    // 登录第二步：提交两步验证码
+    async function loginTwoFactor(challengeToken: string, code: string) {
+      const res = await loginTwoFactorApi({ challenge_token: challengeToken, code })
       if (res.data) {
         token.value = res.data.token
-        user.value = res.data.user
+        refreshToken.value = res.data.refresh_token
+        user.value = withPermissions(res.data.user)
       }
       return res
     }
 
+    // 保存登录结果（已启用两步验证时只返回挑战令牌，由页面继续提交验证码）
+    function applyLogin(data?: LoginResponse) {
+      if (data && !data.two_factor_required) {
+        token.value = data.token
+        refreshToken.value = data.refresh_token
+        user.value = withPermissions(data.user)
+      }
+    }
+
+    // 第三方登录：使用回调页拿到的一次性票据换取令牌
+    async function loginWithOAuthTicket(ticket: string) {
+      const res = await exchangeOAuthTicket(ticket)
+      applyLogin(res.data)
+      return res
+    }
+
+    // 免密登录：使用邮件中的登录链接
+    async function loginWithMagicLink(linkToken: string) {
+      const res = await magicLinkLogin(linkToken)
+      applyLogin(res.data)
+      return res
+    }
+
+    // 通行密钥登录：获取挑战值，由浏览器调用认证器签名后提交
+    async function loginWithPasskey() {
+      const options = await beginPasskeyLogin()
+      const credential = await getPasskeyAssertion(options.data!)
+      const res = await finishPasskeyLogin(credential)
+      applyLogin(res.data)
+      return res
+    }
+
+    // 刷新令牌轮换后更新本地令牌
+    function setTokens(accessToken: string, newRefreshToken: string) {
+      token.value = accessToken
+      refreshToken.value = newRefreshToken
+    }
+
+    // 清除本地登录状态（不请求后端）
+    function clear() {
+      token.value = null
+      refreshToken.value = null
+      user.value = null
+    }
+
     // 注册
     async function register(form: RegisterForm) {
       const res = await registerApi(form)
@@ -55,8 +126,7 @@ export const useAuthStore = defineStore(
       try {
         await logoutApi()
       } finally {
-        token.value = null
-        user.value = null
+        clear()
       }
     }
 
@@ -64,21 +134,29 @@ export const useAuthStore = defineStore(
     async function fetchUserInfo() {
       const res = await getProfile()
       if (res.data) {
-        user.value = res.data
+        user.value = withPermissions(res.data)
       }
       return res
     }
 
     return {
       token,
+      refreshToken,
       user,
       isLoggedIn,
       isSuperAdmin,
       isAdmin,
       hasRole,
+      hasPermission,
       login,
+      loginTwoFactor,
+      loginWithOAuthTicket,
+      loginWithMagicLink,
+      loginWithPasskey,
       register,
       logout,
+      setTokens,
+      clear,
       fetchUserInfo
     }
   },
@@ -87,7 +165,7 @@ export const useAuthStore = defineStore(
     persist: {
       key: 'blog-auth',
       storage: localStorage,
-      pick: ['token', 'user']
+      pick: ['token', 'refreshToken', 'user']
     }
   }
 )
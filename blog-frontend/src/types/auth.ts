@@ -18,7 +18,8 @@ export interface User {
   nickname: string
   avatar: string
   bio: string
-  role: 'super_admin' | 'admin' | 'user'
+  role: 'super_admin' | 'admin' | 'user' | (string & {}) // 内置角色或自定义角色标识
+  permissions?: string[] // 当前角色拥有的权限（登录和获取个人信息时返回）
   status: number
   created_at: string
   updated_at: string
@@ -46,12 +47,19 @@ export interface RegisterForm {
   password: string
   confirmPassword: string
   code?: string
+  invite_code?: string
 }
 
 // 登录响应
 export interface LoginResponse {
   token: string
+  expires_in: number          // 访问令牌有效期（秒）
+  refresh_token: string       // 刷新令牌（只能使用一次）
+  refresh_expires_at: string  // 刷新令牌过期时间
   user: User
+  two_factor_required?: boolean        // 需要提交两步验证码（此时只返回 challenge_token）
+  challenge_token?: string             // 两步验证挑战令牌（5分钟内有效）
+  two_factor_setup_required?: boolean  // 站点要求管理员启用两步验证但尚未启用
 }
 
 // 个人资料表单
@@ -68,3 +76,97 @@ export interface PasswordForm {
   confirm_password?: string
 }
 
+// 登录记录
+export interface LoginHistory {
+  id: number
+  user_id: number | null  // 用户名不存在时为空
+  username: string
+  success: boolean
+  reason: '' | 'password' | 'locked' | 'disabled' | 'two_factor'  // 失败原因
+  ip: string
+  user_agent: string
+  created_at: string
+}
+
+// 个人访问令牌
+export interface AccessToken {
+  id: number
+  user_id: number
+  name: string
+  token_hint: string          // 令牌末尾几位，如 pat_...a1b2
+  scopes: string[]            // 权限范围：posts:write、moments:write、upload
+  expires_at: string
+  last_used_at: string | null
+  last_used_ip: string
+  revoked_at: string | null
+  created_at: string
+  token?: string              // 明文令牌，只在创建时返回
+}
+
+// 第三方登录方式
+export interface OAuthProvider {
+  name: string           // github、gitee
+  display_name: string
+}
+
+// 第三方账号绑定状态
+export interface OAuthBinding {
+  provider: string
+  display_name: string
+  bound: boolean
+  provider_username?: string
+  avatar?: string
+  bound_at?: string
+}
+
+// 通行密钥
+export interface Passkey {
+  id: number
+  user_id: number
+  name: string
+  algorithm: number
+  transports: string
+  last_used_at: string | null
+  last_used_ip: string
+  created_at: string
+}
+
+// 账号注销申请
+export interface AccountDeletion {
+  user_id: number
+  reason: string
+  requested_at: string
+  scheduled_at: string
+}
+
+// 注册邀请码
+export interface InviteCode {
+  id: number
+  code: string
+  max_uses: number
+  used_count: number
+  expire_at?: string | null
+  note: string
+  created_by?: number | null
+  created_at: string
+}
+
+// 通行密钥注册选项（对应 PublicKeyCredentialCreationOptions，二进制字段为 base64url）
+export interface PasskeyRegistrationOptions {
+  challenge: string
+  rp: { id: string; name: string }
+  user: { id: string; name: string; displayName: string }
+  pubKeyCredParams: { type: 'public-key'; alg: number }[]
+  timeout: number
+  attestation: AttestationConveyancePreference
+  excludeCredentials: { type: 'public-key'; id: string; transports?: string[] }[]
+  authenticatorSelection: AuthenticatorSelectionCriteria
+}
+
+// 通行密钥登录选项（对应 PublicKeyCredentialRequestOptions）
+export interface PasskeyLoginOptions {
+  challenge: string
+  rpId: string
+  timeout: number
+  userVerification: UserVerificationRequirement
+}
@@ -16,6 +16,7 @@ declare module 'vue-router' {
     requiresAuth?: boolean
     requiresAdmin?: boolean
     roles?: Array<'super_admin' | 'admin' | 'user'>
+    permission?: string // 访问该页面所需的权限（如 user.manage）
   }
 }
 
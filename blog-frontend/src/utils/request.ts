@@ -23,6 +23,47 @@ const service: AxiosInstance = axios.create({
   }
 })
 
+// 正在进行的刷新请求（多个请求同时 401 时共用一次刷新）
+let refreshing: Promise<boolean> | null = null
+
+/**
+ * 使用刷新令牌换取新的访问令牌
+ * 刷新令牌只能使用一次，因此并发的 401 请求共用同一次刷新
+ * @returns 是否刷新成功
+ */
+function tryRefreshToken(): Promise<boolean> {
+  const authStore = useAuthStore()
+  if (!authStore.refreshToken) {
+    return Promise.resolve(false)
+  }
+  if (!refreshing) {
+    refreshing = axios
+      .post<ApiResponse<{ token: string; refresh_token: string }>>('/api/auth/refresh', {
+        refresh_token: authStore.refreshToken
+      })
+      .then(({ data }) => {
+        if (data.code !== 200 || !data.data) return false
+        authStore.setTokens(data.data.token, data.data.refresh_token)
+        return true
+      })
+      .catch(() => false)
+      .finally(() => {
+        refreshing = null
+      })
+  }
+  return refreshing
+}
+
+// 不触发令牌刷新的接口（登录、刷新、登出本身）
+const noRefreshUrls = ['/auth/login', '/auth/refresh', '/auth/logout']
+
+// 登录失效：清除本地状态并跳转登录页
+function redirectToLogin() {
+  const authStore = useAuthStore()
+  authStore.clear()
+  window.location.href = '/login'
+}
+
 // 请求拦截器
 service.interceptors.request.use(
   (config) => {
@@ -53,9 +94,7 @@ service.interceptors.response.use(
     if (res.code !== 200) {
       // 401: 未授权
       if (res.code === 401) {
-        const authStore = useAuthStore()
-        authStore.logout()
-        window.location.href = '/login'
+        redirectToLogin()
       }
 
       return Promise.reject(new Error(res.message || 'Error'))
@@ -63,9 +102,18 @@ service.interceptors.response.use(
 
     return res
   },
-  (error): any => {
+  async (error): Promise<any> => {
     console.error('Response error:', error)
 
+    // 访问令牌过期：尝试刷新一次后重发原请求
+    const original = error.config
+    if (error.response?.status === 401 && original && !original._retry && !noRefreshUrls.includes(original.url)) {
+      original._retry = true
+      if (await tryRefreshToken()) {
+        return service(original)
+      }
+    }
+
     if (error.response) {
       const { status, data } = error.response
       
@@ -79,9 +127,7 @@ service.interceptors.response.use(
           }
           break
         case 401:
-          const authStore = useAuthStore()
-          authStore.logout()
-          window.location.href = '/login'
+          redirectToLogin()
           error.message = '登录已过期，请重新登录'
           break
         case 403:
@@ -0,0 +1,101 @@
+/*
+ * @ProjectName: go-vue3-blog
+ * @FileName: webauthn.ts
+ * @CreateTime: 2026-10-18 04:31:17
+ * @SystemUser: Administrator
+ * @Author: 無以菱
+ * @Contact: huangjing510@126.com
+ * @Description: 通行密钥（WebAuthn）工具，负责后端选项（base64url）与浏览器凭据接口之间的转换
+ */
+
+import type { PasskeyRegistrationOptions, PasskeyLoginOptions } from '@/types/auth'
+
+/**
+ * 当前浏览器是否支持通行密钥
+ */
+export function isPasskeySupported(): boolean {
+  return typeof window !== 'undefined' && !!window.PublicKeyCredential && !!navigator.credentials
+}
+
+function base64urlToBuffer(value: string): ArrayBuffer {
+  const base64 = value.replace(/-/g, '+').replace(/_/g, '/')
+  const padded = base64 + '='.repeat((4 - (base64.length % 4)) % 4)
+  const binary = atob(padded)
+  const bytes = new Uint8Array(binary.length)
+  for (let i = 0; i < binary.length; i++) {
+    bytes[i] = binary.charCodeAt(i)
+  }
+  return bytes.buffer
+}
+
+function bufferToBase64url(buffer: ArrayBuffer): string {
+  const bytes = new Uint8Array(buffer)
+  let binary = ''
+  for (let i = 0; i < bytes.length; i++) {
+    binary += String.fromCharCode(bytes[i])
+  }
+  return btoa(binary).replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '')
+}
+
+/**
+ * 调用浏览器创建通行密钥
+ * @param options 后端返回的注册选项
+ * @returns 可直接提交给后端的凭据（二进制字段为 base64url）
+ */
+export async function createPasskey(options: PasskeyRegistrationOptions) {
+  const credential = (await navigator.credentials.create({
+    publicKey: {
+      ...options,
+      challenge: base64urlToBuffer(options.challenge),
+      user: { ...options.user, id: base64urlToBuffer(options.user.id) },
+      excludeCredentials: options.excludeCredentials.map((c) => ({
+        type: c.type,
+        id: base64urlToBuffer(c.id),
+        transports: c.transports as AuthenticatorTransport[] | undefined
+      }))
+    }
+  })) as PublicKeyCredential | null
+  if (!credential) {
+    throw new Error('已取消')
+  }
+
+  const response = credential.response as AuthenticatorAttestationResponse
+  return {
+    id: credential.id,
+    type: credential.type,
+    response: {
+      clientDataJSON: bufferToBase64url(response.clientDataJSON),
+      attestationObject: bufferToBase64url(response.attestationObject),
+      transports: response.getTransports?.() ?? []
+    }
+  }
+}
+
+/**
+ * 调用浏览器使用通行密钥签名
+ * @param options 后端返回的登录选项
+ * @returns 可直接提交给后端的登录断言（二进制字段为 base64url）
+ */
+export async function getPasskeyAssertion(options: PasskeyLoginOptions) {
+  const credential = (await navigator.credentials.get({
+    publicKey: {
+      ...options,
+      challenge: base64urlToBuffer(options.challenge)
+    }
+  })) as PublicKeyCredential | null
+  if (!credential) {
+    throw new Error('已取消')
+  }
+
+  const response = credential.response as AuthenticatorAssertionResponse
+  return {
+    id: credential.id,
+    type: credential.type,
+    response: {
+      clientDataJSON: bufferToBase64url(response.clientDataJSON),
+      authenticatorData: bufferToBase64url(response.authenticatorData),
+      signature: bufferToBase64url(response.signature),
+      userHandle: response.userHandle ? bufferToBase64url(response.userHandle) : ''
+    }
+  }
+}
@@ -12,7 +12,7 @@
 
 import type { WebSocketMessage } from '@/api/chat'
 
-export type WebSocketEventCallback = (data: any) => void
+export type WebSocketEventCallback = (data: any, message?: WebSocketMessage) => void
 
 export class ChatWebSocket {
   private ws: WebSocket | null = null
@@ -95,16 +95,36 @@ export class ChatWebSocket {
     }
   }
 
-  // 发送聊天消息
-  sendMessage(content: string) {
-    this.send('message', { content })
+  // 发送聊天消息（未指定聊天室时发送到公共大厅）
+  sendMessage(content: string, roomId?: number) {
+    this.send('message', roomId ? { content, room_id: roomId } : { content })
+  }
+
+  // 进入聊天室
+  joinRoom(roomId: number) {
+    this.send('join', { room_id: roomId })
+  }
+
+  // 离开聊天室
+  leaveRoom(roomId: number) {
+    this.send('leave', { room_id: roomId })
+  }
+
+  // 撤回消息（发送者在时限内撤回自己的消息，管理员可撤回任意消息）
+  recallMessage(messageId: number, roomId?: number) {
+    this.send('recall', { message_id: messageId, room_id: roomId })
+  }
+
+  // 编辑自己发送的消息
+  editMessage(messageId: number, content: string, roomId?: number) {
+    this.send('edit', { message_id: messageId, content, room_id: roomId })
   }
 
   // 处理接收到的消息
   private handleMessage(message: WebSocketMessage) {
     const { type, data } = message
-    // 触发特定类型的事件
-    this.emit(type, data)
+    // 触发特定类型的事件（第二个参数为完整消息，便于读取 room_id）
+    this.emit(type, data, message)
     // 触发通用事件（使用不同的事件名，避免和type='message'冲突）
     this.emit('ws:message', message)
   }
@@ -133,10 +153,10 @@ export class ChatWebSocket {
   }
 
   // 触发事件
-  private emit(event: string, data: any) {
+  private emit(event: string, data: any, message?: WebSocketMessage) {
     const handlers = this.eventHandlers.get(event)
     if (handlers) {
-      handlers.forEach(callback => callback(data))
+      handlers.forEach(callback => callback(data, message))
     }
   }
 